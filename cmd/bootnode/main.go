@@ -119,7 +119,7 @@ func main() {
 	}
 
 	if *runv5 {
-		if _, err := discv5.ListenUDP(nodeKey, conn, "", restrictList); err != nil {
+		if _, err := discv5.ListenUDP(nodeKey, conn, "", restrictList, nil); err != nil {
 			utils.Fatalf("%v", err)
 		}
 	} else {
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"runtime"
 	"strconv"
 	"sync/atomic"
@@ -30,6 +31,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/console"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/eth/downloader"
@@ -37,7 +39,6 @@ import (
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/trie"
-	"github.com/syndtr/goleveldb/leveldb/util"
 	"gopkg.in/urfave/cli.v1"
 )
 
@@ -65,11 +66,14 @@ It expects the genesis file as argument.`,
 		ArgsUsage: "<filename> (<filename 2> ... <filename N>) ",
 		Flags: []cli.Flag{
 			utils.DataDirFlag,
+			utils.AncientFlag,
 			utils.CacheFlag,
 			utils.SyncModeFlag,
 			utils.GCModeFlag,
 			utils.CacheDatabaseFlag,
 			utils.CacheGCFlag,
+			utils.ParallelTxsFlag,
+			utils.StateDiffsFlag,
 		},
 		Category: "BLOCKCHAIN COMMANDS",
 		Description: `
@@ -86,6 +90,7 @@ processing will proceed even if an individual RLP-file import failure occurs.`,
 		ArgsUsage: "<filename> [<blockNumFirst> <blockNumLast>]",
 		Flags: []cli.Flag{
 			utils.DataDirFlag,
+			utils.AncientFlag,
 			utils.CacheFlag,
 			utils.SyncModeFlag,
 		},
@@ -129,18 +134,30 @@ The export-preimages command export hash preimages to an RLP encoded stream`,
 		Action:    utils.MigrateFlags(copyDb),
 		Name:      "copydb",
 		Usage:     "Create a local chain from a target chaindata folder",
-		ArgsUsage: "<sourceChaindataDir>",
+		ArgsUsage: "<sourceChaindataDir> [<sourceAncientDir>]",
 		Flags: []cli.Flag{
 			utils.DataDirFlag,
+			utils.AncientFlag,
 			utils.CacheFlag,
 			utils.SyncModeFlag,
 			utils.FakePoWFlag,
 			utils.TestnetFlag,
 			utils.RinkebyFlag,
+			utils.DBEngineFlag,
+			utils.CopyDBRawFlag,
 		},
 		Category: "BLOCKCHAIN COMMANDS",
 		Description: `
-The first argument must be the directory containing the blockchain to download from`,
+The first argument must be the directory containing the blockchain to download from.
+The source database may use any engine; the local chain is created with the
+engine selected by --db.engine. The optional second argument is the ancient
+store of the source, by default the "ancient" directory inside it.
+
+With --copydb.raw every database entry, including the private state, is copied
+as is instead of syncing the chain. Use it to migrate a database to another
+engine. The local chain database must be empty. Blocks of the source's ancient
+store are written to the local key-value store, the node moves them into its
+own ancient store again if --freezer.threshold is set.`,
 	}
 	removedbCommand = cli.Command{
 		Action:    utils.MigrateFlags(removeDB),
@@ -153,6 +170,25 @@ The first argument must be the directory containing the blockchain to download f
 		Category: "BLOCKCHAIN COMMANDS",
 		Description: `
 Remove blockchain and state databases`,
+	}
+	prunePrivateStateCommand = cli.Command{
+		Action:    utils.MigrateFlags(prunePrivateState),
+		Name:      "prune-private-state",
+		Usage:     "Remove historical private state from the database",
+		ArgsUsage: " ",
+		Flags: []cli.Flag{
+			utils.DataDirFlag,
+			utils.CacheFlag,
+			utils.PrivateStateRetentionFlag,
+		},
+		Category: "BLOCKCHAIN COMMANDS",
+		Description: `
+The prune-private-state command deletes the private state tries of all but the
+most recent --privatestate.retention blocks of the canonical chain. Trie nodes
+and contract code still referenced by a public state or a retained private state
+are kept. The reachable entries are tracked in bloom filters occupying --cache
+megabytes, after which the database is scanned once. The node must be stopped
+while pruning.`,
 	}
 	dumpCommand = cli.Command{
 		Action:    utils.MigrateFlags(dump),
@@ -161,13 +197,45 @@ Remove blockchain and state databases`,
 		ArgsUsage: "[<blockHash> | <blockNum>]...",
 		Flags: []cli.Flag{
 			utils.DataDirFlag,
+			utils.AncientFlag,
 			utils.CacheFlag,
 			utils.SyncModeFlag,
+			utils.PrivateStateFlag,
+			utils.DumpRLPFlag,
 		},
 		Category: "BLOCKCHAIN COMMANDS",
 		Description: `
 The arguments are interpreted as block numbers or hashes.
-Use "ethereum dump 0" to dump the genesis block.`,
+Use "ethereum dump 0" to dump the genesis block.
+
+With --private the private state of the block is dumped instead of its public
+state. With --dump.rlp the state of a single block is written to stdout as an
+RLP stream for import-state, instead of JSON.`,
+	}
+	importStateCommand = cli.Command{
+		Action:    utils.MigrateFlags(importState),
+		Name:      "import-state",
+		Usage:     "Import the state of a block from a dump",
+		ArgsUsage: "<dumpFile> <blockHash | blockNum>",
+		Flags: []cli.Flag{
+			utils.DataDirFlag,
+			utils.CacheFlag,
+			utils.SyncModeFlag,
+			utils.PrivateStateFlag,
+		},
+		Category: "BLOCKCHAIN COMMANDS",
+		Description: `
+The import-state command reads a state dump written by "geth dump", as JSON or
+as an RLP stream (gzipped if the file ends in .gz), and writes it to the
+database as the state of the given block. The imported state must match the
+root recorded in the dump.
+
+Without --private the dump must be the public state of the block. With
+--private it becomes the private state of the block; if the chain head is past
+the block, it is rewound so that later blocks are executed again on top of the
+imported private state. The public state of the block must be present, so on a
+node garbage collecting its state the block must be recent enough or its public
+state imported first.`,
 	}
 )
 
@@ -268,19 +336,15 @@ func importChain(ctx *cli.Context) error {
 	fmt.Printf("Import done in %v.\n\n", time.Since(start))
 
 	// Output pre-compaction stats mostly to see the import trashing
-	db := chainDb.(*ethdb.LDBDatabase)
-
-	stats, err := db.LDB().GetProperty("leveldb.stats")
+	stats, err := chainDb.Stat("stats")
 	if err != nil {
 		utils.Fatalf("Failed to read database stats: %v", err)
 	}
 	fmt.Println(stats)
 
-	ioStats, err := db.LDB().GetProperty("leveldb.iostats")
-	if err != nil {
-		utils.Fatalf("Failed to read database iostats: %v", err)
+	if ioStats, err := chainDb.Stat("iostats"); err == nil {
+		fmt.Println(ioStats)
 	}
-	fmt.Println(ioStats)
 
 	fmt.Printf("Trie cache misses:  %d\n", trie.CacheMisses())
 	fmt.Printf("Trie cache unloads: %d\n\n", trie.CacheUnloads())
@@ -301,22 +365,20 @@ func importChain(ctx *cli.Context) error {
 	// Compact the entire database to more accurately measure disk io and print the stats
 	start = time.Now()
 	fmt.Println("Compacting entire database...")
-	if err = db.LDB().CompactRange(util.Range{}); err != nil {
+	if err = chainDb.Compact(nil, nil); err != nil {
 		utils.Fatalf("Compaction failed: %v", err)
 	}
 	fmt.Printf("Compaction done in %v.\n\n", time.Since(start))
 
-	stats, err = db.LDB().GetProperty("leveldb.stats")
+	stats, err = chainDb.Stat("stats")
 	if err != nil {
 		utils.Fatalf("Failed to read database stats: %v", err)
 	}
 	fmt.Println(stats)
 
-	ioStats, err = db.LDB().GetProperty("leveldb.iostats")
-	if err != nil {
-		utils.Fatalf("Failed to read database iostats: %v", err)
+	if ioStats, err := chainDb.Stat("iostats"); err == nil {
+		fmt.Println(ioStats)
 	}
-	fmt.Println(ioStats)
 
 	return nil
 }
@@ -359,7 +421,7 @@ func importPreimages(ctx *cli.Context) error {
 		utils.Fatalf("This command requires an argument.")
 	}
 	stack := makeFullNode(ctx)
-	diskdb := utils.MakeChainDatabase(ctx, stack).(*ethdb.LDBDatabase)
+	diskdb := utils.MakeChainDatabase(ctx, stack)
 
 	start := time.Now()
 	if err := utils.ImportPreimages(diskdb, ctx.Args().First()); err != nil {
@@ -375,7 +437,7 @@ func exportPreimages(ctx *cli.Context) error {
 		utils.Fatalf("This command requires an argument.")
 	}
 	stack := makeFullNode(ctx)
-	diskdb := utils.MakeChainDatabase(ctx, stack).(*ethdb.LDBDatabase)
+	diskdb := utils.MakeChainDatabase(ctx, stack)
 
 	start := time.Now()
 	if err := utils.ExportPreimages(diskdb, ctx.Args().First()); err != nil {
@@ -387,9 +449,33 @@ func exportPreimages(ctx *cli.Context) error {
 
 func copyDb(ctx *cli.Context) error {
 	// Ensure we have a source chain directory to copy
-	if len(ctx.Args()) != 1 {
+	if len(ctx.Args()) < 1 || len(ctx.Args()) > 2 {
 		utils.Fatalf("Source chaindata directory path argument missing")
 	}
+	// Open the source database with whichever engine created it
+	db, err := ethdb.Open("", ctx.Args().First(), ctx.GlobalInt(utils.CacheFlag.Name), 256)
+	if err != nil {
+		return err
+	}
+	defer func() { db.Close() }()
+
+	// Serve the frozen blocks of the source too, if it has any
+	ancient := ctx.Args().Get(1)
+	if ancient == "" {
+		ancient = filepath.Join(ctx.Args().First(), "ancient")
+	} else if !common.FileExist(ancient) {
+		utils.Fatalf("Source ancient directory %s doesn't exist", ancient)
+	}
+	if common.FileExist(ancient) {
+		frdb, err := rawdb.NewDatabaseWithFreezer(db, ancient)
+		if err != nil {
+			return err
+		}
+		db = frdb
+	}
+	if ctx.GlobalBool(utils.CopyDBRawFlag.Name) {
+		return copyDbRaw(ctx, db)
+	}
 	// Initialize a new chain for the running node to sync into
 	stack := makeFullNode(ctx)
 	chain, chainDb := utils.MakeChain(ctx, stack)
@@ -398,10 +484,6 @@ func copyDb(ctx *cli.Context) error {
 	dl := downloader.New(syncmode, chainDb, new(event.TypeMux), chain, nil, nil)
 
 	// Create a source peer to satisfy downloader requests from
-	db, err := ethdb.NewLDBDatabase(ctx.Args().First(), ctx.GlobalInt(utils.CacheFlag.Name), 256)
-	if err != nil {
-		return err
-	}
 	hc, err := core.NewHeaderChain(db, chain.Config(), chain.Engine(), func() bool { return false })
 	if err != nil {
 		return err
@@ -425,7 +507,7 @@ func copyDb(ctx *cli.Context) error {
 	// Compact the entire database to remove any sync overhead
 	start = time.Now()
 	fmt.Println("Compacting entire database...")
-	if err = chainDb.(*ethdb.LDBDatabase).LDB().CompactRange(util.Range{}); err != nil {
+	if err = chainDb.Compact(nil, nil); err != nil {
 		utils.Fatalf("Compaction failed: %v", err)
 	}
 	fmt.Printf("Compaction done in %v.\n\n", time.Since(start))
@@ -433,6 +515,33 @@ func copyDb(ctx *cli.Context) error {
 	return nil
 }
 
+// copyDbRaw copies every entry of the source database into the local chain
+// database, which may use a different engine.
+func copyDbRaw(ctx *cli.Context, src ethdb.Database) error {
+	stack, _ := makeConfigNode(ctx)
+	chainDb := utils.MakeChainDatabase(ctx, stack)
+	defer chainDb.Close()
+
+	it := chainDb.NewIteratorWithPrefix(nil)
+	empty := !it.Next()
+	it.Release()
+	if ancient, ok := chainDb.(rawdb.AncientReader); !empty || (ok && ancient.Ancients() > 0) {
+		utils.Fatalf("Local chain database is not empty, remove it first")
+	}
+	start := time.Now()
+	copied, err := ethdb.Copy(chainDb, src)
+	if err != nil {
+		utils.Fatalf("Copy failed after %d entries: %v", copied, err)
+	}
+	// Frozen blocks go into the key-value store, the node freezes them again
+	frozen, err := rawdb.CopyAncients(chainDb, src)
+	if err != nil {
+		utils.Fatalf("Copy of ancient blocks failed after %d blocks: %v", frozen, err)
+	}
+	fmt.Printf("Copied %d entries and %d ancient blocks in %v\n", copied, frozen, time.Since(start))
+	return nil
+}
+
 func removeDB(ctx *cli.Context) error {
 	stack, _ := makeConfigNode(ctx)
 
@@ -462,32 +571,111 @@ func removeDB(ctx *cli.Context) error {
 	return nil
 }
 
+// prunePrivateState deletes historical private state tries from the database.
+func prunePrivateState(ctx *cli.Context) error {
+	retention := ctx.GlobalUint64(utils.PrivateStateRetentionFlag.Name)
+	if retention == 0 {
+		utils.Fatalf("Private state retention must be at least one block")
+	}
+	stack, _ := makeConfigNode(ctx)
+	chainDb := utils.MakeChainDatabase(ctx, stack)
+	defer chainDb.Close()
+
+	start := time.Now()
+	deleted, err := core.PrunePrivateState(chainDb, retention, ctx.GlobalInt(utils.CacheFlag.Name))
+	if err != nil {
+		utils.Fatalf("Pruning error: %v", err)
+	}
+	fmt.Printf("Pruned %d private state entries in %v\n", deleted, time.Since(start))
+	return nil
+}
+
 func dump(ctx *cli.Context) error {
+	dumpRLP := ctx.GlobalBool(utils.DumpRLPFlag.Name)
+	if dumpRLP && len(ctx.Args()) != 1 {
+		utils.Fatalf("RLP dumps require exactly one block argument.")
+	}
 	stack := makeFullNode(ctx)
 	chain, chainDb := utils.MakeChain(ctx, stack)
 	for _, arg := range ctx.Args() {
-		var block *types.Block
-		if hashish(arg) {
-			block = chain.GetBlockByHash(common.HexToHash(arg))
-		} else {
-			num, _ := strconv.Atoi(arg)
-			block = chain.GetBlockByNumber(uint64(num))
-		}
+		block := blockByArg(chain, arg)
 		if block == nil {
 			fmt.Println("{}")
 			utils.Fatalf("block not found")
 		} else {
-			state, err := state.New(block.Root(), state.NewDatabase(chainDb))
+			root := block.Root()
+			if ctx.GlobalBool(utils.PrivateStateFlag.Name) {
+				root = core.GetPrivateStateRoot(chainDb, block.Root())
+			}
+			state, err := state.New(root, state.NewDatabase(chainDb))
 			if err != nil {
 				utils.Fatalf("could not create new state: %v", err)
 			}
-			fmt.Printf("%s\n", state.Dump())
+			if dumpRLP {
+				if err := state.DumpRLP(os.Stdout); err != nil {
+					utils.Fatalf("Dump error: %v", err)
+				}
+			} else {
+				fmt.Printf("%s\n", state.Dump())
+			}
 		}
 	}
 	chainDb.Close()
 	return nil
 }
 
+// importState writes a dumped public or private state as the state of a block.
+func importState(ctx *cli.Context) error {
+	if len(ctx.Args()) != 2 {
+		utils.Fatalf("This command requires two arguments.")
+	}
+	stack := makeFullNode(ctx)
+	chain, chainDb := utils.MakeChain(ctx, stack)
+	defer chainDb.Close()
+
+	block := blockByArg(chain, ctx.Args().Get(1))
+	if block == nil {
+		utils.Fatalf("block not found")
+	}
+	// The chain is rewound to the block, which needs its public state to be there
+	// or the rewind ends up at the genesis
+	if ctx.GlobalBool(utils.PrivateStateFlag.Name) && !chain.HasState(block.Root()) {
+		utils.Fatalf("Public state of block #%d is missing, import it first or pick a more recent block", block.NumberU64())
+	}
+	start := time.Now()
+	root, err := utils.ImportState(chainDb, ctx.Args().First())
+	if err != nil {
+		utils.Fatalf("Import error: %v", err)
+	}
+	if !ctx.GlobalBool(utils.PrivateStateFlag.Name) {
+		if root != block.Root() {
+			utils.Fatalf("Dumped state root %x doesn't match block #%d root %x", root, block.NumberU64(), block.Root())
+		}
+		fmt.Printf("Import done in %v\n", time.Since(start))
+		return nil
+	}
+	if err := core.WritePrivateStateRoot(chainDb, block.Root(), root); err != nil {
+		utils.Fatalf("Failed to write private state root: %v", err)
+	}
+	if head := chain.CurrentBlock().NumberU64(); head > block.NumberU64() {
+		log.Warn("Rewinding chain to the imported private state", "from", head, "to", block.NumberU64())
+		if err := chain.SetHead(block.NumberU64()); err != nil {
+			utils.Fatalf("Failed to rewind chain: %v", err)
+		}
+	}
+	fmt.Printf("Import done in %v\n", time.Since(start))
+	return nil
+}
+
+// blockByArg resolves a block number or hash argument to a canonical block.
+func blockByArg(chain *core.BlockChain, arg string) *types.Block {
+	if hashish(arg) {
+		return chain.GetBlockByHash(common.HexToHash(arg))
+	}
+	num, _ := strconv.Atoi(arg)
+	return chain.GetBlockByNumber(uint64(num))
+}
+
 // hashish returns true for strings that look like hashes.
 func hashish(x string) bool {
 	_, err := strconv.Atoi(x)
@@ -183,6 +183,24 @@ func makeFullNode(ctx *cli.Context) *node.Node {
 		utils.RegisterShhService(stack, &cfg.Shh)
 	}
 
+	// Add the GraphQL endpoint if requested.
+	if ctx.GlobalBool(utils.GraphQLEnabledFlag.Name) {
+		if !ctx.GlobalBool(utils.RPCEnabledFlag.Name) {
+			utils.Fatalf("GraphQL requires the HTTP-RPC server, enable it with --rpc")
+		}
+		// GraphQL serves the eth data, so it mustn't sidestep the module whitelist
+		var exposed bool
+		for _, module := range cfg.Node.HTTPModules {
+			if module == "eth" {
+				exposed = true
+			}
+		}
+		if !exposed {
+			utils.Fatalf("GraphQL requires the eth module on the HTTP-RPC server, enable it with --rpcapi")
+		}
+		utils.RegisterGraphQLService(stack)
+	}
+
 	// Add the Ethereum Stats daemon if requested.
 	if cfg.Ethstats.URL != "" {
 		utils.RegisterEthStatsService(stack, cfg.Ethstats.URL)
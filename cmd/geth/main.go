@@ -59,6 +59,8 @@ var (
 		utils.BootnodesV4Flag,
 		utils.BootnodesV5Flag,
 		utils.DataDirFlag,
+		utils.AncientFlag,
+		utils.DBEngineFlag,
 		utils.KeyStoreDirFlag,
 		utils.NoUSBFlag,
 		utils.DashboardEnabledFlag,
@@ -82,8 +84,15 @@ var (
 		utils.TxPoolAccountQueueFlag,
 		utils.TxPoolGlobalQueueFlag,
 		utils.TxPoolLifetimeFlag,
+		utils.TxPoolPriorityAccountsFlag,
+		utils.TxPoolAccountRateFlag,
+		utils.TxPoolRateWindowFlag,
 		utils.SyncModeFlag,
 		utils.GCModeFlag,
+		utils.PrivateStateRetentionFlag,
+		utils.ParallelTxsFlag,
+		utils.StateDiffsFlag,
+		utils.FreezerThresholdFlag,
 		utils.LightServFlag,
 		utils.LightPeersFlag,
 		utils.LightKDFFlag,
@@ -134,6 +143,7 @@ var (
 		utils.EVMInterpreterFlag,
 		configFileFlag,
 		utils.EnableNodePermissionFlag,
+		utils.NodePermissionContractFlag,
 		utils.RaftModeFlag,
 		utils.RaftBlockTimeFlag,
 		utils.RaftJoinExistingFlag,
@@ -141,6 +151,8 @@ var (
 		utils.EmitCheckpointsFlag,
 		utils.IstanbulRequestTimeoutFlag,
 		utils.IstanbulBlockPeriodFlag,
+		utils.IstanbulSignerFlag,
+		utils.IstanbulSignerEndpointFlag,
 	}
 
 	rpcFlags = []cli.Flag{
@@ -148,6 +160,7 @@ var (
 		utils.RPCListenAddrFlag,
 		utils.RPCPortFlag,
 		utils.RPCApiFlag,
+		utils.GraphQLEnabledFlag,
 		utils.WSEnabledFlag,
 		utils.WSListenAddrFlag,
 		utils.WSPortFlag,
@@ -171,6 +184,8 @@ var (
 		utils.MetricsInfluxDBUsernameFlag,
 		utils.MetricsInfluxDBPasswordFlag,
 		utils.MetricsInfluxDBHostTagFlag,
+		utils.MetricsEnablePrometheusFlag,
+		utils.MetricsPrometheusAddrFlag,
 	}
 )
 
@@ -188,7 +203,9 @@ func init() {
 		exportPreimagesCommand,
 		copydbCommand,
 		removedbCommand,
+		prunePrivateStateCommand,
 		dumpCommand,
+		importStateCommand,
 		// See monitorcmd.go:
 		monitorCommand,
 		// See accountcmd.go:
@@ -69,6 +69,8 @@ var AppHelpFlagGroups = []flagGroup{
 		Flags: []cli.Flag{
 			configFileFlag,
 			utils.DataDirFlag,
+			utils.AncientFlag,
+			utils.DBEngineFlag,
 			utils.KeyStoreDirFlag,
 			utils.NoUSBFlag,
 			utils.NetworkIdFlag,
@@ -77,6 +79,10 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.OttomanFlag,
 			utils.SyncModeFlag,
 			utils.GCModeFlag,
+			utils.PrivateStateRetentionFlag,
+			utils.ParallelTxsFlag,
+			utils.StateDiffsFlag,
+			utils.FreezerThresholdFlag,
 			utils.EthStatsURLFlag,
 			utils.IdentityFlag,
 			utils.LightServFlag,
@@ -126,6 +132,9 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.TxPoolAccountQueueFlag,
 			utils.TxPoolGlobalQueueFlag,
 			utils.TxPoolLifetimeFlag,
+			utils.TxPoolPriorityAccountsFlag,
+			utils.TxPoolAccountRateFlag,
+			utils.TxPoolRateWindowFlag,
 		},
 	},
 	{
@@ -152,6 +161,7 @@ var AppHelpFlagGroups = []flagGroup{
 		Name: "QUORUM",
 		Flags: []cli.Flag{
 			utils.EnableNodePermissionFlag,
+			utils.NodePermissionContractFlag,
 		},
 	},
 	{
@@ -177,6 +187,7 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.RPCListenAddrFlag,
 			utils.RPCPortFlag,
 			utils.RPCApiFlag,
+			utils.GraphQLEnabledFlag,
 			utils.WSEnabledFlag,
 			utils.WSListenAddrFlag,
 			utils.WSPortFlag,
@@ -255,6 +266,8 @@ var AppHelpFlagGroups = []flagGroup{
 			utils.MetricsInfluxDBUsernameFlag,
 			utils.MetricsInfluxDBPasswordFlag,
 			utils.MetricsInfluxDBHostTagFlag,
+			utils.MetricsEnablePrometheusFlag,
+			utils.MetricsPrometheusAddrFlag,
 		},
 	},
 	{
@@ -278,6 +291,8 @@ var AppHelpFlagGroups = []flagGroup{
 		Flags: []cli.Flag{
 			utils.IstanbulRequestTimeoutFlag,
 			utils.IstanbulBlockPeriodFlag,
+			utils.IstanbulSignerFlag,
+			utils.IstanbulSignerEndpointFlag,
 		},
 	},
 }
@@ -18,7 +18,9 @@
 package utils
 
 import (
+	"bufio"
 	"compress/gzip"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -30,6 +32,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
@@ -238,7 +241,7 @@ func ExportAppendChain(blockchain *core.BlockChain, fn string, first uint64, las
 }
 
 // ImportPreimages imports a batch of exported hash preimages into the database.
-func ImportPreimages(db *ethdb.LDBDatabase, fn string) error {
+func ImportPreimages(db ethdb.Database, fn string) error {
 	log.Info("Importing preimages", "file", fn)
 
 	// Open the file handle and potentially unwrap the gzip stream
@@ -285,7 +288,7 @@ func ImportPreimages(db *ethdb.LDBDatabase, fn string) error {
 
 // ExportPreimages exports all known hash preimages into the specified file,
 // truncating any data already present in the file.
-func ExportPreimages(db *ethdb.LDBDatabase, fn string) error {
+func ExportPreimages(db ethdb.Database, fn string) error {
 	log.Info("Exporting preimages", "file", fn)
 
 	// Open the file handle and potentially wrap with a gzip stream
@@ -310,3 +313,56 @@ func ExportPreimages(db *ethdb.LDBDatabase, fn string) error {
 	log.Info("Exported preimages", "file", fn)
 	return nil
 }
+
+// ImportState reads a state dump, either JSON as printed by `geth dump` or an
+// RLP stream written with --dump.rlp, commits it to the database and returns
+// its root. The import fails if the rebuilt state doesn't match the dumped root.
+func ImportState(db ethdb.Database, fn string) (common.Hash, error) {
+	log.Info("Importing state", "file", fn)
+
+	// Open the file handle and potentially unwrap the gzip stream
+	fh, err := os.Open(fn)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	defer fh.Close()
+
+	var reader io.Reader = fh
+	if strings.HasSuffix(fn, ".gz") {
+		if reader, err = gzip.NewReader(reader); err != nil {
+			return common.Hash{}, err
+		}
+	}
+	buffered := bufio.NewReader(reader)
+	first, err := buffered.Peek(1)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+
+	var want common.Hash
+	if first[0] == '{' {
+		var dump state.Dump
+		if err := json.NewDecoder(buffered).Decode(&dump); err != nil {
+			return common.Hash{}, err
+		}
+		want, err = statedb.ImportDump(dump)
+	} else {
+		want, err = statedb.ImportRLP(buffered)
+	}
+	if err != nil {
+		return common.Hash{}, err
+	}
+	root, err := statedb.Commit(false)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if root != want {
+		return common.Hash{}, fmt.Errorf("imported state root mismatch: have %x, want %x", root, want)
+	}
+	if err := statedb.Database().TrieDB().Commit(root, true); err != nil {
+		return common.Hash{}, err
+	}
+	log.Info("Imported state", "root", root)
+	return root, nil
+}
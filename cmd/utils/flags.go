@@ -19,6 +19,7 @@ package utils
 
 import (
 	"crypto/ecdsa"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"math/big"
@@ -35,6 +36,7 @@ import (
 	"github.com/ethereum/go-ethereum/consensus/clique"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -44,10 +46,12 @@ import (
 	"github.com/ethereum/go-ethereum/eth/gasprice"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/ethstats"
+	"github.com/ethereum/go-ethereum/graphql"
 	"github.com/ethereum/go-ethereum/les"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/metrics"
 	"github.com/ethereum/go-ethereum/metrics/influxdb"
+	"github.com/ethereum/go-ethereum/metrics/prometheus"
 	"github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/p2p/discv5"
@@ -119,6 +123,19 @@ var (
 		Usage: "Data directory for the databases and keystore",
 		Value: DirectoryString{node.DefaultDataDir()},
 	}
+	AncientFlag = DirectoryFlag{
+		Name:  "datadir.ancient",
+		Usage: "Data directory for the ancient block store (default = inside chaindata)",
+	}
+	FreezerThresholdFlag = cli.Uint64Flag{
+		Name:  "freezer.threshold",
+		Usage: "Number of recent blocks kept in the chain database, older ones are moved to the ancient store (0 = keep all, at least " + strconv.Itoa(params.ImmutabilityThreshold) + " unless using Raft or Istanbul)",
+		Value: eth.DefaultConfig.FreezerThreshold,
+	}
+	DBEngineFlag = cli.StringFlag{
+		Name:  "db.engine",
+		Usage: "Key-value backend for new databases (" + strings.Join(ethdb.Engines(), ", ") + "; default = " + ethdb.DefaultEngine + ")",
+	}
 	KeyStoreDirFlag = DirectoryFlag{
 		Name:  "keystore",
 		Usage: "Directory for the keystore (default = inside the datadir)",
@@ -172,6 +189,32 @@ var (
 		Usage: `Blockchain garbage collection mode ("full", "archive")`,
 		Value: "full",
 	}
+	PrivateStateRetentionFlag = cli.Uint64Flag{
+		Name:  "privatestate.retention",
+		Usage: "Number of recent blocks whose private state is kept before pruning (0 = archive private state)",
+		Value: eth.DefaultConfig.PrivateStateRetention,
+	}
+	ParallelTxsFlag = cli.IntFlag{
+		Name:  "paralleltxs",
+		Usage: "Number of transactions executed speculatively in parallel on block import (Quorum, 0 = serial)",
+		Value: eth.DefaultConfig.ParallelTxs,
+	}
+	StateDiffsFlag = cli.BoolFlag{
+		Name:  "statediffs",
+		Usage: "Record the public and private state diffs of every block (served by debug_stateDiff)",
+	}
+	CopyDBRawFlag = cli.BoolFlag{
+		Name:  "copydb.raw",
+		Usage: "Copy all database entries as is instead of syncing the chain (e.g. to change --db.engine)",
+	}
+	PrivateStateFlag = cli.BoolFlag{
+		Name:  "private",
+		Usage: "Dump or import the private state of the block instead of its public state",
+	}
+	DumpRLPFlag = cli.BoolFlag{
+		Name:  "dump.rlp",
+		Usage: "Write the state dump as an RLP stream that can be imported with import-state",
+	}
 	LightServFlag = cli.IntFlag{
 		Name:  "lightserv",
 		Usage: "Maximum percentage of time allowed for serving LES requests (0-90)",
@@ -290,6 +333,20 @@ var (
 		Usage: "Maximum amount of time non-executable transaction are queued",
 		Value: eth.DefaultConfig.TxPool.Lifetime,
 	}
+	TxPoolPriorityAccountsFlag = cli.StringFlag{
+		Name:  "txpool.priorityaccounts",
+		Usage: "Comma separated accounts whose transactions are included first and never rate limited (Quorum)",
+	}
+	TxPoolAccountRateFlag = cli.Uint64Flag{
+		Name:  "txpool.accountrate",
+		Usage: "Maximum number of transactions included in blocks per remote account and rate window (0 = unlimited)",
+		Value: eth.DefaultConfig.TxPool.AccountRate,
+	}
+	TxPoolRateWindowFlag = cli.DurationFlag{
+		Name:  "txpool.ratewindow",
+		Usage: "Time window over which the account transaction rate is measured",
+		Value: eth.DefaultConfig.TxPool.RateWindow,
+	}
 	// Performance tuning settings
 	CacheFlag = cli.IntFlag{
 		Name:  "cache",
@@ -441,6 +498,10 @@ var (
 		Usage: "API's offered over the HTTP-RPC interface",
 		Value: "",
 	}
+	GraphQLEnabledFlag = cli.BoolFlag{
+		Name:  "graphql",
+		Usage: "Enable the GraphQL query endpoint on /graphql of the HTTP-RPC server (requires --rpc)",
+	}
 	IPCDisabledFlag = cli.BoolFlag{
 		Name:  "ipcdisable",
 		Usage: "Disable the IPC-RPC server",
@@ -606,6 +667,10 @@ var (
 		Name:  "permissioned",
 		Usage: "If enabled, the node will allow only a defined list of nodes to connect",
 	}
+	NodePermissionContractFlag = cli.StringFlag{
+		Name:  "permissioned.contract",
+		Usage: "Address of the node registry contract to use instead of permissioned-nodes.json",
+	}
 
 	// Istanbul settings
 	IstanbulRequestTimeoutFlag = cli.Uint64Flag{
@@ -618,6 +683,14 @@ var (
 		Usage: "Default minimum difference between two consecutive block's timestamps in seconds",
 		Value: eth.DefaultConfig.Istanbul.BlockPeriod,
 	}
+	IstanbulSignerFlag = cli.StringFlag{
+		Name:  "istanbul.signer",
+		Usage: "Account signing Istanbul seals and messages instead of the node key, announced to peers (must be available in the account manager)",
+	}
+	IstanbulSignerEndpointFlag = cli.StringFlag{
+		Name:  "istanbul.signer.endpoint",
+		Usage: "Clef endpoint (IPC path or URL) signing with the --istanbul.signer account instead of the account manager",
+	}
 
 	// Metrics flags
 	MetricsEnabledFlag = cli.BoolFlag{
@@ -657,6 +730,15 @@ var (
 		Usage: "InfluxDB `host` tag attached to all measurements",
 		Value: "localhost",
 	}
+	MetricsEnablePrometheusFlag = cli.BoolFlag{
+		Name:  metrics.PrometheusEnabledFlag,
+		Usage: "Enable the Prometheus metrics HTTP endpoint (implies --metrics)",
+	}
+	MetricsPrometheusAddrFlag = cli.StringFlag{
+		Name:  "metrics.prometheus.addr",
+		Usage: "Prometheus metrics HTTP endpoint listening address, serving /metrics",
+		Value: "127.0.0.1:6061",
+	}
 
 	EWASMInterpreterFlag = cli.StringFlag{
 		Name:  "vm.ewasm",
@@ -1035,6 +1117,9 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 		cfg.DataDir = filepath.Join(node.DefaultDataDir(), "ottoman")
 	}
 
+	if ctx.GlobalIsSet(DBEngineFlag.Name) {
+		cfg.DatabaseEngine = ctx.GlobalString(DBEngineFlag.Name)
+	}
 	if ctx.GlobalIsSet(KeyStoreDirFlag.Name) {
 		cfg.KeyStoreDir = ctx.GlobalString(KeyStoreDirFlag.Name)
 	}
@@ -1096,6 +1181,22 @@ func setTxPool(ctx *cli.Context, cfg *core.TxPoolConfig) {
 	if ctx.GlobalIsSet(TxPoolLifetimeFlag.Name) {
 		cfg.Lifetime = ctx.GlobalDuration(TxPoolLifetimeFlag.Name)
 	}
+	if ctx.GlobalIsSet(TxPoolPriorityAccountsFlag.Name) {
+		accounts := strings.Split(ctx.GlobalString(TxPoolPriorityAccountsFlag.Name), ",")
+		for _, account := range accounts {
+			if trimmed := strings.TrimSpace(account); !common.IsHexAddress(trimmed) {
+				Fatalf("Invalid account in --txpool.priorityaccounts: %s", trimmed)
+			} else {
+				cfg.PriorityAccounts = append(cfg.PriorityAccounts, common.HexToAddress(trimmed))
+			}
+		}
+	}
+	if ctx.GlobalIsSet(TxPoolAccountRateFlag.Name) {
+		cfg.AccountRate = ctx.GlobalUint64(TxPoolAccountRateFlag.Name)
+	}
+	if ctx.GlobalIsSet(TxPoolRateWindowFlag.Name) {
+		cfg.RateWindow = ctx.GlobalDuration(TxPoolRateWindowFlag.Name)
+	}
 }
 
 func setEthash(ctx *cli.Context, cfg *eth.Config) {
@@ -1126,6 +1227,35 @@ func setIstanbul(ctx *cli.Context, cfg *eth.Config) {
 	if ctx.GlobalIsSet(IstanbulBlockPeriodFlag.Name) {
 		cfg.Istanbul.BlockPeriod = ctx.GlobalUint64(IstanbulBlockPeriodFlag.Name)
 	}
+	if ctx.GlobalIsSet(IstanbulSignerFlag.Name) {
+		signer := ctx.GlobalString(IstanbulSignerFlag.Name)
+		if !common.IsHexAddress(signer) {
+			Fatalf("Invalid Istanbul signer address: %s", signer)
+		}
+		cfg.Istanbul.Signer = common.HexToAddress(signer)
+	}
+	if ctx.GlobalIsSet(IstanbulSignerEndpointFlag.Name) {
+		if cfg.Istanbul.Signer == (common.Address{}) {
+			Fatalf("Option %q requires %q", IstanbulSignerEndpointFlag.Name, IstanbulSignerFlag.Name)
+		}
+		cfg.Istanbul.SignerEndpoint = ctx.GlobalString(IstanbulSignerEndpointFlag.Name)
+	}
+}
+
+// setNodePermissionContract configures the node registry contract used for node
+// permissioning.
+func setNodePermissionContract(ctx *cli.Context, cfg *eth.Config) {
+	if !ctx.GlobalIsSet(NodePermissionContractFlag.Name) {
+		return
+	}
+	if !ctx.GlobalBool(EnableNodePermissionFlag.Name) {
+		Fatalf("--%s requires --%s", NodePermissionContractFlag.Name, EnableNodePermissionFlag.Name)
+	}
+	address := ctx.GlobalString(NodePermissionContractFlag.Name)
+	if !common.IsHexAddress(address) {
+		Fatalf("Invalid node registry contract address: %s", address)
+	}
+	cfg.NodePermissionContract = common.HexToAddress(address)
 }
 
 // checkExclusive verifies that only a single instance of the provided flags was
@@ -1194,6 +1324,7 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *eth.Config) {
 	setTxPool(ctx, &cfg.TxPool)
 	setEthash(ctx, cfg)
 	setIstanbul(ctx, cfg)
+	setNodePermissionContract(ctx, cfg)
 
 	if ctx.GlobalIsSet(SyncModeFlag.Name) {
 		cfg.SyncMode = *GlobalTextMarshaler(ctx, SyncModeFlag.Name).(*downloader.SyncMode)
@@ -1212,11 +1343,26 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *eth.Config) {
 		cfg.DatabaseCache = ctx.GlobalInt(CacheFlag.Name) * ctx.GlobalInt(CacheDatabaseFlag.Name) / 100
 	}
 	cfg.DatabaseHandles = makeDatabaseHandles()
+	if ctx.GlobalIsSet(AncientFlag.Name) {
+		cfg.DatabaseFreezer = ctx.GlobalString(AncientFlag.Name)
+	}
+	if ctx.GlobalIsSet(FreezerThresholdFlag.Name) {
+		cfg.FreezerThreshold = ctx.GlobalUint64(FreezerThresholdFlag.Name)
+	}
 
 	if gcmode := ctx.GlobalString(GCModeFlag.Name); gcmode != "full" && gcmode != "archive" {
 		Fatalf("--%s must be either 'full' or 'archive'", GCModeFlag.Name)
 	}
 	cfg.NoPruning = ctx.GlobalString(GCModeFlag.Name) == "archive"
+	if ctx.GlobalIsSet(PrivateStateRetentionFlag.Name) {
+		cfg.PrivateStateRetention = ctx.GlobalUint64(PrivateStateRetentionFlag.Name)
+	}
+	if ctx.GlobalIsSet(ParallelTxsFlag.Name) {
+		cfg.ParallelTxs = ctx.GlobalInt(ParallelTxsFlag.Name)
+	}
+	if ctx.GlobalIsSet(StateDiffsFlag.Name) {
+		cfg.StateDiffs = ctx.GlobalBool(StateDiffsFlag.Name)
+	}
 
 	if ctx.GlobalIsSet(CacheFlag.Name) || ctx.GlobalIsSet(CacheGCFlag.Name) {
 		cfg.TrieCache = ctx.GlobalInt(CacheFlag.Name) * ctx.GlobalInt(CacheGCFlag.Name) / 100
@@ -1383,6 +1529,27 @@ func RegisterEthStatsService(stack *node.Node, url string) {
 	}
 }
 
+// RegisterGraphQLService adds the GraphQL query service to a node, served on
+// the HTTP-RPC endpoint.
+func RegisterGraphQLService(stack *node.Node) {
+	if err := stack.Register(func(ctx *node.ServiceContext) (node.Service, error) {
+		// Try to construct the GraphQL service backed by a full node
+		var ethServ *eth.Ethereum
+		if err := ctx.Service(&ethServ); err == nil {
+			return graphql.New(ethServ.APIBackend)
+		}
+		// Try to construct the GraphQL service backed by a light node
+		var lesServ *les.LightEthereum
+		if err := ctx.Service(&lesServ); err == nil {
+			return graphql.New(lesServ.ApiBackend)
+		}
+		// Well, this should not have happened, bail out
+		return nil, errors.New("no Ethereum service")
+	}); err != nil {
+		Fatalf("Failed to register the GraphQL service: %v", err)
+	}
+}
+
 func SetupMetrics(ctx *cli.Context) {
 	if metrics.Enabled {
 		log.Info("Enabling metrics collection")
@@ -1401,10 +1568,20 @@ func SetupMetrics(ctx *cli.Context) {
 				"host": hosttag,
 			})
 		}
+		if ctx.GlobalBool(MetricsEnablePrometheusFlag.Name) {
+			// Resetting timers are left to InfluxDB if both report them
+			if _, err := prometheus.Start(ctx.GlobalString(MetricsPrometheusAddrFlag.Name), metrics.DefaultRegistry, !enableExport); err != nil {
+				Fatalf("Failed to start the Prometheus metrics endpoint: %v", err)
+			}
+		}
+	} else if ctx.GlobalBool(MetricsEnablePrometheusFlag.Name) {
+		Fatalf("The Prometheus metrics endpoint requires metrics collection, enable it with --%s", metrics.MetricsEnabledFlag)
 	}
 }
 
 // MakeChainDatabase open an LevelDB using the flags passed to the client and will hard crash if it fails.
+// Full chain databases are opened along with their ancient store, but only a
+// running node moves blocks into it.
 func MakeChainDatabase(ctx *cli.Context, stack *node.Node) ethdb.Database {
 	var (
 		cache   = ctx.GlobalInt(CacheFlag.Name) * ctx.GlobalInt(CacheDatabaseFlag.Name) / 100
@@ -1418,6 +1595,20 @@ func MakeChainDatabase(ctx *cli.Context, stack *node.Node) ethdb.Database {
 	if err != nil {
 		Fatalf("Could not open database: %v", err)
 	}
+	if name == "lightchaindata" {
+		return chainDb
+	}
+	freezer := ctx.GlobalString(AncientFlag.Name)
+	if freezer == "" {
+		freezer = filepath.Join(name, "ancient")
+	}
+	if freezer = stack.ResolvePath(freezer); freezer == "" {
+		return chainDb
+	}
+	chainDb, err = rawdb.NewDatabaseWithFreezer(chainDb, freezer)
+	if err != nil {
+		Fatalf("Could not open ancient database: %v", err)
+	}
 	return chainDb
 }
 
@@ -1468,6 +1659,10 @@ func MakeChain(ctx *cli.Context, stack *node.Node) (chain *core.BlockChain, chai
 		Disabled:      ctx.GlobalString(GCModeFlag.Name) == "archive",
 		TrieNodeLimit: eth.DefaultConfig.TrieCache,
 		TrieTimeLimit: eth.DefaultConfig.TrieTimeout,
+
+		PrivateRetention: ctx.GlobalUint64(PrivateStateRetentionFlag.Name),
+		ParallelTxs:      ctx.GlobalInt(ParallelTxsFlag.Name),
+		StateDiffs:       ctx.GlobalBool(StateDiffsFlag.Name),
 	}
 	if ctx.GlobalIsSet(CacheFlag.Name) || ctx.GlobalIsSet(CacheGCFlag.Name) {
 		cache.TrieNodeLimit = ctx.GlobalInt(CacheFlag.Name) * ctx.GlobalInt(CacheGCFlag.Name) / 100
@@ -123,6 +123,19 @@ type Handler interface {
 	SetBroadcaster(Broadcaster)
 }
 
+// ValidatorPeers is an optional interface of Handler for engines whose validators
+// may sign with an account other than their node key. Such validators announce
+// the account to their peers, signed by the account itself.
+type ValidatorPeers interface {
+	// AnnounceValidator sends the local validator account to a newly connected
+	// peer, if it differs from the node key. It must not block.
+	AnnounceValidator(peer Peer)
+
+	// PeerValidator returns the validator account announced by the peer with the
+	// given node key address, if any.
+	PeerValidator(node common.Address) (common.Address, bool)
+}
+
 // PoW is a consensus engine based on proof-of-work.
 type PoW interface {
 	Engine
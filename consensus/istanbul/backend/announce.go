@@ -0,0 +1,128 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/istanbul"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// errInvalidAnnouncement is returned if a validator announcement isn't signed by
+// the announced account or was relayed from another node.
+var errInvalidAnnouncement = errors.New("invalid validator announcement")
+
+// validatorAnnouncement binds the node key of a peer to the validator account it
+// signs consensus messages with. Peers only know the node key from the p2p
+// handshake, so a validator signing with another account has to announce it for
+// its consensus messages to be gossiped to it.
+type validatorAnnouncement struct {
+	Node      common.Address // Address of the node key announcing the account
+	Validator common.Address // Validator account the node signs with
+	Signature []byte         // Signature of the validator account over both addresses
+}
+
+// sigData returns the data the validator account signs.
+func (a *validatorAnnouncement) sigData() []byte {
+	return append(a.Node.Bytes(), a.Validator.Bytes()...)
+}
+
+// AnnounceValidator implements consensus.ValidatorPeers, sending the authorized
+// account to the peer. Validators signing with their node key aren't announced,
+// peers already know them by it, which also keeps them compatible with peers
+// that predate announcements and disconnect on the unknown message.
+func (sb *backend) AnnounceValidator(peer consensus.Peer) {
+	sb.signerMu.RLock()
+	external := sb.signFn != nil
+	sb.signerMu.RUnlock()
+
+	if !external {
+		return
+	}
+	go func() {
+		payload, err := sb.announce()
+		if err != nil {
+			sb.logger.Warn("Failed to sign validator announcement", "err", err)
+			return
+		}
+		peer.Send(istanbulAnnounceMsg, payload)
+	}()
+}
+
+// announce returns the signed announcement of the authorized account, signing it
+// on first use.
+func (sb *backend) announce() ([]byte, error) {
+	sb.announceMu.Lock()
+	defer sb.announceMu.Unlock()
+
+	if sb.announcement != nil {
+		return sb.announcement, nil
+	}
+	announcement := &validatorAnnouncement{
+		Node:      crypto.PubkeyToAddress(sb.privateKey.PublicKey),
+		Validator: sb.Address(),
+	}
+	sig, err := sb.Sign(announcement.sigData())
+	if err != nil {
+		return nil, err
+	}
+	announcement.Signature = sig
+
+	payload, err := rlp.EncodeToBytes(announcement)
+	if err != nil {
+		return nil, err
+	}
+	sb.announcement = payload
+	return payload, nil
+}
+
+// handleAnnouncement verifies the validator account announced by a peer and
+// records it for the peer's node key.
+func (sb *backend) handleAnnouncement(node common.Address, msg p2p.Msg) error {
+	data, _, err := sb.decode(msg)
+	if err != nil {
+		return err
+	}
+	announcement := new(validatorAnnouncement)
+	if err := rlp.DecodeBytes(data, announcement); err != nil {
+		return errDecodeFailed
+	}
+	if announcement.Node != node {
+		return errInvalidAnnouncement
+	}
+	signer, err := istanbul.GetSignatureAddress(announcement.sigData(), announcement.Signature)
+	if err != nil || signer != announcement.Validator {
+		return errInvalidAnnouncement
+	}
+	sb.logger.Debug("Peer announced validator account", "node", node, "validator", announcement.Validator)
+	sb.peerValidators.Add(node, announcement.Validator)
+	return nil
+}
+
+// PeerValidator implements consensus.ValidatorPeers.
+func (sb *backend) PeerValidator(node common.Address) (common.Address, bool) {
+	validator, ok := sb.peerValidators.Get(node)
+	if !ok {
+		return common.Address{}, false
+	}
+	return validator.(common.Address), true
+}
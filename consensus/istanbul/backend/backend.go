@@ -17,11 +17,13 @@
 package backend
 
 import (
+	"context"
 	"crypto/ecdsa"
 	"math/big"
 	"sync"
 	"time"
 
+	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/consensus/istanbul"
@@ -33,6 +35,7 @@ import (
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
 	lru "github.com/hashicorp/golang-lru"
 )
 
@@ -41,12 +44,33 @@ const (
 	fetcherID = "istanbul"
 )
 
+var (
+	// signTimer measures the latency of signing seals and consensus messages.
+	signTimer = metrics.NewRegisteredTimer("consensus/istanbul/sign", nil)
+)
+
+// SignerFn is a signer callback function to request the keccak256 hash of an
+// Istanbul seal or message to be signed by a backing account. The signer has to
+// give up once the context is done, the signature is useless by then.
+type SignerFn func(ctx context.Context, account accounts.Account, data []byte) ([]byte, error)
+
+// WalletSigner returns a signer callback signing with an account of the wallet.
+func WalletSigner(wallet accounts.Wallet) SignerFn {
+	return func(ctx context.Context, account accounts.Account, data []byte) ([]byte, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return wallet.SignHash(account, crypto.Keccak256(data))
+	}
+}
+
 // New creates an Ethereum backend for Istanbul core engine.
 func New(config *istanbul.Config, privateKey *ecdsa.PrivateKey, db ethdb.Database) consensus.Istanbul {
 	// Allocate the snapshot caches and create the engine
 	recents, _ := lru.NewARC(inmemorySnapshots)
 	recentMessages, _ := lru.NewARC(inmemoryPeers)
 	knownMessages, _ := lru.NewARC(inmemoryMessages)
+	peerValidators, _ := lru.NewARC(inmemoryValidators)
 	backend := &backend{
 		config:           config,
 		istanbulEventMux: new(event.TypeMux),
@@ -60,6 +84,7 @@ func New(config *istanbul.Config, privateKey *ecdsa.PrivateKey, db ethdb.Databas
 		coreStarted:      false,
 		recentMessages:   recentMessages,
 		knownMessages:    knownMessages,
+		peerValidators:   peerValidators,
 	}
 	backend.core = istanbulCore.New(backend, backend.config)
 	return backend
@@ -72,6 +97,8 @@ type backend struct {
 	istanbulEventMux *event.TypeMux
 	privateKey       *ecdsa.PrivateKey
 	address          common.Address
+	signFn           SignerFn     // Signer function replacing the private key, if authorized
+	signerMu         sync.RWMutex // Protects the signer fields
 	core             istanbulCore.Engine
 	logger           log.Logger
 	db               ethdb.Database
@@ -98,6 +125,10 @@ type backend struct {
 
 	recentMessages *lru.ARCCache // the cache of peer's messages
 	knownMessages  *lru.ARCCache // the cache of self messages
+
+	announcement   []byte        // Signed announcement of the authorized account, cached
+	announceMu     sync.Mutex    // Protects the announcement, serializing its signing
+	peerValidators *lru.ARCCache // Validator accounts announced by peers, keyed by node key address
 }
 
 // zekun: HACK
@@ -107,9 +138,34 @@ func (sb *backend) CalcDifficulty(chain consensus.ChainReader, time uint64, pare
 
 // Address implements istanbul.Backend.Address
 func (sb *backend) Address() common.Address {
+	sb.signerMu.RLock()
+	defer sb.signerMu.RUnlock()
 	return sb.address
 }
 
+// Authorize replaces the node key with an account whose signatures are requested
+// from signFn, e.g. a wallet of the account manager. It has to be called while the
+// engine is stopped, as the core is recreated for the new validator address.
+func (sb *backend) Authorize(address common.Address, signFn SignerFn) error {
+	sb.coreMu.Lock()
+	defer sb.coreMu.Unlock()
+	if sb.coreStarted {
+		return istanbul.ErrStartedEngine
+	}
+
+	sb.signerMu.Lock()
+	sb.address = address
+	sb.signFn = signFn
+	sb.signerMu.Unlock()
+
+	sb.announceMu.Lock()
+	sb.announcement = nil
+	sb.announceMu.Unlock()
+
+	sb.core = istanbulCore.New(sb, sb.config)
+	return nil
+}
+
 // Validators implements istanbul.Backend.Validators
 func (sb *backend) Validators(proposal istanbul.Proposal) istanbul.ValidatorSet {
 	return sb.getValidators(proposal.Number().Uint64(), proposal.Hash())
@@ -244,8 +300,44 @@ func (sb *backend) Verify(proposal istanbul.Proposal) (time.Duration, error) {
 
 // Sign implements istanbul.Backend.Sign
 func (sb *backend) Sign(data []byte) ([]byte, error) {
-	hashData := crypto.Keccak256([]byte(data))
-	return crypto.Sign(hashData, sb.privateKey)
+	sb.signerMu.RLock()
+	address, signFn := sb.address, sb.signFn
+	sb.signerMu.RUnlock()
+
+	if signFn == nil {
+		hashData := crypto.Keccak256([]byte(data))
+		return crypto.Sign(hashData, sb.privateKey)
+	}
+	return sb.signExternal(address, signFn, data)
+}
+
+// signExternal requests a signature from the authorized signer. A signature that
+// takes longer than a whole round is useless, so the request is cancelled after
+// the request timeout of the block being agreed on and the round is left to time
+// out and change.
+func (sb *backend) signExternal(address common.Address, signFn SignerFn, data []byte) ([]byte, error) {
+	var number *big.Int
+	if sb.currentBlock != nil {
+		number = new(big.Int).Add(sb.currentBlock().Number(), common.Big1)
+	}
+	timeout := time.Duration(sb.config.GetConfig(number).RequestTimeout) * time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	sig, err := signFn(ctx, accounts.Account{Address: address}, data)
+	elapsed := time.Since(start)
+	signTimer.Update(elapsed)
+
+	if ctx.Err() == context.DeadlineExceeded {
+		sb.logger.Error("Istanbul signer timed out", "address", address, "timeout", common.PrettyDuration(timeout))
+		return nil, errSignTimeout
+	}
+	if elapsed > timeout/4 {
+		sb.logger.Warn("Slow Istanbul signer", "address", address, "elapsed", common.PrettyDuration(elapsed), "timeout", common.PrettyDuration(timeout))
+	}
+	return sig, err
 }
 
 // CheckSignature implements istanbul.Backend.CheckSignature
@@ -18,12 +18,15 @@ package backend
 
 import (
 	"bytes"
+	"context"
 	"crypto/ecdsa"
+	"math/big"
 	"sort"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus/istanbul"
 	"github.com/ethereum/go-ethereum/consensus/istanbul/validator"
@@ -48,6 +51,62 @@ func TestSign(t *testing.T) {
 	}
 }
 
+func TestSignAuthorized(t *testing.T) {
+	b := newBackend()
+	key, _ := generatePrivateKey()
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	signFn := func(ctx context.Context, account accounts.Account, data []byte) ([]byte, error) {
+		if account.Address != addr {
+			t.Errorf("account mismatch: have %v, want %v", account.Address.Hex(), addr.Hex())
+		}
+		return crypto.Sign(crypto.Keccak256(data), key)
+	}
+	if err := b.Authorize(addr, signFn); err != istanbul.ErrStartedEngine {
+		t.Errorf("error mismatch: have %v, want %v", err, istanbul.ErrStartedEngine)
+	}
+	b.Stop()
+	if err := b.Authorize(addr, signFn); err != nil {
+		t.Fatalf("error mismatch: have %v, want nil", err)
+	}
+	if b.Address() != addr {
+		t.Errorf("address mismatch: have %v, want %v", b.Address().Hex(), addr.Hex())
+	}
+	data := []byte("Here is a string....")
+	sig, err := b.Sign(data)
+	if err != nil {
+		t.Fatalf("error mismatch: have %v, want nil", err)
+	}
+	if err := b.CheckSignature(data, addr, sig); err != nil {
+		t.Errorf("error mismatch: have %v, want nil", err)
+	}
+}
+
+func TestSignAuthorizedTimeout(t *testing.T) {
+	b := newBackend()
+	// The request timeout of the next block applies, not the initial one
+	config := *b.config
+	config.RequestTimeout = 60000
+	config.Transitions = []istanbul.Transition{{Block: big.NewInt(1), RequestTimeout: 100}}
+	b.config = &config
+	b.Stop()
+
+	cancelled := make(chan struct{})
+	b.Authorize(getAddress(), func(ctx context.Context, account accounts.Account, data []byte) ([]byte, error) {
+		<-ctx.Done()
+		close(cancelled)
+		return nil, ctx.Err()
+	})
+	if _, err := b.Sign([]byte("Here is a string....")); err != errSignTimeout {
+		t.Errorf("error mismatch: have %v, want %v", err, errSignTimeout)
+	}
+	// The signer must have been released rather than left hanging
+	select {
+	case <-cancelled:
+	default:
+		t.Errorf("signer request not cancelled")
+	}
+}
+
 func TestCheckSignature(t *testing.T) {
 	key, _ := generatePrivateKey()
 	data := []byte("Here is a string....")
@@ -42,6 +42,7 @@ const (
 	checkpointInterval = 1024 // Number of blocks after which to save the vote snapshot to the database
 	inmemorySnapshots  = 128  // Number of recent vote snapshots to keep in memory
 	inmemoryPeers      = 40
+	inmemoryValidators = 1024 // Number of validator accounts announced by peers to remember
 	inmemoryMessages   = 1024
 )
 
@@ -82,6 +83,9 @@ var (
 	errEmptyCommittedSeals = errors.New("zero committed seals")
 	// errMismatchTxhashes is returned if the TxHash in header is mismatch.
 	errMismatchTxhashes = errors.New("mismatch transcations hashes")
+	// errSignTimeout is returned if the authorized signer doesn't return a
+	// signature within the request timeout.
+	errSignTimeout = errors.New("signer timed out")
 )
 var (
 	defaultDifficulty = big.NewInt(1)
@@ -169,7 +173,7 @@ func (sb *backend) verifyCascadingFields(chain consensus.ChainReader, header *ty
 	if parent == nil || parent.Number.Uint64() != number-1 || parent.Hash() != header.ParentHash {
 		return consensus.ErrUnknownAncestor
 	}
-	if parent.Time.Uint64()+sb.config.BlockPeriod > header.Time.Uint64() {
+	if parent.Time.Uint64()+sb.config.GetConfig(header.Number).BlockPeriod > header.Time.Uint64() {
 		return errInvalidTimestamp
 	}
 	// Verify validators in extraData. Validators in snapshot and extraData should be the same.
@@ -368,7 +372,7 @@ func (sb *backend) Prepare(chain consensus.ChainReader, header *types.Header) er
 	header.Extra = extra
 
 	// set header's timestamp
-	header.Time = new(big.Int).Add(parent.Time, new(big.Int).SetUint64(sb.config.BlockPeriod))
+	header.Time = new(big.Int).Add(parent.Time, new(big.Int).SetUint64(sb.config.GetConfig(header.Number).BlockPeriod))
 	if header.Time.Int64() < time.Now().Unix() {
 		header.Time = big.NewInt(time.Now().Unix())
 	}
@@ -386,6 +390,19 @@ func (sb *backend) Finalize(chain consensus.ChainReader, header *types.Header, s
 	header.Root = state.IntermediateRoot(chain.Config().IsEIP158(header.Number))
 	header.UncleHash = nilUncleHash
 
+	// Empty blocks are held back up to the empty block period after their parent.
+	// Stamp them here so the seal covers the timestamp, leaving the caller's
+	// header untouched for a later block with transactions.
+	if len(txs) == 0 {
+		if emptyPeriod := sb.config.EmptyBlockPeriod; emptyPeriod > sb.config.GetConfig(header.Number).BlockPeriod {
+			if parent := chain.GetHeader(header.ParentHash, header.Number.Uint64()-1); parent != nil {
+				if emptyTime := new(big.Int).Add(parent.Time, new(big.Int).SetUint64(emptyPeriod)); emptyTime.Cmp(header.Time) > 0 {
+					header = types.CopyHeader(header)
+					header.Time = emptyTime
+				}
+			}
+		}
+	}
 	// Assemble and return the final block for sealing
 	return types.NewBlock(header, txs, nil, receipts), nil
 }
@@ -403,7 +420,7 @@ func (sb *backend) Seal(chain consensus.ChainReader, block *types.Block, results
 	if err != nil {
 		return err
 	}
-	if _, v := snap.ValSet.GetByAddress(sb.address); v == nil {
+	if _, v := snap.ValSet.GetByAddress(sb.Address()); v == nil {
 		return errUnauthorized
 	}
 
@@ -416,7 +433,9 @@ func (sb *backend) Seal(chain consensus.ChainReader, block *types.Block, results
 		return err
 	}
 
-	// wait for the timestamp of header, use this to adjust the block period
+	// wait for the timestamp of header, use this to adjust the block period.
+	// Empty blocks are stamped up to the empty block period later by Finalize,
+	// the miner replaces them on its recommit timer once transactions are pending
 	delay := time.Unix(block.Header().Time.Int64(), 0).Sub(now())
 	select {
 	case <-time.After(delay):
@@ -553,7 +572,8 @@ func (sb *backend) snapshot(chain consensus.ChainReader, number uint64, hash com
 			if err != nil {
 				return nil, err
 			}
-			snap = newSnapshot(sb.config.Epoch, 0, genesis.Hash(), validator.NewSet(istanbulExtra.Validators, sb.config.ProposerPolicy))
+			snap = newSnapshot(sb.config.Epoch, 0, genesis.Hash(), validator.NewWeightedSet(istanbulExtra.Validators, sb.config.ProposerPolicy, sb.config.ProposerWeights))
+			snap.applyTransition(sb.config, 1)
 			if err := snap.store(sb.db); err != nil {
 				return nil, err
 			}
@@ -583,7 +603,7 @@ func (sb *backend) snapshot(chain consensus.ChainReader, number uint64, hash com
 	for i := 0; i < len(headers)/2; i++ {
 		headers[i], headers[len(headers)-1-i] = headers[len(headers)-1-i], headers[i]
 	}
-	snap, err := snap.apply(headers)
+	snap, err := snap.apply(headers, sb.config)
 	if err != nil {
 		return nil, err
 	}
@@ -241,6 +241,66 @@ func TestSealCommitted(t *testing.T) {
 	}
 }
 
+func TestSealEmptyBlockPeriod(t *testing.T) {
+	chain, engine := newBlockChain(1)
+	config := *engine.config
+	config.EmptyBlockPeriod = 5
+	engine.config = &config
+
+	block := makeBlock(chain, engine, chain.Genesis())
+	if _, err := chain.InsertChain(types.Blocks{block}); err != nil {
+		t.Fatalf("failed to insert block: %v", err)
+	}
+	engine.NewChainHead()
+
+	emptyBlock := makeBlockWithoutSeal(chain, engine, block)
+	stop := make(chan struct{})
+	resultCh := make(chan *types.Block, 10)
+	go engine.Seal(chain, emptyBlock, resultCh, stop)
+
+	select {
+	case result := <-resultCh:
+		t.Fatalf("empty block should be held back, got %v", result)
+	case <-time.After(2 * time.Second):
+	}
+	close(stop)
+	if result := <-resultCh; result != nil {
+		t.Errorf("block mismatch: have %v, want nil", result)
+	}
+}
+
+func TestSealEmptyBlockTimestamp(t *testing.T) {
+	chain, engine := newBlockChain(1)
+	config := *engine.config
+	config.EmptyBlockPeriod = 3
+	engine.config = &config
+
+	block := makeBlock(chain, engine, chain.Genesis())
+	if _, err := chain.InsertChain(types.Blocks{block}); err != nil {
+		t.Fatalf("failed to insert block: %v", err)
+	}
+	engine.NewChainHead()
+
+	emptyBlock := makeBlockWithoutSeal(chain, engine, block)
+	resultCh := make(chan *types.Block, 10)
+	go engine.Seal(chain, emptyBlock, resultCh, make(chan struct{}))
+
+	select {
+	case result := <-resultCh:
+		if want := block.Time().Uint64() + config.EmptyBlockPeriod; result.Time().Uint64() < want {
+			t.Errorf("timestamp mismatch: have %d, want at least %d", result.Time(), want)
+		}
+		if err := engine.VerifyHeader(chain, result.Header(), false); err != nil {
+			t.Errorf("held back block failed verification: %v", err)
+		}
+		if have, want := engine.SealHash(result.Header()), engine.SealHash(emptyBlock.Header()); have != want {
+			t.Errorf("seal hash mismatch: have %x, want %x", have, want)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("empty block not sealed")
+	}
+}
+
 func TestVerifyHeader(t *testing.T) {
 	chain, engine := newBlockChain(1)
 
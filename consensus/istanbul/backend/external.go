@@ -0,0 +1,62 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package backend
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// ExternalSigner requests Istanbul signatures from a clef instance, keeping the
+// validator key out of the node.
+type ExternalSigner struct {
+	endpoint string
+	client   *rpc.Client
+}
+
+// NewExternalSigner connects to the clef endpoint, usually its IPC socket.
+func NewExternalSigner(endpoint string) (*ExternalSigner, error) {
+	client, err := rpc.Dial(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return &ExternalSigner{endpoint: endpoint, client: client}, nil
+}
+
+// SignData is a SignerFn requesting the signature through account_signIstanbul.
+// The request is abandoned when the context is done.
+func (s *ExternalSigner) SignData(ctx context.Context, account accounts.Account, data []byte) ([]byte, error) {
+	var sig hexutil.Bytes
+	addr := common.NewMixedcaseAddress(account.Address)
+	if err := s.client.CallContext(ctx, &sig, "account_signIstanbul", &addr, hexutil.Bytes(data)); err != nil {
+		return nil, err
+	}
+	if len(sig) != 65 {
+		return nil, fmt.Errorf("invalid signature length from %s: %d", s.endpoint, len(sig))
+	}
+	return sig, nil
+}
+
+// Close disconnects from the clef endpoint.
+func (s *ExternalSigner) Close() {
+	s.client.Close()
+}
@@ -34,8 +34,9 @@ import (
 )
 
 const (
-	istanbulMsg = 0x11
-	NewBlockMsg = 0x07
+	istanbulMsg         = 0x11
+	istanbulAnnounceMsg = 0x12
+	NewBlockMsg         = 0x07
 )
 
 var (
@@ -48,7 +49,7 @@ func (sb *backend) Protocol() consensus.Protocol {
 	return consensus.Protocol{
 		Name:     "istanbul",
 		Versions: []uint{64},
-		Lengths:  []uint64{18},
+		Lengths:  []uint64{19},
 	}
 }
 
@@ -66,6 +67,9 @@ func (sb *backend) HandleMsg(addr common.Address, msg p2p.Msg) (bool, error) {
 	sb.coreMu.Lock()
 	defer sb.coreMu.Unlock()
 
+	if msg.Code == istanbulAnnounceMsg {
+		return true, sb.handleAnnouncement(addr, msg)
+	}
 	if msg.Code == istanbulMsg {
 		if !sb.coreStarted {
 			return true, istanbul.ErrStoppedEngine
@@ -75,6 +79,10 @@ func (sb *backend) HandleMsg(addr common.Address, msg p2p.Msg) (bool, error) {
 		if err != nil {
 			return true, errDecodeFailed
 		}
+		// Gossip tracks the peers of validators by their validator account
+		if validator, ok := sb.PeerValidator(addr); ok {
+			addr = validator
+		}
 
 		// Mark peer's message
 		ms, ok := sb.recentMessages.Get(addr)
@@ -18,14 +18,19 @@ package backend
 
 import (
 	"bytes"
+	"context"
 	"io/ioutil"
 	"math/big"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/core/types"
 
+	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus/istanbul"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/p2p"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/hashicorp/golang-lru"
@@ -182,3 +187,63 @@ func buildArbitraryP2PNewBlockMessage(t *testing.T, invalidMsg bool) (*types.Blo
 	arbitraryP2PMessage := p2p.Msg{Code: 0x07, Size: uint32(size), Payload: bytes.NewReader(payload)}
 	return arbitraryBlock, arbitraryP2PMessage
 }
+
+func TestValidatorAnnouncement(t *testing.T) {
+	_, local := newBlockChain(1)
+
+	nodeKey, _ := crypto.GenerateKey()
+	signerKey, _ := crypto.GenerateKey()
+	node, signer := crypto.PubkeyToAddress(nodeKey.PublicKey), crypto.PubkeyToAddress(signerKey.PublicKey)
+
+	remote := New(istanbul.DefaultConfig, nodeKey, ethdb.NewMemDatabase()).(*backend)
+	signFn := func(ctx context.Context, account accounts.Account, data []byte) ([]byte, error) {
+		return crypto.Sign(crypto.Keccak256(data), signerKey)
+	}
+	if err := remote.Authorize(signer, signFn); err != nil {
+		t.Fatalf("failed to authorize signer: %v", err)
+	}
+	payload, err := remote.announce()
+	if err != nil {
+		t.Fatalf("failed to sign announcement: %v", err)
+	}
+	// An announcement relayed by another node is rejected
+	if _, err := local.HandleMsg(common.Address{0x01}, makeMsg(istanbulAnnounceMsg, payload)); err != errInvalidAnnouncement {
+		t.Errorf("relayed announcement error mismatch: have %v, want %v", err, errInvalidAnnouncement)
+	}
+	// An account not signing the announcement is rejected
+	forged := new(validatorAnnouncement)
+	if err := rlp.DecodeBytes(payload, forged); err != nil {
+		t.Fatalf("failed to decode announcement: %v", err)
+	}
+	forged.Validator = common.Address{0x02}
+	data, _ := rlp.EncodeToBytes(forged)
+	if _, err := local.HandleMsg(node, makeMsg(istanbulAnnounceMsg, data)); err != errInvalidAnnouncement {
+		t.Errorf("forged announcement error mismatch: have %v, want %v", err, errInvalidAnnouncement)
+	}
+	if _, ok := local.PeerValidator(node); ok {
+		t.Fatalf("validator recorded from an invalid announcement")
+	}
+	// The announcement of the node itself binds the account to its node key
+	if _, err := local.HandleMsg(node, makeMsg(istanbulAnnounceMsg, payload)); err != nil {
+		t.Fatalf("failed to handle announcement: %v", err)
+	}
+	if validator, ok := local.PeerValidator(node); !ok || validator != signer {
+		t.Errorf("announced validator mismatch: have %x (%v), want %x", validator, ok, signer)
+	}
+	// Validators signing with their node key don't announce anything
+	sent := make(chan struct{}, 1)
+	local.AnnounceValidator(sendFunc(func() { sent <- struct{}{} }))
+	select {
+	case <-sent:
+		t.Errorf("node key validator announced itself")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// sendFunc is a consensus peer calling a function on every message sent.
+type sendFunc func()
+
+func (f sendFunc) Send(msgcode uint64, data interface{}) error {
+	f()
+	return nil
+}
@@ -158,9 +158,21 @@ func (s *Snapshot) uncast(address common.Address, authorize bool) bool {
 	return true
 }
 
+// applyTransition replaces the validator set with the one scheduled to take
+// over at the given block number, discarding all pending votes.
+func (s *Snapshot) applyTransition(config *istanbul.Config, number uint64) {
+	validators, ok := config.TransitionValidators(number)
+	if !ok {
+		return
+	}
+	s.ValSet = validator.NewWeightedSet(validators, s.ValSet.Policy(), s.ValSet.Weights())
+	s.Votes = nil
+	s.Tally = make(map[common.Address]Tally)
+}
+
 // apply creates a new authorization snapshot by applying the given headers to
-// the original one.
-func (s *Snapshot) apply(headers []*types.Header) (*Snapshot, error) {
+// the original one, switching validator sets at any scheduled transitions.
+func (s *Snapshot) apply(headers []*types.Header, config *istanbul.Config) (*Snapshot, error) {
 	// Allow passing in no headers for cleaner code
 	if len(headers) == 0 {
 		return s, nil
@@ -251,6 +263,8 @@ func (s *Snapshot) apply(headers []*types.Header) (*Snapshot, error) {
 			}
 			delete(snap.Tally, header.Coinbase)
 		}
+		// The snapshot at this header decides the validators of the next block
+		snap.applyTransition(config, number+1)
 	}
 	snap.Number += uint64(len(headers))
 	snap.Hash = headers[len(headers)-1].Hash()
@@ -282,8 +296,9 @@ type snapshotJSON struct {
 	Tally  map[common.Address]Tally `json:"tally"`
 
 	// for validator set
-	Validators []common.Address        `json:"validators"`
-	Policy     istanbul.ProposerPolicy `json:"policy"`
+	Validators []common.Address          `json:"validators"`
+	Policy     istanbul.ProposerPolicy   `json:"policy"`
+	Weights    map[common.Address]uint64 `json:"weights,omitempty"`
 }
 
 func (s *Snapshot) toJSONStruct() *snapshotJSON {
@@ -295,6 +310,7 @@ func (s *Snapshot) toJSONStruct() *snapshotJSON {
 		Tally:      s.Tally,
 		Validators: s.validators(),
 		Policy:     s.ValSet.Policy(),
+		Weights:    s.ValSet.Weights(),
 	}
 }
 
@@ -310,7 +326,7 @@ func (s *Snapshot) UnmarshalJSON(b []byte) error {
 	s.Hash = j.Hash
 	s.Votes = j.Votes
 	s.Tally = j.Tally
-	s.ValSet = validator.NewSet(j.Validators, j.Policy)
+	s.ValSet = validator.NewWeightedSet(j.Validators, j.Policy, j.Weights)
 	return nil
 }
 
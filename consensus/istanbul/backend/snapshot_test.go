@@ -77,10 +77,11 @@ func (ap *testerAccountPool) address(account string) common.Address {
 func TestVoting(t *testing.T) {
 	// Define the various voting scenarios to test
 	tests := []struct {
-		epoch      uint64
-		validators []string
-		votes      []testerVote
-		results    []string
+		epoch       uint64
+		validators  []string
+		votes       []testerVote
+		transitions map[uint64][]string
+		results     []string
 	}{
 		{
 			// Single validator, no votes cast
@@ -301,6 +302,17 @@ func TestVoting(t *testing.T) {
 				{validator: "B", voted: "F", auth: true}, // Finish authorizing F, 3/3 votes needed
 			},
 			results: []string{"B", "C", "D", "E", "F"},
+		}, {
+			// Scheduled transitions replace the validator set and discard pending votes
+			validators: []string{"A", "B"},
+			votes: []testerVote{
+				{validator: "A", voted: "E", auth: true},
+				{validator: "B"},
+				{validator: "C"}, // Transition block, only C and D are allowed to seal
+				{validator: "D", voted: "A", auth: true},
+			},
+			transitions: map[uint64][]string{3: {"C", "D"}},
+			results:     []string{"C", "D"},
 		}, {
 			// Epoch transitions reset all votes to allow chain checkpointing
 			epoch:      3,
@@ -342,11 +354,18 @@ func TestVoting(t *testing.T) {
 		db := ethdb.NewMemDatabase()
 		genesis.Commit(db)
 
-		config := istanbul.DefaultConfig
+		config := *istanbul.DefaultConfig
 		if tt.epoch != 0 {
 			config.Epoch = tt.epoch
 		}
-		engine := New(config, accounts.accounts[tt.validators[0]], db).(*backend)
+		for block, validators := range tt.transitions {
+			transition := istanbul.Transition{Block: new(big.Int).SetUint64(block)}
+			for _, validator := range validators {
+				transition.Validators = append(transition.Validators, accounts.address(validator))
+			}
+			config.Transitions = append(config.Transitions, transition)
+		}
+		engine := New(&config, accounts.accounts[tt.validators[0]], db).(*backend)
 		chain, err := core.NewBlockChain(db, nil, genesis.Config, engine, vm.Config{}, nil)
 
 		// Assemble a chain of headers from the cast votes
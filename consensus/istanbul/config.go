@@ -16,11 +16,18 @@
 
 package istanbul
 
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
 type ProposerPolicy uint64
 
 const (
 	RoundRobin ProposerPolicy = iota
 	Sticky
+	Weighted
 )
 
 type Config struct {
@@ -28,6 +35,55 @@ type Config struct {
 	BlockPeriod    uint64         `toml:",omitempty"` // Default minimum difference between two consecutive block's timestamps in second
 	ProposerPolicy ProposerPolicy `toml:",omitempty"` // The policy for proposer selection
 	Epoch          uint64         `toml:",omitempty"` // The number of blocks after which to checkpoint and reset the pending votes
+
+	EmptyBlockPeriod uint64         `toml:"-"`          // Maximum difference between the timestamps of a parent and an empty child block in second, 0 to disable (set from the chain config)
+	Signer           common.Address `toml:",omitempty"` // Account signing seals and messages through the account manager instead of the node key
+	SignerEndpoint   string         `toml:",omitempty"` // Clef endpoint (IPC path or URL) holding the Signer account, instead of the account manager
+
+	ProposerWeights map[common.Address]uint64 `toml:",omitempty"` // The relative proposer weight of each validator, used by the Weighted policy
+	Transitions     []Transition              `toml:",omitempty"` // Changes scheduled at fixed block heights, in ascending block order
+}
+
+// Transition schedules a change of the validator set, block period or request
+// timeout from the given block onwards. Zero values leave the setting as is.
+type Transition struct {
+	Block          *big.Int         // Block number from which the transition is effective
+	Validators     []common.Address // Validator set replacing the voted one at Block
+	BlockPeriod    uint64           // Minimum difference between two consecutive block's timestamps in second
+	RequestTimeout uint64           // The timeout for each Istanbul round in milliseconds
+}
+
+// GetConfig returns a copy of the config with the block period and request
+// timeout of all transitions effective at the given block number applied.
+func (c *Config) GetConfig(number *big.Int) Config {
+	cfg := *c
+	for _, t := range c.Transitions {
+		if t.Block == nil || number == nil || t.Block.Cmp(number) > 0 {
+			continue
+		}
+		if t.BlockPeriod != 0 {
+			cfg.BlockPeriod = t.BlockPeriod
+		}
+		if t.RequestTimeout != 0 {
+			cfg.RequestTimeout = t.RequestTimeout
+		}
+	}
+	return cfg
+}
+
+// TransitionValidators returns the validator set scheduled to take over at
+// exactly the given block number, if any.
+func (c *Config) TransitionValidators(number uint64) ([]common.Address, bool) {
+	var (
+		validators []common.Address
+		found      bool
+	)
+	for _, t := range c.Transitions {
+		if t.Block != nil && t.Block.Uint64() == number && len(t.Validators) > 0 {
+			validators, found = t.Validators, true
+		}
+	}
+	return validators, found
 }
 
 var DefaultConfig = &Config{
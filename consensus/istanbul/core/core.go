@@ -34,7 +34,6 @@ import (
 
 // New creates an Istanbul consensus core
 func New(backend istanbul.Backend, config *istanbul.Config) Engine {
-	r := metrics.NewRegistry()
 	c := &core{
 		config:             config,
 		address:            backend.Address(),
@@ -47,15 +46,11 @@ func New(backend istanbul.Backend, config *istanbul.Config) Engine {
 		pendingRequests:    prque.New(),
 		pendingRequestsMu:  new(sync.Mutex),
 		consensusTimestamp: time.Time{},
-		roundMeter:         metrics.NewMeter(),
-		sequenceMeter:      metrics.NewMeter(),
-		consensusTimer:     metrics.NewTimer(),
+		roundMeter:         metrics.GetOrRegisterMeter("consensus/istanbul/core/round", nil),
+		sequenceMeter:      metrics.GetOrRegisterMeter("consensus/istanbul/core/sequence", nil),
+		consensusTimer:     metrics.GetOrRegisterTimer("consensus/istanbul/core/consensus", nil),
 	}
 
-	r.Register("consensus/istanbul/core/round", c.roundMeter)
-	r.Register("consensus/istanbul/core/sequence", c.sequenceMeter)
-	r.Register("consensus/istanbul/core/consensus", c.consensusTimer)
-
 	c.validateFn = c.checkValidatorSignature
 	return c
 }
@@ -246,7 +241,7 @@ func (c *core) startNewRound(round *big.Int) {
 	// New snapshot for new round
 	c.updateRoundState(newView, c.valSet, roundChange)
 	// Calculate new proposer
-	c.valSet.CalcProposer(lastProposer, newView.Round.Uint64())
+	c.valSet.CalcProposer(lastProposer, newView.Sequence.Uint64(), newView.Round.Uint64())
 	c.waitingForRoundChange = false
 	c.setState(StateAcceptRequest)
 	if roundChange && c.IsProposer() && c.current != nil {
@@ -327,10 +322,13 @@ func (c *core) newRoundChangeTimer() {
 	c.stopTimer()
 
 	// set timeout based on the round number
-	timeout := time.Duration(c.config.RequestTimeout) * time.Millisecond
+	timeout := time.Duration(c.config.GetConfig(c.current.Sequence()).RequestTimeout) * time.Millisecond
 	round := c.current.Round().Uint64()
 	if round > 0 {
 		timeout += time.Duration(math.Pow(2, float64(round))) * time.Second
+	} else {
+		// the proposer may legitimately hold back an empty block
+		timeout += time.Duration(c.config.EmptyBlockPeriod) * time.Second
 	}
 
 	c.roundChangeTimer = time.AfterFunc(timeout, func() {
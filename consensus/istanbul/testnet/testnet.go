@@ -0,0 +1,509 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package testnet runs a set of Istanbul validators in a single process, to test
+// the safety and liveness of the consensus under injected network faults.
+package testnet
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/istanbul"
+	"github.com/ethereum/go-ethereum/consensus/istanbul/backend"
+	istanbulCore "github.com/ethereum/go-ethereum/consensus/istanbul/core"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+const (
+	// istanbulMsg is the eth protocol message code of Istanbul messages.
+	istanbulMsg = 0x11
+
+	// Codes of the Istanbul consensus messages, as defined by the core.
+	msgPrepare = 1
+	msgCommit  = 2
+)
+
+// Fault describes how a single consensus message is delivered by the network.
+// The zero value delivers the message unchanged and immediately.
+type Fault struct {
+	Drop       bool          // Never deliver the message
+	Delay      time.Duration // Deliver the message after the given delay
+	Duplicate  bool          // Deliver the message twice
+	Corrupt    bool          // Deliver a tampered copy of the message, failing its signature check
+	Equivocate bool          // Deliver a validly signed PREPARE or COMMIT for a different block in the same round
+}
+
+// FaultFn decides the fault injected into a consensus message sent from the
+// validator with index from to the one with index to.
+type FaultFn func(from, to int, payload []byte) Fault
+
+// Network runs a set of Istanbul validators with real backends, exchanging
+// consensus messages over in-memory p2p message pipes.
+type Network struct {
+	nodes []*node
+
+	faultMu     sync.RWMutex
+	faults      FaultFn
+	equivocated uint64 // Number of conflicting messages delivered (atomic access)
+
+	commitMu  sync.Mutex
+	commits   map[uint64]common.Hash // Hash of the block committed at each height
+	conflicts []error                // Conflicting commits seen so far
+}
+
+// node is a single validator of the network. It acts as the eth protocol
+// manager and miner of its backend: it provides peers, imports committed
+// blocks and keeps sealing on top of the current head.
+type node struct {
+	index   int
+	network *Network
+	key     *ecdsa.PrivateKey
+	address common.Address
+	engine  consensus.Istanbul
+	handler consensus.Handler
+	chain   *core.BlockChain
+	config  *istanbul.Config
+	peers   map[common.Address]*peer // Outbound links to every other validator
+	started bool                     // Whether the engine and sealing loop are running
+
+	importMu sync.Mutex
+	headCh   chan struct{}
+	quit     chan struct{}
+	done     chan struct{}
+}
+
+// New creates the given number of validators sharing the same genesis block, all
+// of them started and sealing.
+func New(size int, config *istanbul.Config) (*Network, error) {
+	keys := make([]*ecdsa.PrivateKey, size)
+	addrs := make([]common.Address, size)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		addrs[i] = crypto.PubkeyToAddress(keys[i].PublicKey)
+	}
+	genesis, err := genesisBlock(addrs)
+	if err != nil {
+		return nil, err
+	}
+	network := &Network{commits: make(map[uint64]common.Hash)}
+	for i, key := range keys {
+		db := ethdb.NewMemDatabase()
+		genesis.MustCommit(db)
+
+		engine := backend.New(config, key, db)
+		chain, err := core.NewBlockChain(db, nil, genesis.Config, engine, vm.Config{}, nil)
+		if err != nil {
+			return nil, err
+		}
+		n := &node{
+			index:   i,
+			network: network,
+			key:     key,
+			address: addrs[i],
+			engine:  engine,
+			handler: engine.(consensus.Handler),
+			chain:   chain,
+			config:  config,
+			peers:   make(map[common.Address]*peer),
+			headCh:  make(chan struct{}, 1),
+			quit:    make(chan struct{}),
+			done:    make(chan struct{}),
+		}
+		n.handler.SetBroadcaster(n)
+		network.nodes = append(network.nodes, n)
+	}
+	// Connect every pair of validators in both directions
+	for _, from := range network.nodes {
+		for _, to := range network.nodes {
+			if from != to {
+				from.peers[to.address] = newPeer(from, to)
+			}
+		}
+	}
+	for _, n := range network.nodes {
+		if err := n.engine.Start(n.chain, n.chain.CurrentBlock, n.chain.HasBadBlock); err != nil {
+			network.Stop()
+			return nil, fmt.Errorf("failed to start validator %d: %v", n.index, err)
+		}
+		n.started = true
+		go n.mine()
+	}
+	return network, nil
+}
+
+// genesisBlock creates an Istanbul genesis block with the given validators.
+func genesisBlock(validators []common.Address) (*core.Genesis, error) {
+	extra, err := rlp.EncodeToBytes(&types.IstanbulExtra{
+		Validators:    validators,
+		Seal:          []byte{},
+		CommittedSeal: [][]byte{},
+	})
+	if err != nil {
+		return nil, err
+	}
+	config := *params.TestChainConfig
+	config.Ethash = nil
+	config.Istanbul = &params.IstanbulConfig{}
+
+	genesis := core.DefaultGenesisBlock()
+	genesis.Config = &config
+	genesis.Difficulty = big.NewInt(1)
+	genesis.Nonce = 0
+	genesis.Mixhash = types.IstanbulDigest
+	genesis.ExtraData = append(make([]byte, types.IstanbulExtraVanity), extra...)
+	return genesis, nil
+}
+
+// Size returns the number of validators in the network.
+func (net *Network) Size() int {
+	return len(net.nodes)
+}
+
+// Address returns the address of the validator with the given index.
+func (net *Network) Address(index int) common.Address {
+	return net.nodes[index].address
+}
+
+// Head returns the current head block number of the validator with the given
+// index.
+func (net *Network) Head(index int) uint64 {
+	return net.nodes[index].chain.CurrentBlock().NumberU64()
+}
+
+// Equivocations returns the number of conflicting PREPARE and COMMIT messages
+// delivered so far.
+func (net *Network) Equivocations() uint64 {
+	return atomic.LoadUint64(&net.equivocated)
+}
+
+// SetFaults replaces the fault injection of the network, nil delivers every
+// message unchanged.
+func (net *Network) SetFaults(faults FaultFn) {
+	net.faultMu.Lock()
+	defer net.faultMu.Unlock()
+	net.faults = faults
+}
+
+// Stop terminates all validators of the network.
+func (net *Network) Stop() {
+	for _, n := range net.nodes {
+		close(n.quit)
+		if n.started {
+			<-n.done
+			n.engine.Stop()
+		}
+	}
+	for _, n := range net.nodes {
+		n.chain.Stop()
+		for _, p := range n.peers {
+			p.rw.Close()
+		}
+	}
+}
+
+// commit records a block committed by any validator, remembering it as a
+// safety violation if a different block was already committed at its height.
+func (net *Network) commit(n *node, block *types.Block) {
+	net.commitMu.Lock()
+	defer net.commitMu.Unlock()
+
+	number := block.NumberU64()
+	if hash, ok := net.commits[number]; ok && hash != block.Hash() {
+		net.conflicts = append(net.conflicts, fmt.Errorf("validator %d committed conflicting block %d: have %x, want %x", n.index, number, block.Hash(), hash))
+		return
+	}
+	net.commits[number] = block.Hash()
+}
+
+// WaitForHeight waits until every validator imported the given height.
+func (net *Network) WaitForHeight(number uint64, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		var lagging []int
+		for _, n := range net.nodes {
+			if n.chain.CurrentBlock().NumberU64() < number {
+				lagging = append(lagging, n.index)
+			}
+		}
+		if len(lagging) == 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("validators %v did not reach height %d in %v", lagging, number, timeout)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// CheckSafety verifies that no conflicting blocks were ever committed and that
+// all validators agree on every block up to the lowest head in the network.
+func (net *Network) CheckSafety() error {
+	net.commitMu.Lock()
+	conflicts := net.conflicts
+	net.commitMu.Unlock()
+	if len(conflicts) > 0 {
+		return conflicts[0]
+	}
+	head := net.Head(0)
+	for i := range net.nodes {
+		if number := net.Head(i); number < head {
+			head = number
+		}
+	}
+	for number := uint64(1); number <= head; number++ {
+		want := net.nodes[0].chain.GetBlockByNumber(number).Hash()
+		for _, n := range net.nodes[1:] {
+			if have := n.chain.GetBlockByNumber(number).Hash(); have != want {
+				return fmt.Errorf("validator %d block %d mismatch: have %x, want %x", n.index, number, have, want)
+			}
+		}
+	}
+	return nil
+}
+
+// Enqueue implements consensus.Broadcaster, importing blocks committed by the
+// core which were proposed by another validator.
+func (n *node) Enqueue(id string, block *types.Block) {
+	go n.importBlock(block, nil)
+}
+
+// FindPeers implements consensus.Broadcaster, returning the links to every
+// other validator that is targeted.
+func (n *node) FindPeers(targets map[common.Address]bool) map[common.Address]consensus.Peer {
+	peers := make(map[common.Address]consensus.Peer)
+	for addr, p := range n.peers {
+		if targets[addr] {
+			peers[addr] = p
+		}
+	}
+	return peers
+}
+
+// mine keeps sealing a new block on top of the current head until the head
+// changes or the node is stopped, like the miner does.
+func (n *node) mine() {
+	defer close(n.done)
+
+	for {
+		results := make(chan *types.Block, 1)
+		stop := make(chan struct{})
+		if block, err := n.makeBlock(n.chain.CurrentBlock()); err == nil {
+			go n.engine.Seal(n.chain, block, results, stop)
+		}
+		select {
+		case result := <-results:
+			if result != nil {
+				n.importBlock(result, nil)
+			}
+		case <-n.headCh:
+			close(stop)
+		case <-n.quit:
+			close(stop)
+			return
+		}
+	}
+}
+
+// makeBlock assembles an empty, unsealed block on top of the parent.
+func (n *node) makeBlock(parent *types.Block) (*types.Block, error) {
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number(), common.Big1),
+		GasLimit:   core.CalcGasLimit(parent, parent.GasLimit(), parent.GasLimit()),
+		Extra:      parent.Extra(),
+		Time:       new(big.Int).Add(parent.Time(), new(big.Int).SetUint64(n.config.BlockPeriod)),
+		Difficulty: big.NewInt(1),
+	}
+	if err := n.engine.Prepare(n.chain, header); err != nil {
+		return nil, err
+	}
+	state, _, err := n.chain.StateAt(parent.Root())
+	if err != nil {
+		return nil, err
+	}
+	return n.engine.Finalize(n.chain, header, state, nil, nil, nil)
+}
+
+// importBlock inserts a committed block into the local chain, fetching any
+// missing ancestors from the given validator, and announces it to the rest of
+// the network like the eth protocol would.
+func (n *node) importBlock(block *types.Block, from *node) {
+	n.importMu.Lock()
+	defer n.importMu.Unlock()
+
+	select {
+	case <-n.quit:
+		return
+	default:
+	}
+	if n.chain.HasBlock(block.Hash(), block.NumberU64()) {
+		return
+	}
+	blocks := types.Blocks{block}
+	for from != nil && !n.chain.HasBlock(blocks[0].ParentHash(), blocks[0].NumberU64()-1) {
+		parent := from.chain.GetBlock(blocks[0].ParentHash(), blocks[0].NumberU64()-1)
+		if parent == nil {
+			return
+		}
+		blocks = append(types.Blocks{parent}, blocks...)
+	}
+	// A stopped chain silently skips the import, so check the outcome
+	if _, err := n.chain.InsertChain(blocks); err != nil || !n.chain.HasBlock(block.Hash(), block.NumberU64()) {
+		return
+	}
+	n.network.commit(n, block)
+	n.handler.NewChainHead()
+	select {
+	case n.headCh <- struct{}{}:
+	default:
+	}
+	for _, other := range n.network.nodes {
+		if other != n {
+			go other.importBlock(block, n)
+		}
+	}
+}
+
+// peer is a one way link between two validators. Messages are written to an
+// in-memory p2p pipe after applying the faults configured in the network, and
+// handed to the receiving backend as they are read from the other end.
+type peer struct {
+	from, to *node
+	rw       *p2p.MsgPipeRW
+}
+
+// newPeer connects the two validators and starts delivering the messages sent
+// over the link.
+func newPeer(from, to *node) *peer {
+	local, remote := p2p.MsgPipe()
+	go func() {
+		for {
+			msg, err := remote.ReadMsg()
+			if err != nil {
+				return
+			}
+			to.handler.HandleMsg(from.address, msg)
+			msg.Discard()
+		}
+	}()
+	return &peer{from: from, to: to, rw: local}
+}
+
+// Send implements consensus.Peer.
+func (p *peer) Send(msgcode uint64, data interface{}) error {
+	payload, ok := data.([]byte)
+	if !ok {
+		return fmt.Errorf("unexpected message data %T", data)
+	}
+	var f Fault
+	p.from.network.faultMu.RLock()
+	if faults := p.from.network.faults; faults != nil && msgcode == istanbulMsg {
+		f = faults(p.from.index, p.to.index, payload)
+	}
+	p.from.network.faultMu.RUnlock()
+
+	if f.Drop {
+		return nil
+	}
+	if f.Equivocate {
+		if conflicting, err := p.from.equivocate(payload); err == nil {
+			payload = conflicting
+			atomic.AddUint64(&p.from.network.equivocated, 1)
+		}
+	}
+	if f.Corrupt {
+		payload = common.CopyBytes(payload)
+		payload[rand.Intn(len(payload))] ^= 0xff
+	}
+	if f.Delay > 0 {
+		time.Sleep(f.Delay)
+	}
+	if err := p2p.Send(p.rw, msgcode, payload); err != nil {
+		return err
+	}
+	if f.Duplicate {
+		return p2p.Send(p.rw, msgcode, payload)
+	}
+	return nil
+}
+
+// message mirrors the encoding of Istanbul consensus messages.
+type message struct {
+	Code          uint64
+	Msg           []byte
+	Address       common.Address
+	Signature     []byte
+	CommittedSeal []byte
+}
+
+// errNotEquivocable is returned when asked to equivocate a message which isn't
+// a PREPARE or COMMIT.
+var errNotEquivocable = errors.New("not a prepare or commit message")
+
+// equivocate turns a PREPARE or COMMIT of the validator into one for a different
+// block in the same round, validly signed with the validator key.
+func (n *node) equivocate(payload []byte) ([]byte, error) {
+	var msg message
+	if err := rlp.DecodeBytes(payload, &msg); err != nil {
+		return nil, err
+	}
+	if msg.Code != msgPrepare && msg.Code != msgCommit {
+		return nil, errNotEquivocable
+	}
+	var subject istanbul.Subject
+	if err := rlp.DecodeBytes(msg.Msg, &subject); err != nil {
+		return nil, err
+	}
+	subject.Digest = crypto.Keccak256Hash(subject.Digest[:])
+
+	enc, err := rlp.EncodeToBytes(&subject)
+	if err != nil {
+		return nil, err
+	}
+	msg.Msg = enc
+	if msg.Code == msgCommit {
+		seal, err := crypto.Sign(crypto.Keccak256(istanbulCore.PrepareCommittedSeal(subject.Digest)), n.key)
+		if err != nil {
+			return nil, err
+		}
+		msg.CommittedSeal = seal
+	}
+	msg.Signature = []byte{}
+	unsigned, err := rlp.EncodeToBytes(&msg)
+	if err != nil {
+		return nil, err
+	}
+	if msg.Signature, err = crypto.Sign(crypto.Keccak256(unsigned), n.key); err != nil {
+		return nil, err
+	}
+	return rlp.EncodeToBytes(&msg)
+}
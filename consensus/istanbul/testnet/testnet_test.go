@@ -0,0 +1,150 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package testnet
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/consensus/istanbul"
+)
+
+func newTestNetwork(t *testing.T, n int) *Network {
+	config := *istanbul.DefaultConfig
+	config.BlockPeriod = 0
+	config.RequestTimeout = 1000
+
+	net, err := New(n, &config)
+	if err != nil {
+		t.Fatalf("failed to create network: %v", err)
+	}
+	return net
+}
+
+func checkSafety(t *testing.T, net *Network) {
+	if err := net.CheckSafety(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestNetworkCommits(t *testing.T) {
+	net := newTestNetwork(t, 4)
+	defer net.Stop()
+
+	if err := net.WaitForHeight(5, 30*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	checkSafety(t, net)
+}
+
+func TestNetworkUnreliableMessages(t *testing.T) {
+	net := newTestNetwork(t, 4)
+	defer net.Stop()
+
+	var mu sync.Mutex
+	rnd := rand.New(rand.NewSource(1))
+	net.SetFaults(func(from, to int, payload []byte) Fault {
+		mu.Lock()
+		defer mu.Unlock()
+		return Fault{
+			Drop:      rnd.Intn(10) == 0,
+			Delay:     time.Duration(rnd.Intn(50)) * time.Millisecond,
+			Duplicate: rnd.Intn(10) == 0,
+		}
+	})
+	if err := net.WaitForHeight(5, 60*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	checkSafety(t, net)
+}
+
+func TestNetworkSilentValidator(t *testing.T) {
+	net := newTestNetwork(t, 4)
+	defer net.Stop()
+
+	// The silent validator is still the proposer every fourth round, so the
+	// remaining ones have to change rounds to make progress.
+	net.SetFaults(func(from, to int, payload []byte) Fault {
+		return Fault{Drop: from == 0}
+	})
+	if err := net.WaitForHeight(6, 60*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	checkSafety(t, net)
+}
+
+func TestNetworkCorruptingValidator(t *testing.T) {
+	net := newTestNetwork(t, 4)
+	defer net.Stop()
+
+	net.SetFaults(func(from, to int, payload []byte) Fault {
+		return Fault{Corrupt: from == 0, Duplicate: from == 0}
+	})
+	if err := net.WaitForHeight(6, 60*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	checkSafety(t, net)
+}
+
+func TestNetworkEquivocatingValidator(t *testing.T) {
+	net := newTestNetwork(t, 4)
+	defer net.Stop()
+
+	// The byzantine validator prepares and commits a different block towards
+	// half of the network in every round.
+	net.SetFaults(func(from, to int, payload []byte) Fault {
+		return Fault{Equivocate: from == 0 && to%2 == 1}
+	})
+	if err := net.WaitForHeight(6, 60*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if net.Equivocations() == 0 {
+		t.Fatal("no conflicting messages delivered")
+	}
+	checkSafety(t, net)
+}
+
+func TestNetworkNoQuorum(t *testing.T) {
+	net := newTestNetwork(t, 4)
+	defer net.Stop()
+
+	if err := net.WaitForHeight(1, 30*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	// Without a quorum of reachable validators no block may be committed
+	net.SetFaults(func(from, to int, payload []byte) Fault {
+		return Fault{Drop: from < 2}
+	})
+	time.Sleep(time.Second)
+	head := net.Head(2)
+	time.Sleep(3 * time.Second)
+	for i := 0; i < net.Size(); i++ {
+		if number := net.Head(i); number > head+1 {
+			t.Errorf("validator %d made progress without quorum: have %d, want <= %d", i, number, head+1)
+		}
+	}
+	checkSafety(t, net)
+
+	// Liveness is restored once the network heals
+	net.SetFaults(nil)
+	if err := net.WaitForHeight(head+3, 60*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	checkSafety(t, net)
+}
@@ -50,7 +50,7 @@ func (slice Validators) Swap(i, j int) {
 
 type ValidatorSet interface {
 	// Calculate the proposer
-	CalcProposer(lastProposer common.Address, round uint64)
+	CalcProposer(lastProposer common.Address, sequence uint64, round uint64)
 	// Return the validator size
 	Size() int
 	// Return the validator array
@@ -73,8 +73,10 @@ type ValidatorSet interface {
 	F() int
 	// Get proposer policy
 	Policy() ProposerPolicy
+	// Get the configured proposer weights
+	Weights() map[common.Address]uint64
 }
 
 // ----------------------------------------------------------------------------
 
-type ProposalSelector func(ValidatorSet, common.Address, uint64) Validator
+type ProposalSelector func(valSet ValidatorSet, lastProposer common.Address, sequence uint64, round uint64) Validator
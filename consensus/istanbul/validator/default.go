@@ -24,6 +24,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus/istanbul"
+	"github.com/ethereum/go-ethereum/params"
 )
 
 type defaultValidator struct {
@@ -43,16 +44,18 @@ func (val *defaultValidator) String() string {
 type defaultSet struct {
 	validators istanbul.Validators
 	policy     istanbul.ProposerPolicy
+	weights    map[common.Address]uint64
 
 	proposer    istanbul.Validator
 	validatorMu sync.RWMutex
 	selector    istanbul.ProposalSelector
 }
 
-func newDefaultSet(addrs []common.Address, policy istanbul.ProposerPolicy) *defaultSet {
+func newDefaultSet(addrs []common.Address, policy istanbul.ProposerPolicy, weights map[common.Address]uint64) *defaultSet {
 	valSet := &defaultSet{}
 
 	valSet.policy = policy
+	valSet.weights = weights
 	// init validators
 	valSet.validators = make([]istanbul.Validator, len(addrs))
 	for i, addr := range addrs {
@@ -64,9 +67,13 @@ func newDefaultSet(addrs []common.Address, policy istanbul.ProposerPolicy) *defa
 	if valSet.Size() > 0 {
 		valSet.proposer = valSet.GetByIndex(0)
 	}
-	valSet.selector = roundRobinProposer
-	if policy == istanbul.Sticky {
+	switch policy {
+	case istanbul.Sticky:
 		valSet.selector = stickyProposer
+	case istanbul.Weighted:
+		valSet.selector = weightedProposer
+	default:
+		valSet.selector = roundRobinProposer
 	}
 
 	return valSet
@@ -111,10 +118,10 @@ func (valSet *defaultSet) IsProposer(address common.Address) bool {
 	return reflect.DeepEqual(valSet.GetProposer(), val)
 }
 
-func (valSet *defaultSet) CalcProposer(lastProposer common.Address, round uint64) {
+func (valSet *defaultSet) CalcProposer(lastProposer common.Address, sequence uint64, round uint64) {
 	valSet.validatorMu.RLock()
 	defer valSet.validatorMu.RUnlock()
-	valSet.proposer = valSet.selector(valSet, lastProposer, round)
+	valSet.proposer = valSet.selector(valSet, lastProposer, sequence, round)
 }
 
 func calcSeed(valSet istanbul.ValidatorSet, proposer common.Address, round uint64) uint64 {
@@ -129,7 +136,7 @@ func emptyAddress(addr common.Address) bool {
 	return addr == common.Address{}
 }
 
-func roundRobinProposer(valSet istanbul.ValidatorSet, proposer common.Address, round uint64) istanbul.Validator {
+func roundRobinProposer(valSet istanbul.ValidatorSet, proposer common.Address, sequence uint64, round uint64) istanbul.Validator {
 	if valSet.Size() == 0 {
 		return nil
 	}
@@ -143,7 +150,7 @@ func roundRobinProposer(valSet istanbul.ValidatorSet, proposer common.Address, r
 	return valSet.GetByIndex(pick)
 }
 
-func stickyProposer(valSet istanbul.ValidatorSet, proposer common.Address, round uint64) istanbul.Validator {
+func stickyProposer(valSet istanbul.ValidatorSet, proposer common.Address, sequence uint64, round uint64) istanbul.Validator {
 	if valSet.Size() == 0 {
 		return nil
 	}
@@ -157,6 +164,46 @@ func stickyProposer(valSet istanbul.ValidatorSet, proposer common.Address, round
 	return valSet.GetByIndex(pick)
 }
 
+// weightedProposer picks the proposer from a deterministic cycle in which every
+// validator owns as many consecutive slots as its weight. The slot for round 0
+// is chosen by the sequence, so all nodes agree on the proposer without any
+// extra state. Subsequent rounds move on to the next validators in the set, so
+// a round change never hands the proposal back to the validator that just failed
+// to propose.
+func weightedProposer(valSet istanbul.ValidatorSet, proposer common.Address, sequence uint64, round uint64) istanbul.Validator {
+	if valSet.Size() == 0 {
+		return nil
+	}
+	var (
+		validators = valSet.List()
+		weights    = valSet.Weights()
+		cumulative = make([]uint64, len(validators))
+		total      uint64
+	)
+	for i, val := range validators {
+		total += proposerWeight(weights, val.Address())
+		cumulative[i] = total
+	}
+	slot := sequence % total
+	owner := sort.Search(len(cumulative), func(i int) bool { return cumulative[i] > slot })
+
+	return validators[(uint64(owner)+round)%uint64(len(validators))]
+}
+
+// proposerWeight returns the proposer weight of a validator, bounded to the range
+// [1, MaxIstanbulProposerWeight]. Validators without a configured weight are given
+// a weight of 1.
+func proposerWeight(weights map[common.Address]uint64, addr common.Address) uint64 {
+	switch w := weights[addr]; {
+	case w == 0:
+		return 1
+	case w > params.MaxIstanbulProposerWeight:
+		return params.MaxIstanbulProposerWeight
+	default:
+		return w
+	}
+}
+
 func (valSet *defaultSet) AddValidator(address common.Address) bool {
 	valSet.validatorMu.Lock()
 	defer valSet.validatorMu.Unlock()
@@ -193,9 +240,11 @@ func (valSet *defaultSet) Copy() istanbul.ValidatorSet {
 	for _, v := range valSet.validators {
 		addresses = append(addresses, v.Address())
 	}
-	return NewSet(addresses, valSet.policy)
+	return NewWeightedSet(addresses, valSet.policy, valSet.weights)
 }
 
 func (valSet *defaultSet) F() int { return int(math.Ceil(float64(valSet.Size())/3)) - 1 }
 
 func (valSet *defaultSet) Policy() istanbul.ProposerPolicy { return valSet.policy }
+
+func (valSet *defaultSet) Weights() map[common.Address]uint64 { return valSet.weights }
@@ -17,6 +17,7 @@
 package validator
 
 import (
+	"math"
 	"reflect"
 	"strings"
 	"testing"
@@ -24,6 +25,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus/istanbul"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
 )
 
 var (
@@ -36,6 +38,7 @@ func TestValidatorSet(t *testing.T) {
 	testNormalValSet(t)
 	testEmptyValSet(t)
 	testStickyProposer(t)
+	testWeightedProposer(t)
 	testAddAndRemoveValidator(t)
 }
 
@@ -78,7 +81,7 @@ func testNormalValSet(t *testing.T) {
 	val1 := New(addr1)
 	val2 := New(addr2)
 
-	valSet := newDefaultSet([]common.Address{addr1, addr2}, istanbul.RoundRobin)
+	valSet := newDefaultSet([]common.Address{addr1, addr2}, istanbul.RoundRobin, nil)
 	if valSet == nil {
 		t.Errorf("the format of validator set is invalid")
 		t.FailNow()
@@ -111,17 +114,17 @@ func testNormalValSet(t *testing.T) {
 	}
 	// test calculate proposer
 	lastProposer := addr1
-	valSet.CalcProposer(lastProposer, uint64(0))
+	valSet.CalcProposer(lastProposer, uint64(1), uint64(0))
 	if val := valSet.GetProposer(); !reflect.DeepEqual(val, val2) {
 		t.Errorf("proposer mismatch: have %v, want %v", val, val2)
 	}
-	valSet.CalcProposer(lastProposer, uint64(3))
+	valSet.CalcProposer(lastProposer, uint64(1), uint64(3))
 	if val := valSet.GetProposer(); !reflect.DeepEqual(val, val1) {
 		t.Errorf("proposer mismatch: have %v, want %v", val, val1)
 	}
 	// test empty last proposer
 	lastProposer = common.Address{}
-	valSet.CalcProposer(lastProposer, uint64(3))
+	valSet.CalcProposer(lastProposer, uint64(1), uint64(3))
 	if val := valSet.GetProposer(); !reflect.DeepEqual(val, val2) {
 		t.Errorf("proposer mismatch: have %v, want %v", val, val2)
 	}
@@ -182,7 +185,7 @@ func testStickyProposer(t *testing.T) {
 	val1 := New(addr1)
 	val2 := New(addr2)
 
-	valSet := newDefaultSet([]common.Address{addr1, addr2}, istanbul.Sticky)
+	valSet := newDefaultSet([]common.Address{addr1, addr2}, istanbul.Sticky, nil)
 
 	// test get proposer
 	if val := valSet.GetProposer(); !reflect.DeepEqual(val, val1) {
@@ -190,19 +193,61 @@ func testStickyProposer(t *testing.T) {
 	}
 	// test calculate proposer
 	lastProposer := addr1
-	valSet.CalcProposer(lastProposer, uint64(0))
+	valSet.CalcProposer(lastProposer, uint64(1), uint64(0))
 	if val := valSet.GetProposer(); !reflect.DeepEqual(val, val1) {
 		t.Errorf("proposer mismatch: have %v, want %v", val, val1)
 	}
 
-	valSet.CalcProposer(lastProposer, uint64(1))
+	valSet.CalcProposer(lastProposer, uint64(1), uint64(1))
 	if val := valSet.GetProposer(); !reflect.DeepEqual(val, val2) {
 		t.Errorf("proposer mismatch: have %v, want %v", val, val2)
 	}
 	// test empty last proposer
 	lastProposer = common.Address{}
-	valSet.CalcProposer(lastProposer, uint64(3))
+	valSet.CalcProposer(lastProposer, uint64(1), uint64(3))
 	if val := valSet.GetProposer(); !reflect.DeepEqual(val, val2) {
 		t.Errorf("proposer mismatch: have %v, want %v", val, val2)
 	}
 }
+
+func testWeightedProposer(t *testing.T) {
+	addr1 := common.BytesToAddress(common.Hex2Bytes(testAddress))
+	addr2 := common.BytesToAddress(common.Hex2Bytes(testAddress2))
+	weights := map[common.Address]uint64{addr1: 3, addr2: 1}
+
+	valSet := newDefaultSet([]common.Address{addr1, addr2}, istanbul.Weighted, weights)
+
+	// test proposals over a full cycle follow the configured weights
+	counts := make(map[common.Address]int)
+	for seq := uint64(1); seq <= 8; seq++ {
+		valSet.CalcProposer(common.Address{}, seq, 0)
+		counts[valSet.GetProposer().Address()]++
+	}
+	if counts[addr1] != 6 || counts[addr2] != 2 {
+		t.Errorf("proposal count mismatch: have %d/%d, want 6/2", counts[addr1], counts[addr2])
+	}
+	// test round change moves on to a different validator
+	for seq := uint64(1); seq <= 4; seq++ {
+		valSet.CalcProposer(common.Address{}, seq, 0)
+		first := valSet.GetProposer()
+		valSet.CalcProposer(common.Address{}, seq, 1)
+		if second := valSet.GetProposer(); second.Address() == first.Address() {
+			t.Errorf("proposer mismatch at sequence %d: round change kept proposer %v", seq, first)
+		}
+	}
+	// test huge weights are bounded without expanding a schedule
+	huge := newDefaultSet([]common.Address{addr1, addr2}, istanbul.Weighted, map[common.Address]uint64{addr1: math.MaxUint64})
+	huge.CalcProposer(common.Address{}, params.MaxIstanbulProposerWeight-1, 0)
+	if val := huge.GetProposer(); val.Address() != addr1 {
+		t.Errorf("proposer mismatch: have %v, want %v", val, addr1)
+	}
+	huge.CalcProposer(common.Address{}, params.MaxIstanbulProposerWeight, 0)
+	if val := huge.GetProposer(); val.Address() != addr2 {
+		t.Errorf("proposer mismatch: have %v, want %v", val, addr2)
+	}
+	// test the copy retains the weights
+	cpy := valSet.Copy()
+	if !reflect.DeepEqual(cpy.Weights(), weights) {
+		t.Errorf("weights mismatch: have %v, want %v", cpy.Weights(), weights)
+	}
+}
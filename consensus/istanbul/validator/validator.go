@@ -28,7 +28,13 @@ func New(addr common.Address) istanbul.Validator {
 }
 
 func NewSet(addrs []common.Address, policy istanbul.ProposerPolicy) istanbul.ValidatorSet {
-	return newDefaultSet(addrs, policy)
+	return newDefaultSet(addrs, policy, nil)
+}
+
+// NewWeightedSet creates a validator set carrying the given proposer weights,
+// which are only consulted by the Weighted proposer policy.
+func NewWeightedSet(addrs []common.Address, policy istanbul.ProposerPolicy, weights map[common.Address]uint64) istanbul.ValidatorSet {
+	return newDefaultSet(addrs, policy, weights)
 }
 
 func ExtractValidators(extraData []byte) []common.Address {
@@ -292,6 +292,30 @@ func TestPrettyError(t *testing.T) {
 	}
 }
 
+// Tests that the optional arguments of admin.banPeer may be omitted.
+func TestBanPeer(t *testing.T) {
+	tester := newTester(t, nil)
+	defer tester.Close(t)
+
+	tester.console.Evaluate(`admin.banPeer("` + fmt.Sprintf("%064x", 1) + `")`)
+	tester.console.Evaluate(`admin.banPeer("` + fmt.Sprintf("%064x", 2) + `", 3600)`)
+	tester.console.Evaluate(`admin.banPeer("` + fmt.Sprintf("%064x", 3) + `", "60", "spam")`)
+
+	bans := tester.stack.Server().BannedPeers()
+	if len(bans) != 3 {
+		t.Fatalf("ban count mismatch: have %d, want 3\n%s", len(bans), tester.output)
+	}
+	if bans[0].Expires != nil || bans[0].Reason != "banned by admin" {
+		t.Errorf("default ban mismatch: %+v", bans[0])
+	}
+	if bans[1].Expires == nil || time.Until(*bans[1].Expires) < 59*time.Minute {
+		t.Errorf("timed ban mismatch: %+v", bans[1])
+	}
+	if bans[2].Expires == nil || time.Until(*bans[2].Expires) > time.Minute || bans[2].Reason != "spam" {
+		t.Errorf("ban with reason mismatch: %+v", bans[2])
+	}
+}
+
 // Tests that tests if the number of indents for JS input is calculated correct.
 func TestIndenting(t *testing.T) {
 	testCases := []struct {
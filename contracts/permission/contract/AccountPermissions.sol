@@ -0,0 +1,41 @@
+pragma solidity ^0.4.24;
+
+/**
+ * AccountPermissions holds the ledger access of accounts on a permissioned
+ * network. Nodes read the access mapping directly from the contract storage
+ * at the accessSlot declared in the genesis, which is 0 for this contract as
+ * the mapping is its first state variable. Changing the storage layout
+ * requires declaring the new slot.
+ *
+ * Access levels: 0 unset (genesis configuration applies), 1 read-only,
+ * 2 transact, 3 contract deploy, 4 admin.
+ */
+contract AccountPermissions {
+    uint8 constant ReadOnly = 1;
+    uint8 constant Admin = 4;
+
+    mapping(address => uint8) public access;
+
+    event AccessChanged(address indexed account, uint8 access);
+
+    modifier onlyAdmin() {
+        require(access[msg.sender] == Admin);
+        _;
+    }
+
+    constructor(address[] admins) public {
+        for (uint i = 0; i < admins.length; i++) {
+            access[admins[i]] = Admin;
+            emit AccessChanged(admins[i], Admin);
+        }
+    }
+
+    // setAccess changes the access of an account. Setting it to 0 reverts the
+    // account to the access in the genesis configuration.
+    function setAccess(address account, uint8 level) public onlyAdmin {
+        require(level <= Admin);
+        require(account != msg.sender);
+        access[account] = level;
+        emit AccessChanged(account, level);
+    }
+}
@@ -0,0 +1,138 @@
+pragma solidity ^0.4.24;
+
+/**
+ * NodeRegistry keeps the set of nodes allowed to join a permissioned network.
+ * Nodes are keyed by their enode ID, the keccak256 hash of the node public key.
+ * New nodes are proposed by an admin and become approved once a majority of
+ * the current admins voted for them, votes of removed admins don't count.
+ */
+contract NodeRegistry {
+    enum Status { NotInList, PendingApproval, Approved, Deactivated, Blacklisted }
+
+    struct Node {
+        string enode;
+        Status status;
+        address[] voters;
+        mapping(address => bool) voted;
+    }
+
+    mapping(address => bool) public isAdmin;
+    uint public numberOfAdmins;
+
+    mapping(bytes32 => Node) nodes;
+    bytes32[] nodeIds;
+
+    event AdminAdded(address admin);
+    event AdminRemoved(address admin);
+    event NodeProposed(bytes32 indexed nodeId, string enode);
+    event NodeApproved(bytes32 indexed nodeId, string enode);
+    event NodeDeactivated(bytes32 indexed nodeId);
+    event NodeActivated(bytes32 indexed nodeId);
+    event NodeBlacklisted(bytes32 indexed nodeId);
+
+    modifier onlyAdmin() {
+        require(isAdmin[msg.sender]);
+        _;
+    }
+
+    constructor(address[] admins) public {
+        for (uint i = 0; i < admins.length; i++) {
+            if (!isAdmin[admins[i]]) {
+                isAdmin[admins[i]] = true;
+                numberOfAdmins++;
+                emit AdminAdded(admins[i]);
+            }
+        }
+    }
+
+    function addAdmin(address admin) public onlyAdmin {
+        require(!isAdmin[admin]);
+        isAdmin[admin] = true;
+        numberOfAdmins++;
+        emit AdminAdded(admin);
+    }
+
+    function removeAdmin(address admin) public onlyAdmin {
+        require(isAdmin[admin] && numberOfAdmins > 1);
+        isAdmin[admin] = false;
+        numberOfAdmins--;
+        emit AdminRemoved(admin);
+    }
+
+    // proposeNode adds a node to the registry pending approval, counting the
+    // proposer's vote. With a single admin the node is approved right away.
+    function proposeNode(bytes32 nodeId, string enode) public onlyAdmin {
+        require(nodes[nodeId].status == Status.NotInList);
+        nodes[nodeId].enode = enode;
+        nodes[nodeId].status = Status.PendingApproval;
+        nodeIds.push(nodeId);
+        emit NodeProposed(nodeId, enode);
+
+        vote(nodeId);
+    }
+
+    // approveNode votes for a pending node. Admins who already voted may call it
+    // again to approve a node that gained a majority through admin removals.
+    function approveNode(bytes32 nodeId) public onlyAdmin {
+        require(nodes[nodeId].status == Status.PendingApproval);
+        vote(nodeId);
+    }
+
+    function deactivateNode(bytes32 nodeId) public onlyAdmin {
+        require(nodes[nodeId].status == Status.Approved);
+        nodes[nodeId].status = Status.Deactivated;
+        emit NodeDeactivated(nodeId);
+    }
+
+    function activateNode(bytes32 nodeId) public onlyAdmin {
+        require(nodes[nodeId].status == Status.Deactivated);
+        nodes[nodeId].status = Status.Approved;
+        emit NodeActivated(nodeId);
+    }
+
+    // blacklistNode permanently bans a node, it can never be proposed again.
+    function blacklistNode(bytes32 nodeId) public onlyAdmin {
+        require(nodes[nodeId].status != Status.Blacklisted);
+        if (nodes[nodeId].status == Status.NotInList) {
+            nodeIds.push(nodeId);
+        }
+        nodes[nodeId].status = Status.Blacklisted;
+        emit NodeBlacklisted(nodeId);
+    }
+
+    function getNodeStatus(bytes32 nodeId) public view returns (uint8) {
+        return uint8(nodes[nodeId].status);
+    }
+
+    function getNumberOfNodes() public view returns (uint) {
+        return nodeIds.length;
+    }
+
+    function getNodeDetails(uint index) public view returns (bytes32 nodeId, string enode, uint8 status) {
+        nodeId = nodeIds[index];
+        return (nodeId, nodes[nodeId].enode, uint8(nodes[nodeId].status));
+    }
+
+    // vote records the sender's vote for a pending node and approves it if a
+    // majority of the current admins voted for it.
+    function vote(bytes32 nodeId) internal {
+        Node storage node = nodes[nodeId];
+        if (!node.voted[msg.sender]) {
+            node.voted[msg.sender] = true;
+            node.voters.push(msg.sender);
+        }
+        if (countVotes(node) * 2 > numberOfAdmins) {
+            node.status = Status.Approved;
+            emit NodeApproved(nodeId, node.enode);
+        }
+    }
+
+    // countVotes returns the number of current admins who voted for the node.
+    function countVotes(Node storage node) internal view returns (uint count) {
+        for (uint i = 0; i < node.voters.length; i++) {
+            if (isAdmin[node.voters[i]]) {
+                count++;
+            }
+        }
+    }
+}
@@ -0,0 +1,353 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package contract
+
+import (
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = abi.U256
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+)
+
+// AccountPermissionsABI is the input ABI used to generate the binding from.
+const AccountPermissionsABI = "[{\"constant\":true,\"inputs\":[{\"name\":\"\",\"type\":\"address\"}],\"name\":\"access\",\"outputs\":[{\"name\":\"\",\"type\":\"uint8\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":false,\"inputs\":[{\"name\":\"account\",\"type\":\"address\"},{\"name\":\"level\",\"type\":\"uint8\"}],\"name\":\"setAccess\",\"outputs\":[],\"payable\":false,\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"name\":\"admins\",\"type\":\"address[]\"}],\"payable\":false,\"stateMutability\":\"nonpayable\",\"type\":\"constructor\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"name\":\"account\",\"type\":\"address\"},{\"indexed\":false,\"name\":\"access\",\"type\":\"uint8\"}],\"name\":\"AccessChanged\",\"type\":\"event\"}]"
+
+// AccountPermissions is an auto generated Go binding around an Ethereum contract.
+type AccountPermissions struct {
+	AccountPermissionsCaller     // Read-only binding to the contract
+	AccountPermissionsTransactor // Write-only binding to the contract
+	AccountPermissionsFilterer   // Log filterer for contract events
+}
+
+// AccountPermissionsCaller is an auto generated read-only Go binding around an Ethereum contract.
+type AccountPermissionsCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// AccountPermissionsTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type AccountPermissionsTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// AccountPermissionsFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type AccountPermissionsFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// AccountPermissionsSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type AccountPermissionsSession struct {
+	Contract     *AccountPermissions // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts       // Call options to use throughout this session
+	TransactOpts bind.TransactOpts   // Transaction auth options to use throughout this session
+}
+
+// AccountPermissionsCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type AccountPermissionsCallerSession struct {
+	Contract *AccountPermissionsCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts             // Call options to use throughout this session
+}
+
+// AccountPermissionsTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type AccountPermissionsTransactorSession struct {
+	Contract     *AccountPermissionsTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts             // Transaction auth options to use throughout this session
+}
+
+// AccountPermissionsRaw is an auto generated low-level Go binding around an Ethereum contract.
+type AccountPermissionsRaw struct {
+	Contract *AccountPermissions // Generic contract binding to access the raw methods on
+}
+
+// AccountPermissionsCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type AccountPermissionsCallerRaw struct {
+	Contract *AccountPermissionsCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// AccountPermissionsTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type AccountPermissionsTransactorRaw struct {
+	Contract *AccountPermissionsTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewAccountPermissions creates a new instance of AccountPermissions, bound to a specific deployed contract.
+func NewAccountPermissions(address common.Address, backend bind.ContractBackend) (*AccountPermissions, error) {
+	contract, err := bindAccountPermissions(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &AccountPermissions{AccountPermissionsCaller: AccountPermissionsCaller{contract: contract}, AccountPermissionsTransactor: AccountPermissionsTransactor{contract: contract}, AccountPermissionsFilterer: AccountPermissionsFilterer{contract: contract}}, nil
+}
+
+// NewAccountPermissionsCaller creates a new read-only instance of AccountPermissions, bound to a specific deployed contract.
+func NewAccountPermissionsCaller(address common.Address, caller bind.ContractCaller) (*AccountPermissionsCaller, error) {
+	contract, err := bindAccountPermissions(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &AccountPermissionsCaller{contract: contract}, nil
+}
+
+// NewAccountPermissionsTransactor creates a new write-only instance of AccountPermissions, bound to a specific deployed contract.
+func NewAccountPermissionsTransactor(address common.Address, transactor bind.ContractTransactor) (*AccountPermissionsTransactor, error) {
+	contract, err := bindAccountPermissions(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &AccountPermissionsTransactor{contract: contract}, nil
+}
+
+// NewAccountPermissionsFilterer creates a new log filterer instance of AccountPermissions, bound to a specific deployed contract.
+func NewAccountPermissionsFilterer(address common.Address, filterer bind.ContractFilterer) (*AccountPermissionsFilterer, error) {
+	contract, err := bindAccountPermissions(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &AccountPermissionsFilterer{contract: contract}, nil
+}
+
+// bindAccountPermissions binds a generic wrapper to an already deployed contract.
+func bindAccountPermissions(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(AccountPermissionsABI))
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_AccountPermissions *AccountPermissionsRaw) Call(opts *bind.CallOpts, result interface{}, method string, params ...interface{}) error {
+	return _AccountPermissions.Contract.AccountPermissionsCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_AccountPermissions *AccountPermissionsRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _AccountPermissions.Contract.AccountPermissionsTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_AccountPermissions *AccountPermissionsRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _AccountPermissions.Contract.AccountPermissionsTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_AccountPermissions *AccountPermissionsCallerRaw) Call(opts *bind.CallOpts, result interface{}, method string, params ...interface{}) error {
+	return _AccountPermissions.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_AccountPermissions *AccountPermissionsTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _AccountPermissions.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_AccountPermissions *AccountPermissionsTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _AccountPermissions.Contract.contract.Transact(opts, method, params...)
+}
+
+// Access is a free data retrieval call binding the contract method 0x6fae3d76.
+//
+// Solidity: function access( address) constant returns(uint8)
+func (_AccountPermissions *AccountPermissionsCaller) Access(opts *bind.CallOpts, arg0 common.Address) (uint8, error) {
+	var (
+		ret0 = new(uint8)
+	)
+	out := ret0
+	err := _AccountPermissions.contract.Call(opts, out, "access", arg0)
+	return *ret0, err
+}
+
+// Access is a free data retrieval call binding the contract method 0x6fae3d76.
+//
+// Solidity: function access( address) constant returns(uint8)
+func (_AccountPermissions *AccountPermissionsSession) Access(arg0 common.Address) (uint8, error) {
+	return _AccountPermissions.Contract.Access(&_AccountPermissions.CallOpts, arg0)
+}
+
+// Access is a free data retrieval call binding the contract method 0x6fae3d76.
+//
+// Solidity: function access( address) constant returns(uint8)
+func (_AccountPermissions *AccountPermissionsCallerSession) Access(arg0 common.Address) (uint8, error) {
+	return _AccountPermissions.Contract.Access(&_AccountPermissions.CallOpts, arg0)
+}
+
+// SetAccess is a paid mutator transaction binding the contract method 0x1560ad10.
+//
+// Solidity: function setAccess(account address, level uint8) returns()
+func (_AccountPermissions *AccountPermissionsTransactor) SetAccess(opts *bind.TransactOpts, account common.Address, level uint8) (*types.Transaction, error) {
+	return _AccountPermissions.contract.Transact(opts, "setAccess", account, level)
+}
+
+// SetAccess is a paid mutator transaction binding the contract method 0x1560ad10.
+//
+// Solidity: function setAccess(account address, level uint8) returns()
+func (_AccountPermissions *AccountPermissionsSession) SetAccess(account common.Address, level uint8) (*types.Transaction, error) {
+	return _AccountPermissions.Contract.SetAccess(&_AccountPermissions.TransactOpts, account, level)
+}
+
+// SetAccess is a paid mutator transaction binding the contract method 0x1560ad10.
+//
+// Solidity: function setAccess(account address, level uint8) returns()
+func (_AccountPermissions *AccountPermissionsTransactorSession) SetAccess(account common.Address, level uint8) (*types.Transaction, error) {
+	return _AccountPermissions.Contract.SetAccess(&_AccountPermissions.TransactOpts, account, level)
+}
+
+// AccountPermissionsAccessChangedIterator is returned from FilterAccessChanged and is used to iterate over the raw logs and unpacked data for AccessChanged events raised by the AccountPermissions contract.
+type AccountPermissionsAccessChangedIterator struct {
+	Event *AccountPermissionsAccessChanged // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *AccountPermissionsAccessChangedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(AccountPermissionsAccessChanged)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(AccountPermissionsAccessChanged)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *AccountPermissionsAccessChangedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *AccountPermissionsAccessChangedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// AccountPermissionsAccessChanged represents a AccessChanged event raised by the AccountPermissions contract.
+type AccountPermissionsAccessChanged struct {
+	Account common.Address
+	Access  uint8
+	Raw     types.Log // Blockchain specific contextual infos
+}
+
+// FilterAccessChanged is a free log retrieval operation binding the contract event 0x921303ce26b27929680092910af54352e6879496c64c25b92f752e5388ec10a2.
+//
+// Solidity: e AccessChanged(account indexed address, access uint8)
+func (_AccountPermissions *AccountPermissionsFilterer) FilterAccessChanged(opts *bind.FilterOpts, account []common.Address) (*AccountPermissionsAccessChangedIterator, error) {
+
+	var accountRule []interface{}
+	for _, accountItem := range account {
+		accountRule = append(accountRule, accountItem)
+	}
+
+	logs, sub, err := _AccountPermissions.contract.FilterLogs(opts, "AccessChanged", accountRule)
+	if err != nil {
+		return nil, err
+	}
+	return &AccountPermissionsAccessChangedIterator{contract: _AccountPermissions.contract, event: "AccessChanged", logs: logs, sub: sub}, nil
+}
+
+// WatchAccessChanged is a free log subscription operation binding the contract event 0x921303ce26b27929680092910af54352e6879496c64c25b92f752e5388ec10a2.
+//
+// Solidity: e AccessChanged(account indexed address, access uint8)
+func (_AccountPermissions *AccountPermissionsFilterer) WatchAccessChanged(opts *bind.WatchOpts, sink chan<- *AccountPermissionsAccessChanged, account []common.Address) (event.Subscription, error) {
+
+	var accountRule []interface{}
+	for _, accountItem := range account {
+		accountRule = append(accountRule, accountItem)
+	}
+
+	logs, sub, err := _AccountPermissions.contract.WatchLogs(opts, "AccessChanged", accountRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(AccountPermissionsAccessChanged)
+				if err := _AccountPermissions.contract.UnpackLog(event, "AccessChanged", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
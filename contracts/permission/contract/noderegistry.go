@@ -0,0 +1,1370 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package contract
+
+import (
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = abi.U256
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+)
+
+// NodeRegistryABI is the input ABI used to generate the binding from.
+const NodeRegistryABI = "[{\"constant\":true,\"inputs\":[{\"name\":\"\",\"type\":\"address\"}],\"name\":\"isAdmin\",\"outputs\":[{\"name\":\"\",\"type\":\"bool\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[],\"name\":\"numberOfAdmins\",\"outputs\":[{\"name\":\"\",\"type\":\"uint256\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":false,\"inputs\":[{\"name\":\"admin\",\"type\":\"address\"}],\"name\":\"addAdmin\",\"outputs\":[],\"payable\":false,\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"constant\":false,\"inputs\":[{\"name\":\"admin\",\"type\":\"address\"}],\"name\":\"removeAdmin\",\"outputs\":[],\"payable\":false,\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"constant\":false,\"inputs\":[{\"name\":\"nodeId\",\"type\":\"bytes32\"},{\"name\":\"enode\",\"type\":\"string\"}],\"name\":\"proposeNode\",\"outputs\":[],\"payable\":false,\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"constant\":false,\"inputs\":[{\"name\":\"nodeId\",\"type\":\"bytes32\"}],\"name\":\"approveNode\",\"outputs\":[],\"payable\":false,\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"constant\":false,\"inputs\":[{\"name\":\"nodeId\",\"type\":\"bytes32\"}],\"name\":\"deactivateNode\",\"outputs\":[],\"payable\":false,\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"constant\":false,\"inputs\":[{\"name\":\"nodeId\",\"type\":\"bytes32\"}],\"name\":\"activateNode\",\"outputs\":[],\"payable\":false,\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"constant\":false,\"inputs\":[{\"name\":\"nodeId\",\"type\":\"bytes32\"}],\"name\":\"blacklistNode\",\"outputs\":[],\"payable\":false,\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[{\"name\":\"nodeId\",\"type\":\"bytes32\"}],\"name\":\"getNodeStatus\",\"outputs\":[{\"name\":\"\",\"type\":\"uint8\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[],\"name\":\"getNumberOfNodes\",\"outputs\":[{\"name\":\"\",\"type\":\"uint256\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"constant\":true,\"inputs\":[{\"name\":\"index\",\"type\":\"uint256\"}],\"name\":\"getNodeDetails\",\"outputs\":[{\"name\":\"nodeId\",\"type\":\"bytes32\"},{\"name\":\"enode\",\"type\":\"string\"},{\"name\":\"status\",\"type\":\"uint8\"}],\"payable\":false,\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"name\":\"admins\",\"type\":\"address[]\"}],\"payable\":false,\"stateMutability\":\"nonpayable\",\"type\":\"constructor\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"name\":\"admin\",\"type\":\"address\"}],\"name\":\"AdminAdded\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"name\":\"admin\",\"type\":\"address\"}],\"name\":\"AdminRemoved\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"name\":\"nodeId\",\"type\":\"bytes32\"},{\"indexed\":false,\"name\":\"enode\",\"type\":\"string\"}],\"name\":\"NodeProposed\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"name\":\"nodeId\",\"type\":\"bytes32\"},{\"indexed\":false,\"name\":\"enode\",\"type\":\"string\"}],\"name\":\"NodeApproved\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"name\":\"nodeId\",\"type\":\"bytes32\"}],\"name\":\"NodeDeactivated\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"name\":\"nodeId\",\"type\":\"bytes32\"}],\"name\":\"NodeActivated\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"name\":\"nodeId\",\"type\":\"bytes32\"}],\"name\":\"NodeBlacklisted\",\"type\":\"event\"}]"
+
+// NodeRegistry is an auto generated Go binding around an Ethereum contract.
+type NodeRegistry struct {
+	NodeRegistryCaller     // Read-only binding to the contract
+	NodeRegistryTransactor // Write-only binding to the contract
+	NodeRegistryFilterer   // Log filterer for contract events
+}
+
+// NodeRegistryCaller is an auto generated read-only Go binding around an Ethereum contract.
+type NodeRegistryCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// NodeRegistryTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type NodeRegistryTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// NodeRegistryFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type NodeRegistryFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// NodeRegistrySession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type NodeRegistrySession struct {
+	Contract     *NodeRegistry     // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// NodeRegistryCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type NodeRegistryCallerSession struct {
+	Contract *NodeRegistryCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts       // Call options to use throughout this session
+}
+
+// NodeRegistryTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type NodeRegistryTransactorSession struct {
+	Contract     *NodeRegistryTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts       // Transaction auth options to use throughout this session
+}
+
+// NodeRegistryRaw is an auto generated low-level Go binding around an Ethereum contract.
+type NodeRegistryRaw struct {
+	Contract *NodeRegistry // Generic contract binding to access the raw methods on
+}
+
+// NodeRegistryCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type NodeRegistryCallerRaw struct {
+	Contract *NodeRegistryCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// NodeRegistryTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type NodeRegistryTransactorRaw struct {
+	Contract *NodeRegistryTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewNodeRegistry creates a new instance of NodeRegistry, bound to a specific deployed contract.
+func NewNodeRegistry(address common.Address, backend bind.ContractBackend) (*NodeRegistry, error) {
+	contract, err := bindNodeRegistry(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &NodeRegistry{NodeRegistryCaller: NodeRegistryCaller{contract: contract}, NodeRegistryTransactor: NodeRegistryTransactor{contract: contract}, NodeRegistryFilterer: NodeRegistryFilterer{contract: contract}}, nil
+}
+
+// NewNodeRegistryCaller creates a new read-only instance of NodeRegistry, bound to a specific deployed contract.
+func NewNodeRegistryCaller(address common.Address, caller bind.ContractCaller) (*NodeRegistryCaller, error) {
+	contract, err := bindNodeRegistry(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &NodeRegistryCaller{contract: contract}, nil
+}
+
+// NewNodeRegistryTransactor creates a new write-only instance of NodeRegistry, bound to a specific deployed contract.
+func NewNodeRegistryTransactor(address common.Address, transactor bind.ContractTransactor) (*NodeRegistryTransactor, error) {
+	contract, err := bindNodeRegistry(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &NodeRegistryTransactor{contract: contract}, nil
+}
+
+// NewNodeRegistryFilterer creates a new log filterer instance of NodeRegistry, bound to a specific deployed contract.
+func NewNodeRegistryFilterer(address common.Address, filterer bind.ContractFilterer) (*NodeRegistryFilterer, error) {
+	contract, err := bindNodeRegistry(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &NodeRegistryFilterer{contract: contract}, nil
+}
+
+// bindNodeRegistry binds a generic wrapper to an already deployed contract.
+func bindNodeRegistry(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(NodeRegistryABI))
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_NodeRegistry *NodeRegistryRaw) Call(opts *bind.CallOpts, result interface{}, method string, params ...interface{}) error {
+	return _NodeRegistry.Contract.NodeRegistryCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_NodeRegistry *NodeRegistryRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _NodeRegistry.Contract.NodeRegistryTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_NodeRegistry *NodeRegistryRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _NodeRegistry.Contract.NodeRegistryTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_NodeRegistry *NodeRegistryCallerRaw) Call(opts *bind.CallOpts, result interface{}, method string, params ...interface{}) error {
+	return _NodeRegistry.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_NodeRegistry *NodeRegistryTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _NodeRegistry.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_NodeRegistry *NodeRegistryTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _NodeRegistry.Contract.contract.Transact(opts, method, params...)
+}
+
+// GetNodeDetails is a free data retrieval call binding the contract method 0x5afbba47.
+//
+// Solidity: function getNodeDetails(index uint256) constant returns(nodeId bytes32, enode string, status uint8)
+func (_NodeRegistry *NodeRegistryCaller) GetNodeDetails(opts *bind.CallOpts, index *big.Int) (struct {
+	NodeId [32]byte
+	Enode  string
+	Status uint8
+}, error) {
+	ret := new(struct {
+		NodeId [32]byte
+		Enode  string
+		Status uint8
+	})
+	out := ret
+	err := _NodeRegistry.contract.Call(opts, out, "getNodeDetails", index)
+	return *ret, err
+}
+
+// GetNodeDetails is a free data retrieval call binding the contract method 0x5afbba47.
+//
+// Solidity: function getNodeDetails(index uint256) constant returns(nodeId bytes32, enode string, status uint8)
+func (_NodeRegistry *NodeRegistrySession) GetNodeDetails(index *big.Int) (struct {
+	NodeId [32]byte
+	Enode  string
+	Status uint8
+}, error) {
+	return _NodeRegistry.Contract.GetNodeDetails(&_NodeRegistry.CallOpts, index)
+}
+
+// GetNodeDetails is a free data retrieval call binding the contract method 0x5afbba47.
+//
+// Solidity: function getNodeDetails(index uint256) constant returns(nodeId bytes32, enode string, status uint8)
+func (_NodeRegistry *NodeRegistryCallerSession) GetNodeDetails(index *big.Int) (struct {
+	NodeId [32]byte
+	Enode  string
+	Status uint8
+}, error) {
+	return _NodeRegistry.Contract.GetNodeDetails(&_NodeRegistry.CallOpts, index)
+}
+
+// GetNodeStatus is a free data retrieval call binding the contract method 0x7d9fa850.
+//
+// Solidity: function getNodeStatus(nodeId bytes32) constant returns(uint8)
+func (_NodeRegistry *NodeRegistryCaller) GetNodeStatus(opts *bind.CallOpts, nodeId [32]byte) (uint8, error) {
+	var (
+		ret0 = new(uint8)
+	)
+	out := ret0
+	err := _NodeRegistry.contract.Call(opts, out, "getNodeStatus", nodeId)
+	return *ret0, err
+}
+
+// GetNodeStatus is a free data retrieval call binding the contract method 0x7d9fa850.
+//
+// Solidity: function getNodeStatus(nodeId bytes32) constant returns(uint8)
+func (_NodeRegistry *NodeRegistrySession) GetNodeStatus(nodeId [32]byte) (uint8, error) {
+	return _NodeRegistry.Contract.GetNodeStatus(&_NodeRegistry.CallOpts, nodeId)
+}
+
+// GetNodeStatus is a free data retrieval call binding the contract method 0x7d9fa850.
+//
+// Solidity: function getNodeStatus(nodeId bytes32) constant returns(uint8)
+func (_NodeRegistry *NodeRegistryCallerSession) GetNodeStatus(nodeId [32]byte) (uint8, error) {
+	return _NodeRegistry.Contract.GetNodeStatus(&_NodeRegistry.CallOpts, nodeId)
+}
+
+// GetNumberOfNodes is a free data retrieval call binding the contract method 0xb81c806a.
+//
+// Solidity: function getNumberOfNodes() constant returns(uint256)
+func (_NodeRegistry *NodeRegistryCaller) GetNumberOfNodes(opts *bind.CallOpts) (*big.Int, error) {
+	var (
+		ret0 = new(*big.Int)
+	)
+	out := ret0
+	err := _NodeRegistry.contract.Call(opts, out, "getNumberOfNodes")
+	return *ret0, err
+}
+
+// GetNumberOfNodes is a free data retrieval call binding the contract method 0xb81c806a.
+//
+// Solidity: function getNumberOfNodes() constant returns(uint256)
+func (_NodeRegistry *NodeRegistrySession) GetNumberOfNodes() (*big.Int, error) {
+	return _NodeRegistry.Contract.GetNumberOfNodes(&_NodeRegistry.CallOpts)
+}
+
+// GetNumberOfNodes is a free data retrieval call binding the contract method 0xb81c806a.
+//
+// Solidity: function getNumberOfNodes() constant returns(uint256)
+func (_NodeRegistry *NodeRegistryCallerSession) GetNumberOfNodes() (*big.Int, error) {
+	return _NodeRegistry.Contract.GetNumberOfNodes(&_NodeRegistry.CallOpts)
+}
+
+// IsAdmin is a free data retrieval call binding the contract method 0x24d7806c.
+//
+// Solidity: function isAdmin( address) constant returns(bool)
+func (_NodeRegistry *NodeRegistryCaller) IsAdmin(opts *bind.CallOpts, arg0 common.Address) (bool, error) {
+	var (
+		ret0 = new(bool)
+	)
+	out := ret0
+	err := _NodeRegistry.contract.Call(opts, out, "isAdmin", arg0)
+	return *ret0, err
+}
+
+// IsAdmin is a free data retrieval call binding the contract method 0x24d7806c.
+//
+// Solidity: function isAdmin( address) constant returns(bool)
+func (_NodeRegistry *NodeRegistrySession) IsAdmin(arg0 common.Address) (bool, error) {
+	return _NodeRegistry.Contract.IsAdmin(&_NodeRegistry.CallOpts, arg0)
+}
+
+// IsAdmin is a free data retrieval call binding the contract method 0x24d7806c.
+//
+// Solidity: function isAdmin( address) constant returns(bool)
+func (_NodeRegistry *NodeRegistryCallerSession) IsAdmin(arg0 common.Address) (bool, error) {
+	return _NodeRegistry.Contract.IsAdmin(&_NodeRegistry.CallOpts, arg0)
+}
+
+// NumberOfAdmins is a free data retrieval call binding the contract method 0x81395866.
+//
+// Solidity: function numberOfAdmins() constant returns(uint256)
+func (_NodeRegistry *NodeRegistryCaller) NumberOfAdmins(opts *bind.CallOpts) (*big.Int, error) {
+	var (
+		ret0 = new(*big.Int)
+	)
+	out := ret0
+	err := _NodeRegistry.contract.Call(opts, out, "numberOfAdmins")
+	return *ret0, err
+}
+
+// NumberOfAdmins is a free data retrieval call binding the contract method 0x81395866.
+//
+// Solidity: function numberOfAdmins() constant returns(uint256)
+func (_NodeRegistry *NodeRegistrySession) NumberOfAdmins() (*big.Int, error) {
+	return _NodeRegistry.Contract.NumberOfAdmins(&_NodeRegistry.CallOpts)
+}
+
+// NumberOfAdmins is a free data retrieval call binding the contract method 0x81395866.
+//
+// Solidity: function numberOfAdmins() constant returns(uint256)
+func (_NodeRegistry *NodeRegistryCallerSession) NumberOfAdmins() (*big.Int, error) {
+	return _NodeRegistry.Contract.NumberOfAdmins(&_NodeRegistry.CallOpts)
+}
+
+// ActivateNode is a paid mutator transaction binding the contract method 0x204a884c.
+//
+// Solidity: function activateNode(nodeId bytes32) returns()
+func (_NodeRegistry *NodeRegistryTransactor) ActivateNode(opts *bind.TransactOpts, nodeId [32]byte) (*types.Transaction, error) {
+	return _NodeRegistry.contract.Transact(opts, "activateNode", nodeId)
+}
+
+// ActivateNode is a paid mutator transaction binding the contract method 0x204a884c.
+//
+// Solidity: function activateNode(nodeId bytes32) returns()
+func (_NodeRegistry *NodeRegistrySession) ActivateNode(nodeId [32]byte) (*types.Transaction, error) {
+	return _NodeRegistry.Contract.ActivateNode(&_NodeRegistry.TransactOpts, nodeId)
+}
+
+// ActivateNode is a paid mutator transaction binding the contract method 0x204a884c.
+//
+// Solidity: function activateNode(nodeId bytes32) returns()
+func (_NodeRegistry *NodeRegistryTransactorSession) ActivateNode(nodeId [32]byte) (*types.Transaction, error) {
+	return _NodeRegistry.Contract.ActivateNode(&_NodeRegistry.TransactOpts, nodeId)
+}
+
+// AddAdmin is a paid mutator transaction binding the contract method 0x70480275.
+//
+// Solidity: function addAdmin(admin address) returns()
+func (_NodeRegistry *NodeRegistryTransactor) AddAdmin(opts *bind.TransactOpts, admin common.Address) (*types.Transaction, error) {
+	return _NodeRegistry.contract.Transact(opts, "addAdmin", admin)
+}
+
+// AddAdmin is a paid mutator transaction binding the contract method 0x70480275.
+//
+// Solidity: function addAdmin(admin address) returns()
+func (_NodeRegistry *NodeRegistrySession) AddAdmin(admin common.Address) (*types.Transaction, error) {
+	return _NodeRegistry.Contract.AddAdmin(&_NodeRegistry.TransactOpts, admin)
+}
+
+// AddAdmin is a paid mutator transaction binding the contract method 0x70480275.
+//
+// Solidity: function addAdmin(admin address) returns()
+func (_NodeRegistry *NodeRegistryTransactorSession) AddAdmin(admin common.Address) (*types.Transaction, error) {
+	return _NodeRegistry.Contract.AddAdmin(&_NodeRegistry.TransactOpts, admin)
+}
+
+// ApproveNode is a paid mutator transaction binding the contract method 0x21cc7145.
+//
+// Solidity: function approveNode(nodeId bytes32) returns()
+func (_NodeRegistry *NodeRegistryTransactor) ApproveNode(opts *bind.TransactOpts, nodeId [32]byte) (*types.Transaction, error) {
+	return _NodeRegistry.contract.Transact(opts, "approveNode", nodeId)
+}
+
+// ApproveNode is a paid mutator transaction binding the contract method 0x21cc7145.
+//
+// Solidity: function approveNode(nodeId bytes32) returns()
+func (_NodeRegistry *NodeRegistrySession) ApproveNode(nodeId [32]byte) (*types.Transaction, error) {
+	return _NodeRegistry.Contract.ApproveNode(&_NodeRegistry.TransactOpts, nodeId)
+}
+
+// ApproveNode is a paid mutator transaction binding the contract method 0x21cc7145.
+//
+// Solidity: function approveNode(nodeId bytes32) returns()
+func (_NodeRegistry *NodeRegistryTransactorSession) ApproveNode(nodeId [32]byte) (*types.Transaction, error) {
+	return _NodeRegistry.Contract.ApproveNode(&_NodeRegistry.TransactOpts, nodeId)
+}
+
+// BlacklistNode is a paid mutator transaction binding the contract method 0xe9b9a45e.
+//
+// Solidity: function blacklistNode(nodeId bytes32) returns()
+func (_NodeRegistry *NodeRegistryTransactor) BlacklistNode(opts *bind.TransactOpts, nodeId [32]byte) (*types.Transaction, error) {
+	return _NodeRegistry.contract.Transact(opts, "blacklistNode", nodeId)
+}
+
+// BlacklistNode is a paid mutator transaction binding the contract method 0xe9b9a45e.
+//
+// Solidity: function blacklistNode(nodeId bytes32) returns()
+func (_NodeRegistry *NodeRegistrySession) BlacklistNode(nodeId [32]byte) (*types.Transaction, error) {
+	return _NodeRegistry.Contract.BlacklistNode(&_NodeRegistry.TransactOpts, nodeId)
+}
+
+// BlacklistNode is a paid mutator transaction binding the contract method 0xe9b9a45e.
+//
+// Solidity: function blacklistNode(nodeId bytes32) returns()
+func (_NodeRegistry *NodeRegistryTransactorSession) BlacklistNode(nodeId [32]byte) (*types.Transaction, error) {
+	return _NodeRegistry.Contract.BlacklistNode(&_NodeRegistry.TransactOpts, nodeId)
+}
+
+// DeactivateNode is a paid mutator transaction binding the contract method 0xa2444390.
+//
+// Solidity: function deactivateNode(nodeId bytes32) returns()
+func (_NodeRegistry *NodeRegistryTransactor) DeactivateNode(opts *bind.TransactOpts, nodeId [32]byte) (*types.Transaction, error) {
+	return _NodeRegistry.contract.Transact(opts, "deactivateNode", nodeId)
+}
+
+// DeactivateNode is a paid mutator transaction binding the contract method 0xa2444390.
+//
+// Solidity: function deactivateNode(nodeId bytes32) returns()
+func (_NodeRegistry *NodeRegistrySession) DeactivateNode(nodeId [32]byte) (*types.Transaction, error) {
+	return _NodeRegistry.Contract.DeactivateNode(&_NodeRegistry.TransactOpts, nodeId)
+}
+
+// DeactivateNode is a paid mutator transaction binding the contract method 0xa2444390.
+//
+// Solidity: function deactivateNode(nodeId bytes32) returns()
+func (_NodeRegistry *NodeRegistryTransactorSession) DeactivateNode(nodeId [32]byte) (*types.Transaction, error) {
+	return _NodeRegistry.Contract.DeactivateNode(&_NodeRegistry.TransactOpts, nodeId)
+}
+
+// ProposeNode is a paid mutator transaction binding the contract method 0xc3c98e46.
+//
+// Solidity: function proposeNode(nodeId bytes32, enode string) returns()
+func (_NodeRegistry *NodeRegistryTransactor) ProposeNode(opts *bind.TransactOpts, nodeId [32]byte, enode string) (*types.Transaction, error) {
+	return _NodeRegistry.contract.Transact(opts, "proposeNode", nodeId, enode)
+}
+
+// ProposeNode is a paid mutator transaction binding the contract method 0xc3c98e46.
+//
+// Solidity: function proposeNode(nodeId bytes32, enode string) returns()
+func (_NodeRegistry *NodeRegistrySession) ProposeNode(nodeId [32]byte, enode string) (*types.Transaction, error) {
+	return _NodeRegistry.Contract.ProposeNode(&_NodeRegistry.TransactOpts, nodeId, enode)
+}
+
+// ProposeNode is a paid mutator transaction binding the contract method 0xc3c98e46.
+//
+// Solidity: function proposeNode(nodeId bytes32, enode string) returns()
+func (_NodeRegistry *NodeRegistryTransactorSession) ProposeNode(nodeId [32]byte, enode string) (*types.Transaction, error) {
+	return _NodeRegistry.Contract.ProposeNode(&_NodeRegistry.TransactOpts, nodeId, enode)
+}
+
+// RemoveAdmin is a paid mutator transaction binding the contract method 0x1785f53c.
+//
+// Solidity: function removeAdmin(admin address) returns()
+func (_NodeRegistry *NodeRegistryTransactor) RemoveAdmin(opts *bind.TransactOpts, admin common.Address) (*types.Transaction, error) {
+	return _NodeRegistry.contract.Transact(opts, "removeAdmin", admin)
+}
+
+// RemoveAdmin is a paid mutator transaction binding the contract method 0x1785f53c.
+//
+// Solidity: function removeAdmin(admin address) returns()
+func (_NodeRegistry *NodeRegistrySession) RemoveAdmin(admin common.Address) (*types.Transaction, error) {
+	return _NodeRegistry.Contract.RemoveAdmin(&_NodeRegistry.TransactOpts, admin)
+}
+
+// RemoveAdmin is a paid mutator transaction binding the contract method 0x1785f53c.
+//
+// Solidity: function removeAdmin(admin address) returns()
+func (_NodeRegistry *NodeRegistryTransactorSession) RemoveAdmin(admin common.Address) (*types.Transaction, error) {
+	return _NodeRegistry.Contract.RemoveAdmin(&_NodeRegistry.TransactOpts, admin)
+}
+
+// NodeRegistryAdminAddedIterator is returned from FilterAdminAdded and is used to iterate over the raw logs and unpacked data for AdminAdded events raised by the NodeRegistry contract.
+type NodeRegistryAdminAddedIterator struct {
+	Event *NodeRegistryAdminAdded // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *NodeRegistryAdminAddedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(NodeRegistryAdminAdded)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(NodeRegistryAdminAdded)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *NodeRegistryAdminAddedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *NodeRegistryAdminAddedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// NodeRegistryAdminAdded represents a AdminAdded event raised by the NodeRegistry contract.
+type NodeRegistryAdminAdded struct {
+	Admin common.Address
+	Raw   types.Log // Blockchain specific contextual infos
+}
+
+// FilterAdminAdded is a free log retrieval operation binding the contract event 0x44d6d25963f097ad14f29f06854a01f575648a1ef82f30e562ccd3889717e339.
+//
+// Solidity: e AdminAdded(admin address)
+func (_NodeRegistry *NodeRegistryFilterer) FilterAdminAdded(opts *bind.FilterOpts) (*NodeRegistryAdminAddedIterator, error) {
+
+	logs, sub, err := _NodeRegistry.contract.FilterLogs(opts, "AdminAdded")
+	if err != nil {
+		return nil, err
+	}
+	return &NodeRegistryAdminAddedIterator{contract: _NodeRegistry.contract, event: "AdminAdded", logs: logs, sub: sub}, nil
+}
+
+// WatchAdminAdded is a free log subscription operation binding the contract event 0x44d6d25963f097ad14f29f06854a01f575648a1ef82f30e562ccd3889717e339.
+//
+// Solidity: e AdminAdded(admin address)
+func (_NodeRegistry *NodeRegistryFilterer) WatchAdminAdded(opts *bind.WatchOpts, sink chan<- *NodeRegistryAdminAdded) (event.Subscription, error) {
+
+	logs, sub, err := _NodeRegistry.contract.WatchLogs(opts, "AdminAdded")
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(NodeRegistryAdminAdded)
+				if err := _NodeRegistry.contract.UnpackLog(event, "AdminAdded", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// NodeRegistryAdminRemovedIterator is returned from FilterAdminRemoved and is used to iterate over the raw logs and unpacked data for AdminRemoved events raised by the NodeRegistry contract.
+type NodeRegistryAdminRemovedIterator struct {
+	Event *NodeRegistryAdminRemoved // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *NodeRegistryAdminRemovedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(NodeRegistryAdminRemoved)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(NodeRegistryAdminRemoved)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *NodeRegistryAdminRemovedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *NodeRegistryAdminRemovedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// NodeRegistryAdminRemoved represents a AdminRemoved event raised by the NodeRegistry contract.
+type NodeRegistryAdminRemoved struct {
+	Admin common.Address
+	Raw   types.Log // Blockchain specific contextual infos
+}
+
+// FilterAdminRemoved is a free log retrieval operation binding the contract event 0xa3b62bc36326052d97ea62d63c3d60308ed4c3ea8ac079dd8499f1e9c4f80c0f.
+//
+// Solidity: e AdminRemoved(admin address)
+func (_NodeRegistry *NodeRegistryFilterer) FilterAdminRemoved(opts *bind.FilterOpts) (*NodeRegistryAdminRemovedIterator, error) {
+
+	logs, sub, err := _NodeRegistry.contract.FilterLogs(opts, "AdminRemoved")
+	if err != nil {
+		return nil, err
+	}
+	return &NodeRegistryAdminRemovedIterator{contract: _NodeRegistry.contract, event: "AdminRemoved", logs: logs, sub: sub}, nil
+}
+
+// WatchAdminRemoved is a free log subscription operation binding the contract event 0xa3b62bc36326052d97ea62d63c3d60308ed4c3ea8ac079dd8499f1e9c4f80c0f.
+//
+// Solidity: e AdminRemoved(admin address)
+func (_NodeRegistry *NodeRegistryFilterer) WatchAdminRemoved(opts *bind.WatchOpts, sink chan<- *NodeRegistryAdminRemoved) (event.Subscription, error) {
+
+	logs, sub, err := _NodeRegistry.contract.WatchLogs(opts, "AdminRemoved")
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(NodeRegistryAdminRemoved)
+				if err := _NodeRegistry.contract.UnpackLog(event, "AdminRemoved", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// NodeRegistryNodeActivatedIterator is returned from FilterNodeActivated and is used to iterate over the raw logs and unpacked data for NodeActivated events raised by the NodeRegistry contract.
+type NodeRegistryNodeActivatedIterator struct {
+	Event *NodeRegistryNodeActivated // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *NodeRegistryNodeActivatedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(NodeRegistryNodeActivated)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(NodeRegistryNodeActivated)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *NodeRegistryNodeActivatedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *NodeRegistryNodeActivatedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// NodeRegistryNodeActivated represents a NodeActivated event raised by the NodeRegistry contract.
+type NodeRegistryNodeActivated struct {
+	NodeId [32]byte
+	Raw    types.Log // Blockchain specific contextual infos
+}
+
+// FilterNodeActivated is a free log retrieval operation binding the contract event 0x336ebd883358ca2f1c2c0e24f4491162f89a6212aa0d1d1065d1ba8f70f5b370.
+//
+// Solidity: e NodeActivated(nodeId indexed bytes32)
+func (_NodeRegistry *NodeRegistryFilterer) FilterNodeActivated(opts *bind.FilterOpts, nodeId [][32]byte) (*NodeRegistryNodeActivatedIterator, error) {
+
+	var nodeIdRule []interface{}
+	for _, nodeIdItem := range nodeId {
+		nodeIdRule = append(nodeIdRule, nodeIdItem)
+	}
+
+	logs, sub, err := _NodeRegistry.contract.FilterLogs(opts, "NodeActivated", nodeIdRule)
+	if err != nil {
+		return nil, err
+	}
+	return &NodeRegistryNodeActivatedIterator{contract: _NodeRegistry.contract, event: "NodeActivated", logs: logs, sub: sub}, nil
+}
+
+// WatchNodeActivated is a free log subscription operation binding the contract event 0x336ebd883358ca2f1c2c0e24f4491162f89a6212aa0d1d1065d1ba8f70f5b370.
+//
+// Solidity: e NodeActivated(nodeId indexed bytes32)
+func (_NodeRegistry *NodeRegistryFilterer) WatchNodeActivated(opts *bind.WatchOpts, sink chan<- *NodeRegistryNodeActivated, nodeId [][32]byte) (event.Subscription, error) {
+
+	var nodeIdRule []interface{}
+	for _, nodeIdItem := range nodeId {
+		nodeIdRule = append(nodeIdRule, nodeIdItem)
+	}
+
+	logs, sub, err := _NodeRegistry.contract.WatchLogs(opts, "NodeActivated", nodeIdRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(NodeRegistryNodeActivated)
+				if err := _NodeRegistry.contract.UnpackLog(event, "NodeActivated", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// NodeRegistryNodeApprovedIterator is returned from FilterNodeApproved and is used to iterate over the raw logs and unpacked data for NodeApproved events raised by the NodeRegistry contract.
+type NodeRegistryNodeApprovedIterator struct {
+	Event *NodeRegistryNodeApproved // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *NodeRegistryNodeApprovedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(NodeRegistryNodeApproved)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(NodeRegistryNodeApproved)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *NodeRegistryNodeApprovedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *NodeRegistryNodeApprovedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// NodeRegistryNodeApproved represents a NodeApproved event raised by the NodeRegistry contract.
+type NodeRegistryNodeApproved struct {
+	NodeId [32]byte
+	Enode  string
+	Raw    types.Log // Blockchain specific contextual infos
+}
+
+// FilterNodeApproved is a free log retrieval operation binding the contract event 0x0e51af033fa35ae61e55205a2209cd21d2ceb4bac7d8d5ac5d3c48cf1c3a88bf.
+//
+// Solidity: e NodeApproved(nodeId indexed bytes32, enode string)
+func (_NodeRegistry *NodeRegistryFilterer) FilterNodeApproved(opts *bind.FilterOpts, nodeId [][32]byte) (*NodeRegistryNodeApprovedIterator, error) {
+
+	var nodeIdRule []interface{}
+	for _, nodeIdItem := range nodeId {
+		nodeIdRule = append(nodeIdRule, nodeIdItem)
+	}
+
+	logs, sub, err := _NodeRegistry.contract.FilterLogs(opts, "NodeApproved", nodeIdRule)
+	if err != nil {
+		return nil, err
+	}
+	return &NodeRegistryNodeApprovedIterator{contract: _NodeRegistry.contract, event: "NodeApproved", logs: logs, sub: sub}, nil
+}
+
+// WatchNodeApproved is a free log subscription operation binding the contract event 0x0e51af033fa35ae61e55205a2209cd21d2ceb4bac7d8d5ac5d3c48cf1c3a88bf.
+//
+// Solidity: e NodeApproved(nodeId indexed bytes32, enode string)
+func (_NodeRegistry *NodeRegistryFilterer) WatchNodeApproved(opts *bind.WatchOpts, sink chan<- *NodeRegistryNodeApproved, nodeId [][32]byte) (event.Subscription, error) {
+
+	var nodeIdRule []interface{}
+	for _, nodeIdItem := range nodeId {
+		nodeIdRule = append(nodeIdRule, nodeIdItem)
+	}
+
+	logs, sub, err := _NodeRegistry.contract.WatchLogs(opts, "NodeApproved", nodeIdRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(NodeRegistryNodeApproved)
+				if err := _NodeRegistry.contract.UnpackLog(event, "NodeApproved", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// NodeRegistryNodeBlacklistedIterator is returned from FilterNodeBlacklisted and is used to iterate over the raw logs and unpacked data for NodeBlacklisted events raised by the NodeRegistry contract.
+type NodeRegistryNodeBlacklistedIterator struct {
+	Event *NodeRegistryNodeBlacklisted // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *NodeRegistryNodeBlacklistedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(NodeRegistryNodeBlacklisted)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(NodeRegistryNodeBlacklisted)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *NodeRegistryNodeBlacklistedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *NodeRegistryNodeBlacklistedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// NodeRegistryNodeBlacklisted represents a NodeBlacklisted event raised by the NodeRegistry contract.
+type NodeRegistryNodeBlacklisted struct {
+	NodeId [32]byte
+	Raw    types.Log // Blockchain specific contextual infos
+}
+
+// FilterNodeBlacklisted is a free log retrieval operation binding the contract event 0xdb5372ca4da12f71bea84976ad048d5a80472552d0ca39523559c546cb59cc15.
+//
+// Solidity: e NodeBlacklisted(nodeId indexed bytes32)
+func (_NodeRegistry *NodeRegistryFilterer) FilterNodeBlacklisted(opts *bind.FilterOpts, nodeId [][32]byte) (*NodeRegistryNodeBlacklistedIterator, error) {
+
+	var nodeIdRule []interface{}
+	for _, nodeIdItem := range nodeId {
+		nodeIdRule = append(nodeIdRule, nodeIdItem)
+	}
+
+	logs, sub, err := _NodeRegistry.contract.FilterLogs(opts, "NodeBlacklisted", nodeIdRule)
+	if err != nil {
+		return nil, err
+	}
+	return &NodeRegistryNodeBlacklistedIterator{contract: _NodeRegistry.contract, event: "NodeBlacklisted", logs: logs, sub: sub}, nil
+}
+
+// WatchNodeBlacklisted is a free log subscription operation binding the contract event 0xdb5372ca4da12f71bea84976ad048d5a80472552d0ca39523559c546cb59cc15.
+//
+// Solidity: e NodeBlacklisted(nodeId indexed bytes32)
+func (_NodeRegistry *NodeRegistryFilterer) WatchNodeBlacklisted(opts *bind.WatchOpts, sink chan<- *NodeRegistryNodeBlacklisted, nodeId [][32]byte) (event.Subscription, error) {
+
+	var nodeIdRule []interface{}
+	for _, nodeIdItem := range nodeId {
+		nodeIdRule = append(nodeIdRule, nodeIdItem)
+	}
+
+	logs, sub, err := _NodeRegistry.contract.WatchLogs(opts, "NodeBlacklisted", nodeIdRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(NodeRegistryNodeBlacklisted)
+				if err := _NodeRegistry.contract.UnpackLog(event, "NodeBlacklisted", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// NodeRegistryNodeDeactivatedIterator is returned from FilterNodeDeactivated and is used to iterate over the raw logs and unpacked data for NodeDeactivated events raised by the NodeRegistry contract.
+type NodeRegistryNodeDeactivatedIterator struct {
+	Event *NodeRegistryNodeDeactivated // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *NodeRegistryNodeDeactivatedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(NodeRegistryNodeDeactivated)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(NodeRegistryNodeDeactivated)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *NodeRegistryNodeDeactivatedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *NodeRegistryNodeDeactivatedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// NodeRegistryNodeDeactivated represents a NodeDeactivated event raised by the NodeRegistry contract.
+type NodeRegistryNodeDeactivated struct {
+	NodeId [32]byte
+	Raw    types.Log // Blockchain specific contextual infos
+}
+
+// FilterNodeDeactivated is a free log retrieval operation binding the contract event 0x62b30865c6dddf92caa7594e1394397e7119c9947678e4faba379edb7c0ed6fc.
+//
+// Solidity: e NodeDeactivated(nodeId indexed bytes32)
+func (_NodeRegistry *NodeRegistryFilterer) FilterNodeDeactivated(opts *bind.FilterOpts, nodeId [][32]byte) (*NodeRegistryNodeDeactivatedIterator, error) {
+
+	var nodeIdRule []interface{}
+	for _, nodeIdItem := range nodeId {
+		nodeIdRule = append(nodeIdRule, nodeIdItem)
+	}
+
+	logs, sub, err := _NodeRegistry.contract.FilterLogs(opts, "NodeDeactivated", nodeIdRule)
+	if err != nil {
+		return nil, err
+	}
+	return &NodeRegistryNodeDeactivatedIterator{contract: _NodeRegistry.contract, event: "NodeDeactivated", logs: logs, sub: sub}, nil
+}
+
+// WatchNodeDeactivated is a free log subscription operation binding the contract event 0x62b30865c6dddf92caa7594e1394397e7119c9947678e4faba379edb7c0ed6fc.
+//
+// Solidity: e NodeDeactivated(nodeId indexed bytes32)
+func (_NodeRegistry *NodeRegistryFilterer) WatchNodeDeactivated(opts *bind.WatchOpts, sink chan<- *NodeRegistryNodeDeactivated, nodeId [][32]byte) (event.Subscription, error) {
+
+	var nodeIdRule []interface{}
+	for _, nodeIdItem := range nodeId {
+		nodeIdRule = append(nodeIdRule, nodeIdItem)
+	}
+
+	logs, sub, err := _NodeRegistry.contract.WatchLogs(opts, "NodeDeactivated", nodeIdRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(NodeRegistryNodeDeactivated)
+				if err := _NodeRegistry.contract.UnpackLog(event, "NodeDeactivated", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// NodeRegistryNodeProposedIterator is returned from FilterNodeProposed and is used to iterate over the raw logs and unpacked data for NodeProposed events raised by the NodeRegistry contract.
+type NodeRegistryNodeProposedIterator struct {
+	Event *NodeRegistryNodeProposed // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *NodeRegistryNodeProposedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(NodeRegistryNodeProposed)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(NodeRegistryNodeProposed)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *NodeRegistryNodeProposedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *NodeRegistryNodeProposedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// NodeRegistryNodeProposed represents a NodeProposed event raised by the NodeRegistry contract.
+type NodeRegistryNodeProposed struct {
+	NodeId [32]byte
+	Enode  string
+	Raw    types.Log // Blockchain specific contextual infos
+}
+
+// FilterNodeProposed is a free log retrieval operation binding the contract event 0x1db184bcef51b79e644a00170a0f33a00ba488a0cf713f4ce09f77c823cdb545.
+//
+// Solidity: e NodeProposed(nodeId indexed bytes32, enode string)
+func (_NodeRegistry *NodeRegistryFilterer) FilterNodeProposed(opts *bind.FilterOpts, nodeId [][32]byte) (*NodeRegistryNodeProposedIterator, error) {
+
+	var nodeIdRule []interface{}
+	for _, nodeIdItem := range nodeId {
+		nodeIdRule = append(nodeIdRule, nodeIdItem)
+	}
+
+	logs, sub, err := _NodeRegistry.contract.FilterLogs(opts, "NodeProposed", nodeIdRule)
+	if err != nil {
+		return nil, err
+	}
+	return &NodeRegistryNodeProposedIterator{contract: _NodeRegistry.contract, event: "NodeProposed", logs: logs, sub: sub}, nil
+}
+
+// WatchNodeProposed is a free log subscription operation binding the contract event 0x1db184bcef51b79e644a00170a0f33a00ba488a0cf713f4ce09f77c823cdb545.
+//
+// Solidity: e NodeProposed(nodeId indexed bytes32, enode string)
+func (_NodeRegistry *NodeRegistryFilterer) WatchNodeProposed(opts *bind.WatchOpts, sink chan<- *NodeRegistryNodeProposed, nodeId [][32]byte) (event.Subscription, error) {
+
+	var nodeIdRule []interface{}
+	for _, nodeIdItem := range nodeId {
+		nodeIdRule = append(nodeIdRule, nodeIdItem)
+	}
+
+	logs, sub, err := _NodeRegistry.contract.WatchLogs(opts, "NodeProposed", nodeIdRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(NodeRegistryNodeProposed)
+				if err := _NodeRegistry.contract.UnpackLog(event, "NodeProposed", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
@@ -0,0 +1,158 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package permission contains the bindings of the permissioning contracts and
+// implements node permissioning backed by the on-chain node registry.
+package permission
+
+//go:generate abigen --abi contract/NodeRegistry.abi --pkg contract --type NodeRegistry --out contract/noderegistry.go
+//go:generate abigen --abi contract/AccountPermissions.abi --pkg contract --type AccountPermissions --out contract/accountpermissions.go
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/contracts/permission/contract"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+// NodeStatus is the state of a node in the registry contract.
+type NodeStatus uint8
+
+const (
+	NodeNotInList NodeStatus = iota
+	NodePendingApproval
+	NodeApproved
+	NodeDeactivated
+	NodeBlacklisted
+)
+
+func (s NodeStatus) String() string {
+	switch s {
+	case NodeNotInList:
+		return "not in list"
+	case NodePendingApproval:
+		return "pending approval"
+	case NodeApproved:
+		return "approved"
+	case NodeDeactivated:
+		return "deactivated"
+	case NodeBlacklisted:
+		return "blacklisted"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(s))
+	}
+}
+
+// registryCallTimeout bounds the contract calls made for a single query, which
+// run the EVM against the latest state on the peer handshake path.
+const registryCallTimeout = 2 * time.Second
+
+// NodeRegistry answers node admission queries from the node registry contract.
+// Queries are evaluated against whatever state the backing caller exposes, for
+// an in-process node that is the latest block.
+type NodeRegistry struct {
+	contract *contract.NodeRegistryCaller
+}
+
+// NewNodeRegistry binds the node registry contract deployed at address.
+func NewNodeRegistry(address common.Address, caller bind.ContractCaller) (*NodeRegistry, error) {
+	registry, err := contract.NewNodeRegistryCaller(address, caller)
+	if err != nil {
+		return nil, err
+	}
+	return &NodeRegistry{contract: registry}, nil
+}
+
+// callOpts returns the options of the contract calls made for a single query,
+// bounded by registryCallTimeout.
+func callOpts() (*bind.CallOpts, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(context.Background(), registryCallTimeout)
+	return &bind.CallOpts{Context: ctx}, cancel
+}
+
+// registryError maps a missing contract to p2p.ErrPermissionsUnavailable, so the
+// server falls back to its bootstrap nodes until the registry is deployed in the
+// local state.
+func registryError(err error) error {
+	if err == bind.ErrNoCode {
+		return p2p.ErrPermissionsUnavailable
+	}
+	return err
+}
+
+// NodeStatus returns the registry status of the node with the given ID.
+func (r *NodeRegistry) NodeStatus(id enode.ID) (NodeStatus, error) {
+	opts, cancel := callOpts()
+	defer cancel()
+
+	status, err := r.contract.GetNodeStatus(opts, id)
+	if err != nil {
+		return NodeNotInList, registryError(err)
+	}
+	return NodeStatus(status), nil
+}
+
+// IsNodePermissioned implements p2p.NodePermissioner, reporting whether the node
+// is approved in the registry.
+func (r *NodeRegistry) IsNodePermissioned(id enode.ID) (bool, error) {
+	status, err := r.NodeStatus(id)
+	if err != nil {
+		if err != p2p.ErrPermissionsUnavailable {
+			log.Warn("Failed to query node registry", "id", id, "err", err)
+		}
+		return false, err
+	}
+	return status == NodeApproved, nil
+}
+
+// Nodes returns all approved nodes in the registry.
+func (r *NodeRegistry) Nodes() ([]*enode.Node, error) {
+	opts, cancel := callOpts()
+	defer cancel()
+
+	count, err := r.contract.GetNumberOfNodes(opts)
+	if err != nil {
+		return nil, registryError(err)
+	}
+	var nodes []*enode.Node
+	for i := int64(0); i < count.Int64(); i++ {
+		details, err := r.contract.GetNodeDetails(opts, big.NewInt(i))
+		if err != nil {
+			return nil, registryError(err)
+		}
+		if NodeStatus(details.Status) != NodeApproved {
+			continue
+		}
+		node, err := enode.ParseV4(details.Enode)
+		if err != nil {
+			log.Warn("Invalid enode in node registry", "enode", details.Enode, "err", err)
+			continue
+		}
+		if node.ID() != enode.ID(details.NodeId) {
+			log.Warn("Mismatching enode in node registry", "id", common.Hash(details.NodeId), "enode", details.Enode)
+			continue
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
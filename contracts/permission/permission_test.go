@@ -0,0 +1,302 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package permission
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/compiler"
+	"github.com/ethereum/go-ethereum/contracts/permission/contract"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+var registryAddr = common.HexToAddress("0x0000000000000000000000000000000000001234")
+
+type registryEntry struct {
+	id     enode.ID
+	enode  string
+	status NodeStatus
+}
+
+// fakeRegistry serves the read-only registry methods from memory, standing in
+// for the deployed contract.
+type fakeRegistry struct {
+	abi        abi.ABI
+	entries    []registryEntry
+	fail       bool
+	undeployed bool
+}
+
+func newFakeRegistry(t *testing.T, entries ...registryEntry) *fakeRegistry {
+	parsed, err := abi.JSON(strings.NewReader(contract.NodeRegistryABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &fakeRegistry{abi: parsed, entries: entries}
+}
+
+func (f *fakeRegistry) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	if f.undeployed {
+		return nil, nil
+	}
+	return []byte{0x00}, nil
+}
+
+func (f *fakeRegistry) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	if f.fail {
+		return nil, errors.New("state unavailable")
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		return nil, errors.New("unbounded contract call")
+	}
+	if f.undeployed {
+		return nil, nil
+	}
+	if call.To == nil || *call.To != registryAddr {
+		return nil, fmt.Errorf("unexpected call target %v", call.To)
+	}
+	method, err := f.abi.MethodById(call.Data[:4])
+	if err != nil {
+		return nil, err
+	}
+	args := call.Data[4:]
+	switch method.Name {
+	case "getNodeStatus":
+		for _, e := range f.entries {
+			if e.id == enode.ID(common.BytesToHash(args[:32])) {
+				return method.Outputs.Pack(uint8(e.status))
+			}
+		}
+		return method.Outputs.Pack(uint8(NodeNotInList))
+	case "getNumberOfNodes":
+		return method.Outputs.Pack(big.NewInt(int64(len(f.entries))))
+	case "getNodeDetails":
+		e := f.entries[new(big.Int).SetBytes(args[:32]).Int64()]
+		return method.Outputs.Pack([32]byte(e.id), e.enode, uint8(e.status))
+	}
+	return nil, fmt.Errorf("unexpected method %s", method.Name)
+}
+
+func newTestEntry(status NodeStatus) registryEntry {
+	key, _ := crypto.GenerateKey()
+	node := enode.NewV4(&key.PublicKey, net.IP{127, 0, 0, 1}, 30303, 30303, 0)
+	return registryEntry{id: node.ID(), enode: node.String(), status: status}
+}
+
+func TestNodeRegistryPermissions(t *testing.T) {
+	var (
+		approved    = newTestEntry(NodeApproved)
+		pending     = newTestEntry(NodePendingApproval)
+		deactivated = newTestEntry(NodeDeactivated)
+		blacklisted = newTestEntry(NodeBlacklisted)
+		unknown     = newTestEntry(NodeNotInList)
+	)
+	backend := newFakeRegistry(t, approved, pending, deactivated, blacklisted)
+	registry, err := NewNodeRegistry(registryAddr, backend)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		id   enode.ID
+		want bool
+	}{
+		{approved.id, true},
+		{pending.id, false},
+		{deactivated.id, false},
+		{blacklisted.id, false},
+		{unknown.id, false},
+	}
+	for i, tt := range tests {
+		have, err := registry.IsNodePermissioned(tt.id)
+		if err != nil {
+			t.Fatalf("test %d: failed to query registry: %v", i, err)
+		}
+		if have != tt.want {
+			t.Errorf("test %d: permissioned mismatch: have %v, want %v", i, have, tt.want)
+		}
+	}
+	// Contract failures must never admit a node
+	backend.fail = true
+	if have, err := registry.IsNodePermissioned(approved.id); have || err == nil {
+		t.Errorf("node permissioned with failing registry: %v, %v", have, err)
+	}
+	// A registry missing from the state is reported, for the server to admit
+	// its bootstrap nodes instead
+	backend.fail, backend.undeployed = false, true
+	if _, err := registry.IsNodePermissioned(approved.id); err != p2p.ErrPermissionsUnavailable {
+		t.Errorf("error mismatch: have %v, want %v", err, p2p.ErrPermissionsUnavailable)
+	}
+	if _, err := registry.Nodes(); err != p2p.ErrPermissionsUnavailable {
+		t.Errorf("error mismatch: have %v, want %v", err, p2p.ErrPermissionsUnavailable)
+	}
+}
+
+func TestNodeRegistryNodes(t *testing.T) {
+	var (
+		first  = newTestEntry(NodeApproved)
+		second = newTestEntry(NodeApproved)
+		forged = newTestEntry(NodeApproved)
+	)
+	forged.enode = second.enode
+
+	backend := newFakeRegistry(t, first, newTestEntry(NodeBlacklisted), second, newTestEntry(NodePendingApproval), forged)
+	registry, err := NewNodeRegistry(registryAddr, backend)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes, err := registry.Nodes()
+	if err != nil {
+		t.Fatalf("failed to list nodes: %v", err)
+	}
+	if len(nodes) != 2 {
+		t.Fatalf("node count mismatch: have %d, want 2", len(nodes))
+	}
+	if nodes[0].ID() != first.id || nodes[1].ID() != second.id {
+		t.Errorf("node list mismatch: have [%v %v], want [%v %v]", nodes[0].ID(), nodes[1].ID(), first.id, second.id)
+	}
+}
+
+// deployNodeRegistry compiles NodeRegistry.sol and deploys it on a simulated
+// chain, administered by the given keys.
+func deployNodeRegistry(t *testing.T, admins ...*ecdsa.PrivateKey) (*backends.SimulatedBackend, common.Address, *contract.NodeRegistry) {
+	if _, err := exec.LookPath("solc"); err != nil {
+		t.Skip(err)
+	}
+	contracts, err := compiler.CompileSolidity("", filepath.Join("contract", "NodeRegistry.sol"))
+	if err != nil {
+		t.Fatalf("failed to compile registry: %v", err)
+	}
+	var code string
+	for name, c := range contracts {
+		if strings.HasSuffix(name, ":NodeRegistry") {
+			code = c.Code
+		}
+	}
+	if code == "" {
+		t.Fatal("registry missing from compiler output")
+	}
+	parsed, err := abi.JSON(strings.NewReader(contract.NodeRegistryABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	alloc := make(core.GenesisAlloc)
+	addrs := make([]common.Address, len(admins))
+	for i, key := range admins {
+		addrs[i] = crypto.PubkeyToAddress(key.PublicKey)
+		alloc[addrs[i]] = core.GenesisAccount{Balance: big.NewInt(1000000000000000000)}
+	}
+	sim := backends.NewSimulatedBackend(alloc, 10000000)
+	address, _, _, err := bind.DeployContract(bind.NewKeyedTransactor(admins[0]), parsed, common.FromHex(code), sim, addrs)
+	if err != nil {
+		t.Fatalf("failed to deploy registry: %v", err)
+	}
+	sim.Commit()
+
+	registry, err := contract.NewNodeRegistry(address, sim)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sim, address, registry
+}
+
+// Tests the admission decisions against the compiled registry contract.
+func TestNodeRegistryContract(t *testing.T) {
+	var admins []*ecdsa.PrivateKey
+	for i := 0; i < 3; i++ {
+		key, _ := crypto.GenerateKey()
+		admins = append(admins, key)
+	}
+	sim, address, registry := deployNodeRegistry(t, admins...)
+
+	perms, err := NewNodeRegistry(address, sim)
+	if err != nil {
+		t.Fatal(err)
+	}
+	node := newTestEntry(NodeNotInList)
+	check := func(step string, want NodeStatus) {
+		sim.Commit()
+		status, err := perms.NodeStatus(node.id)
+		if err != nil {
+			t.Fatalf("%s: failed to query registry: %v", step, err)
+		}
+		if status != want {
+			t.Fatalf("%s: status mismatch: have %v, want %v", step, status, want)
+		}
+		permitted, _ := perms.IsNodePermissioned(node.id)
+		if permitted != (want == NodeApproved) {
+			t.Fatalf("%s: permissioned mismatch: have %v, want %v", step, permitted, want == NodeApproved)
+		}
+	}
+	transact := func(step string, key *ecdsa.PrivateKey, fn func(*bind.TransactOpts) (*types.Transaction, error)) {
+		if _, err := fn(bind.NewKeyedTransactor(key)); err != nil {
+			t.Fatalf("%s: transaction failed: %v", step, err)
+		}
+	}
+	check("unknown", NodeNotInList)
+
+	transact("propose", admins[0], func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return registry.ProposeNode(opts, node.id, node.enode)
+	})
+	check("proposed", NodePendingApproval)
+
+	// The proposer is removed, its vote mustn't count towards the majority of
+	// the two remaining admins anymore
+	transact("remove admin", admins[1], func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return registry.RemoveAdmin(opts, crypto.PubkeyToAddress(admins[0].PublicKey))
+	})
+	transact("approve", admins[1], func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return registry.ApproveNode(opts, node.id)
+	})
+	check("approved by one of two admins", NodePendingApproval)
+
+	transact("approve", admins[2], func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return registry.ApproveNode(opts, node.id)
+	})
+	check("approved by two of two admins", NodeApproved)
+
+	nodes, err := perms.Nodes()
+	if err != nil || len(nodes) != 1 || nodes[0].ID() != node.id {
+		t.Fatalf("node list mismatch: have %v, %v, want [%v]", nodes, err, node.id)
+	}
+	transact("deactivate", admins[1], func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return registry.DeactivateNode(opts, node.id)
+	})
+	check("deactivated", NodeDeactivated)
+
+	transact("blacklist", admins[2], func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return registry.BlacklistNode(opts, node.id)
+	})
+	check("blacklisted", NodeBlacklisted)
+}
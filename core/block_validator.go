@@ -18,6 +18,7 @@ package core
 
 import (
 	"fmt"
+	"math/big"
 
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/core/state"
@@ -64,6 +65,9 @@ func (v *BlockValidator) ValidateBody(block *types.Block) error {
 	if hash := types.DeriveSha(block.Transactions()); hash != header.TxHash {
 		return fmt.Errorf("transaction root hash mismatch: have %x, want %x", hash, header.TxHash)
 	}
+	if err := v.validateGasLimits(block); err != nil {
+		return err
+	}
 	if !v.bc.HasBlockAndState(block.ParentHash(), block.NumberU64()-1) {
 		if !v.bc.HasBlock(block.ParentHash(), block.NumberU64()-1) {
 			return consensus.ErrUnknownAncestor
@@ -73,6 +77,28 @@ func (v *BlockValidator) ValidateBody(block *types.Block) error {
 	return nil
 }
 
+// validateGasLimits checks the gas limit of a Quorum block and the gas of its
+// transactions against the limits in effect, which Quorum consensus engines don't.
+func (v *BlockValidator) validateGasLimits(block *types.Block) error {
+	limit := v.config.QuorumBlockGasLimit(block.Number())
+	if limit == 0 {
+		return nil
+	}
+	if block.GasLimit() > limit {
+		return fmt.Errorf("invalid gas limit: have %d, max %d", block.GasLimit(), limit)
+	}
+	if block.GasUsed() > block.GasLimit() {
+		return fmt.Errorf("invalid gas used: have %d, gas limit %d", block.GasUsed(), block.GasLimit())
+	}
+	txLimit := v.config.QuorumTxGasLimit(block.Number())
+	for i, tx := range block.Transactions() {
+		if tx.Gas() > txLimit {
+			return fmt.Errorf("invalid transaction %d gas: have %d, max %d", i, tx.Gas(), txLimit)
+		}
+	}
+	return nil
+}
+
 // ValidateState validates the various changes that happen after a state
 // transition, such as amount of used gas, the receipt roots and the state root
 // itself. ValidateState returns a database batch if the validation was a success
@@ -103,6 +129,15 @@ func (v *BlockValidator) ValidateState(block, parent *types.Block, statedb *stat
 	return nil
 }
 
+// CapGasLimit lowers the gas limit of a new Quorum block to the block gas limit
+// in effect at its number, if any.
+func CapGasLimit(config *params.ChainConfig, number *big.Int, gasLimit uint64) uint64 {
+	if limit := config.QuorumBlockGasLimit(number); limit != 0 && gasLimit > limit {
+		return limit
+	}
+	return gasLimit
+}
+
 // CalcGasLimit computes the gas limit of the next block after parent. It aims
 // to keep the baseline gas above the provided floor, and increase it towards the
 // ceil if the blocks are full. If the ceil is exceeded, it will always decrease
@@ -17,10 +17,12 @@
 package core
 
 import (
+	"math/big"
 	"runtime"
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
@@ -197,3 +199,39 @@ func testHeaderConcurrentAbortion(t *testing.T, threads int) {
 		t.Errorf("verification count too large: have %d, want below %d", verified, 2*threads)
 	}
 }
+
+// Tests that the Quorum block and transaction gas limits are only enforced once
+// activated.
+func TestQuorumGasLimitValidation(t *testing.T) {
+	config := *params.QuorumTestChainConfig
+	config.GasLimits = &params.QuorumGasLimitConfig{Block: big.NewInt(2), BlockGasLimit: 1000000, TxGasLimit: 100000}
+	validator := NewBlockValidator(&config, nil, nil)
+
+	tx := func(gas uint64) *types.Transaction {
+		return types.NewTransaction(0, common.Address{}, common.Big0, gas, common.Big0, nil)
+	}
+	tests := []struct {
+		number, gasLimit, gasUsed uint64
+		txs                       []*types.Transaction
+		valid                     bool
+	}{
+		{1, 5000000, 4000000, []*types.Transaction{tx(4000000)}, true},
+		{2, 5000000, 0, nil, false},
+		{2, 1000000, 2000000, nil, false},
+		{2, 1000000, 0, []*types.Transaction{tx(200000)}, false},
+		{2, 1000000, 100000, []*types.Transaction{tx(100000)}, true},
+	}
+	for i, tt := range tests {
+		header := &types.Header{Number: new(big.Int).SetUint64(tt.number), GasLimit: tt.gasLimit, GasUsed: tt.gasUsed}
+		block := types.NewBlock(header, tt.txs, nil, nil)
+		if err := validator.validateGasLimits(block); (err == nil) != tt.valid {
+			t.Errorf("test %d: validity mismatch: have %v, want valid %v", i, err, tt.valid)
+		}
+	}
+	if limit := CapGasLimit(&config, big.NewInt(1), 5000000); limit != 5000000 {
+		t.Errorf("gas limit capped before activation: have %d", limit)
+	}
+	if limit := CapGasLimit(&config, big.NewInt(2), 5000000); limit != 1000000 {
+		t.Errorf("gas limit not capped after activation: have %d, want %d", limit, 1000000)
+	}
+}
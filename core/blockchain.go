@@ -72,6 +72,10 @@ type CacheConfig struct {
 	Disabled      bool          // Whether to disable trie write caching (archive node)
 	TrieNodeLimit int           // Memory limit (MB) at which to flush the current in-memory trie to disk
 	TrieTimeLimit time.Duration // Time limit after which to flush the current in-memory trie to disk
+
+	PrivateRetention uint64 // Number of recent blocks whose private state is kept in memory, sharing TrieNodeLimit (0 = archive private state)
+	ParallelTxs      int    // Number of transactions executed speculatively in parallel on import (0 or 1 = serial)
+	StateDiffs       bool   // Whether to record the public and private state diffs of every block written
 }
 
 // BlockChain represents the canonical chain given a database with a genesis
@@ -102,6 +106,7 @@ type BlockChain struct {
 	chainSideFeed event.Feed
 	chainHeadFeed event.Feed
 	logsFeed      event.Feed
+	stateDiffFeed event.Feed
 	scope         event.SubscriptionScope
 	genesisBlock  *types.Block
 
@@ -135,6 +140,7 @@ type BlockChain struct {
 	shouldPreserve func(*types.Block) bool // Function used to determine whether should preserve the given block.
 
 	privateStateCache state.Database // Private state database to reuse between imports (contains state cache)
+	privateTriegc     *prque.Prque   // Priority queue mapping block numbers to private tries to gc
 }
 
 // NewBlockChain returns a fully initialised block chain using information
@@ -147,6 +153,12 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 			TrieTimeLimit: 5 * time.Minute,
 		}
 	}
+	// The private state is flushed together with the public one, so it can't be
+	// garbage collected sooner than that
+	if cacheConfig.PrivateRetention > 0 && cacheConfig.PrivateRetention < triesInMemory {
+		log.Warn("Private state retention too low, raising", "provided", cacheConfig.PrivateRetention, "updated", triesInMemory)
+		cacheConfig.PrivateRetention = triesInMemory
+	}
 	bodyCache, _ := lru.New(bodyCacheLimit)
 	bodyRLPCache, _ := lru.New(bodyCacheLimit)
 	receiptsCache, _ := lru.New(receiptsCacheLimit)
@@ -171,6 +183,7 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 		vmConfig:          vmConfig,
 		badBlocks:         badBlocks,
 		privateStateCache: state.NewDatabase(db),
+		privateTriegc:     prque.New(nil),
 	}
 	bc.SetValidator(NewBlockValidator(chainConfig, bc, engine))
 	bc.SetProcessor(NewStateProcessor(chainConfig, bc, engine))
@@ -226,8 +239,8 @@ func (bc *BlockChain) loadLastState() error {
 		log.Warn("Head block missing, resetting chain", "hash", head)
 		return bc.Reset()
 	}
-	// Make sure the state associated with the block is available
-	if _, err := state.New(currentBlock.Root(), bc.stateCache); err != nil {
+	// Make sure the public and private state associated with the block is available
+	if !bc.stateAvailable(currentBlock) {
 		// Dangling block without a state associated, init from scratch
 		log.Warn("Head state missing, repairing chain", "number", currentBlock.Number(), "hash", currentBlock.Hash())
 		if err := bc.repair(&currentBlock); err != nil {
@@ -235,13 +248,6 @@ func (bc *BlockChain) loadLastState() error {
 		}
 	}
 
-	// Quorum
-	if _, err := state.New(GetPrivateStateRoot(bc.db, currentBlock.Root()), bc.privateStateCache); err != nil {
-		log.Warn("Head private state missing, resetting chain", "number", currentBlock.Number(), "hash", currentBlock.Hash())
-		return bc.Reset()
-	}
-	// /Quorum
-
 	// Everything seems to be fine, set as the head block
 	bc.currentBlock.Store(currentBlock)
 
@@ -305,7 +311,7 @@ func (bc *BlockChain) SetHead(head uint64) error {
 		bc.currentBlock.Store(bc.GetBlock(currentHeader.Hash(), currentHeader.Number.Uint64()))
 	}
 	if currentBlock := bc.CurrentBlock(); currentBlock != nil {
-		if _, err := state.New(currentBlock.Root(), bc.stateCache); err != nil {
+		if !bc.stateAvailable(currentBlock) {
 			// Rewound state missing, rolled back to before pivot, reset to genesis
 			bc.currentBlock.Store(bc.genesisBlock)
 		}
@@ -341,6 +347,13 @@ func (bc *BlockChain) FastSyncCommitHead(hash common.Hash) error {
 	if _, err := trie.NewSecure(block.Root(), bc.stateCache.TrieDB(), 0); err != nil {
 		return err
 	}
+	// Quorum: private state can't be synced, rebuild it from the local payloads
+	if bc.chainConfig.IsQuorum {
+		if err := bc.rebuildPrivateState(block); err != nil {
+			return err
+		}
+	}
+
 	// If all checks out, manually set the head block
 	bc.mu.Lock()
 	bc.currentBlock.Store(block)
@@ -355,11 +368,11 @@ func (bc *BlockChain) GasLimit() uint64 {
 	bc.mu.RLock()
 	defer bc.mu.RUnlock()
 
-	if bc.Config().IsQuorum {
+	head := bc.CurrentBlock()
+	if bc.Config().IsQuorum && bc.Config().QuorumBlockGasLimit(head.Number()) == 0 {
 		return math.MaxBig256.Uint64() // HACK(joel) a very large number
-	} else {
-		return bc.CurrentBlock().GasLimit()
 	}
+	return head.GasLimit()
 }
 
 // CurrentBlock retrieves the current head block of the canonical chain. The
@@ -452,16 +465,31 @@ func (bc *BlockChain) ResetWithGenesisBlock(genesis *types.Block) error {
 	return nil
 }
 
+// stateAvailable checks whether both the public and the private state of a block
+// are available in the database.
+func (bc *BlockChain) stateAvailable(block *types.Block) bool {
+	if _, err := state.New(block.Root(), bc.stateCache); err != nil {
+		return false
+	}
+	// Quorum
+	if _, err := state.New(GetPrivateStateRoot(bc.db, block.Root()), bc.privateStateCache); err != nil {
+		return false
+	}
+	// /Quorum
+	return true
+}
+
 // repair tries to repair the current blockchain by rolling back the current block
-// until one with associated state is found. This is needed to fix incomplete db
-// writes caused either by crashes/power outages, or simply non-committed tries.
+// until one with associated public and private state is found. This is needed to
+// fix incomplete db writes caused either by crashes/power outages, or simply
+// non-committed tries.
 //
 // This method only rolls back the current block. The current header and current
 // fast block are left intact.
 func (bc *BlockChain) repair(head **types.Block) error {
 	for {
 		// Abort if we've rewound to a head block that does have associated state
-		if _, err := state.New((*head).Root(), bc.stateCache); err == nil {
+		if bc.stateAvailable(*head) {
 			log.Info("Rewound blockchain to past state", "number", (*head).Number(), "hash", (*head).Hash())
 			return nil
 		}
@@ -650,6 +678,36 @@ func (bc *BlockChain) GetReceiptsByHash(hash common.Hash) types.Receipts {
 	return receipts
 }
 
+// stateDiffs is the database representation of the state diffs of a block.
+type stateDiffs struct {
+	Public  *state.StateDiff
+	Private *state.StateDiff
+}
+
+// GetStateDiff retrieves the public and private state diffs recorded for a block,
+// or nils if none were recorded.
+func (bc *BlockChain) GetStateDiff(hash common.Hash) (*state.StateDiff, *state.StateDiff) {
+	number := rawdb.ReadHeaderNumber(bc.db, hash)
+	if number == nil {
+		return nil, nil
+	}
+	data := rawdb.ReadStateDiff(bc.db, hash, *number)
+	if len(data) == 0 {
+		return nil, nil
+	}
+	diffs := new(stateDiffs)
+	if err := rlp.DecodeBytes(data, diffs); err != nil {
+		log.Error("Invalid state diff RLP", "hash", hash, "err", err)
+		return nil, nil
+	}
+	return diffs.Public, diffs.Private
+}
+
+// StateDiffsEnabled reports whether the state diffs of written blocks are recorded.
+func (bc *BlockChain) StateDiffsEnabled() bool {
+	return bc.cacheConfig.StateDiffs
+}
+
 // GetBlocksFromHash returns the block corresponding to hash and up to n-1 ancestors.
 // [deprecated by eth/62]
 func (bc *BlockChain) GetBlocksFromHash(hash common.Hash, n int) (blocks []*types.Block) {
@@ -724,6 +782,28 @@ func (bc *BlockChain) Stop() {
 			log.Error("Dangling trie nodes after full cleanup")
 		}
 	}
+	// Quorum: same for the private state, unless it's archived
+	if !bc.privateArchive() {
+		triedb := bc.privateStateCache.TrieDB()
+
+		for _, offset := range []uint64{0, 1, triesInMemory - 1} {
+			if number := bc.CurrentBlock().NumberU64(); number > offset {
+				recent := bc.GetBlockByNumber(number - offset)
+				root := GetPrivateStateRoot(bc.db, recent.Root())
+
+				log.Info("Writing cached private state to disk", "block", recent.Number(), "hash", recent.Hash(), "root", root)
+				if err := triedb.Commit(root, true); err != nil {
+					log.Error("Failed to commit recent private state trie", "err", err)
+				}
+			}
+		}
+		for !bc.privateTriegc.Empty() {
+			triedb.Dereference(bc.privateTriegc.PopItem().(common.Hash))
+		}
+		if size, _ := triedb.Size(); size != 0 {
+			log.Error("Dangling private trie nodes after full cleanup")
+		}
+	}
 	log.Info("Blockchain manager stopped")
 }
 
@@ -922,6 +1002,66 @@ func (bc *BlockChain) WriteBlockWithoutState(block *types.Block, td *big.Int) (e
 	return nil
 }
 
+// privateArchive reports whether every private state trie is written to disk
+// instead of being garbage collected.
+func (bc *BlockChain) privateArchive() bool {
+	return bc.cacheConfig.Disabled || bc.cacheConfig.PrivateRetention == 0
+}
+
+// trieNodeLimits splits the TrieNodeLimit memory allowance between the public
+// and the private in-memory trie databases. An archived private state is never
+// held in memory, leaving the whole allowance to the public state.
+func (bc *BlockChain) trieNodeLimits() (public, private common.StorageSize) {
+	limit := common.StorageSize(bc.cacheConfig.TrieNodeLimit) * 1024 * 1024
+	if bc.privateArchive() {
+		return limit, 0
+	}
+	return limit - limit/4, limit / 4
+}
+
+// writePrivateState stores the private state trie of a block. Archived private
+// state is written straight to disk, otherwise the trie is kept referenced in
+// memory for PrivateRetention blocks and flushed alongside the public state.
+func (bc *BlockChain) writePrivateState(block *types.Block, root common.Hash, flushed *types.Header) error {
+	triedb := bc.privateStateCache.TrieDB()
+	if bc.privateArchive() {
+		return triedb.Commit(root, false)
+	}
+	triedb.Reference(root, common.Hash{}) // metadata reference to keep trie alive
+	bc.privateTriegc.Push(root, -int64(block.NumberU64()))
+
+	// If we exceeded our memory allowance, flush matured singleton nodes to disk
+	var (
+		nodes, imgs = triedb.Size()
+		_, limit    = bc.trieNodeLimits()
+	)
+	if nodes > limit || imgs > 4*1024*1024 {
+		triedb.Cap(limit - ethdb.IdealBatchSize)
+	}
+	// Flush the private state of the block whose public state went to disk, so
+	// that a restart finds both of them
+	if flushed != nil {
+		if err := triedb.Commit(GetPrivateStateRoot(bc.db, flushed.Root), true); err != nil {
+			return err
+		}
+	}
+	// Garbage collect anything below our required retention
+	current := block.NumberU64()
+	if current <= bc.cacheConfig.PrivateRetention {
+		return nil
+	}
+	chosen := current - bc.cacheConfig.PrivateRetention
+	for !bc.privateTriegc.Empty() {
+		root, number := bc.privateTriegc.Pop()
+		if uint64(-number) > chosen {
+			bc.privateTriegc.Push(root, number)
+			break
+		}
+		triedb.Dereference(root.(common.Hash))
+	}
+	return nil
+}
+
 // WriteBlockWithState writes the block and all associated state to the database.
 func (bc *BlockChain) WriteBlockWithState(block *types.Block, receipts []*types.Receipt, state, privateState *state.StateDB) (status WriteStatus, err error) {
 	bc.wg.Add(1)
@@ -953,17 +1093,14 @@ func (bc *BlockChain) WriteBlockWithState(block *types.Block, receipts []*types.
 	}
 	triedb := bc.stateCache.TrieDB()
 
-	// Explicit commit for privateStateTriedb to handle Raft db issues
+	var privateRoot common.Hash
 	if privateState != nil {
-		privateRoot, err := privateState.Commit(bc.chainConfig.IsEIP158(block.Number()))
-		if err != nil {
-			return NonStatTy, err
-		}
-		privateTriedb := bc.privateStateCache.TrieDB()
-		if err := privateTriedb.Commit(privateRoot, false); err != nil {
+		if privateRoot, err = privateState.Commit(bc.chainConfig.IsEIP158(block.Number())); err != nil {
 			return NonStatTy, err
 		}
 	}
+	// Public state trie that was flushed to disk with this block, if any
+	var flushed *types.Header
 
 	// If we're running an archive node, always flush
 	if bc.cacheConfig.Disabled {
@@ -980,7 +1117,7 @@ func (bc *BlockChain) WriteBlockWithState(block *types.Block, receipts []*types.
 			// If we exceeded our memory allowance, flush matured singleton nodes to disk
 			var (
 				nodes, imgs = triedb.Size()
-				limit       = common.StorageSize(bc.cacheConfig.TrieNodeLimit) * 1024 * 1024
+				limit, _    = bc.trieNodeLimits()
 			)
 			if nodes > limit || imgs > 4*1024*1024 {
 				triedb.Cap(limit - ethdb.IdealBatchSize)
@@ -1000,6 +1137,7 @@ func (bc *BlockChain) WriteBlockWithState(block *types.Block, receipts []*types.
 				triedb.Commit(header.Root, true)
 				lastWrite = chosen
 				bc.gcproc = 0
+				flushed = header
 			}
 			// Garbage collect anything below our required write retention
 			for !bc.triegc.Empty() {
@@ -1012,11 +1150,28 @@ func (bc *BlockChain) WriteBlockWithState(block *types.Block, receipts []*types.
 			}
 		}
 	}
+	if privateState != nil {
+		if err := bc.writePrivateState(block, privateRoot, flushed); err != nil {
+			return NonStatTy, err
+		}
+	}
 
 	// Write other block data using a batch.
 	batch := bc.db.NewBatch()
 	rawdb.WriteReceipts(batch, block.Hash(), block.NumberU64(), receipts)
 
+	if diff := state.Diff(); diff != nil {
+		diffs := &stateDiffs{Public: diff}
+		if privateState != nil {
+			diffs.Private = privateState.Diff()
+		}
+		enc, err := rlp.EncodeToBytes(diffs)
+		if err != nil {
+			return NonStatTy, err
+		}
+		rawdb.WriteStateDiff(batch, block.Hash(), block.NumberU64(), enc)
+	}
+
 	// If the total difficulty is higher than our known, add it to the canonical chain
 	// Second clause in the if statement reduces the vulnerability to selfish mining.
 	// Please refer to http://www.cs.cornell.edu/~ie53/publications/btcProcFC.pdf
@@ -1095,6 +1250,46 @@ func mergeReceipts(pub, priv types.Receipts) types.Receipts {
 	return ret
 }
 
+// PublicReceipts returns the receipts of a block that all nodes agree on. The
+// receipts stored locally carry the private receipt of the private transactions
+// this node took part in, while the public receipt of a private transaction is
+// always successful and has no logs. Receipts before Byzantium hold intermediate
+// state roots that can't be recovered, so nil is returned for pre-Byzantium
+// blocks with private transactions, which must not be served.
+func PublicReceipts(config *params.ChainConfig, block *types.Block, receipts types.Receipts) types.Receipts {
+	if !config.IsQuorum {
+		return receipts
+	}
+	txs := block.Transactions()
+	if len(txs) != len(receipts) {
+		return nil
+	}
+	if !config.IsByzantium(block.Number()) {
+		for _, tx := range txs {
+			if tx.IsPrivate() {
+				return nil
+			}
+		}
+		return receipts
+	}
+	public := make(types.Receipts, len(receipts))
+	copy(public, receipts)
+	for i, tx := range txs {
+		if !tx.IsPrivate() {
+			continue
+		}
+		receipt := types.NewReceipt(nil, false, receipts[i].CumulativeGasUsed)
+		receipt.TxHash = receipts[i].TxHash
+		receipt.ContractAddress = receipts[i].ContractAddress
+		receipt.GasUsed = receipts[i].GasUsed
+		receipt.Logs = []*types.Log{}
+		receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+
+		public[i] = receipt
+	}
+	return public
+}
+
 // insertChain will execute the actual chain insertion and event aggregation. The
 // only reason this method exists as a separate one is to make locking cleaner
 // with deferred statements.
@@ -1250,6 +1445,11 @@ func (bc *BlockChain) insertChain(chain types.Blocks) (int, []interface{}, []*ty
 		}
 		// /Quorum
 
+		if bc.cacheConfig.StateDiffs {
+			state.EnableDiff()
+			privateState.EnableDiff()
+		}
+
 		// Process block using the parent state as reference point.
 		receipts, privateReceipts, logs, usedGas, err := bc.processor.Process(block, state, privateState, bc.vmConfig)
 		if err != nil {
@@ -1285,6 +1485,9 @@ func (bc *BlockChain) insertChain(chain types.Blocks) (int, []interface{}, []*ty
 		if err := WritePrivateBlockBloom(bc.db, block.NumberU64(), privateReceipts); err != nil {
 			return i, events, coalescedLogs, err
 		}
+		if bc.cacheConfig.StateDiffs {
+			events = append(events, StateDiffEvent{block, state.Diff(), privateState.Diff()})
+		}
 		switch status {
 		case CanonStatTy:
 			log.Debug("Inserted new block", "number", block.Number(), "hash", block.Hash(), "uncles", len(block.Uncles()),
@@ -1506,6 +1709,9 @@ func (bc *BlockChain) PostChainEvents(events []interface{}, logs []*types.Log) {
 
 		case ChainSideEvent:
 			bc.chainSideFeed.Send(ev)
+
+		case StateDiffEvent:
+			bc.stateDiffFeed.Send(ev)
 		}
 	}
 }
@@ -1704,6 +1910,11 @@ func (bc *BlockChain) SubscribeChainSideEvent(ch chan<- ChainSideEvent) event.Su
 	return bc.scope.Track(bc.chainSideFeed.Subscribe(ch))
 }
 
+// SubscribeStateDiffEvent registers a subscription of StateDiffEvent.
+func (bc *BlockChain) SubscribeStateDiffEvent(ch chan<- StateDiffEvent) event.Subscription {
+	return bc.scope.Track(bc.stateDiffFeed.Subscribe(ch))
+}
+
 // SubscribeLogsEvent registers a subscription of []*types.Log.
 func (bc *BlockChain) SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription {
 	return bc.scope.Track(bc.logsFeed.Subscribe(ch))
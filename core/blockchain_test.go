@@ -17,9 +17,13 @@
 package core
 
 import (
+	"bytes"
+	"crypto/ecdsa"
+	"errors"
 	"fmt"
 	"math/big"
 	"math/rand"
+	"reflect"
 	"sync"
 	"testing"
 	"time"
@@ -34,6 +38,7 @@ import (
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/private"
 )
 
 // So we can deterministically seed different blockchains
@@ -1485,3 +1490,397 @@ func BenchmarkBlockChain_1x1000Executions(b *testing.B) {
 
 	benchmarkLargeNumberOfValueToNonexisting(b, numTxs, numBlocks, recipientFn, dataFn)
 }
+
+// stubPrivateTxManager is a transaction manager holding a fixed set of payloads.
+// It isn't a party to any other private transaction.
+type stubPrivateTxManager map[string][]byte
+
+func (m stubPrivateTxManager) Send(data []byte, from string, to []string) ([]byte, error) {
+	return nil, errors.New("not supported")
+}
+
+func (m stubPrivateTxManager) SendSignedTx(data []byte, to []string) ([]byte, error) {
+	return nil, errors.New("not supported")
+}
+
+func (m stubPrivateTxManager) Receive(data []byte) ([]byte, error) {
+	if payload, ok := m[string(data)]; ok {
+		return payload, nil
+	}
+	return nil, errors.New("unknown payload")
+}
+
+// Tests that committing the head of a fast sync rebuilds the private state and
+// the private receipts from the payloads of the local transaction manager.
+func TestFastSyncCommitHeadPrivateState(t *testing.T) {
+	defer func(p private.PrivateTransactionManager) { private.P = p }(private.P)
+
+	var (
+		key, _  = crypto.GenerateKey()
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		config  = *params.QuorumTestChainConfig
+		gspec   = &Genesis{Config: &config}
+		gendb   = ethdb.NewMemDatabase()
+		genesis = gspec.MustCommit(gendb)
+	)
+	config.ByzantiumBlock = big.NewInt(0)
+
+	// Generate the chain on a node that isn't a party to the private transaction,
+	// which creates a contract storing 0x2a in its first slot
+	private.P = stubPrivateTxManager{}
+	blocks, _ := GenerateChain(&config, genesis, ethash.NewFaker(), gendb, 3, func(i int, block *BlockGen) {
+		if i == 1 {
+			tx, _ := types.SignTx(types.NewContractCreation(0, common.Big0, 100000, common.Big0, []byte("payload")), types.HomesteadSigner{}, key)
+			tx.SetPrivate()
+			block.AddTx(tx)
+		}
+	})
+	private.P = stubPrivateTxManager{"payload": common.Hex2Bytes("602a600055")}
+
+	// Import the chain into a full node
+	fulldb := ethdb.NewMemDatabase()
+	gspec.MustCommit(fulldb)
+	full, _ := NewBlockChain(fulldb, &CacheConfig{Disabled: true}, &config, ethash.NewFaker(), vm.Config{}, nil)
+	defer full.Stop()
+
+	if n, err := full.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert block %d: %v", n, err)
+	}
+	// Fast sync it with the public receipts and the public state of the head
+	fastdb := ethdb.NewMemDatabase()
+	gspec.MustCommit(fastdb)
+	fast, _ := NewBlockChain(fastdb, nil, &config, ethash.NewFaker(), vm.Config{}, nil)
+	defer fast.Stop()
+
+	headers := make([]*types.Header, len(blocks))
+	receipts := make([]types.Receipts, len(blocks))
+	for i, block := range blocks {
+		headers[i] = block.Header()
+		receipts[i] = PublicReceipts(&config, block, rawdb.ReadReceipts(fulldb, block.Hash(), block.NumberU64()))
+	}
+	if n, err := fast.InsertHeaderChain(headers, 1); err != nil {
+		t.Fatalf("failed to insert header %d: %v", n, err)
+	}
+	if n, err := fast.InsertReceiptChain(blocks, receipts); err != nil {
+		t.Fatalf("failed to insert receipt %d: %v", n, err)
+	}
+	head := blocks[len(blocks)-1]
+	statedb, _, _ := full.StateAt(head.Root())
+	for it := state.NewNodeIterator(statedb); it.Next(); {
+		if it.Hash != (common.Hash{}) {
+			blob, _ := fulldb.Get(it.Hash.Bytes())
+			fastdb.Put(it.Hash.Bytes(), blob)
+		}
+	}
+	if err := fast.FastSyncCommitHead(head.Hash()); err != nil {
+		t.Fatalf("failed to commit head: %v", err)
+	}
+	for _, block := range blocks {
+		if have, want := GetPrivateStateRoot(fastdb, block.Root()), GetPrivateStateRoot(fulldb, block.Root()); have != want {
+			t.Errorf("block #%d: private state root mismatch: have %x, want %x", block.Number(), have, want)
+		}
+		have, want := rawdb.ReadReceipts(fastdb, block.Hash(), block.NumberU64()), rawdb.ReadReceipts(fulldb, block.Hash(), block.NumberU64())
+		if !reflect.DeepEqual(have, want) {
+			t.Errorf("block #%d: receipts mismatch: have %v, want %v", block.Number(), have, want)
+		}
+	}
+	_, privateState, err := fast.State()
+	if err != nil {
+		t.Fatalf("failed to open head state: %v", err)
+	}
+	if value := privateState.GetState(crypto.CreateAddress(address, 0), common.Hash{}); value != common.BytesToHash([]byte{0x2a}) {
+		t.Errorf("private contract storage mismatch: have %x, want 0x2a", value)
+	}
+}
+
+// Tests that the receipts of private transactions are replaced by their public
+// counterparts when served to other nodes.
+func TestPublicReceipts(t *testing.T) {
+	publicTx := types.NewTransaction(0, common.Address{}, common.Big0, 21000, common.Big0, nil)
+	privateTx := types.NewTransaction(1, common.Address{}, common.Big0, 50000, common.Big0, []byte{0x01})
+	privateTx.SetPrivate()
+
+	config := *params.QuorumTestChainConfig
+	config.ByzantiumBlock = big.NewInt(0)
+	block := types.NewBlock(&types.Header{Number: big.NewInt(1)}, []*types.Transaction{publicTx, privateTx}, nil, nil)
+
+	log := &types.Log{Address: common.Address{0x01}}
+	receipts := types.Receipts{types.NewReceipt(nil, false, 21000), types.NewReceipt(nil, true, 60000)}
+	receipts[1].TxHash, receipts[1].GasUsed, receipts[1].Logs = privateTx.Hash(), 39000, []*types.Log{log}
+	receipts[1].Bloom = types.CreateBloom(receipts[1:])
+
+	public := PublicReceipts(&config, block, receipts)
+	if public[0] != receipts[0] {
+		t.Errorf("public transaction receipt replaced")
+	}
+	if have := public[1]; have.Status != types.ReceiptStatusSuccessful || len(have.Logs) != 0 || have.Bloom != (types.Bloom{}) ||
+		have.CumulativeGasUsed != 60000 || have.GasUsed != 39000 || have.TxHash != privateTx.Hash() {
+		t.Errorf("private transaction receipt mismatch: have %+v", have)
+	}
+	if len(receipts[1].Logs) != 1 {
+		t.Errorf("stored receipts modified")
+	}
+	// Before Byzantium the public receipts can't be recovered, so none are served
+	config.ByzantiumBlock = big.NewInt(2)
+	if public := PublicReceipts(&config, block, receipts); public != nil {
+		t.Errorf("pre-Byzantium receipts with private transactions served: %v", public)
+	}
+	block = types.NewBlock(&types.Header{Number: big.NewInt(1)}, []*types.Transaction{publicTx}, nil, nil)
+	if public := PublicReceipts(&config, block, receipts[:1]); len(public) != 1 || public[0] != receipts[0] {
+		t.Errorf("pre-Byzantium public receipts mismatch: have %v", public)
+	}
+}
+
+// Tests that blocks imported with speculative parallel execution end up in the
+// same state, with the same receipts and logs, as when executed serially, both
+// for independent transactions and for ones depending on each other.
+func TestParallelProcessing(t *testing.T) {
+	var (
+		keys  = make([]*ecdsa.PrivateKey, 8)
+		addrs = make([]common.Address, len(keys))
+		// A contract emitting an empty log on every call
+		logger = common.Address{0xaa}
+		alloc  = GenesisAlloc{logger: {Code: common.FromHex("60006000a000"), Balance: common.Big1}}
+	)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		addrs[i] = crypto.PubkeyToAddress(keys[i].PublicKey)
+		alloc[addrs[i]] = GenesisAccount{Balance: big.NewInt(1000000000)}
+	}
+	config := *params.QuorumTestChainConfig
+	config.ByzantiumBlock = big.NewInt(0)
+
+	gspec := &Genesis{Config: &config, Alloc: alloc, GasLimit: 10000000}
+	gendb := ethdb.NewMemDatabase()
+	genesis := gspec.MustCommit(gendb)
+
+	signer := types.HomesteadSigner{}
+	blocks, receipts := GenerateChain(&config, genesis, ethash.NewFaker(), gendb, 4, func(i int, block *BlockGen) {
+		block.SetCoinbase(common.Address{0xcc})
+		for j, key := range keys {
+			var tx *types.Transaction
+			switch {
+			case j%4 == 0:
+				// Pay the next account, which sends its own transaction afterwards
+				tx = types.NewTransaction(block.TxNonce(addrs[j]), addrs[j+1], big.NewInt(1000), 21000, common.Big0, nil)
+			case j%4 == 2:
+				// Call the shared logger contract
+				tx = types.NewTransaction(block.TxNonce(addrs[j]), logger, common.Big0, 50000, common.Big0, nil)
+			default:
+				// Pay a fresh account
+				tx = types.NewTransaction(block.TxNonce(addrs[j]), common.Address{byte(i), byte(j)}, big.NewInt(1000), 21000, common.Big0, nil)
+			}
+			tx, _ = types.SignTx(tx, signer, key)
+			block.AddTx(tx)
+		}
+	})
+	// Import the chain with parallel execution, which validates the state roots
+	db := ethdb.NewMemDatabase()
+	gspec.MustCommit(db)
+
+	chain, _ := NewBlockChain(db, &CacheConfig{TrieNodeLimit: 256, TrieTimeLimit: 5 * time.Minute, ParallelTxs: 4}, &config, ethash.NewFaker(), vm.Config{}, nil)
+	defer chain.Stop()
+
+	if !chain.processor.(*StateProcessor).parallel(blocks[0], vm.Config{}) {
+		t.Fatalf("parallel execution not enabled")
+	}
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert block %d: %v", n, err)
+	}
+	for i, block := range blocks {
+		have := chain.GetReceiptsByHash(block.Hash())
+		if len(have) != len(receipts[i]) {
+			t.Fatalf("block %d: receipt count mismatch: have %d, want %d", i, len(have), len(receipts[i]))
+		}
+		for j, receipt := range receipts[i] {
+			if have[j].CumulativeGasUsed != receipt.CumulativeGasUsed || len(have[j].Logs) != len(receipt.Logs) {
+				t.Fatalf("block %d, tx %d: receipt mismatch: have %+v, want %+v", i, j, have[j], receipt)
+			}
+			for k, log := range receipt.Logs {
+				if have[j].Logs[k].Index != log.Index {
+					t.Errorf("block %d, tx %d: log %d index mismatch: have %d, want %d", i, j, k, have[j].Logs[k].Index, log.Index)
+				}
+			}
+		}
+	}
+}
+
+// Tests that the state diffs of imported blocks are stored and announced, and
+// that speculative parallel execution records the same diffs as serial one.
+func TestStateDiffs(t *testing.T) {
+	var (
+		key1, _ = crypto.GenerateKey()
+		key2, _ = crypto.GenerateKey()
+		addr1   = crypto.PubkeyToAddress(key1.PublicKey)
+		addr2   = crypto.PubkeyToAddress(key2.PublicKey)
+		miner   = common.Address{0xcc}
+	)
+	config := *params.QuorumTestChainConfig
+	config.ByzantiumBlock = big.NewInt(0)
+
+	gspec := &Genesis{
+		Config:   &config,
+		Alloc:    GenesisAlloc{addr1: {Balance: big.NewInt(1000000)}, addr2: {Balance: big.NewInt(1000000)}},
+		GasLimit: 10000000,
+	}
+	gendb := ethdb.NewMemDatabase()
+	genesis := gspec.MustCommit(gendb)
+
+	signer := types.HomesteadSigner{}
+	blocks, _ := GenerateChain(&config, genesis, ethash.NewFaker(), gendb, 2, func(i int, block *BlockGen) {
+		block.SetCoinbase(miner)
+		tx1, _ := types.SignTx(types.NewTransaction(block.TxNonce(addr1), common.Address{byte(i + 1)}, big.NewInt(1000), 21000, common.Big0, nil), signer, key1)
+		tx2, _ := types.SignTx(types.NewTransaction(block.TxNonce(addr2), common.Address{0xee}, big.NewInt(500), 21000, common.Big0, nil), signer, key2)
+		block.AddTx(tx1)
+		block.AddTx(tx2)
+	})
+	importDiffs := func(parallel int) []*state.StateDiff {
+		db := ethdb.NewMemDatabase()
+		gspec.MustCommit(db)
+
+		chain, _ := NewBlockChain(db, &CacheConfig{TrieNodeLimit: 256, TrieTimeLimit: 5 * time.Minute, ParallelTxs: parallel, StateDiffs: true}, &config, ethash.NewFaker(), vm.Config{}, nil)
+		defer chain.Stop()
+
+		events := make(chan StateDiffEvent, len(blocks))
+		sub := chain.SubscribeStateDiffEvent(events)
+		defer sub.Unsubscribe()
+
+		if n, err := chain.InsertChain(blocks); err != nil {
+			t.Fatalf("failed to insert block %d: %v", n, err)
+		}
+		var diffs []*state.StateDiff
+		for i, block := range blocks {
+			public, private := chain.GetStateDiff(block.Hash())
+			if public == nil || private == nil {
+				t.Fatalf("block %d: state diff missing", i)
+			}
+			if len(private.Accounts) != 0 {
+				t.Errorf("block %d: private diff not empty: %v", i, private.Accounts)
+			}
+			select {
+			case ev := <-events:
+				if ev.Block.Hash() != block.Hash() || len(ev.Public.Accounts) != len(public.Accounts) {
+					t.Errorf("block %d: state diff event mismatch", i)
+				}
+			default:
+				t.Fatalf("block %d: state diff event missing", i)
+			}
+			diffs = append(diffs, public)
+		}
+		return diffs
+	}
+	serial, parallel := importDiffs(0), importDiffs(4)
+
+	// Check the accounts changed by the first block
+	accounts := make(map[common.Address]*state.AccountDiff)
+	for _, account := range serial[0].Accounts {
+		accounts[account.Address] = account
+	}
+	if len(accounts) != 5 {
+		t.Fatalf("account count mismatch: have %d, want %d", len(accounts), 5)
+	}
+	if acc := accounts[addr1]; acc == nil || acc.Created || acc.PrevNonce != 0 || acc.Nonce != 1 || acc.PrevBalance.Int64()-acc.Balance.Int64() != 1000 {
+		t.Errorf("sender diff mismatch: %+v", acc)
+	}
+	if acc := accounts[common.Address{1}]; acc == nil || !acc.Created || acc.Balance.Int64() != 1000 {
+		t.Errorf("recipient diff mismatch: %+v", acc)
+	}
+	if acc := accounts[miner]; acc == nil || !acc.Created {
+		t.Errorf("coinbase diff mismatch: %+v", acc)
+	}
+	// The recipient existing after the first block must not be recreated
+	for _, account := range serial[1].Accounts {
+		if account.Address == (common.Address{0xee}) && (account.Created || account.PrevBalance.Int64() != 500) {
+			t.Errorf("existing recipient diff mismatch: %+v", account)
+		}
+	}
+	for i := range serial {
+		if !reflect.DeepEqual(serial[i], parallel[i]) {
+			t.Errorf("block %d: parallel diff mismatch: have %v, want %v", i, parallel[i].Accounts, serial[i].Accounts)
+		}
+	}
+}
+
+// Tests that the private state changes of a block are recorded in its private
+// diff and survive the database round trip.
+func TestPrivateStateDiffs(t *testing.T) {
+	var (
+		db       = ethdb.NewMemDatabase()
+		gspec    = &Genesis{Config: params.QuorumTestChainConfig, GasLimit: 10000000}
+		genesis  = gspec.MustCommit(db)
+		contract = common.Address{0xaa}
+		code     = []byte{0x60, 0x00, 0x60, 0x00, 0xf3}
+		slot     = common.Hash{0x01}
+	)
+	blocks, _ := GenerateChain(params.QuorumTestChainConfig, genesis, ethash.NewFaker(), db, 2, func(i int, block *BlockGen) {})
+
+	chain, _ := NewBlockChain(db, &CacheConfig{TrieNodeLimit: 256, TrieTimeLimit: 5 * time.Minute, StateDiffs: true}, params.QuorumTestChainConfig, ethash.NewFaker(), vm.Config{}, nil)
+	defer chain.Stop()
+
+	// Process the blocks by hand, writing to the private state along the way
+	parent := genesis
+	for i, block := range blocks {
+		statedb, privateState, err := chain.StateAt(parent.Root())
+		if err != nil {
+			t.Fatalf("block %d: failed to open parent state: %v", i, err)
+		}
+		statedb.EnableDiff()
+		privateState.EnableDiff()
+
+		receipts, _, _, _, err := chain.Processor().Process(block, statedb, privateState, vm.Config{})
+		if err != nil {
+			t.Fatalf("block %d: failed to process: %v", i, err)
+		}
+		if i == 0 {
+			privateState.SetNonce(contract, 1)
+			privateState.SetCode(contract, code)
+		}
+		privateState.SetState(contract, slot, common.Hash{byte(i + 1)})
+
+		if _, err := chain.WriteBlockWithState(block, receipts, statedb, privateState); err != nil {
+			t.Fatalf("block %d: failed to write: %v", i, err)
+		}
+		privateRoot, _ := privateState.Commit(params.QuorumTestChainConfig.IsEIP158(block.Number()))
+		if err := WritePrivateStateRoot(db, block.Root(), privateRoot); err != nil {
+			t.Fatalf("block %d: failed to write private state root: %v", i, err)
+		}
+		parent = block
+	}
+	// The first block creates the contract with its code and storage
+	public, private := chain.GetStateDiff(blocks[0].Hash())
+	if public == nil || private == nil {
+		t.Fatalf("state diff missing: public %v, private %v", public, private)
+	}
+	if len(private.Accounts) != 1 {
+		t.Fatalf("private account count mismatch: have %d, want %d", len(private.Accounts), 1)
+	}
+	acc := private.Accounts[0]
+	if acc.Address != contract || !acc.Created || acc.Destroyed || acc.PrevNonce != 0 || acc.Nonce != 1 {
+		t.Errorf("private account diff mismatch: %+v", acc)
+	}
+	if acc.PrevCodeHash != (common.Hash{}) {
+		t.Errorf("previous code hash mismatch: have %x, want none", acc.PrevCodeHash)
+	}
+	if acc.CodeHash != crypto.Keccak256Hash(code) || !bytes.Equal(acc.Code, code) {
+		t.Errorf("code mismatch: have %x (%x), want %x (%x)", acc.Code, acc.CodeHash, code, crypto.Keccak256Hash(code))
+	}
+	if want := []state.StorageDiff{{Key: slot, Value: common.Hash{0x01}}}; !reflect.DeepEqual(acc.Storage, want) {
+		t.Errorf("storage diff mismatch: have %v, want %v", acc.Storage, want)
+	}
+	for _, account := range public.Accounts {
+		if account.Address == contract {
+			t.Errorf("private account leaked into public diff: %+v", account)
+		}
+	}
+	// The second block only updates the existing storage slot
+	if _, private = chain.GetStateDiff(blocks[1].Hash()); private == nil || len(private.Accounts) != 1 {
+		t.Fatalf("second private diff mismatch: %v", private)
+	}
+	acc = private.Accounts[0]
+	if acc.Created || acc.Nonce != 1 || len(acc.Code) != 0 {
+		t.Errorf("second private account diff mismatch: %+v", acc)
+	}
+	if want := []state.StorageDiff{{Key: slot, Prev: common.Hash{0x01}, Value: common.Hash{0x02}}}; !reflect.DeepEqual(acc.Storage, want) {
+		t.Errorf("second storage diff mismatch: have %v, want %v", acc.Storage, want)
+	}
+}
@@ -231,6 +231,7 @@ func makeHeader(chain consensus.ChainReader, parent *types.Block, state *state.S
 	} else {
 		time = new(big.Int).Add(parent.Time(), big.NewInt(10)) // block time is fixed at 10 seconds
 	}
+	number := new(big.Int).Add(parent.Number(), common.Big1)
 
 	return &types.Header{
 		Root:       state.IntermediateRoot(chain.Config().IsEIP158(parent.Number())),
@@ -242,8 +243,8 @@ func makeHeader(chain consensus.ChainReader, parent *types.Block, state *state.S
 			Difficulty: parent.Difficulty(),
 			UncleHash:  parent.UncleHash(),
 		}),
-		GasLimit: CalcGasLimit(parent, parent.GasLimit(), parent.GasLimit()),
-		Number:   new(big.Int).Add(parent.Number(), common.Big1),
+		GasLimit: CapGasLimit(chain.Config(), number, CalcGasLimit(parent, parent.GasLimit(), parent.GasLimit())),
+		Number:   number,
 		Time:     time,
 	}
 }
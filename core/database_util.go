@@ -25,6 +25,7 @@ import (
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/log"
@@ -668,12 +669,9 @@ func WritePrivateBlockBloom(db ethdb.Database, number uint64, receipts types.Rec
 }
 
 // GetPrivateBlockBloom retrieves the private bloom associated with the given number.
-func GetPrivateBlockBloom(db ethdb.Database, number uint64) (bloom types.Bloom) {
-	data, _ := db.Get(append(privateBloomPrefix, encodeBlockNumber(number)...))
-	if len(data) > 0 {
-		bloom = types.BytesToBloom(data)
-	}
-	return bloom
+// Blooms of blocks moved to the ancient store are read from there.
+func GetPrivateBlockBloom(db ethdb.Database, number uint64) types.Bloom {
+	return rawdb.ReadPrivateBloom(db, number)
 }
 
 
@@ -26,6 +26,10 @@ var (
 	// by a transaction is higher than what's left in the block.
 	ErrGasLimitReached = errors.New("gas limit reached")
 
+	// ErrTxGasLimit is returned if the gas of a transaction is higher than the
+	// Quorum transaction gas limit in effect.
+	ErrTxGasLimit = errors.New("exceeds transaction gas limit")
+
 	// ErrBlacklistedHash is returned if a block to import is on the blacklist.
 	ErrBlacklistedHash = errors.New("blacklisted hash")
 
@@ -18,6 +18,7 @@ package core
 
 import (
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
 )
 
@@ -49,3 +50,50 @@ type ChainSideEvent struct {
 }
 
 type ChainHeadEvent struct{ Block *types.Block }
+
+// StateDiffEvent is posted when a block is written with state diffs recorded.
+type StateDiffEvent struct {
+	Block   *types.Block
+	Public  *state.StateDiff
+	Private *state.StateDiff
+}
+
+// TxChangeType is the kind of change a transaction went through in the pool.
+type TxChangeType uint
+
+const (
+	TxAdded    TxChangeType = iota // Accepted into the pool, queued until executable
+	TxPromoted                     // Moved into the executable pending set
+	TxReplaced                     // Superseded by a transaction with the same nonce, the reason holds its hash
+	TxDropped                      // Removed from the pool without being included, the reason tells why
+	TxIncluded                     // Removed from the pool as it was included in a block
+)
+
+func (t TxChangeType) String() string {
+	switch t {
+	case TxAdded:
+		return "added"
+	case TxPromoted:
+		return "promoted"
+	case TxReplaced:
+		return "replaced"
+	case TxDropped:
+		return "dropped"
+	case TxIncluded:
+		return "included"
+	default:
+		return "unknown"
+	}
+}
+
+// TxChange is a single lifecycle change of a pooled transaction.
+type TxChange struct {
+	Type   TxChangeType
+	Tx     *types.Transaction
+	From   common.Address
+	Reason string
+}
+
+// TxLifecycleEvent is posted when transactions change state in the pool. The
+// changes are listed, and the events delivered, in the order they happened.
+type TxLifecycleEvent struct{ Changes []TxChange }
@@ -209,7 +209,16 @@ func SetupGenesisBlock(db ethdb.Database, genesis *Genesis) (*params.ChainConfig
 	if height == nil {
 		return newcfg, stored, fmt.Errorf("missing block number for head header hash")
 	}
+	if newcfg.Istanbul != nil {
+		if err := newcfg.Istanbul.Validate(); err != nil {
+			return newcfg, stored, err
+		}
+	}
 	compatErr := storedcfg.CheckCompatible(newcfg, *height, GetIsQuorumEIP155Activated(db))
+	if compatErr != nil && *height != 0 && compatErr.What == params.IstanbulProposerSelection {
+		// Quorum: proposer selection can't be rewound to, refuse to fork the chain
+		return newcfg, stored, fmt.Errorf("cannot change %s of an initialised chain: %v", compatErr.What, compatErr)
+	}
 	if compatErr != nil && *height != 0 && compatErr.RewindTo != 0 {
 		return newcfg, stored, compatErr
 	}
@@ -479,6 +479,13 @@ func (hc *HeaderChain) SetHead(head uint64, delFn DeleteCallback) {
 	}
 	batch.Write()
 
+	// Drop any frozen blocks above the new head, they are not canonical anymore
+	if ancient, ok := hc.chainDb.(rawdb.AncientTruncater); ok {
+		if err := ancient.TruncateAncients(head + 1); err != nil {
+			log.Error("Failed to truncate ancient blocks", "head", head, "err", err)
+		}
+	}
+
 	// Clear out any stale content from the caches
 	hc.headerCache.Purge()
 	hc.tdCache.Purge()
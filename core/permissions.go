@@ -0,0 +1,86 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var (
+	// ErrTransactNotPermitted is returned if the sender of a transaction only has
+	// read-only access to the ledger.
+	ErrTransactNotPermitted = errors.New("account is not permitted to transact")
+
+	// ErrContractDeployNotPermitted is returned if the sender of a contract
+	// creation transaction is not permitted to deploy contracts.
+	ErrContractDeployNotPermitted = errors.New("account is not permitted to deploy contracts")
+)
+
+// GetAccountAccess returns the access level of an account under the account
+// permissions of the chain. The permissions contract, if configured, is read
+// from the given state, at the access mapping slot declared in the config.
+func GetAccountAccess(config *params.ChainConfig, statedb *state.StateDB, addr common.Address) params.AccountAccess {
+	perms := config.AccountPermissions
+	if perms == nil {
+		return params.AccountAccessAdmin
+	}
+	if perms.Contract != nil && perms.AccessSlot != nil {
+		// Solidity stores mapping values at keccak256(key . slot)
+		slot := common.BigToHash(new(big.Int).SetUint64(*perms.AccessSlot))
+		key := crypto.Keccak256Hash(common.LeftPadBytes(addr.Bytes(), common.HashLength), slot.Bytes())
+		value := statedb.GetState(*perms.Contract, key)
+		if access := params.AccountAccess(value[common.HashLength-1]); access != params.AccountAccessUnset && access <= params.AccountAccessAdmin {
+			return access
+		}
+	}
+	if access := perms.Accounts[addr]; access != params.AccountAccessUnset {
+		return access
+	}
+	if perms.DefaultAccess != params.AccountAccessUnset {
+		return perms.DefaultAccess
+	}
+	return params.AccountAccessReadOnly
+}
+
+// checkAccountPermission verifies that the sender is allowed to send a
+// transaction to the given recipient in block number, nil meaning a contract
+// creation.
+func checkAccountPermission(config *params.ChainConfig, statedb *state.StateDB, number *big.Int, from common.Address, to *common.Address) error {
+	if !config.IsAccountPermissions(number) {
+		return nil
+	}
+	return checkAccountAccess(config, statedb, from, to)
+}
+
+// checkAccountAccess verifies the access of the sender regardless of whether
+// account permissions are enforced yet.
+func checkAccountAccess(config *params.ChainConfig, statedb *state.StateDB, from common.Address, to *common.Address) error {
+	access := GetAccountAccess(config, statedb, from)
+	if access < params.AccountAccessTransact {
+		return ErrTransactNotPermitted
+	}
+	if to == nil && access < params.AccountAccessContractDeploy {
+		return ErrContractDeployNotPermitted
+	}
+	return nil
+}
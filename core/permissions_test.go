@@ -0,0 +1,234 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var (
+	permissionsContract = common.HexToAddress("0x0000000000000000000000000000000000000020")
+	permissionsSlot     = uint64(1)
+)
+
+// setContractAccess stores the access of an account in the permissions contract
+// the same way the Solidity mapping at permissionsSlot does.
+func setContractAccess(statedb *state.StateDB, addr common.Address, access params.AccountAccess) {
+	key := crypto.Keccak256Hash(common.LeftPadBytes(addr.Bytes(), common.HashLength), common.BigToHash(new(big.Int).SetUint64(permissionsSlot)).Bytes())
+	statedb.SetState(permissionsContract, key, common.BigToHash(big.NewInt(int64(access))))
+}
+
+func permissionedChainConfig(perms *params.AccountPermissionsConfig) *params.ChainConfig {
+	config := *params.QuorumTestChainConfig
+	config.AccountPermissionsBlock = big.NewInt(0)
+	config.AccountPermissions = perms
+	return &config
+}
+
+func TestGetAccountAccess(t *testing.T) {
+	var (
+		listed   = common.HexToAddress("0x01")
+		inBoth   = common.HexToAddress("0x02")
+		unlisted = common.HexToAddress("0x03")
+	)
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	setContractAccess(statedb, inBoth, params.AccountAccessAdmin)
+
+	tests := []struct {
+		perms *params.AccountPermissionsConfig
+		addr  common.Address
+		want  params.AccountAccess
+	}{
+		// Unrestricted chains grant everything
+		{nil, unlisted, params.AccountAccessAdmin},
+		// Unlisted accounts default to read-only
+		{&params.AccountPermissionsConfig{}, unlisted, params.AccountAccessReadOnly},
+		{&params.AccountPermissionsConfig{DefaultAccess: params.AccountAccessTransact}, unlisted, params.AccountAccessTransact},
+		// Listed accounts override the default
+		{
+			&params.AccountPermissionsConfig{
+				DefaultAccess: params.AccountAccessTransact,
+				Accounts:      map[common.Address]params.AccountAccess{listed: params.AccountAccessReadOnly},
+			},
+			listed, params.AccountAccessReadOnly,
+		},
+		// The contract overrides the listed accounts, but only if it has an entry
+		{
+			&params.AccountPermissionsConfig{
+				Accounts:   map[common.Address]params.AccountAccess{listed: params.AccountAccessContractDeploy, inBoth: params.AccountAccessReadOnly},
+				Contract:   &permissionsContract,
+				AccessSlot: &permissionsSlot,
+			},
+			inBoth, params.AccountAccessAdmin,
+		},
+		{
+			&params.AccountPermissionsConfig{
+				Accounts:   map[common.Address]params.AccountAccess{listed: params.AccountAccessContractDeploy, inBoth: params.AccountAccessReadOnly},
+				Contract:   &permissionsContract,
+				AccessSlot: &permissionsSlot,
+			},
+			listed, params.AccountAccessContractDeploy,
+		},
+	}
+	for i, tt := range tests {
+		config := permissionedChainConfig(tt.perms)
+		if have := GetAccountAccess(config, statedb, tt.addr); have != tt.want {
+			t.Errorf("test %d: access mismatch: have %v, want %v", i, have, tt.want)
+		}
+	}
+}
+
+func TestTxPoolAccountPermissions(t *testing.T) {
+	var (
+		readonly, _ = crypto.GenerateKey()
+		transact, _ = crypto.GenerateKey()
+		deployer, _ = crypto.GenerateKey()
+	)
+	config := permissionedChainConfig(&params.AccountPermissionsConfig{
+		Accounts: map[common.Address]params.AccountAccess{
+			crypto.PubkeyToAddress(transact.PublicKey): params.AccountAccessTransact,
+			crypto.PubkeyToAddress(deployer.PublicKey): params.AccountAccessContractDeploy,
+		},
+		Contract:   &permissionsContract,
+		AccessSlot: &permissionsSlot,
+	})
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	blockchain := &testBlockChain{statedb, statedb, 1000000, new(event.Feed)}
+
+	pool := NewTxPool(testTxPoolConfig, config, blockchain)
+	defer pool.Stop()
+
+	call := func(nonce uint64, key *ecdsa.PrivateKey) *types.Transaction {
+		tx, _ := types.SignTx(types.NewTransaction(nonce, common.Address{}, common.Big0, 100000, common.Big0, nil), types.HomesteadSigner{}, key)
+		return tx
+	}
+	create := func(nonce uint64, key *ecdsa.PrivateKey) *types.Transaction {
+		tx, _ := types.SignTx(types.NewContractCreation(nonce, common.Big0, 100000, common.Big0, []byte{0x00}), types.HomesteadSigner{}, key)
+		return tx
+	}
+	tests := []struct {
+		tx   *types.Transaction
+		want error
+	}{
+		{call(0, readonly), ErrTransactNotPermitted},
+		{create(0, readonly), ErrTransactNotPermitted},
+		{call(0, transact), nil},
+		{create(1, transact), ErrContractDeployNotPermitted},
+		{call(0, deployer), nil},
+		{create(1, deployer), nil},
+		{call(2, deployer), nil},
+		{call(5, transact), nil},
+	}
+	for i, tt := range tests {
+		if err := pool.AddRemote(tt.tx); err != tt.want {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.want)
+		}
+	}
+	if pending, queued := pool.Stats(); pending != 4 || queued != 1 {
+		t.Fatalf("pool stats mismatch: have %d pending %d queued, want 4 pending 1 queued", pending, queued)
+	}
+	// Downgrading the deployer through the contract must drop its contract
+	// creation and queue back the later transactions
+	setContractAccess(statedb, crypto.PubkeyToAddress(deployer.PublicKey), params.AccountAccessTransact)
+	pool.lockedReset(nil, nil)
+
+	if pending, queued := pool.Stats(); pending != 2 || queued != 2 {
+		t.Errorf("pool stats mismatch: have %d pending %d queued, want 2 pending 2 queued", pending, queued)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+	// Revoking the transacting account must drop its queued transactions too
+	setContractAccess(statedb, crypto.PubkeyToAddress(transact.PublicKey), params.AccountAccessReadOnly)
+	pool.lockedReset(nil, nil)
+	pool.mu.Lock()
+	pool.promoteExecutables(nil)
+	pool.mu.Unlock()
+
+	if pending, queued := pool.Stats(); pending != 1 || queued != 1 {
+		t.Errorf("pool stats mismatch: have %d pending %d queued, want 1 pending 1 queued", pending, queued)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+func TestTxPoolAccountPermissionsActivation(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	config := permissionedChainConfig(&params.AccountPermissionsConfig{})
+	config.AccountPermissionsBlock = big.NewInt(2)
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	blockchain := &testBlockChain{statedb, statedb, 1000000, new(event.Feed)}
+
+	pool := NewTxPool(testTxPoolConfig, config, blockchain)
+	defer pool.Stop()
+
+	// The pool builds on the genesis block, so the next one is still unrestricted
+	tx, _ := types.SignTx(types.NewTransaction(0, common.Address{}, common.Big0, 100000, common.Big0, nil), types.HomesteadSigner{}, key)
+	if err := pool.AddRemote(tx); err != nil {
+		t.Fatalf("transaction rejected before activation: %v", err)
+	}
+}
+
+func TestApplyTransactionAccountPermissions(t *testing.T) {
+	var (
+		allowed, _ = crypto.GenerateKey()
+		denied, _  = crypto.GenerateKey()
+		author     = common.HexToAddress("0xaa")
+	)
+	config := permissionedChainConfig(&params.AccountPermissionsConfig{
+		Accounts: map[common.Address]params.AccountAccess{
+			crypto.PubkeyToAddress(allowed.PublicKey): params.AccountAccessTransact,
+		},
+	})
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	header := &types.Header{Number: big.NewInt(1), GasLimit: 1000000, Difficulty: common.Big1, Time: common.Big0}
+
+	tests := []struct {
+		key  *ecdsa.PrivateKey
+		want error
+	}{
+		{allowed, nil},
+		{denied, ErrTransactNotPermitted},
+	}
+	for i, tt := range tests {
+		tx, _ := types.SignTx(types.NewTransaction(0, common.Address{}, common.Big0, 100000, common.Big0, nil), types.HomesteadSigner{}, tt.key)
+		gp := new(GasPool).AddGas(header.GasLimit)
+		if _, _, _, err := ApplyTransaction(config, nil, &author, gp, statedb, statedb, header, tx, new(uint64), vm.Config{}); err != tt.want {
+			t.Errorf("test %d: error mismatch: have %v, want %v", i, err, tt.want)
+		}
+	}
+	// Blocks before the activation block are not restricted
+	config.AccountPermissionsBlock = big.NewInt(2)
+	tx, _ := types.SignTx(types.NewTransaction(0, common.Address{}, common.Big0, 100000, common.Big0, nil), types.HomesteadSigner{}, denied)
+	gp := new(GasPool).AddGas(header.GasLimit)
+	if _, _, _, err := ApplyTransaction(config, nil, &author, gp, statedb, statedb, header, tx, new(uint64), vm.Config{}); err != nil {
+		t.Errorf("transaction rejected before activation: %v", err)
+	}
+}
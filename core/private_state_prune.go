@@ -0,0 +1,242 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/hashicorp/golang-lru"
+)
+
+const (
+	// pruneSeenLimit is the number of shallow trie nodes remembered while marking
+	// states, so that sub-tries shared between consecutive states are only
+	// walked once.
+	pruneSeenLimit = 1 << 18
+
+	// pruneSeenDepth is the maximum path length, in nibbles, of the nodes
+	// remembered while marking states. Deeper nodes are too numerous to be worth
+	// caching and cheap to walk again.
+	pruneSeenDepth = 4
+)
+
+// PrunePrivateState removes the private state of historical blocks from the
+// database, keeping the private state of the most recent retention blocks of
+// the canonical chain. Only trie nodes and contract code that are no longer
+// reachable from any retained private state or from any public state are
+// deleted, since both tries share the same key space.
+//
+// The reachable entries are recorded in two bloom filters sharing cache
+// megabytes of memory, after which the database is iterated and the stale
+// entries deleted. A false positive in the filter of live entries merely keeps
+// a stale one around. A false positive in the filter of stale entries may only
+// delete a node that no canonical state references, such as a side chain's.
+//
+// The database must not be in use by a running node. The number of deleted
+// entries is returned.
+func PrunePrivateState(db ethdb.Database, retention uint64, cache int) (int, error) {
+	hash := rawdb.ReadHeadBlockHash(db)
+	if hash == (common.Hash{}) {
+		return 0, errors.New("empty database")
+	}
+	number := rawdb.ReadHeaderNumber(db, hash)
+	if number == nil {
+		return 0, fmt.Errorf("head block %x missing", hash)
+	}
+	if cache < 2 {
+		cache = 2
+	}
+	triedb := trie.NewDatabase(db)
+	keep := newStateMarker(triedb, uint64(cache/2)*1024*1024)
+	stale := newStateMarker(triedb, uint64(cache/2)*1024*1024)
+
+	for n := uint64(0); n <= *number; n++ {
+		header := rawdb.ReadHeader(db, rawdb.ReadCanonicalHash(db, n), n)
+		if header == nil {
+			return 0, fmt.Errorf("canonical header #%d missing", n)
+		}
+		// Everything reachable from a public state stays, whatever its age
+		if err := keep.markState(header.Root); err != nil {
+			return 0, fmt.Errorf("public state of block #%d: %v", n, err)
+		}
+		root := GetPrivateStateRoot(db, header.Root)
+		if n+retention > *number {
+			if err := keep.markState(root); err != nil {
+				return 0, fmt.Errorf("private state of block #%d: %v", n, err)
+			}
+			continue
+		}
+		// Historical private states may already be partially pruned
+		if err := stale.markState(root); err != nil {
+			log.Debug("Incomplete historical private state", "number", n, "root", root, "err", err)
+		}
+		if n%10000 == 0 {
+			log.Info("Scanning private state", "number", n, "head", *number)
+		}
+	}
+	var (
+		it      = db.NewIteratorWithPrefix(nil)
+		batch   = db.NewBatch()
+		deleted int
+	)
+	defer it.Release()
+
+	for it.Next() {
+		key := it.Key()
+		if len(key) != common.HashLength {
+			continue
+		}
+		hash := common.BytesToHash(key)
+		if !stale.bloom.contains(hash) || keep.bloom.contains(hash) {
+			continue
+		}
+		if err := batch.Delete(hash[:]); err != nil {
+			return deleted, err
+		}
+		deleted++
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return deleted, err
+			}
+			batch.Reset()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return deleted, err
+	}
+	if err := batch.Write(); err != nil {
+		return deleted, err
+	}
+	return deleted, nil
+}
+
+// stateBloom is a bloom filter of trie node and contract code hashes. As the
+// hashes are uniformly distributed, the filter's four hash functions are simply
+// the four words of the hash.
+type stateBloom []uint64
+
+// newStateBloom creates a bloom filter occupying size bytes.
+func newStateBloom(size uint64) stateBloom {
+	if size < 8 {
+		size = 8
+	}
+	return make(stateBloom, size/8)
+}
+
+// add inserts a hash into the filter.
+func (b stateBloom) add(hash common.Hash) {
+	bits := uint64(len(b)) * 64
+	for i := 0; i < common.HashLength; i += 8 {
+		bit := binary.BigEndian.Uint64(hash[i:]) % bits
+		b[bit/64] |= 1 << (bit % 64)
+	}
+}
+
+// contains reports whether the hash might have been inserted into the filter.
+func (b stateBloom) contains(hash common.Hash) bool {
+	bits := uint64(len(b)) * 64
+	for i := 0; i < common.HashLength; i += 8 {
+		bit := binary.BigEndian.Uint64(hash[i:]) % bits
+		if b[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// stateMarker records the trie nodes and contract code reachable from a series
+// of state roots in a bloom filter.
+type stateMarker struct {
+	db    *trie.Database
+	bloom stateBloom
+	seen  *lru.Cache // Shallow nodes whose sub-trie has already been marked
+}
+
+// newStateMarker creates a marker whose bloom filter occupies size bytes.
+func newStateMarker(db *trie.Database, size uint64) *stateMarker {
+	seen, _ := lru.New(pruneSeenLimit)
+	return &stateMarker{db: db, bloom: newStateBloom(size), seen: seen}
+}
+
+// markState marks all the trie nodes and contract code reachable from a state
+// root. A state whose root is not in the database is ignored.
+func (m *stateMarker) markState(root common.Hash) error {
+	if m.seen.Contains(root) {
+		return nil
+	}
+	if _, err := m.db.Node(root); err != nil && root != types.EmptyRootHash {
+		return nil
+	}
+	emptyCode := crypto.Keccak256Hash(nil)
+	return m.markTrie(root, func(blob []byte) error {
+		var account state.Account
+		if err := rlp.Decode(bytes.NewReader(blob), &account); err != nil {
+			return err
+		}
+		if codeHash := common.BytesToHash(account.CodeHash); codeHash != emptyCode {
+			m.bloom.add(codeHash)
+		}
+		return m.markTrie(account.Root, nil)
+	})
+}
+
+// markTrie marks all the nodes of a trie, calling onLeaf for every leaf that is
+// not under an already marked shallow node.
+func (m *stateMarker) markTrie(root common.Hash, onLeaf func([]byte) error) error {
+	t, err := trie.New(root, m.db)
+	if err != nil {
+		return err
+	}
+	it, descend := t.NodeIterator(nil), true
+	for it.Next(descend) {
+		descend = true
+		if it.Leaf() {
+			if onLeaf != nil {
+				if err := onLeaf(it.LeafBlob()); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		hash := it.Hash()
+		if hash == (common.Hash{}) {
+			// Embedded node, stored as part of its parent
+			continue
+		}
+		if len(it.Path()) <= pruneSeenDepth {
+			if m.seen.Contains(hash) {
+				descend = false
+				continue
+			}
+			m.seen.Add(hash, struct{}{})
+		}
+		m.bloom.add(hash)
+	}
+	return it.Error()
+}
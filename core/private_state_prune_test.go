@@ -0,0 +1,148 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that private state tries are garbage collected once they fall out of
+// the retention window, unless the private state is archived.
+func TestPrivateStateGarbageCollection(t *testing.T) {
+	for _, retention := range []uint64{0, triesInMemory} {
+		db := ethdb.NewMemDatabase()
+		new(Genesis).MustCommit(db)
+
+		cacheConfig := &CacheConfig{TrieNodeLimit: 256, TrieTimeLimit: 5 * time.Minute, PrivateRetention: retention}
+		chain, err := NewBlockChain(db, cacheConfig, params.TestChainConfig, ethash.NewFaker(), vm.Config{}, nil)
+		if err != nil {
+			t.Fatalf("failed to create chain: %v", err)
+		}
+		var (
+			blocks = 2 * triesInMemory
+			roots  []common.Hash
+			root   common.Hash
+		)
+		for i := 1; i <= blocks; i++ {
+			statedb, _ := state.New(root, chain.privateStateCache)
+			statedb.SetState(common.Address{1}, common.BigToHash(big.NewInt(int64(i))), common.Hash{1})
+			root, _ = statedb.Commit(false)
+
+			block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(int64(i))})
+			if err := chain.writePrivateState(block, root, nil); err != nil {
+				t.Fatalf("block %d: failed to write private state: %v", i, err)
+			}
+			roots = append(roots, root)
+		}
+		for i, root := range roots {
+			number := uint64(i + 1)
+			_, err := chain.privateStateCache.TrieDB().Node(root)
+
+			want := retention == 0 || number > uint64(blocks)-retention
+			if have := err == nil; have != want {
+				t.Errorf("retention %d, block %d: private state available mismatch: have %v, want %v", retention, number, have, want)
+			}
+			if retention == 0 {
+				if ok, _ := db.Has(root[:]); !ok {
+					t.Errorf("block %d: archived private state missing from disk", number)
+				}
+			}
+		}
+		chain.Stop()
+	}
+}
+
+// Tests that pruning removes historical private states, but keeps recent ones
+// along with everything still referenced by a public state.
+func TestPrunePrivateState(t *testing.T) {
+	var (
+		db      = ethdb.NewMemDatabase()
+		statedb = state.NewDatabase(db)
+		code    = []byte{0x60, 0x00, 0x60, 0x00, 0xf3}
+		parent  common.Hash
+		private []common.Hash
+		public  []common.Hash
+	)
+	for i := int64(0); i < 5; i++ {
+		pub, _ := state.New(common.Hash{}, statedb)
+		pub.SetBalance(common.Address{2}, big.NewInt(i))
+		pub.SetCode(common.Address{2}, code)
+		pubRoot, _ := pub.Commit(false)
+
+		// The old private states share contract code with the public ones
+		priv, _ := state.New(common.Hash{}, statedb)
+		priv.SetState(common.Address{1}, common.BigToHash(big.NewInt(i)), common.Hash{1})
+		if i < 2 {
+			priv.SetCode(common.Address{3}, code)
+		}
+		privRoot, _ := priv.Commit(false)
+
+		for _, root := range []common.Hash{pubRoot, privRoot} {
+			if err := statedb.TrieDB().Commit(root, false); err != nil {
+				t.Fatalf("failed to commit state: %v", err)
+			}
+		}
+		WritePrivateStateRoot(db, pubRoot, privRoot)
+
+		header := &types.Header{Number: big.NewInt(i), ParentHash: parent, Root: pubRoot}
+		rawdb.WriteHeader(db, header)
+		rawdb.WriteCanonicalHash(db, header.Hash(), uint64(i))
+		rawdb.WriteHeadBlockHash(db, header.Hash())
+		parent = header.Hash()
+
+		public = append(public, pubRoot)
+		private = append(private, privRoot)
+	}
+	deleted, err := PrunePrivateState(db, 2, 2)
+	if err != nil {
+		t.Fatalf("failed to prune private state: %v", err)
+	}
+	if deleted == 0 {
+		t.Fatalf("nothing pruned")
+	}
+	for i, root := range private {
+		ok, _ := db.Has(root[:])
+		if want := i >= 3; ok != want {
+			t.Errorf("block %d: private state present mismatch: have %v, want %v", i, ok, want)
+		}
+		if i >= 3 {
+			if _, err := state.New(root, state.NewDatabase(db)); err != nil {
+				t.Errorf("block %d: retained private state unusable: %v", i, err)
+			}
+		}
+	}
+	for i, root := range public {
+		if _, err := state.New(root, state.NewDatabase(db)); err != nil {
+			t.Errorf("block %d: public state unusable: %v", i, err)
+		}
+	}
+	if ok, _ := db.Has(crypto.Keccak256(code)); !ok {
+		t.Errorf("shared contract code pruned")
+	}
+}
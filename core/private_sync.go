@@ -0,0 +1,156 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// rebuildPrivateState builds the private state of a fast synced head block by
+// executing the private transactions of the canonical chain up to it against
+// an empty private state, using the payloads held by the local transaction
+// manager. The private state root and the private receipts of every block are
+// recorded as a full sync would, and the private state is retained like that of
+// imported blocks.
+//
+// Only the public state of the head block is available, so the transactions run
+// on top of it, with the nonce of the sender set to the one of the transaction.
+// Private contracts reading public contracts see their state as of the head
+// block rather than as of the block they were executed in.
+func (bc *BlockChain) rebuildPrivateState(head *types.Block) error {
+	var (
+		root   = GetPrivateStateRoot(bc.db, bc.genesisBlock.Root())
+		count  int
+		start  = time.Now()
+		logged = time.Now()
+	)
+	for number := uint64(1); number <= head.NumberU64(); number++ {
+		block := bc.GetBlockByNumber(number)
+		if block == nil {
+			return fmt.Errorf("missing block #%d", number)
+		}
+		privateState, err := state.New(root, bc.privateStateCache)
+		if err != nil {
+			return err
+		}
+		receipts, err := bc.applyPrivateTransactions(block, head.Root(), privateState)
+		if err != nil {
+			return err
+		}
+		if root, err = privateState.Commit(bc.chainConfig.IsEIP158(block.Number())); err != nil {
+			return err
+		}
+		if err := WritePrivateStateRoot(bc.db, block.Root(), root); err != nil {
+			return err
+		}
+		if err := bc.writePrivateState(block, root, nil); err != nil {
+			return err
+		}
+		if len(receipts) > 0 {
+			if err := bc.writePrivateReceipts(block, receipts); err != nil {
+				return err
+			}
+			count += len(receipts)
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Rebuilding private state", "number", number, "head", head.Number(), "txs", count, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	// Flush the private state of the head block, whose public state is on disk
+	if err := bc.privateStateCache.TrieDB().Commit(root, true); err != nil {
+		return err
+	}
+	log.Info("Rebuilt private state", "number", head.Number(), "hash", head.Hash(), "root", root, "txs", count, "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
+// applyPrivateTransactions executes the private transactions of a block on the
+// private state, on top of a throwaway copy of the public state with the given
+// root. It returns the private receipts, which lack the cumulative gas used.
+func (bc *BlockChain) applyPrivateTransactions(block *types.Block, publicRoot common.Hash, privateState *state.StateDB) (types.Receipts, error) {
+	var (
+		header   = block.Header()
+		signer   = types.MakeSigner(bc.chainConfig, header.Number)
+		receipts types.Receipts
+	)
+	for i, tx := range block.Transactions() {
+		if !tx.IsPrivate() {
+			continue
+		}
+		msg, err := tx.AsMessage(signer)
+		if err != nil {
+			return nil, err
+		}
+		statedb, err := state.New(publicRoot, bc.stateCache)
+		if err != nil {
+			return nil, err
+		}
+		statedb.SetNonce(msg.From(), msg.Nonce())
+		privateState.Prepare(tx.Hash(), block.Hash(), i)
+
+		vmenv := vm.NewEVM(NewEVMContext(msg, header, bc, nil), statedb, privateState, bc.chainConfig, bc.vmConfig)
+		_, gas, failed, err := ApplyMessage(vmenv, msg, new(GasPool).AddGas(msg.Gas()))
+		if err != nil {
+			return nil, fmt.Errorf("private transaction %x in block #%d: %v", tx.Hash(), block.Number(), err)
+		}
+		var root []byte
+		if bc.chainConfig.IsByzantium(header.Number) {
+			privateState.Finalise(true)
+		} else {
+			root = privateState.IntermediateRoot(bc.chainConfig.IsEIP158(header.Number)).Bytes()
+		}
+		receipt := types.NewReceipt(root, failed, 0)
+		receipt.TxHash = tx.Hash()
+		receipt.GasUsed = gas
+		if msg.To() == nil {
+			receipt.ContractAddress = crypto.CreateAddress(msg.From(), tx.Nonce())
+		}
+		receipt.Logs = privateState.GetLogs(tx.Hash())
+		receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
+
+		receipts = append(receipts, receipt)
+	}
+	return receipts, nil
+}
+
+// writePrivateReceipts replaces the fast synced public receipts of the private
+// transactions of a block with their private receipts.
+func (bc *BlockChain) writePrivateReceipts(block *types.Block, privateReceipts types.Receipts) error {
+	receipts := rawdb.ReadReceipts(bc.db, block.Hash(), block.NumberU64())
+	if len(receipts) != len(block.Transactions()) {
+		return fmt.Errorf("missing receipts of block #%d", block.Number())
+	}
+	cumulative := make(map[common.Hash]uint64)
+	for _, receipt := range receipts {
+		cumulative[receipt.TxHash] = receipt.CumulativeGasUsed
+	}
+	for _, receipt := range privateReceipts {
+		receipt.CumulativeGasUsed = cumulative[receipt.TxHash]
+	}
+	rawdb.WriteReceipts(bc.db, block.Hash(), block.NumberU64(), mergeReceipts(receipts, privateReceipts))
+	return WritePrivateBlockBloom(bc.db, block.NumberU64(), privateReceipts)
+}
@@ -28,8 +28,15 @@ import (
 )
 
 // ReadCanonicalHash retrieves the hash assigned to a canonical block number.
+// The key-value store takes precedence over the ancient store, so that a block
+// made canonical by a reorg isn't shadowed by the frozen one.
 func ReadCanonicalHash(db DatabaseReader, number uint64) common.Hash {
 	data, _ := db.Get(headerHashKey(number))
+	if len(data) == 0 {
+		if ancient, ok := db.(AncientReader); ok && number < ancient.Ancients() {
+			data, _ = ancient.Ancient(freezerHashTable, number)
+		}
+	}
 	if len(data) == 0 {
 		return common.Hash{}
 	}
@@ -128,12 +135,18 @@ func WriteFastTrieProgress(db DatabaseWriter, count uint64) {
 
 // ReadHeaderRLP retrieves a block header in its raw RLP database encoding.
 func ReadHeaderRLP(db DatabaseReader, hash common.Hash, number uint64) rlp.RawValue {
+	if data := readAncient(db, freezerHeaderTable, hash, number); len(data) != 0 {
+		return data
+	}
 	data, _ := db.Get(headerKey(number, hash))
 	return data
 }
 
 // HasHeader verifies the existence of a block header corresponding to the hash.
 func HasHeader(db DatabaseReader, hash common.Hash, number uint64) bool {
+	if frozenBlock(db, hash, number) != nil {
+		return true
+	}
 	if has, err := db.Has(headerKey(number, hash)); !has || err != nil {
 		return false
 	}
@@ -190,6 +203,9 @@ func DeleteHeader(db DatabaseDeleter, hash common.Hash, number uint64) {
 
 // ReadBodyRLP retrieves the block body (transactions and uncles) in RLP encoding.
 func ReadBodyRLP(db DatabaseReader, hash common.Hash, number uint64) rlp.RawValue {
+	if data := readAncient(db, freezerBodiesTable, hash, number); len(data) != 0 {
+		return data
+	}
 	data, _ := db.Get(blockBodyKey(number, hash))
 	return data
 }
@@ -203,6 +219,9 @@ func WriteBodyRLP(db DatabaseWriter, hash common.Hash, number uint64, rlp rlp.Ra
 
 // HasBody verifies the existence of a block body corresponding to the hash.
 func HasBody(db DatabaseReader, hash common.Hash, number uint64) bool {
+	if frozenBlock(db, hash, number) != nil {
+		return true
+	}
 	if has, err := db.Has(blockBodyKey(number, hash)); !has || err != nil {
 		return false
 	}
@@ -241,7 +260,10 @@ func DeleteBody(db DatabaseDeleter, hash common.Hash, number uint64) {
 
 // ReadTd retrieves a block's total difficulty corresponding to the hash.
 func ReadTd(db DatabaseReader, hash common.Hash, number uint64) *big.Int {
-	data, _ := db.Get(headerTDKey(number, hash))
+	data := readAncient(db, freezerDifficultyTable, hash, number)
+	if len(data) == 0 {
+		data, _ = db.Get(headerTDKey(number, hash))
+	}
 	if len(data) == 0 {
 		return nil
 	}
@@ -274,7 +296,10 @@ func DeleteTd(db DatabaseDeleter, hash common.Hash, number uint64) {
 // ReadReceipts retrieves all the transaction receipts belonging to a block.
 func ReadReceipts(db DatabaseReader, hash common.Hash, number uint64) types.Receipts {
 	// Retrieve the flattened receipt slice
-	data, _ := db.Get(blockReceiptsKey(number, hash))
+	data := readAncient(db, freezerReceiptTable, hash, number)
+	if len(data) == 0 {
+		data, _ = db.Get(blockReceiptsKey(number, hash))
+	}
 	if len(data) == 0 {
 		return nil
 	}
@@ -315,6 +340,42 @@ func DeleteReceipts(db DatabaseDeleter, hash common.Hash, number uint64) {
 	}
 }
 
+// ReadStateDiff retrieves the RLP encoded state diffs of a block.
+func ReadStateDiff(db DatabaseReader, hash common.Hash, number uint64) rlp.RawValue {
+	data, _ := db.Get(stateDiffKey(number, hash))
+	return data
+}
+
+// WriteStateDiff stores the RLP encoded state diffs of a block.
+func WriteStateDiff(db DatabaseWriter, hash common.Hash, number uint64, diff rlp.RawValue) {
+	if err := db.Put(stateDiffKey(number, hash), diff); err != nil {
+		log.Crit("Failed to store state diff", "err", err)
+	}
+}
+
+// DeleteStateDiff removes the state diffs of a block.
+func DeleteStateDiff(db DatabaseDeleter, hash common.Hash, number uint64) {
+	if err := db.Delete(stateDiffKey(number, hash)); err != nil {
+		log.Crit("Failed to delete state diff", "err", err)
+	}
+}
+
+// ReadPrivateBloom retrieves the bloom of the private receipts of the block with
+// the given number.
+func ReadPrivateBloom(db DatabaseReader, number uint64) types.Bloom {
+	var data []byte
+	if ancient, ok := db.(AncientReader); ok && number < ancient.Ancients() {
+		data, _ = ancient.Ancient(freezerPrivateBloomTable, number)
+	}
+	if len(data) == 0 {
+		data, _ = db.Get(privateBloomKey(number))
+	}
+	if len(data) == 0 {
+		return types.Bloom{}
+	}
+	return types.BytesToBloom(data)
+}
+
 // ReadBlock retrieves an entire block corresponding to the hash, assembling it
 // back from the stored header and body. If either the header or body could not
 // be retrieved nil is returned.
@@ -342,6 +403,7 @@ func WriteBlock(db DatabaseWriter, block *types.Block) {
 // DeleteBlock removes all block data associated with a hash.
 func DeleteBlock(db DatabaseDeleter, hash common.Hash, number uint64) {
 	DeleteReceipts(db, hash, number)
+	DeleteStateDiff(db, hash, number)
 	DeleteHeader(db, hash, number)
 	DeleteBody(db, hash, number)
 	DeleteTd(db, hash, number)
@@ -0,0 +1,497 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// freezerRecheckInterval is the frequency to check the key-value database for
+	// chain progression that might permit new blocks to be frozen into immutable
+	// storage.
+	freezerRecheckInterval = time.Minute
+
+	// freezerBatchLimit is the maximum number of blocks to freeze in one batch
+	// before doing an fsync and deleting it from the key-value store.
+	freezerBatchLimit = 30000
+)
+
+// freezer is an append-only database to store immutable chain data into flat
+// files. The append only nature ensures that disk writes are minimized, and the
+// flat files allow the data to be read without going through the key-value
+// store, so the LevelDB compactions don't have to shuffle it around.
+//
+// Every table holds one item per block, so all of them are kept at the same
+// length and the number of items is the number of frozen blocks.
+type freezer struct {
+	frozen    uint64 // Number of blocks already frozen (atomic access)
+	threshold uint64 // Number of recent blocks kept in the key-value store (0 = not freezing)
+
+	tables map[string]*freezerTable // Data tables for storing everything
+	lock   sync.Mutex               // Serializes freezing and truncation
+
+	quit chan struct{}
+	wg   sync.WaitGroup
+}
+
+// newFreezer opens the ancient store in the given directory, repairing any
+// inconsistency between its tables.
+func newFreezer(datadir string, threshold uint64) (*freezer, error) {
+	freezer := &freezer{
+		threshold: threshold,
+		tables:    make(map[string]*freezerTable),
+		quit:      make(chan struct{}),
+	}
+	for name, noCompression := range freezerNoSnappy {
+		table, err := newTable(datadir, name, noCompression)
+		if err != nil {
+			freezer.closeTables()
+			return nil, err
+		}
+		freezer.tables[name] = table
+	}
+	if err := freezer.repair(); err != nil {
+		freezer.closeTables()
+		return nil, err
+	}
+	log.Info("Opened ancient database", "database", datadir, "frozen", freezer.frozen)
+	return freezer, nil
+}
+
+// repair truncates all tables to the length of the shortest one, dropping the
+// blocks only partially written by a crashed freezing run.
+func (f *freezer) repair() error {
+	min := uint64(0)
+	for i, table := range f.tableList() {
+		if items := table.Items(); i == 0 || items < min {
+			min = items
+		}
+	}
+	for _, table := range f.tables {
+		if err := table.truncate(min); err != nil {
+			return err
+		}
+	}
+	atomic.StoreUint64(&f.frozen, min)
+	return nil
+}
+
+// tableList returns the tables of the freezer in a slice.
+func (f *freezer) tableList() []*freezerTable {
+	tables := make([]*freezerTable, 0, len(f.tables))
+	for _, table := range f.tables {
+		tables = append(tables, table)
+	}
+	return tables
+}
+
+// Close terminates the background freezing and closes all the data files.
+func (f *freezer) Close() error {
+	close(f.quit)
+	f.wg.Wait()
+
+	return f.closeTables()
+}
+
+func (f *freezer) closeTables() error {
+	var errs []error
+	for _, table := range f.tables {
+		if err := table.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
+
+// Ancient retrieves an ancient binary blob of the given kind from the freezer.
+func (f *freezer) Ancient(kind string, number uint64) ([]byte, error) {
+	if table := f.tables[kind]; table != nil {
+		return table.Retrieve(number)
+	}
+	return nil, fmt.Errorf("unknown ancient table %q", kind)
+}
+
+// Ancients returns the number of blocks in the freezer.
+func (f *freezer) Ancients() uint64 {
+	return atomic.LoadUint64(&f.frozen)
+}
+
+// TruncateAncients discards all but the first items blocks of the freezer.
+func (f *freezer) TruncateAncients(items uint64) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.Ancients() <= items {
+		return nil
+	}
+	// Shrink the visible range first, so no reader looks past the new end
+	atomic.StoreUint64(&f.frozen, items)
+	for _, table := range f.tables {
+		if err := table.truncate(items); err != nil {
+			return err
+		}
+	}
+	log.Warn("Truncated ancient database", "frozen", items)
+	return nil
+}
+
+// appendAncient appends the data of a canonical block to all the tables. On
+// failure the tables are rolled back to the previous block.
+func (f *freezer) appendAncient(number uint64, hash, header, body, receipts, td, privateBloom []byte) (err error) {
+	defer func() {
+		if err != nil {
+			for _, table := range f.tables {
+				table.truncate(number)
+			}
+		}
+	}()
+	blobs := map[string][]byte{
+		freezerHashTable:         hash,
+		freezerHeaderTable:       header,
+		freezerBodiesTable:       body,
+		freezerReceiptTable:      receipts,
+		freezerDifficultyTable:   td,
+		freezerPrivateBloomTable: privateBloom,
+	}
+	for kind, blob := range blobs {
+		if err := f.tables[kind].Append(number, blob); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sync flushes all the tables to disk.
+func (f *freezer) sync() error {
+	for _, table := range f.tables {
+		if err := table.Sync(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// freeze is a background loop that periodically moves the canonical blocks more
+// than threshold blocks behind the head from the key-value store into the
+// freezer.
+func (f *freezer) freeze(db ethdb.Database) {
+	defer f.wg.Done()
+
+	for {
+		frozen, err := f.freezeBatch(db)
+		if err != nil {
+			log.Error("Failed to freeze blocks", "err", err)
+		}
+		// Carry on immediately if there's still a backlog, wait otherwise
+		if err == nil && frozen == freezerBatchLimit {
+			select {
+			case <-f.quit:
+				return
+			default:
+				continue
+			}
+		}
+		select {
+		case <-time.After(freezerRecheckInterval):
+		case <-f.quit:
+			return
+		}
+	}
+}
+
+// freezeBatch moves up to freezerBatchLimit immutable blocks into the freezer
+// and deletes them from the key-value store, returning the number of blocks
+// frozen.
+func (f *freezer) freezeBatch(db ethdb.Database) (int, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	hash := ReadHeadBlockHash(db)
+	if hash == (common.Hash{}) {
+		return 0, nil
+	}
+	head := ReadHeaderNumber(db, hash)
+	if head == nil {
+		return 0, fmt.Errorf("missing head block number %x", hash)
+	}
+	if *head < f.threshold {
+		return 0, nil
+	}
+	var (
+		start  = time.Now()
+		first  = f.Ancients()
+		limit  = *head - f.threshold
+		hashes []common.Hash
+	)
+	if limit <= first {
+		return 0, nil
+	}
+	if limit-first > freezerBatchLimit {
+		limit = first + freezerBatchLimit
+	}
+	for number := first; number < limit; number++ {
+		hash := ReadCanonicalHash(db, number)
+		if hash == (common.Hash{}) {
+			return 0, fmt.Errorf("canonical hash missing, can't freeze block %d", number)
+		}
+		header := ReadHeaderRLP(db, hash, number)
+		if len(header) == 0 {
+			return 0, fmt.Errorf("block header missing, can't freeze block %d", number)
+		}
+		body := ReadBodyRLP(db, hash, number)
+		if len(body) == 0 {
+			return 0, fmt.Errorf("block body missing, can't freeze block %d", number)
+		}
+		receipts, _ := db.Get(blockReceiptsKey(number, hash))
+		if len(receipts) == 0 {
+			return 0, fmt.Errorf("block receipts missing, can't freeze block %d", number)
+		}
+		td, _ := db.Get(headerTDKey(number, hash))
+		if len(td) == 0 {
+			return 0, fmt.Errorf("total difficulty missing, can't freeze block %d", number)
+		}
+		// Blocks without private transactions have an empty private bloom
+		privateBloom, _ := db.Get(privateBloomKey(number))
+
+		if err := f.appendAncient(number, hash.Bytes(), header, body, receipts, td, privateBloom); err != nil {
+			return 0, err
+		}
+		hashes = append(hashes, hash)
+	}
+	if len(hashes) == 0 {
+		return 0, nil
+	}
+	if err := f.sync(); err != nil {
+		return 0, err
+	}
+	// The blocks are safely on disk, make them visible and wipe them from the
+	// key-value store, including any side chain stored at the same heights
+	atomic.StoreUint64(&f.frozen, limit)
+
+	batch := db.NewBatch()
+	for i, hash := range hashes {
+		number := first + uint64(i)
+		if err := deleteFrozenBlock(db, batch, hash, number); err != nil {
+			return 0, err
+		}
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return 0, err
+			}
+			batch.Reset()
+		}
+	}
+	if err := batch.Write(); err != nil {
+		return 0, err
+	}
+	log.Info("Moved blocks into ancient database", "blocks", len(hashes), "frozen", limit, "elapsed", common.PrettyDuration(time.Since(start)))
+	return len(hashes), nil
+}
+
+// deleteFrozenBlock removes the key-value data of all blocks at the height of a
+// frozen canonical block. The hash to number mapping of the canonical block is
+// kept, it is needed to look blocks up by hash.
+func deleteFrozenBlock(db ethdb.Database, batch ethdb.Batch, hash common.Hash, number uint64) error {
+	// Collect the headers and total difficulties of all the blocks at this height
+	prefix := append(headerPrefix, encodeBlockNumber(number)...)
+	it := db.NewIteratorWithPrefix(prefix)
+	for it.Next() {
+		key := it.Key()
+		switch {
+		case len(key) == len(prefix)+common.HashLength:
+			if side := common.BytesToHash(key[len(prefix):]); side != hash {
+				batch.Delete(headerNumberKey(side))
+			}
+			batch.Delete(common.CopyBytes(key))
+		case len(key) == len(prefix)+common.HashLength+len(headerTDSuffix) && bytes.HasSuffix(key, headerTDSuffix):
+			batch.Delete(common.CopyBytes(key))
+		}
+	}
+	it.Release()
+	if err := it.Error(); err != nil {
+		return err
+	}
+	batch.Delete(headerHashKey(number))
+	batch.Delete(privateBloomKey(number))
+
+	// Bodies and receipts share their key layout
+	for _, prefix := range [][]byte{blockBodyPrefix, blockReceiptsPrefix} {
+		prefix = append(prefix, encodeBlockNumber(number)...)
+
+		it := db.NewIteratorWithPrefix(prefix)
+		for it.Next() {
+			if len(it.Key()) == len(prefix)+common.HashLength {
+				batch.Delete(common.CopyBytes(it.Key()))
+			}
+		}
+		it.Release()
+		if err := it.Error(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// frozenBlock returns the ancient store of the database if it has one holding
+// the given canonical block, or nil otherwise.
+func frozenBlock(db DatabaseReader, hash common.Hash, number uint64) AncientReader {
+	ancient, ok := db.(AncientReader)
+	if !ok || number >= ancient.Ancients() {
+		return nil
+	}
+	if frozen, err := ancient.Ancient(freezerHashTable, number); err != nil || !bytes.Equal(frozen, hash.Bytes()) {
+		return nil
+	}
+	return ancient
+}
+
+// readAncient retrieves an item of a frozen canonical block from the ancient
+// store, or nil if the block is not frozen.
+func readAncient(db DatabaseReader, kind string, hash common.Hash, number uint64) []byte {
+	if ancient := frozenBlock(db, hash, number); ancient != nil {
+		data, _ := ancient.Ancient(kind, number)
+		return data
+	}
+	return nil
+}
+
+// freezerdb is a database wrapper that enables freezer data retrievals.
+type freezerdb struct {
+	ethdb.Database
+	*freezer
+}
+
+// NewDatabaseWithFreezer wraps a key-value database with an ancient store kept
+// in the given directory. The accessors of this package read the blocks frozen
+// in the ancient store from there transparently. No blocks are moved into it
+// until freezing is started with the Freeze method of the returned database.
+func NewDatabaseWithFreezer(db ethdb.Database, freezer string) (ethdb.Database, error) {
+	frdb, err := newFreezer(freezer, 0)
+	if err != nil {
+		return nil, err
+	}
+	// A rewind below the frozen blocks may have been cut short by a crash, drop
+	// the ancient blocks past the head as it would have
+	if hash := ReadHeadHeaderHash(db); hash != (common.Hash{}) {
+		if head := ReadHeaderNumber(db, hash); head != nil && *head+1 < frdb.Ancients() {
+			if err := frdb.TruncateAncients(*head + 1); err != nil {
+				frdb.Close()
+				return nil, err
+			}
+		}
+	}
+	return &freezerdb{
+		Database: db,
+		freezer:  frdb,
+	}, nil
+}
+
+// Freeze starts moving the canonical blocks more than threshold blocks behind
+// the head from the key-value store into the ancient store in the background.
+// The blocks are assumed to be final by then, a reorg reaching below them can't
+// be carried out.
+func (frdb *freezerdb) Freeze(threshold uint64) error {
+	if threshold == 0 {
+		return errors.New("zero freezer threshold")
+	}
+	f := frdb.freezer
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.threshold != 0 {
+		return errors.New("already freezing")
+	}
+	f.threshold = threshold
+	f.wg.Add(1)
+	go f.freeze(frdb.Database)
+
+	return nil
+}
+
+// CopyAncients writes the blocks frozen in the ancient store of src, if it has
+// one, into the key-value store of dst and returns the number of blocks copied.
+func CopyAncients(dst ethdb.Database, src ethdb.Database) (int, error) {
+	ancient, ok := src.(AncientReader)
+	if !ok {
+		return 0, nil
+	}
+	frozen := ancient.Ancients()
+
+	batch := dst.NewBatch()
+	for number := uint64(0); number < frozen; number++ {
+		blobs := make(map[string][]byte)
+		for kind := range freezerNoSnappy {
+			blob, err := ancient.Ancient(kind, number)
+			if err != nil {
+				return int(number), err
+			}
+			blobs[kind] = blob
+		}
+		hash := common.BytesToHash(blobs[freezerHashTable])
+
+		batch.Put(headerHashKey(number), hash.Bytes())
+		batch.Put(headerNumberKey(hash), encodeBlockNumber(number))
+		batch.Put(headerKey(number, hash), blobs[freezerHeaderTable])
+		batch.Put(blockBodyKey(number, hash), blobs[freezerBodiesTable])
+		batch.Put(blockReceiptsKey(number, hash), blobs[freezerReceiptTable])
+		batch.Put(headerTDKey(number, hash), blobs[freezerDifficultyTable])
+		if bloom := blobs[freezerPrivateBloomTable]; len(bloom) > 0 {
+			batch.Put(privateBloomKey(number), bloom)
+		}
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return int(number), err
+			}
+			batch.Reset()
+		}
+	}
+	if err := batch.Write(); err != nil {
+		return 0, err
+	}
+	return int(frozen), nil
+}
+
+// Close implements ethdb.Database, closing both the freezer and the key-value
+// store.
+func (frdb *freezerdb) Close() {
+	if err := frdb.freezer.Close(); err != nil {
+		log.Error("Failed to close ancient database", "err", err)
+	}
+	frdb.Database.Close()
+}
+
+// Meter forwards the metrics collection request to the key-value store, if it
+// supports it.
+func (frdb *freezerdb) Meter(prefix string) {
+	if db, ok := frdb.Database.(interface{ Meter(prefix string) }); ok {
+		db.Meter(prefix)
+	}
+}
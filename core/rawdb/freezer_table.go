@@ -0,0 +1,266 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/golang/snappy"
+)
+
+var (
+	// errOutOfBounds is returned if the item requested is not contained within
+	// the freezer table.
+	errOutOfBounds = errors.New("out of bounds")
+
+	// errClosed is returned if an operation attempts to read from or write to
+	// the freezer table after it has already been closed.
+	errClosed = errors.New("closed")
+)
+
+// indexEntrySize is the size of an index entry, the big endian end offset of
+// the item in the data file.
+const indexEntrySize = 8
+
+// freezerTable is an append-only table of items numbered from zero. The items
+// are stored back to back in a data file and an index file records where each
+// of them ends, so item n spans the data between the end offsets n-1 and n.
+type freezerTable struct {
+	noCompression bool // Whether the items are stored as is or snappy compressed
+	items         uint64
+	size          uint64 // Size of the data file, the end offset of the last item
+
+	index *os.File // File descriptor of the item end offsets
+	data  *os.File // File descriptor of the concatenated items
+
+	logger log.Logger
+	lock   sync.RWMutex // Mutex protecting the files and counters
+}
+
+// newTable opens a freezer table in the given directory, creating it if it
+// doesn't exist yet. A torn write of a previous run is repaired by dropping
+// the items whose data wasn't fully written.
+func newTable(path string, name string, noCompression bool) (*freezerTable, error) {
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return nil, err
+	}
+	ext := ".cdat"
+	if noCompression {
+		ext = ".rdat"
+	}
+	index, err := os.OpenFile(filepath.Join(path, name+".idx"), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.OpenFile(filepath.Join(path, name+ext), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		index.Close()
+		return nil, err
+	}
+	tab := &freezerTable{
+		noCompression: noCompression,
+		index:         index,
+		data:          data,
+		logger:        log.New("database", path, "table", name),
+	}
+	if err := tab.repair(); err != nil {
+		tab.Close()
+		return nil, err
+	}
+	return tab, nil
+}
+
+// repair cross checks the index and data files and truncates them to the last
+// item that was completely written.
+func (t *freezerTable) repair() error {
+	stat, err := t.index.Stat()
+	if err != nil {
+		return err
+	}
+	indexSize := stat.Size() - stat.Size()%indexEntrySize
+
+	if stat, err = t.data.Stat(); err != nil {
+		return err
+	}
+	dataSize := stat.Size()
+
+	// Drop the index entries pointing past the end of the data file
+	var end uint64
+	for ; indexSize > 0; indexSize -= indexEntrySize {
+		buf := make([]byte, indexEntrySize)
+		if _, err := t.index.ReadAt(buf, indexSize-indexEntrySize); err != nil {
+			return err
+		}
+		if end = binary.BigEndian.Uint64(buf); end <= uint64(dataSize) {
+			break
+		}
+	}
+	if indexSize == 0 {
+		end = 0
+	}
+	if err := t.index.Truncate(indexSize); err != nil {
+		return err
+	}
+	if uint64(dataSize) != end {
+		t.logger.Warn("Truncating dangling freezer data", "items", indexSize/indexEntrySize, "size", dataSize, "end", end)
+	}
+	if err := t.data.Truncate(int64(end)); err != nil {
+		return err
+	}
+	t.items, t.size = uint64(indexSize/indexEntrySize), end
+	return nil
+}
+
+// Items returns the number of items in the table.
+func (t *freezerTable) Items() uint64 {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return t.items
+}
+
+// Append injects a binary blob at the end of the table. The item number must
+// be the next one in the sequence.
+func (t *freezerTable) Append(item uint64, blob []byte) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.index == nil {
+		return errClosed
+	}
+	if item != t.items {
+		return fmt.Errorf("appending unexpected item: want %d, have %d", t.items, item)
+	}
+	if !t.noCompression {
+		blob = snappy.Encode(nil, blob)
+	}
+	// Write the data before the index, so a crash in between is repaired on open
+	if _, err := t.data.WriteAt(blob, int64(t.size)); err != nil {
+		return err
+	}
+	entry := make([]byte, indexEntrySize)
+	binary.BigEndian.PutUint64(entry, t.size+uint64(len(blob)))
+	if _, err := t.index.WriteAt(entry, int64(t.items*indexEntrySize)); err != nil {
+		return err
+	}
+	t.items++
+	t.size += uint64(len(blob))
+	return nil
+}
+
+// Retrieve looks up the data blob of the given item.
+func (t *freezerTable) Retrieve(item uint64) ([]byte, error) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	if t.index == nil {
+		return nil, errClosed
+	}
+	if item >= t.items {
+		return nil, errOutOfBounds
+	}
+	// Read the start and end offsets of the item
+	var start, end uint64
+	buf := make([]byte, 2*indexEntrySize)
+	if item == 0 {
+		if _, err := t.index.ReadAt(buf[indexEntrySize:], 0); err != nil {
+			return nil, err
+		}
+	} else if _, err := t.index.ReadAt(buf, int64((item-1)*indexEntrySize)); err != nil {
+		return nil, err
+	}
+	start, end = binary.BigEndian.Uint64(buf), binary.BigEndian.Uint64(buf[indexEntrySize:])
+
+	blob := make([]byte, end-start)
+	if _, err := t.data.ReadAt(blob, int64(start)); err != nil {
+		return nil, err
+	}
+	if t.noCompression {
+		return blob, nil
+	}
+	return snappy.Decode(nil, blob)
+}
+
+// truncate discards all items after the first n ones.
+func (t *freezerTable) truncate(items uint64) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.index == nil {
+		return errClosed
+	}
+	if t.items <= items {
+		return nil
+	}
+	var end uint64
+	if items > 0 {
+		buf := make([]byte, indexEntrySize)
+		if _, err := t.index.ReadAt(buf, int64((items-1)*indexEntrySize)); err != nil {
+			return err
+		}
+		end = binary.BigEndian.Uint64(buf)
+	}
+	if err := t.index.Truncate(int64(items * indexEntrySize)); err != nil {
+		return err
+	}
+	if err := t.data.Truncate(int64(end)); err != nil {
+		return err
+	}
+	t.items, t.size = items, end
+	return nil
+}
+
+// Sync flushes the data and index files to disk, data first.
+func (t *freezerTable) Sync() error {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	if t.index == nil {
+		return errClosed
+	}
+	if err := t.data.Sync(); err != nil {
+		return err
+	}
+	return t.index.Sync()
+}
+
+// Close closes the files of the table.
+func (t *freezerTable) Close() error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	var errs []error
+	if t.index != nil {
+		if err := t.index.Close(); err != nil {
+			errs = append(errs, err)
+		}
+		if err := t.data.Close(); err != nil {
+			errs = append(errs, err)
+		}
+		t.index, t.data = nil, nil
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
@@ -0,0 +1,262 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rawdb
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
+)
+
+// Tests that freezer table items survive a reopen, that truncation drops the
+// tail and that a torn write is repaired.
+func TestFreezerTable(t *testing.T) {
+	dir, err := ioutil.TempDir("", "freezer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, noCompression := range []bool{false, true} {
+		name := fmt.Sprintf("test-%v", noCompression)
+
+		table, err := newTable(dir, name, noCompression)
+		if err != nil {
+			t.Fatalf("failed to open table: %v", err)
+		}
+		for i := uint64(0); i < 10; i++ {
+			if err := table.Append(i, bytes.Repeat([]byte{byte(i)}, int(i)*10)); err != nil {
+				t.Fatalf("failed to append item %d: %v", i, err)
+			}
+		}
+		if err := table.Append(11, []byte{1}); err == nil {
+			t.Fatalf("appended out of order item")
+		}
+		table.Close()
+
+		// Reopen the table and check the items
+		if table, err = newTable(dir, name, noCompression); err != nil {
+			t.Fatalf("failed to reopen table: %v", err)
+		}
+		if items := table.Items(); items != 10 {
+			t.Fatalf("items mismatch: have %d, want %d", items, 10)
+		}
+		for i := uint64(0); i < 10; i++ {
+			blob, err := table.Retrieve(i)
+			if err != nil {
+				t.Fatalf("failed to retrieve item %d: %v", i, err)
+			}
+			if !bytes.Equal(blob, bytes.Repeat([]byte{byte(i)}, int(i)*10)) {
+				t.Fatalf("item %d mismatch: %x", i, blob)
+			}
+		}
+		if _, err := table.Retrieve(10); err != errOutOfBounds {
+			t.Fatalf("out of bounds retrieval error mismatch: have %v, want %v", err, errOutOfBounds)
+		}
+		// Truncate the table and chop off the last item's data on disk
+		if err := table.truncate(8); err != nil {
+			t.Fatalf("failed to truncate table: %v", err)
+		}
+		table.Close()
+
+		ext := ".cdat"
+		if noCompression {
+			ext = ".rdat"
+		}
+		path := filepath.Join(dir, name+ext)
+		stat, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Truncate(path, stat.Size()-1); err != nil {
+			t.Fatal(err)
+		}
+		if table, err = newTable(dir, name, noCompression); err != nil {
+			t.Fatalf("failed to reopen table: %v", err)
+		}
+		if items := table.Items(); items != 7 {
+			t.Fatalf("items mismatch after repair: have %d, want %d", items, 7)
+		}
+		if err := table.Append(7, []byte("new")); err != nil {
+			t.Fatalf("failed to append after repair: %v", err)
+		}
+		if blob, _ := table.Retrieve(7); string(blob) != "new" {
+			t.Fatalf("appended item mismatch: %q", blob)
+		}
+		table.Close()
+	}
+}
+
+// Tests that old canonical blocks are moved into the freezer, read back through
+// the accessors and removed from the key-value store along with side chains.
+func TestFreezeBlocks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "freezer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Write a chain of ten blocks and a side block at height three
+	db := ethdb.NewMemDatabase()
+
+	var blocks []*types.Block
+	for i := 0; i < 10; i++ {
+		header := &types.Header{Number: big.NewInt(int64(i)), Extra: []byte("test block")}
+		if i > 0 {
+			header.ParentHash = blocks[i-1].Hash()
+		}
+		block := types.NewBlockWithHeader(header)
+		blocks = append(blocks, block)
+
+		WriteBlock(db, block)
+		WriteTd(db, block.Hash(), block.NumberU64(), big.NewInt(int64(i+1)))
+		WriteReceipts(db, block.Hash(), block.NumberU64(), types.Receipts{{Status: types.ReceiptStatusSuccessful, CumulativeGasUsed: uint64(i)}})
+		WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+		db.Put(privateBloomKey(block.NumberU64()), types.BytesToBloom([]byte{byte(i)}).Bytes())
+	}
+	WriteHeadBlockHash(db, blocks[9].Hash())
+
+	side := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(3), Extra: []byte("side block")})
+	WriteBlock(db, side)
+	WriteTd(db, side.Hash(), 3, big.NewInt(3))
+
+	// Freeze everything more than four blocks behind the head
+	f, err := newFreezer(dir, 4)
+	if err != nil {
+		t.Fatalf("failed to open freezer: %v", err)
+	}
+	defer f.Close()
+
+	if n, err := f.freezeBatch(db); n != 5 || err != nil {
+		t.Fatalf("freeze mismatch: have %d/%v, want %d/nil", n, err, 5)
+	}
+	if frozen := f.Ancients(); frozen != 5 {
+		t.Fatalf("frozen blocks mismatch: have %d, want %d", frozen, 5)
+	}
+	frdb := &freezerdb{Database: db, freezer: f}
+
+	for i, block := range blocks {
+		hash, number := block.Hash(), block.NumberU64()
+		if have := ReadCanonicalHash(frdb, number); have != hash {
+			t.Errorf("block %d: canonical hash mismatch: have %x, want %x", i, have, hash)
+		}
+		if header := ReadHeader(frdb, hash, number); header == nil || header.Hash() != hash {
+			t.Errorf("block %d: header mismatch: %v", i, header)
+		}
+		if !HasHeader(frdb, hash, number) || !HasBody(frdb, hash, number) {
+			t.Errorf("block %d: header or body reported missing", i)
+		}
+		if entry := ReadBlock(frdb, hash, number); entry == nil || entry.Hash() != hash {
+			t.Errorf("block %d: block mismatch: %v", i, entry)
+		}
+		if td := ReadTd(frdb, hash, number); td == nil || td.Int64() != int64(i+1) {
+			t.Errorf("block %d: total difficulty mismatch: %v", i, td)
+		}
+		if receipts := ReadReceipts(frdb, hash, number); len(receipts) != 1 || receipts[0].CumulativeGasUsed != uint64(i) {
+			t.Errorf("block %d: receipts mismatch: %v", i, receipts)
+		}
+		if bloom := ReadPrivateBloom(frdb, number); bloom != types.BytesToBloom([]byte{byte(i)}) {
+			t.Errorf("block %d: private bloom mismatch", i)
+		}
+		// Frozen blocks must be gone from the key-value store, recent ones kept
+		if has, _ := db.Has(headerKey(number, hash)); has == (i < 5) {
+			t.Errorf("block %d: header in key-value store: %v", i, has)
+		}
+		if has, _ := db.Has(blockBodyKey(number, hash)); has == (i < 5) {
+			t.Errorf("block %d: body in key-value store: %v", i, has)
+		}
+		if ReadHeaderNumber(frdb, hash) == nil {
+			t.Errorf("block %d: hash to number mapping missing", i)
+		}
+	}
+	if ReadHeader(frdb, side.Hash(), 3) != nil || ReadHeaderNumber(frdb, side.Hash()) != nil {
+		t.Errorf("side block at frozen height retained")
+	}
+	// Frozen blocks can be copied back into a plain key-value store
+	copied := ethdb.NewMemDatabase()
+	if n, err := CopyAncients(copied, frdb); n != 5 || err != nil {
+		t.Fatalf("copy mismatch: have %d/%v, want %d/nil", n, err, 5)
+	}
+	for i, block := range blocks[:5] {
+		hash, number := block.Hash(), block.NumberU64()
+		if have := ReadCanonicalHash(copied, number); have != hash {
+			t.Errorf("copied block %d: canonical hash mismatch: have %x, want %x", i, have, hash)
+		}
+		if entry := ReadBlock(copied, hash, number); entry == nil || entry.Hash() != hash {
+			t.Errorf("copied block %d: block mismatch: %v", i, entry)
+		}
+		if receipts := ReadReceipts(copied, hash, number); len(receipts) != 1 || receipts[0].CumulativeGasUsed != uint64(i) {
+			t.Errorf("copied block %d: receipts mismatch: %v", i, receipts)
+		}
+		if bloom := ReadPrivateBloom(copied, number); bloom != types.BytesToBloom([]byte{byte(i)}) {
+			t.Errorf("copied block %d: private bloom mismatch", i)
+		}
+	}
+	// A canonical hash written by a reorg takes precedence over the frozen one
+	WriteCanonicalHash(db, side.Hash(), 3)
+	if have := ReadCanonicalHash(frdb, 3); have != side.Hash() {
+		t.Errorf("reorged canonical hash mismatch: have %x, want %x", have, side.Hash())
+	}
+	DeleteCanonicalHash(db, 3)
+	// Freezing again must not do anything until the chain progresses
+	if n, err := f.freezeBatch(db); n != 0 || err != nil {
+		t.Fatalf("refreeze mismatch: have %d/%v, want 0/nil", n, err)
+	}
+	// Rewinding below the frozen blocks drops them from the freezer
+	if err := frdb.TruncateAncients(3); err != nil {
+		t.Fatalf("failed to truncate freezer: %v", err)
+	}
+	if header := ReadHeader(frdb, blocks[2].Hash(), 2); header == nil {
+		t.Errorf("block below truncation lost")
+	}
+	if header := ReadHeader(frdb, blocks[3].Hash(), 3); header != nil {
+		t.Errorf("block above truncation retained")
+	}
+}
+
+// Tests that freezing only starts once requested, and only once.
+func TestFreezerStart(t *testing.T) {
+	dir, err := ioutil.TempDir("", "freezer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	db, err := NewDatabaseWithFreezer(ethdb.NewMemDatabase(), dir)
+	if err != nil {
+		t.Fatalf("failed to open freezer: %v", err)
+	}
+	defer db.Close()
+
+	freezer := db.(AncientFreezer)
+	if err := freezer.Freeze(0); err == nil {
+		t.Errorf("zero threshold accepted")
+	}
+	if err := freezer.Freeze(16); err != nil {
+		t.Fatalf("failed to start freezing: %v", err)
+	}
+	if err := freezer.Freeze(16); err == nil {
+		t.Errorf("freezing started twice")
+	}
+}
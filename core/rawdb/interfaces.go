@@ -31,3 +31,27 @@ type DatabaseWriter interface {
 type DatabaseDeleter interface {
 	Delete(key []byte) error
 }
+
+// AncientReader wraps the methods of a database with an ancient store holding
+// the immutable chain segments moved out of the key-value store.
+type AncientReader interface {
+	// Ancient retrieves an item of the given kind of a frozen block.
+	Ancient(kind string, number uint64) ([]byte, error)
+
+	// Ancients returns the number of frozen blocks, all of them below this number.
+	Ancients() uint64
+}
+
+// AncientFreezer wraps the Freeze method of a database with an ancient store.
+type AncientFreezer interface {
+	// Freeze starts moving blocks more than threshold blocks behind the head into
+	// the ancient store.
+	Freeze(threshold uint64) error
+}
+
+// AncientTruncater wraps the TruncateAncients method of a database with an
+// ancient store.
+type AncientTruncater interface {
+	// TruncateAncients discards all but the first items frozen blocks.
+	TruncateAncients(items uint64) error
+}
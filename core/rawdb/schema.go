@@ -53,6 +53,9 @@ var (
 	txLookupPrefix  = []byte("l") // txLookupPrefix + hash -> transaction/receipt lookup metadata
 	bloomBitsPrefix = []byte("B") // bloomBitsPrefix + bit (uint16 big endian) + section (uint64 big endian) + hash -> bloom bits
 
+	privateBloomPrefix = []byte("Pb") // privateBloomPrefix + num (uint64 big endian) -> bloom of the private receipts
+	stateDiffPrefix    = []byte("Sd") // stateDiffPrefix + num (uint64 big endian) + hash -> state diffs of the block
+
 	preimagePrefix = []byte("secure-key-")      // preimagePrefix + hash -> preimage
 	configPrefix   = []byte("ethereum-config-") // config prefix for the db
 
@@ -63,6 +66,40 @@ var (
 	preimageHitCounter = metrics.NewRegisteredCounter("db/preimage/hits", nil)
 )
 
+// The freezer tables holding the chain segments moved out of the key-value
+// store, one item per canonical block.
+const (
+	// freezerHashTable indicates the name of the freezer canonical hash table.
+	freezerHashTable = "hashes"
+
+	// freezerHeaderTable indicates the name of the freezer header table.
+	freezerHeaderTable = "headers"
+
+	// freezerBodiesTable indicates the name of the freezer block body table.
+	freezerBodiesTable = "bodies"
+
+	// freezerReceiptTable indicates the name of the freezer receipts table. The
+	// receipts of private transactions are stored merged with the public ones.
+	freezerReceiptTable = "receipts"
+
+	// freezerDifficultyTable indicates the name of the freezer total difficulty table.
+	freezerDifficultyTable = "diffs"
+
+	// freezerPrivateBloomTable indicates the name of the freezer private bloom table.
+	freezerPrivateBloomTable = "privateblooms"
+)
+
+// freezerNoSnappy configures whether compression is disabled for the ancient
+// tables. Hashes, difficulties and blooms are too short or too random to shrink.
+var freezerNoSnappy = map[string]bool{
+	freezerHashTable:         true,
+	freezerHeaderTable:       false,
+	freezerBodiesTable:       false,
+	freezerReceiptTable:      false,
+	freezerDifficultyTable:   true,
+	freezerPrivateBloomTable: true,
+}
+
 // TxLookupEntry is a positional metadata to help looking up the data content of
 // a transaction or receipt given only its hash.
 type TxLookupEntry struct {
@@ -108,6 +145,16 @@ func blockReceiptsKey(number uint64, hash common.Hash) []byte {
 	return append(append(blockReceiptsPrefix, encodeBlockNumber(number)...), hash.Bytes()...)
 }
 
+// privateBloomKey = privateBloomPrefix + num (uint64 big endian)
+func privateBloomKey(number uint64) []byte {
+	return append(privateBloomPrefix, encodeBlockNumber(number)...)
+}
+
+// stateDiffKey = stateDiffPrefix + num (uint64 big endian) + hash
+func stateDiffKey(number uint64, hash common.Hash) []byte {
+	return append(append(stateDiffPrefix, encodeBlockNumber(number)...), hash.Bytes()...)
+}
+
 // txLookupKey = txLookupPrefix + hash
 func txLookupKey(hash common.Hash) []byte {
 	return append(txLookupPrefix, hash.Bytes()...)
@@ -0,0 +1,140 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// AccessSet holds the accounts read and written on a state. It allows running
+// transactions speculatively on separate copies of the same state and telling
+// afterwards whether they would have seen each other's changes.
+type AccessSet struct {
+	Reads  map[common.Address]struct{} // Accounts loaded, whether they exist or not
+	Writes map[common.Address]struct{} // Accounts changed by finalised transactions
+}
+
+// NewAccessSet creates an empty access set.
+func NewAccessSet() *AccessSet {
+	return &AccessSet{
+		Reads:  make(map[common.Address]struct{}),
+		Writes: make(map[common.Address]struct{}),
+	}
+}
+
+// Add merges the accounts accessed in another set into this one.
+func (s *AccessSet) Add(other *AccessSet) {
+	for addr := range other.Reads {
+		s.Reads[addr] = struct{}{}
+	}
+	for addr := range other.Writes {
+		s.Writes[addr] = struct{}{}
+	}
+}
+
+// Conflicts reports whether any account accessed in this set was written in the
+// other one, in which case the accesses depend on the order they happen in.
+func (s *AccessSet) Conflicts(other *AccessSet) bool {
+	for addr := range s.Reads {
+		if _, ok := other.Writes[addr]; ok {
+			return true
+		}
+	}
+	for addr := range s.Writes {
+		if _, ok := other.Writes[addr]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// TrackAccesses starts recording the accounts read and written on the state into
+// the given set. Writes are recorded as the changes are finalised. A nil set
+// stops the tracking.
+func (self *StateDB) TrackAccesses(set *AccessSet) {
+	self.accesses = set
+}
+
+// trackRead records an account load if accesses are tracked.
+func (self *StateDB) trackRead(addr common.Address) {
+	if self.accesses != nil {
+		self.accesses.Reads[addr] = struct{}{}
+	}
+}
+
+// trackWrites records the accounts changed since the last finalisation if
+// accesses are tracked. Accounts created and deleted again, such as the touched
+// empty coinbase of a zero priced transaction, are left out as nothing changed.
+//
+// Note, this method must be called before the journal is cleared!
+func (self *StateDB) trackWrites() {
+	if self.accesses == nil {
+		return
+	}
+	created := make(map[common.Address]struct{})
+	for _, entry := range self.journal.entries {
+		if change, ok := entry.(createObjectChange); ok {
+			created[*change.account] = struct{}{}
+		}
+	}
+	for addr := range self.journal.dirties {
+		obj, exist := self.stateObjects[addr]
+		if !exist {
+			continue
+		}
+		if _, ok := created[addr]; ok && obj.deleted {
+			continue
+		}
+		self.accesses.Writes[addr] = struct{}{}
+	}
+}
+
+// Merge applies the changes a transaction made on a finalised copy of the state
+// to this state, along with the logs and preimages it produced, as if it had run
+// here. Only the accounts written in the given access set are taken over.
+func (self *StateDB) Merge(from *StateDB, accesses *AccessSet, txHash common.Hash) {
+	for addr := range accesses.Writes {
+		obj, exist := from.stateObjects[addr]
+		if !exist {
+			continue
+		}
+		obj = obj.deepCopy(self)
+		self.setStateObject(obj)
+		if obj.deleted {
+			self.deleteStateObject(obj)
+		} else {
+			self.updateStateObject(obj)
+		}
+		self.stateObjectsDirty[addr] = struct{}{}
+	}
+	for _, l := range from.logs[txHash] {
+		cpy := new(types.Log)
+		*cpy = *l
+		cpy.Index = self.logSize
+		self.logs[txHash] = append(self.logs[txHash], cpy)
+		self.logSize++
+	}
+	for hash, preimage := range from.preimages {
+		if _, ok := self.preimages[hash]; !ok {
+			self.preimages[hash] = preimage
+		}
+	}
+	if self.accesses != nil {
+		self.accesses.Add(accesses)
+	}
+}
@@ -0,0 +1,223 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// StateDiff holds the changes committed to a state, ordered by account address.
+type StateDiff struct {
+	Accounts []*AccountDiff `json:"accounts"`
+}
+
+// AccountDiff holds the changes committed to a single account. The storage of
+// destroyed accounts isn't listed, all of it is gone.
+type AccountDiff struct {
+	Address   common.Address
+	Created   bool // Account didn't exist before, or was recreated wiping its storage
+	Destroyed bool // Account doesn't exist anymore
+
+	PrevBalance  *big.Int
+	Balance      *big.Int
+	PrevNonce    uint64
+	Nonce        uint64
+	PrevCodeHash common.Hash
+	CodeHash     common.Hash
+	Code         []byte // New code of the account, only set if changed
+
+	Storage []StorageDiff
+
+	slots map[common.Hash]int // Index of the storage slots in Storage
+}
+
+// StorageDiff is the change of a single storage slot.
+type StorageDiff struct {
+	Key   common.Hash `json:"key"`
+	Prev  common.Hash `json:"prev"`
+	Value common.Hash `json:"value"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (d *AccountDiff) MarshalJSON() ([]byte, error) {
+	type AccountDiff struct {
+		Address      common.Address `json:"address"`
+		Created      bool           `json:"created,omitempty"`
+		Destroyed    bool           `json:"destroyed,omitempty"`
+		PrevBalance  *hexutil.Big   `json:"prevBalance"`
+		Balance      *hexutil.Big   `json:"balance"`
+		PrevNonce    hexutil.Uint64 `json:"prevNonce"`
+		Nonce        hexutil.Uint64 `json:"nonce"`
+		PrevCodeHash common.Hash    `json:"prevCodeHash"`
+		CodeHash     common.Hash    `json:"codeHash"`
+		Code         hexutil.Bytes  `json:"code,omitempty"`
+		Storage      []StorageDiff  `json:"storage,omitempty"`
+	}
+	return json.Marshal(&AccountDiff{
+		Address:      d.Address,
+		Created:      d.Created,
+		Destroyed:    d.Destroyed,
+		PrevBalance:  (*hexutil.Big)(d.PrevBalance),
+		Balance:      (*hexutil.Big)(d.Balance),
+		PrevNonce:    hexutil.Uint64(d.PrevNonce),
+		Nonce:        hexutil.Uint64(d.Nonce),
+		PrevCodeHash: d.PrevCodeHash,
+		CodeHash:     d.CodeHash,
+		Code:         d.Code,
+		Storage:      d.Storage,
+	})
+}
+
+// copy creates a deep copy of the account diff.
+func (d *AccountDiff) copy() *AccountDiff {
+	cpy := *d
+	cpy.PrevBalance = new(big.Int).Set(d.PrevBalance)
+	cpy.Balance = new(big.Int).Set(d.Balance)
+	cpy.Storage = append([]StorageDiff(nil), d.Storage...)
+	cpy.slots = make(map[common.Hash]int, len(d.slots))
+	for key, idx := range d.slots {
+		cpy.slots[key] = idx
+	}
+	return &cpy
+}
+
+// EnableDiff makes the state record the changes of all following commits, to be
+// retrieved with Diff.
+func (self *StateDB) EnableDiff() {
+	self.diffs = make(map[common.Address]*AccountDiff)
+}
+
+// Diff returns the changes committed to the state since diffing was enabled, or
+// nil if it wasn't.
+func (self *StateDB) Diff() *StateDiff {
+	if self.diffs == nil {
+		return nil
+	}
+	diff := &StateDiff{Accounts: make([]*AccountDiff, 0, len(self.diffs))}
+	for _, account := range self.diffs {
+		account = account.copy()
+		sort.Slice(account.Storage, func(i, j int) bool {
+			return bytes.Compare(account.Storage[i].Key[:], account.Storage[j].Key[:]) < 0
+		})
+		diff.Accounts = append(diff.Accounts, account)
+	}
+	sort.Slice(diff.Accounts, func(i, j int) bool {
+		return bytes.Compare(diff.Accounts[i].Address[:], diff.Accounts[j].Address[:]) < 0
+	})
+	return diff
+}
+
+// diffObject records the changes of a state object being committed, comparing
+// it against the account in the last committed state trie. Unchanged accounts,
+// such as touched ones, are left out.
+func (self *StateDB) diffObject(prevTrie Trie, obj *stateObject, destroyed bool) {
+	addr := obj.Address()
+
+	// Retrieve the account as of the last commit
+	var prev *Account
+	if enc, err := prevTrie.TryGet(addr[:]); err != nil {
+		self.setError(err)
+	} else if len(enc) > 0 {
+		prev = new(Account)
+		if err := rlp.DecodeBytes(enc, prev); err != nil {
+			self.setError(err)
+			prev = nil
+		}
+	}
+	if prev == nil && destroyed {
+		return
+	}
+	// Collect the storage slots whose value differs from the last commit
+	var (
+		slots    []StorageDiff
+		prevSlot = func(key common.Hash) (value common.Hash) { return }
+	)
+	if prev != nil && !obj.created {
+		tr, err := self.db.OpenStorageTrie(obj.addrHash, prev.Root)
+		if err != nil {
+			self.setError(err)
+		} else {
+			prevSlot = func(key common.Hash) (value common.Hash) {
+				enc, err := tr.TryGet(key[:])
+				if err != nil {
+					self.setError(err)
+				} else if len(enc) > 0 {
+					_, content, _, _ := rlp.Split(enc)
+					value.SetBytes(content)
+				}
+				return value
+			}
+		}
+	}
+	if !destroyed {
+		for key := range obj.diffKeys {
+			if old, value := prevSlot(key), obj.originStorage[key]; old != value {
+				slots = append(slots, StorageDiff{Key: key, Prev: old, Value: value})
+			}
+		}
+	}
+	// Skip the account if nothing changed
+	balance, nonce, codeHash := new(big.Int), uint64(0), common.Hash{}
+	if !destroyed {
+		balance, nonce, codeHash = obj.Balance(), obj.Nonce(), common.BytesToHash(obj.CodeHash())
+	}
+	prevBalance, prevNonce, prevCodeHash := new(big.Int), uint64(0), common.Hash{}
+	if prev != nil {
+		prevBalance, prevNonce, prevCodeHash = prev.Balance, prev.Nonce, common.BytesToHash(prev.CodeHash)
+	}
+	created := prev == nil || obj.created
+	if !created && !destroyed && len(slots) == 0 && balance.Cmp(prevBalance) == 0 && nonce == prevNonce && codeHash == prevCodeHash {
+		return
+	}
+	// Merge the changes into the ones of earlier commits
+	diff := self.diffs[addr]
+	if diff == nil {
+		diff = &AccountDiff{
+			Address:      addr,
+			PrevBalance:  new(big.Int).Set(prevBalance),
+			PrevNonce:    prevNonce,
+			PrevCodeHash: prevCodeHash,
+			slots:        make(map[common.Hash]int),
+		}
+		self.diffs[addr] = diff
+	}
+	if created {
+		diff.Created = true
+	}
+	diff.Destroyed = destroyed
+	diff.Balance, diff.Nonce, diff.CodeHash = new(big.Int).Set(balance), nonce, codeHash
+	if !destroyed && diff.CodeHash != diff.PrevCodeHash && diff.CodeHash != emptyCode {
+		diff.Code = common.CopyBytes(obj.Code(self.db))
+	} else {
+		diff.Code = nil
+	}
+	for _, slot := range slots {
+		if idx, ok := diff.slots[slot.Key]; ok {
+			diff.Storage[idx].Value = slot.Value
+			continue
+		}
+		diff.slots[slot.Key] = len(diff.Storage)
+		diff.Storage = append(diff.Storage, slot)
+	}
+}
@@ -19,6 +19,8 @@ package state
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/rlp"
@@ -79,3 +81,120 @@ func (self *StateDB) Dump() []byte {
 
 	return json
 }
+
+// dumpRLPAccount is an account in an RLP state dump, keyed by its address and
+// with its storage keyed by the unhashed slots, so it can be imported again.
+type dumpRLPAccount struct {
+	Address common.Address
+	Nonce   uint64
+	Balance *big.Int
+	Code    []byte
+	Storage []dumpRLPStorage
+}
+
+type dumpRLPStorage struct {
+	Key   common.Hash
+	Value common.Hash
+}
+
+// DumpRLP writes the state to w as an RLP stream: the state root followed by
+// every account with its code and storage. Unlike RawDump it fails if the
+// preimage of an account address or storage slot is missing, since the dump
+// could not be imported again.
+func (self *StateDB) DumpRLP(w io.Writer) error {
+	if err := rlp.Encode(w, self.trie.Hash()); err != nil {
+		return err
+	}
+	it := trie.NewIterator(self.trie.NodeIterator(nil))
+	for it.Next() {
+		addr := self.trie.GetKey(it.Key)
+		if addr == nil {
+			return fmt.Errorf("missing preimage of account %x", it.Key)
+		}
+		var data Account
+		if err := rlp.DecodeBytes(it.Value, &data); err != nil {
+			return err
+		}
+		obj := newObject(nil, common.BytesToAddress(addr), data)
+		account := dumpRLPAccount{
+			Address: obj.Address(),
+			Nonce:   data.Nonce,
+			Balance: data.Balance,
+			Code:    obj.Code(self.db),
+		}
+		storageIt := trie.NewIterator(obj.getTrie(self.db).NodeIterator(nil))
+		for storageIt.Next() {
+			key := self.trie.GetKey(storageIt.Key)
+			if key == nil {
+				return fmt.Errorf("missing preimage of storage slot %x of account %x", storageIt.Key, addr)
+			}
+			_, value, _, err := rlp.Split(storageIt.Value)
+			if err != nil {
+				return err
+			}
+			account.Storage = append(account.Storage, dumpRLPStorage{common.BytesToHash(key), common.BytesToHash(value)})
+		}
+		if storageIt.Err != nil {
+			return storageIt.Err
+		}
+		if err := rlp.Encode(w, &account); err != nil {
+			return err
+		}
+	}
+	return it.Err
+}
+
+// ImportDump adds the accounts of a dump produced by RawDump to the state and
+// returns the root of the dumped state. The caller should commit the state and
+// compare the roots to make sure nothing was lost.
+func (self *StateDB) ImportDump(dump Dump) (common.Hash, error) {
+	for addrHex, account := range dump.Accounts {
+		addr := common.HexToAddress(addrHex)
+		balance, ok := new(big.Int).SetString(account.Balance, 10)
+		if !ok {
+			return common.Hash{}, fmt.Errorf("invalid balance %q of account %x", account.Balance, addr)
+		}
+		self.SetNonce(addr, account.Nonce)
+		self.SetBalance(addr, balance)
+		if code := common.FromHex(account.Code); len(code) > 0 {
+			self.SetCode(addr, code)
+		}
+		for key, value := range account.Storage {
+			_, content, _, err := rlp.Split(common.FromHex(value))
+			if err != nil {
+				return common.Hash{}, fmt.Errorf("invalid storage slot %s of account %x: %v", key, addr, err)
+			}
+			self.SetState(addr, common.HexToHash(key), common.BytesToHash(content))
+		}
+	}
+	return common.HexToHash(dump.Root), nil
+}
+
+// ImportRLP adds the accounts of a dump produced by DumpRLP to the state and
+// returns the root of the dumped state.
+func (self *StateDB) ImportRLP(r io.Reader) (common.Hash, error) {
+	stream := rlp.NewStream(r, 0)
+
+	var root common.Hash
+	if err := stream.Decode(&root); err != nil {
+		return common.Hash{}, err
+	}
+	for {
+		var account dumpRLPAccount
+		if err := stream.Decode(&account); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return common.Hash{}, err
+		}
+		self.SetNonce(account.Address, account.Nonce)
+		self.SetBalance(account.Address, account.Balance)
+		if len(account.Code) > 0 {
+			self.SetCode(account.Address, account.Code)
+		}
+		for _, slot := range account.Storage {
+			self.SetState(account.Address, slot.Key, slot.Value)
+		}
+	}
+	return root, nil
+}
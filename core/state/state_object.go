@@ -87,6 +87,10 @@ type stateObject struct {
 	suicided  bool
 	touched   bool
 	deleted   bool
+
+	// Diff tracking, only maintained if the owning state records diffs.
+	created  bool                     // true if the object was (re)created since the last commit
+	diffKeys map[common.Hash]struct{} // Storage slots updated since the last commit
 }
 
 // empty returns whether the account is considered empty.
@@ -232,6 +236,12 @@ func (self *stateObject) updateTrie(db Database) Trie {
 		}
 		self.originStorage[key] = value
 
+		if self.db.diffs != nil {
+			if self.diffKeys == nil {
+				self.diffKeys = make(map[common.Hash]struct{})
+			}
+			self.diffKeys[key] = struct{}{}
+		}
 		if (value == common.Hash{}) {
 			self.setError(tr.TryDelete(key[:]))
 			continue
@@ -313,6 +323,13 @@ func (self *stateObject) deepCopy(db *StateDB) *stateObject {
 	stateObject.suicided = self.suicided
 	stateObject.dirtyCode = self.dirtyCode
 	stateObject.deleted = self.deleted
+	stateObject.created = self.created
+	if self.diffKeys != nil {
+		stateObject.diffKeys = make(map[common.Hash]struct{}, len(self.diffKeys))
+		for key := range self.diffKeys {
+			stateObject.diffKeys[key] = struct{}{}
+		}
+	}
 	return stateObject
 }
 
@@ -86,6 +86,40 @@ func (s *StateSuite) TestDump(c *checker.C) {
 	}
 }
 
+// Tests that both JSON and RLP dumps can be imported into an empty state and
+// reproduce the original state root.
+func TestDumpImport(t *testing.T) {
+	state, _ := New(common.Hash{}, NewDatabase(ethdb.NewMemDatabase()))
+	state.SetBalance(toAddr([]byte{0x01}), big.NewInt(22))
+	state.SetNonce(toAddr([]byte{0x01}), 3)
+	state.SetCode(toAddr([]byte{0x02}), []byte{3, 3, 3})
+	state.SetState(toAddr([]byte{0x02}), common.Hash{1}, common.Hash{2})
+	state.SetState(toAddr([]byte{0x02}), common.Hash{3}, common.BytesToHash([]byte{4}))
+	root, _ := state.Commit(false)
+	state.Database().TrieDB().Commit(root, false)
+
+	imported, _ := New(common.Hash{}, NewDatabase(ethdb.NewMemDatabase()))
+	want, err := imported.ImportDump(state.RawDump())
+	if err != nil {
+		t.Fatalf("failed to import JSON dump: %v", err)
+	}
+	if have, _ := imported.Commit(false); want != root || have != root {
+		t.Errorf("JSON dump root mismatch: have %x, dumped %x, want %x", have, want, root)
+	}
+
+	var buf bytes.Buffer
+	if err := state.DumpRLP(&buf); err != nil {
+		t.Fatalf("failed to dump RLP: %v", err)
+	}
+	imported, _ = New(common.Hash{}, NewDatabase(ethdb.NewMemDatabase()))
+	if want, err = imported.ImportRLP(&buf); err != nil {
+		t.Fatalf("failed to import RLP dump: %v", err)
+	}
+	if have, _ := imported.Commit(false); want != root || have != root {
+		t.Errorf("RLP dump root mismatch: have %x, dumped %x, want %x", have, want, root)
+	}
+}
+
 func (s *StateSuite) SetUpTest(c *checker.C) {
 	s.db = ethdb.NewMemDatabase()
 	s.state, _ = New(common.Hash{}, NewDatabase(s.db))
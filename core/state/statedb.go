@@ -86,6 +86,14 @@ type StateDB struct {
 	journal        *journal
 	validRevisions []revision
 	nextRevisionId int
+
+	// Accounts read and written, nil unless tracked for speculative execution.
+	accesses *AccessSet
+
+	// Changes committed since diffing was enabled, nil unless enabled, and the
+	// root of the last commit they are computed against.
+	diffs         map[common.Address]*AccountDiff
+	committedRoot common.Hash
 }
 
 // Create a new state from a given trie.
@@ -102,6 +110,7 @@ func New(root common.Hash, db Database) (*StateDB, error) {
 		logs:              make(map[common.Hash][]*types.Log),
 		preimages:         make(map[common.Hash][]byte),
 		journal:           newJournal(),
+		committedRoot:     root,
 	}, nil
 }
 
@@ -132,6 +141,7 @@ func (self *StateDB) Reset(root common.Hash) error {
 	self.logs = make(map[common.Hash][]*types.Log)
 	self.logSize = 0
 	self.preimages = make(map[common.Hash][]byte)
+	self.committedRoot = root
 	self.clearJournalAndRefund()
 	return nil
 }
@@ -413,6 +423,8 @@ func (self *StateDB) deleteStateObject(stateObject *stateObject) {
 
 // Retrieve a state object given by the address. Returns nil if not found.
 func (self *StateDB) getStateObject(addr common.Address) (stateObject *stateObject) {
+	self.trackRead(addr)
+
 	// Prefer 'live' objects.
 	if obj := self.stateObjects[addr]; obj != nil {
 		if obj.deleted {
@@ -457,6 +469,7 @@ func (self *StateDB) createObject(addr common.Address) (newobj, prev *stateObjec
 	prev = self.getStateObject(addr)
 	newobj = newObject(self, addr, Account{})
 	newobj.setNonce(0) // sets the object to dirty
+	newobj.created = true
 	if prev == nil {
 		self.journal.append(createObjectChange{account: &addr})
 	} else {
@@ -513,6 +526,7 @@ func (self *StateDB) Copy() *StateDB {
 		logSize:           self.logSize,
 		preimages:         make(map[common.Hash][]byte),
 		journal:           newJournal(),
+		committedRoot:     self.committedRoot,
 	}
 	// Copy the dirty states, logs, and preimages
 	for addr := range self.journal.dirties {
@@ -545,6 +559,12 @@ func (self *StateDB) Copy() *StateDB {
 	for hash, preimage := range self.preimages {
 		state.preimages[hash] = preimage
 	}
+	if self.diffs != nil {
+		state.diffs = make(map[common.Address]*AccountDiff, len(self.diffs))
+		for addr, diff := range self.diffs {
+			state.diffs[addr] = diff.copy()
+		}
+	}
 	return state
 }
 
@@ -600,6 +620,8 @@ func (s *StateDB) Finalise(deleteEmptyObjects bool) {
 		}
 		s.stateObjectsDirty[addr] = struct{}{}
 	}
+	s.trackWrites()
+
 	// Invalidate journal because reverting across transactions is not allowed.
 	s.clearJournalAndRefund()
 }
@@ -633,11 +655,19 @@ func (s *StateDB) Commit(deleteEmptyObjects bool) (root common.Hash, err error)
 	for addr := range s.journal.dirties {
 		s.stateObjectsDirty[addr] = struct{}{}
 	}
+	// Open the last committed state to diff against, if requested.
+	var prevTrie Trie
+	if s.diffs != nil {
+		if prevTrie, err = s.db.OpenTrie(s.committedRoot); err != nil {
+			return common.Hash{}, err
+		}
+	}
 	// Commit objects to the trie.
 	for addr, stateObject := range s.stateObjects {
 		_, isDirty := s.stateObjectsDirty[addr]
+		destroyed := stateObject.suicided || (isDirty && deleteEmptyObjects && stateObject.empty())
 		switch {
-		case stateObject.suicided || (isDirty && deleteEmptyObjects && stateObject.empty()):
+		case destroyed:
 			// If the object has been removed, don't bother syncing it
 			// and just mark it for deletion in the trie.
 			s.deleteStateObject(stateObject)
@@ -654,8 +684,15 @@ func (s *StateDB) Commit(deleteEmptyObjects bool) (root common.Hash, err error)
 			// Update the object in the main account trie.
 			s.updateStateObject(stateObject)
 		}
+		if prevTrie != nil && (isDirty || destroyed) {
+			s.diffObject(prevTrie, stateObject, destroyed)
+			stateObject.created, stateObject.diffKeys = false, nil
+		}
 		delete(s.stateObjectsDirty, addr)
 	}
+	if prevTrie != nil && s.dbErr != nil {
+		return common.Hash{}, s.dbErr
+	}
 	// Write trie changes.
 	root, err = s.trie.Commit(func(leaf []byte, parent common.Hash) error {
 		var account Account
@@ -672,5 +709,8 @@ func (s *StateDB) Commit(deleteEmptyObjects bool) (root common.Hash, err error)
 		return nil
 	})
 	log.Debug("Trie cache stats after commit", "misses", trie.CacheMisses(), "unloads", trie.CacheUnloads())
+	if err == nil {
+		s.committedRoot = root
+	}
 	return root, err
 }
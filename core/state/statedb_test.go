@@ -32,7 +32,9 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/rlp"
 )
 
 // Tests that updating a state trie does not leak any database writes prior to
@@ -466,3 +468,87 @@ func TestCopyOfCopy(t *testing.T) {
 		t.Fatalf("2nd copy fail, expected 42, got %v", got)
 	}
 }
+
+// Tests that committed changes are diffed against the previously committed state,
+// and that the diffs of consecutive commits are merged.
+func TestStateDiff(t *testing.T) {
+	var (
+		a, b, c, d, e  = toAddr([]byte{1}), toAddr([]byte{2}), toAddr([]byte{3}), toAddr([]byte{4}), toAddr([]byte{5})
+		k1, k2, k3     = common.Hash{1}, common.Hash{2}, common.Hash{3}
+		v1, v2, v3, v4 = common.Hash{0x11}, common.Hash{0x22}, common.Hash{0x33}, common.Hash{0x44}
+	)
+	db := NewDatabase(ethdb.NewMemDatabase())
+	state, _ := New(common.Hash{}, db)
+	state.SetBalance(a, big.NewInt(10))
+	state.SetState(a, k1, v1)
+	state.SetState(a, k2, v2)
+	state.SetBalance(b, big.NewInt(5))
+	state.SetCode(c, []byte{1, 2, 3})
+	root, _ := state.Commit(true)
+
+	if diff := state.Diff(); diff != nil {
+		t.Fatalf("diff recorded without being enabled: %v", diff)
+	}
+	// Modify, destroy, create and touch accounts over two commits
+	state, _ = New(root, db)
+	state.EnableDiff()
+
+	state.SetBalance(a, big.NewInt(20))
+	state.SetState(a, k1, v3)
+	state.SetState(a, k2, common.Hash{})
+	state.SetState(a, k3, v4)
+	state.Suicide(b)
+	state.SetBalance(d, big.NewInt(7))
+	state.SetCode(d, []byte{4, 5})
+	state.AddBalance(e, new(big.Int))
+	state.GetBalance(c)
+	if _, err := state.Commit(true); err != nil {
+		t.Fatalf("failed to commit state: %v", err)
+	}
+	state.SetNonce(a, 1)
+	state.SetState(a, k3, v2)
+	if _, err := state.Commit(true); err != nil {
+		t.Fatalf("failed to commit state: %v", err)
+	}
+	diff := state.Diff()
+	if len(diff.Accounts) != 3 {
+		t.Fatalf("account count mismatch: have %d, want %d", len(diff.Accounts), 3)
+	}
+	// Check the modified account
+	acc := diff.Accounts[0]
+	if acc.Address != a || acc.Created || acc.Destroyed {
+		t.Errorf("account a: flags mismatch: %+v", acc)
+	}
+	if acc.PrevBalance.Int64() != 10 || acc.Balance.Int64() != 20 || acc.PrevNonce != 0 || acc.Nonce != 1 {
+		t.Errorf("account a: balance or nonce mismatch: %+v", acc)
+	}
+	want := []StorageDiff{{k1, v1, v3}, {k2, v2, common.Hash{}}, {k3, common.Hash{}, v2}}
+	if !reflect.DeepEqual(acc.Storage, want) {
+		t.Errorf("account a: storage mismatch: have %v, want %v", acc.Storage, want)
+	}
+	// Check the destroyed account
+	acc = diff.Accounts[1]
+	if acc.Address != b || !acc.Destroyed || acc.PrevBalance.Int64() != 5 || acc.Balance.Sign() != 0 {
+		t.Errorf("account b: mismatch: %+v", acc)
+	}
+	// Check the created account
+	acc = diff.Accounts[2]
+	if acc.Address != d || !acc.Created || acc.Destroyed || acc.Balance.Int64() != 7 || !bytes.Equal(acc.Code, []byte{4, 5}) {
+		t.Errorf("account d: mismatch: %+v", acc)
+	}
+	if acc.PrevCodeHash != (common.Hash{}) || acc.CodeHash != crypto.Keccak256Hash([]byte{4, 5}) {
+		t.Errorf("account d: code hash mismatch: %x -> %x", acc.PrevCodeHash, acc.CodeHash)
+	}
+	// Ensure the diff survives a round trip through the database encoding
+	enc, err := rlp.EncodeToBytes(diff)
+	if err != nil {
+		t.Fatalf("failed to encode diff: %v", err)
+	}
+	dec := new(StateDiff)
+	if err := rlp.DecodeBytes(enc, dec); err != nil {
+		t.Fatalf("failed to decode diff: %v", err)
+	}
+	if len(dec.Accounts) != 3 || !reflect.DeepEqual(dec.Accounts[0].Storage, want) {
+		t.Errorf("decoded diff mismatch: %v", dec.Accounts)
+	}
+}
@@ -17,6 +17,8 @@
 package core
 
 import (
+	"sync"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/consensus/misc"
@@ -24,6 +26,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/params"
 )
 
@@ -68,6 +71,15 @@ func (p *StateProcessor) Process(block *types.Block, statedb, privateState *stat
 	if p.config.DAOForkSupport && p.config.DAOForkBlock != nil && p.config.DAOForkBlock.Cmp(block.Number()) == 0 {
 		misc.ApplyDAOHardFork(statedb)
 	}
+	// Execute the transactions speculatively in parallel if enabled and possible
+	if p.parallel(block, cfg) {
+		receipts, privateReceipts, allLogs, err := p.processParallel(block, statedb, privateState, cfg, gp, usedGas)
+		if err != nil {
+			return nil, nil, nil, 0, err
+		}
+		p.engine.Finalize(p.bc, header, statedb, block.Transactions(), block.Uncles(), receipts)
+		return receipts, privateReceipts, allLogs, *usedGas, nil
+	}
 	// Iterate over and process the individual transactions
 	for i, tx := range block.Transactions() {
 		statedb.Prepare(tx.Hash(), block.Hash(), i)
@@ -93,6 +105,139 @@ func (p *StateProcessor) Process(block *types.Block, statedb, privateState *stat
 	return receipts, privateReceipts, allLogs, *usedGas, nil
 }
 
+// speculativeTx is the outcome of a transaction executed on its own copy of the
+// state at the start of the block.
+type speculativeTx struct {
+	statedb, privateState   *state.StateDB
+	accesses, privAccesses  *state.AccessSet
+	receipt, privateReceipt *types.Receipt
+	gas                     uint64
+	err                     error
+}
+
+// parallel reports whether the transactions of a block may be executed
+// speculatively in parallel. Only Quorum chains qualify, as everywhere else all
+// transactions pay the coinbase and would conflict. Intermediate state roots in
+// pre-Byzantium receipts and tracing need serial execution.
+func (p *StateProcessor) parallel(block *types.Block, cfg vm.Config) bool {
+	if p.bc == nil || p.bc.cacheConfig.ParallelTxs < 2 || len(block.Transactions()) < 2 {
+		return false
+	}
+	if !p.config.IsQuorum || !p.config.IsByzantium(block.Number()) || cfg.Debug {
+		return false
+	}
+	return !(p.config.DAOForkSupport && p.config.DAOForkBlock != nil && p.config.DAOForkBlock.Cmp(block.Number()) == 0)
+}
+
+// processParallel executes all transactions of a block at once, each on its own
+// copy of the state, tracking the accounts they access. The results are then
+// applied in order, unless a transaction accessed an account written by one
+// before it, in which case it is executed again on the actual state.
+func (p *StateProcessor) processParallel(block *types.Block, statedb, privateState *state.StateDB, cfg vm.Config, gp *GasPool, usedGas *uint64) (types.Receipts, types.Receipts, []*types.Log, error) {
+	var (
+		txs     = block.Transactions()
+		header  = block.Header()
+		results = make([]*speculativeTx, len(txs))
+		tasks   = make(chan int, len(txs))
+		pend    sync.WaitGroup
+	)
+	for i := range txs {
+		tasks <- i
+	}
+	close(tasks)
+
+	threads := p.bc.cacheConfig.ParallelTxs
+	if threads > len(txs) {
+		threads = len(txs)
+	}
+	for n := 0; n < threads; n++ {
+		pend.Add(1)
+		go func() {
+			defer pend.Done()
+
+			for i := range tasks {
+				res := &speculativeTx{
+					statedb:      statedb.Copy(),
+					privateState: privateState.Copy(),
+					accesses:     state.NewAccessSet(),
+					privAccesses: state.NewAccessSet(),
+				}
+				res.statedb.TrackAccesses(res.accesses)
+				res.privateState.TrackAccesses(res.privAccesses)
+				res.statedb.Prepare(txs[i].Hash(), block.Hash(), i)
+				res.privateState.Prepare(txs[i].Hash(), block.Hash(), i)
+
+				gp := new(GasPool).AddGas(block.GasLimit())
+				res.receipt, res.privateReceipt, res.gas, res.err = ApplyTransaction(p.config, p.bc, nil, gp, res.statedb, res.privateState, header, txs[i], new(uint64), cfg)
+				results[i] = res
+			}
+		}()
+	}
+	pend.Wait()
+
+	// Apply the results in order, executing the conflicting transactions again
+	var (
+		receipts        types.Receipts
+		privateReceipts types.Receipts
+		allLogs         []*types.Log
+
+		written     = state.NewAccessSet()
+		privWritten = state.NewAccessSet()
+		conflicts   int
+	)
+	for i, tx := range txs {
+		statedb.Prepare(tx.Hash(), block.Hash(), i)
+		privateState.Prepare(tx.Hash(), block.Hash(), i)
+
+		res := results[i]
+		if res.err == nil && gp.Gas() >= tx.Gas() && !res.accesses.Conflicts(written) && !res.privAccesses.Conflicts(privWritten) {
+			statedb.Merge(res.statedb, res.accesses, tx.Hash())
+			written.Add(res.accesses)
+			if res.privateReceipt != nil {
+				privateState.Merge(res.privateState, res.privAccesses, tx.Hash())
+				privWritten.Add(res.privAccesses)
+			}
+			gp.SubGas(res.gas)
+			*usedGas += res.gas
+
+			// Fix up the block wide fields the speculative run couldn't know
+			res.receipt.CumulativeGasUsed = *usedGas
+			res.receipt.Logs = statedb.GetLogs(tx.Hash())
+			res.receipt.Bloom = types.CreateBloom(types.Receipts{res.receipt})
+			if res.privateReceipt != nil {
+				res.privateReceipt.CumulativeGasUsed = *usedGas
+				res.privateReceipt.Logs = privateState.GetLogs(tx.Hash())
+				res.privateReceipt.Bloom = types.CreateBloom(types.Receipts{res.privateReceipt})
+			}
+		} else {
+			conflicts++
+
+			accesses, privAccesses := state.NewAccessSet(), state.NewAccessSet()
+			statedb.TrackAccesses(accesses)
+			privateState.TrackAccesses(privAccesses)
+
+			res.receipt, res.privateReceipt, _, res.err = ApplyTransaction(p.config, p.bc, nil, gp, statedb, privateState, header, tx, usedGas, cfg)
+
+			statedb.TrackAccesses(nil)
+			privateState.TrackAccesses(nil)
+			if res.err != nil {
+				return nil, nil, nil, res.err
+			}
+			written.Add(accesses)
+			privWritten.Add(privAccesses)
+		}
+		receipts = append(receipts, res.receipt)
+		allLogs = append(allLogs, res.receipt.Logs...)
+
+		if res.privateReceipt != nil {
+			privateReceipts = append(privateReceipts, res.privateReceipt)
+			allLogs = append(allLogs, res.privateReceipt.Logs...)
+		}
+	}
+	log.Debug("Executed transactions in parallel", "number", block.Number(), "txs", len(txs), "reexecuted", conflicts)
+	return receipts, privateReceipts, allLogs, nil
+}
+
 // ApplyTransaction attempts to apply a transaction to the given state database
 // and uses the input parameters for its environment. It returns the receipt
 // for the transaction, gas used and an error if the transaction failed,
@@ -105,11 +250,17 @@ func ApplyTransaction(config *params.ChainConfig, bc *BlockChain, author *common
 	if config.IsQuorum && tx.GasPrice() != nil && tx.GasPrice().Cmp(common.Big0) > 0 {
 		return nil, nil, 0, ErrInvalidGasPrice
 	}
+	if limit := config.QuorumTxGasLimit(header.Number); limit != 0 && tx.Gas() > limit {
+		return nil, nil, 0, ErrTxGasLimit
+	}
 
 	msg, err := tx.AsMessage(types.MakeSigner(config, header.Number))
 	if err != nil {
 		return nil, nil, 0, err
 	}
+	if err := checkAccountPermission(config, statedb, header.Number, msg.From(), msg.To()); err != nil {
+		return nil, nil, 0, err
+	}
 	// Create a new context to be used in the EVM environment
 	context := NewEVMContext(msg, header, bc, author)
 	// Create a new environment which holds all relevant information
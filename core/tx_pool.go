@@ -84,9 +84,22 @@ var (
 	ErrEtherValueUnsupported = errors.New("ether value is not supported for private transactions")
 )
 
+// Reasons reported in TxLifecycleEvent for transactions dropped from the pool.
+const (
+	TxDropStale        = "stale nonce" // Nonce below the account's, taken by another transaction
+	TxDropUnderpriced  = "underpriced"
+	TxDropUnpayable    = "insufficient funds or gas"
+	TxDropUnpermitted  = "sender not permitted"
+	TxDropAccountLimit = "account limit exceeded"
+	TxDropPoolLimit    = "pool limit exceeded"
+	TxDropExpired      = "expired"
+	TxDropRemoved      = "removed by admin"
+)
+
 var (
 	evictionInterval    = time.Minute     // Time interval to check for evictable transactions
 	statsReportInterval = 8 * time.Second // Time interval to report transaction pool stats
+	txChangeBacklog     = 16384           // Lifecycle changes kept for slow subscribers before dropping the oldest
 )
 
 var (
@@ -105,6 +118,7 @@ var (
 	// General tx metrics
 	invalidTxCounter     = metrics.NewRegisteredCounter("txpool/invalid", nil)
 	underpricedTxCounter = metrics.NewRegisteredCounter("txpool/underpriced", nil)
+	lostTxChangeCounter  = metrics.NewRegisteredCounter("txpool/changes/lost", nil) // Lifecycle changes not delivered to slow subscribers
 )
 
 // TxStatus is the current status of a transaction as seen by the pool.
@@ -145,6 +159,12 @@ type TxPoolConfig struct {
 	GlobalQueue  uint64 // Maximum number of non-executable transaction slots for all accounts
 
 	Lifetime time.Duration // Maximum amount of time non-executable transaction are queued
+
+	// Quorum chains force a zero gas price, so pricing can't keep a single account
+	// from filling the pool. These settings share the pool and blocks fairly instead.
+	PriorityAccounts []common.Address // Accounts exempt from eviction and rate limits, included in blocks first
+	AccountRate      uint64           // Maximum number of transactions included per remote account and rate window (0 = unlimited)
+	RateWindow       time.Duration    // Time window over which the account rate is measured
 }
 
 // DefaultTxPoolConfig contains the default configurations for the transaction
@@ -164,6 +184,8 @@ var DefaultTxPoolConfig = TxPoolConfig{
 	GlobalQueue:  1024,
 
 	Lifetime: 3 * time.Hour,
+
+	RateWindow: time.Minute,
 }
 
 // sanitize checks the provided user configurations and changes anything that's
@@ -182,6 +204,10 @@ func (config *TxPoolConfig) sanitize() TxPoolConfig {
 		log.Warn("Sanitizing invalid txpool price bump", "provided", conf.PriceBump, "updated", DefaultTxPoolConfig.PriceBump)
 		conf.PriceBump = DefaultTxPoolConfig.PriceBump
 	}
+	if conf.AccountRate > 0 && conf.RateWindow < time.Second {
+		log.Warn("Sanitizing invalid txpool rate window", "provided", conf.RateWindow, "updated", DefaultTxPoolConfig.RateWindow)
+		conf.RateWindow = DefaultTxPoolConfig.RateWindow
+	}
 	return conf
 }
 
@@ -198,6 +224,7 @@ type TxPool struct {
 	chain        blockChain
 	gasPrice     *big.Int
 	txFeed       event.Feed
+	changeFeed   event.Feed
 	scope        event.SubscriptionScope
 	chainHeadCh  chan ChainHeadEvent
 	chainHeadSub event.Subscription
@@ -207,9 +234,12 @@ type TxPool struct {
 	currentState  *state.StateDB      // Current state in the blockchain head
 	pendingState  *state.ManagedState // Pending state tracking virtual nonces
 	currentMaxGas uint64              // Current gas limit for transaction caps
+	permissioned  bool                // Whether account permissions are enforced in the next block
 
-	locals  *accountSet // Set of local transaction to exempt from eviction rules
-	journal *txJournal  // Journal of local transaction to back up to disk
+	locals   *accountSet                     // Set of local transaction to exempt from eviction rules
+	priority *accountSet                     // Set of priority accounts to exempt from eviction and rate limits
+	rates    map[common.Address]*accountRate // Transactions included from remote accounts in their rate window
+	journal  *txJournal                      // Journal of local transaction to back up to disk
 
 	pending map[common.Address]*txList   // All currently processable transactions
 	queue   map[common.Address]*txList   // Queued but non-processable transactions
@@ -217,6 +247,14 @@ type TxPool struct {
 	all     *txLookup                    // All transactions to allow lookups
 	priced  *txPricedList                // All transactions sorted by price
 
+	changes     []TxChange               // Lifecycle changes of the current operation, guarded by mu
+	mined       map[common.Hash]struct{} // Transactions of the blocks being applied by reset, guarded by mu
+	deliveries  [][]TxChange             // Lifecycle changes waiting to be sent to the subscribers
+	queued      int                      // Number of changes in deliveries
+	deliverLock sync.Mutex               // Protects deliveries, so the pool never waits for subscribers
+	deliverCh   chan struct{}            // Wakes the delivery loop on new changes
+	quit        chan struct{}
+
 	wg sync.WaitGroup // for shutdown sync
 
 	homestead bool
@@ -237,15 +275,23 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 		pending:     make(map[common.Address]*txList),
 		queue:       make(map[common.Address]*txList),
 		beats:       make(map[common.Address]time.Time),
+		rates:       make(map[common.Address]*accountRate),
 		all:         newTxLookup(),
 		chainHeadCh: make(chan ChainHeadEvent, chainHeadChanSize),
 		gasPrice:    new(big.Int).SetUint64(config.PriceLimit),
+		deliverCh:   make(chan struct{}, 1),
+		quit:        make(chan struct{}),
 	}
 	pool.locals = newAccountSet(pool.signer)
 	for _, addr := range config.Locals {
 		log.Info("Setting new local account", "address", addr)
 		pool.locals.add(addr)
 	}
+	pool.priority = newAccountSet(pool.signer)
+	for _, addr := range config.PriorityAccounts {
+		log.Info("Setting new priority account", "address", addr)
+		pool.priority.add(addr)
+	}
 	pool.priced = newTxPricedList(pool.all)
 	pool.reset(nil, chain.CurrentBlock().Header())
 
@@ -263,9 +309,10 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 	// Subscribe events from blockchain
 	pool.chainHeadSub = pool.chain.SubscribeChainHeadEvent(pool.chainHeadCh)
 
-	// Start the event loop and return
-	pool.wg.Add(1)
+	// Start the event loops and return
+	pool.wg.Add(2)
 	go pool.loop()
+	go pool.deliverLoop()
 
 	return pool
 }
@@ -304,6 +351,7 @@ func (pool *TxPool) loop() {
 				pool.reset(head.Header(), ev.Block.Header())
 				head = ev.Block
 
+				pool.flushChanges()
 				pool.mu.Unlock()
 			}
 		// Be unsubscribed due to system stopped
@@ -326,17 +374,25 @@ func (pool *TxPool) loop() {
 		case <-evict.C:
 			pool.mu.Lock()
 			for addr := range pool.queue {
-				// Skip local transactions from the eviction mechanism
-				if pool.locals.contains(addr) {
+				// Skip local and priority transactions from the eviction mechanism
+				if pool.protected(addr) {
 					continue
 				}
 				// Any non-locals old enough should be removed
 				if time.Since(pool.beats[addr]) > pool.config.Lifetime {
 					for _, tx := range pool.queue[addr].Flatten() {
+						pool.txChanged(TxDropped, addr, tx, TxDropExpired)
 						pool.removeTx(tx.Hash(), true)
 					}
 				}
 			}
+			// Forget the rates of accounts whose window has passed
+			for addr, rate := range pool.rates {
+				if time.Since(rate.start) >= pool.config.RateWindow {
+					delete(pool.rates, addr)
+				}
+			}
+			pool.flushChanges()
 			pool.mu.Unlock()
 
 		// Handle local transaction journal rotation
@@ -357,6 +413,7 @@ func (pool *TxPool) loop() {
 func (pool *TxPool) lockedReset(oldHead, newHead *types.Header) {
 	pool.mu.Lock()
 	defer pool.mu.Unlock()
+	defer pool.flushChanges()
 
 	pool.reset(oldHead, newHead)
 }
@@ -409,8 +466,17 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 				}
 			}
 			reinject = types.TxDifference(discarded, included)
+			pool.setMined(included)
+			pool.chargeRates(included)
+		}
+	} else if oldHead != nil {
+		if block := pool.chain.GetBlock(newHead.Hash(), newHead.Number.Uint64()); block != nil {
+			pool.setMined(block.Transactions())
+			pool.chargeRates(block.Transactions())
 		}
 	}
+	defer pool.setMined(nil)
+
 	// Initialize the internal state to the current head
 	if newHead == nil {
 		newHead = pool.chain.CurrentBlock().Header() // Special case during testing
@@ -424,6 +490,13 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 	pool.pendingState = state.ManageState(statedb)
 	pool.currentMaxGas = newHead.GasLimit
 
+	// Quorum: transactions above the gas limit of the next block won't be mined
+	next := new(big.Int).Add(newHead.Number, big.NewInt(1))
+	if limit := pool.chainconfig.QuorumTxGasLimit(next); limit != 0 && limit < pool.currentMaxGas {
+		pool.currentMaxGas = limit
+	}
+	pool.permissioned = pool.chainconfig.IsAccountPermissions(next)
+
 	// Inject any transactions discarded due to reorgs
 	log.Debug("Reinjecting stale transactions", "count", len(reinject))
 	senderCacher.recover(pool.signer, reinject)
@@ -452,6 +525,7 @@ func (pool *TxPool) Stop() {
 
 	// Unsubscribe subscriptions registered from blockchain
 	pool.chainHeadSub.Unsubscribe()
+	close(pool.quit)
 	pool.wg.Wait()
 
 	if pool.journal != nil {
@@ -466,6 +540,102 @@ func (pool *TxPool) SubscribeNewTxsEvent(ch chan<- NewTxsEvent) event.Subscripti
 	return pool.scope.Track(pool.txFeed.Subscribe(ch))
 }
 
+// SubscribeTxLifecycleEvent registers a subscription of TxLifecycleEvent and
+// starts sending event to the given channel.
+func (pool *TxPool) SubscribeTxLifecycleEvent(ch chan<- TxLifecycleEvent) event.Subscription {
+	return pool.scope.Track(pool.changeFeed.Subscribe(ch))
+}
+
+// txChanged records a lifecycle change of a transaction, to be delivered to the
+// subscribers once the current operation completes.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) txChanged(typ TxChangeType, from common.Address, tx *types.Transaction, reason string) {
+	pool.changes = append(pool.changes, TxChange{Type: typ, Tx: tx, From: from, Reason: reason})
+}
+
+// staleTxChanged records the removal of a transaction whose nonce fell below the
+// account's, as included if it is in the blocks being applied. Transactions of
+// reorgs too deep for the pool to look at are reported as dropped.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) staleTxChanged(addr common.Address, tx *types.Transaction) {
+	if _, ok := pool.mined[tx.Hash()]; ok {
+		pool.txChanged(TxIncluded, addr, tx, "")
+		return
+	}
+	pool.txChanged(TxDropped, addr, tx, TxDropStale)
+}
+
+// setMined records the transactions of the blocks being applied by reset.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) setMined(txs types.Transactions) {
+	if len(txs) == 0 {
+		pool.mined = nil
+		return
+	}
+	pool.mined = make(map[common.Hash]struct{}, len(txs))
+	for _, tx := range txs {
+		pool.mined[tx.Hash()] = struct{}{}
+	}
+}
+
+// flushChanges hands the lifecycle changes recorded so far over to the delivery
+// loop.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) flushChanges() {
+	if len(pool.changes) == 0 {
+		return
+	}
+	pool.deliverLock.Lock()
+	pool.deliveries = append(pool.deliveries, pool.changes)
+	pool.queued += len(pool.changes)
+
+	// Drop the oldest changes if the subscribers can't keep up
+	var lost int
+	for pool.queued > txChangeBacklog && len(pool.deliveries) > 1 {
+		lost += len(pool.deliveries[0])
+		pool.queued -= len(pool.deliveries[0])
+		pool.deliveries = pool.deliveries[1:]
+	}
+	pool.deliverLock.Unlock()
+
+	if lost > 0 {
+		lostTxChangeCounter.Inc(int64(lost))
+		log.Warn("Transaction pool subscribers too slow, dropping lifecycle changes", "lost", lost)
+	}
+
+	pool.changes = nil
+	select {
+	case pool.deliverCh <- struct{}{}:
+	default:
+	}
+}
+
+// deliverLoop sends the recorded lifecycle changes to the subscribers in order,
+// without holding up the pool if they are slow to consume them.
+func (pool *TxPool) deliverLoop() {
+	defer pool.wg.Done()
+
+	for {
+		select {
+		case <-pool.deliverCh:
+			pool.deliverLock.Lock()
+			deliveries := pool.deliveries
+			pool.deliveries, pool.queued = nil, 0
+			pool.deliverLock.Unlock()
+
+			for _, changes := range deliveries {
+				pool.changeFeed.Send(TxLifecycleEvent{Changes: changes})
+			}
+		case <-pool.quit:
+			return
+		}
+	}
+}
+
 // GasPrice returns the current gas price enforced by the transaction pool.
 func (pool *TxPool) GasPrice() *big.Int {
 	pool.mu.RLock()
@@ -482,8 +652,11 @@ func (pool *TxPool) SetGasPrice(price *big.Int) {
 
 	pool.gasPrice = price
 	for _, tx := range pool.priced.Cap(price, pool.locals) {
+		from, _ := types.Sender(pool.signer, tx) // already validated
+		pool.txChanged(TxDropped, from, tx, TxDropUnderpriced)
 		pool.removeTx(tx.Hash(), false)
 	}
+	pool.flushChanges()
 	log.Info("Transaction pool price threshold updated", "price", price)
 }
 
@@ -573,6 +746,64 @@ func (pool *TxPool) local() map[common.Address]types.Transactions {
 	return txs
 }
 
+// Priority retrieves the accounts whose transactions are included in blocks
+// before all others.
+func (pool *TxPool) Priority() []common.Address {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	return pool.priority.flatten()
+}
+
+// protected reports whether the transactions of an account are exempt from the
+// eviction rules, being either local or a priority account.
+func (pool *TxPool) protected(addr common.Address) bool {
+	return pool.locals.contains(addr) || pool.priority.contains(addr)
+}
+
+// RateAllowance returns the number of transactions of an account that may still
+// be included in blocks within its current rate window. The bool is false if the
+// account isn't rate limited, being a local or priority one or having no rate
+// configured.
+func (pool *TxPool) RateAllowance(addr common.Address) (uint64, bool) {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	if pool.config.AccountRate == 0 || pool.protected(addr) {
+		return 0, false
+	}
+	rate := pool.rates[addr]
+	if rate == nil || time.Since(rate.start) >= pool.config.RateWindow {
+		return pool.config.AccountRate, true
+	}
+	if rate.count >= pool.config.AccountRate {
+		return 0, true
+	}
+	return pool.config.AccountRate - rate.count, true
+}
+
+// chargeRates counts the transactions included in the blocks being applied by
+// reset against the rate windows of their senders.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) chargeRates(txs types.Transactions) {
+	if pool.config.AccountRate == 0 {
+		return
+	}
+	for _, tx := range txs {
+		from, err := types.Sender(pool.signer, tx)
+		if err != nil || pool.protected(from) {
+			continue
+		}
+		rate := pool.rates[from]
+		if rate == nil || time.Since(rate.start) >= pool.config.RateWindow {
+			rate = &accountRate{start: time.Now()}
+			pool.rates[from] = rate
+		}
+		rate.count++
+	}
+}
+
 // validateTx checks whether a transaction is valid according to the consensus
 // rules and adheres to some heuristic limits of the local node (price and size).
 func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
@@ -603,6 +834,12 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	if err != nil {
 		return ErrInvalidSender
 	}
+	// Make sure the sender is permitted to write to the ledger
+	if pool.permissioned {
+		if err := checkAccountAccess(pool.chainconfig, pool.currentState, from, tx.To()); err != nil {
+			return err
+		}
+	}
 	// Drop non-local transactions under our own minimal accepted gas price
 	local = local || pool.locals.contains(from) // account may be local even if the transaction arrived from the network
 	if !isQuorum && !local && pool.gasPrice.Cmp(tx.GasPrice()) > 0 {
@@ -665,6 +902,9 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 		for _, tx := range drop {
 			log.Trace("Discarding freshly underpriced transaction", "hash", tx.Hash(), "price", tx.GasPrice())
 			underpricedTxCounter.Inc(1)
+
+			from, _ := types.Sender(pool.signer, tx) // already validated
+			pool.txChanged(TxDropped, from, tx, TxDropUnderpriced)
 			pool.removeTx(tx.Hash(), false)
 		}
 	}
@@ -682,10 +922,13 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 			pool.all.Remove(old.Hash())
 			pool.priced.Removed()
 			pendingReplaceCounter.Inc(1)
+			pool.txChanged(TxReplaced, from, old, hash.Hex())
 		}
 		pool.all.Add(tx)
 		pool.priced.Put(tx)
 		pool.journalTx(from, tx)
+		pool.txChanged(TxAdded, from, tx, "")
+		pool.txChanged(TxPromoted, from, tx, "")
 
 		log.Trace("Pooled new executable transaction", "hash", hash, "from", from, "to", tx.To())
 
@@ -699,6 +942,7 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 	if err != nil {
 		return false, err
 	}
+	pool.txChanged(TxAdded, from, tx, "")
 	// Mark local addresses and journal local transactions
 	if local {
 		if !pool.locals.contains(from) {
@@ -732,6 +976,7 @@ func (pool *TxPool) enqueueTx(hash common.Hash, tx *types.Transaction) (bool, er
 		pool.all.Remove(old.Hash())
 		pool.priced.Removed()
 		queuedReplaceCounter.Inc(1)
+		pool.txChanged(TxReplaced, from, old, hash.Hex())
 	}
 	if pool.all.Get(hash) == nil {
 		pool.all.Add(tx)
@@ -770,6 +1015,7 @@ func (pool *TxPool) promoteTx(addr common.Address, hash common.Hash, tx *types.T
 		pool.priced.Removed()
 
 		pendingDiscardCounter.Inc(1)
+		pool.txChanged(TxDropped, addr, tx, ErrReplaceUnderpriced.Error())
 		return false
 	}
 	// Otherwise discard any previous transaction and mark this
@@ -778,6 +1024,7 @@ func (pool *TxPool) promoteTx(addr common.Address, hash common.Hash, tx *types.T
 		pool.priced.Removed()
 
 		pendingReplaceCounter.Inc(1)
+		pool.txChanged(TxReplaced, addr, old, hash.Hex())
 	}
 	// Failsafe to work around direct pending inserts (tests)
 	if pool.all.Get(hash) == nil {
@@ -823,6 +1070,7 @@ func (pool *TxPool) AddRemotes(txs []*types.Transaction) []error {
 func (pool *TxPool) addTx(tx *types.Transaction, local bool) error {
 	pool.mu.Lock()
 	defer pool.mu.Unlock()
+	defer pool.flushChanges()
 
 	// Try to inject the transaction and update any state
 	replace, err := pool.add(tx, local)
@@ -841,6 +1089,7 @@ func (pool *TxPool) addTx(tx *types.Transaction, local bool) error {
 func (pool *TxPool) addTxs(txs []*types.Transaction, local bool) []error {
 	pool.mu.Lock()
 	defer pool.mu.Unlock()
+	defer pool.flushChanges()
 
 	return pool.addTxsLocked(txs, local)
 }
@@ -896,6 +1145,31 @@ func (pool *TxPool) Get(hash common.Hash) *types.Transaction {
 	return pool.all.Get(hash)
 }
 
+// Remove drops a single transaction from the pool, moving all subsequent
+// transactions of the sender back to the future queue. It returns whether the
+// transaction was found.
+func (pool *TxPool) Remove(hash common.Hash) bool {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	defer pool.flushChanges()
+
+	tx := pool.all.Get(hash)
+	if tx == nil {
+		return false
+	}
+	from, _ := types.Sender(pool.signer, tx) // already validated
+	pool.txChanged(TxDropped, from, tx, TxDropRemoved)
+	pool.removeTx(hash, true)
+
+	// Make sure a removed local transaction doesn't come back from the journal
+	if pool.journal != nil && pool.locals.contains(from) {
+		if err := pool.journal.rotate(pool.local()); err != nil {
+			log.Warn("Failed to rotate local tx journal", "err", err)
+		}
+	}
+	return true
+}
+
 // removeTx removes a single transaction from the queue, moving all subsequent
 // transactions back to the future queue.
 func (pool *TxPool) removeTx(hash common.Hash, outofbound bool) {
@@ -968,6 +1242,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 			log.Trace("Removed old queued transaction", "hash", hash)
 			pool.all.Remove(hash)
 			pool.priced.Removed()
+			pool.staleTxChanged(addr, tx)
 		}
 		if !isQuorum {
 			// Drop all transactions that are too costly (low balance or out of gas)
@@ -978,6 +1253,20 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 				pool.all.Remove(hash)
 				pool.priced.Removed()
 				queuedNofundsCounter.Inc(1)
+				pool.txChanged(TxDropped, addr, tx, TxDropUnpayable)
+			}
+		}
+		// Drop all transactions the sender lost the permission for while queued
+		if pool.permissioned {
+			unpermitted := list.txs.Filter(func(tx *types.Transaction) bool {
+				return checkAccountAccess(pool.chainconfig, pool.currentState, addr, tx.To()) != nil
+			})
+			for _, tx := range unpermitted {
+				hash := tx.Hash()
+				log.Trace("Removed unpermitted queued transaction", "hash", hash)
+				pool.all.Remove(hash)
+				pool.priced.Removed()
+				pool.txChanged(TxDropped, addr, tx, TxDropUnpermitted)
 			}
 		}
 		// Gather all executable transactions and promote them
@@ -987,16 +1276,18 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 			if pool.promoteTx(addr, hash, tx) {
 				log.Trace("Promoting queued transaction", "hash", hash)
 				promoted = append(promoted, tx)
+				pool.txChanged(TxPromoted, addr, tx, "")
 			}
 		}
 		// Drop all transactions over the allowed limit
-		if !pool.locals.contains(addr) {
+		if !pool.protected(addr) {
 			for _, tx := range list.Cap(int(pool.config.AccountQueue)) {
 				hash := tx.Hash()
 				pool.all.Remove(hash)
 				pool.priced.Removed()
 				queuedRateLimitCounter.Inc(1)
 				log.Trace("Removed cap-exceeding queued transaction", "hash", hash)
+				pool.txChanged(TxDropped, addr, tx, TxDropAccountLimit)
 			}
 		}
 		// Delete the entire queue entry if it became empty.
@@ -1019,7 +1310,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 		spammers := prque.New(nil)
 		for addr, list := range pool.pending {
 			// Only evict transactions from high rollers
-			if !pool.locals.contains(addr) && uint64(list.Len()) > pool.config.AccountSlots {
+			if !pool.protected(addr) && uint64(list.Len()) > pool.config.AccountSlots {
 				spammers.Push(addr, int64(list.Len()))
 			}
 		}
@@ -1050,6 +1341,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 								pool.pendingState.SetNonce(offenders[i], nonce)
 							}
 							log.Trace("Removed fairness-exceeding pending transaction", "hash", hash)
+							pool.txChanged(TxDropped, offenders[i], tx, TxDropPoolLimit)
 						}
 						pending--
 					}
@@ -1072,6 +1364,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 							pool.pendingState.SetNonce(addr, nonce)
 						}
 						log.Trace("Removed fairness-exceeding pending transaction", "hash", hash)
+						pool.txChanged(TxDropped, addr, tx, TxDropPoolLimit)
 					}
 					pending--
 				}
@@ -1088,7 +1381,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 		// Sort all accounts with queued transactions by heartbeat
 		addresses := make(addressesByHeartbeat, 0, len(pool.queue))
 		for addr := range pool.queue {
-			if !pool.locals.contains(addr) { // don't drop locals
+			if !pool.protected(addr) { // don't drop locals or priority accounts
 				addresses = append(addresses, addressByHeartbeat{addr, pool.beats[addr]})
 			}
 		}
@@ -1104,6 +1397,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 			// Drop all transactions if they are less than the overflow
 			if size := uint64(list.Len()); size <= drop {
 				for _, tx := range list.Flatten() {
+					pool.txChanged(TxDropped, addr.address, tx, TxDropPoolLimit)
 					pool.removeTx(tx.Hash(), true)
 				}
 				drop -= size
@@ -1113,6 +1407,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 			// Otherwise drop only last few transactions
 			txs := list.Flatten()
 			for i := len(txs) - 1; i >= 0 && drop > 0; i-- {
+				pool.txChanged(TxDropped, addr.address, txs[i], TxDropPoolLimit)
 				pool.removeTx(txs[i].Hash(), true)
 				drop--
 				queuedRateLimitCounter.Inc(1)
@@ -1135,6 +1430,32 @@ func (pool *TxPool) demoteUnexecutables() {
 			log.Trace("Removed old pending transaction", "hash", hash)
 			pool.all.Remove(hash)
 			pool.priced.Removed()
+			pool.staleTxChanged(addr, tx)
+		}
+		// Drop all transactions the sender lost the permission for, and queue back
+		// the subsequent ones as they became unexecutable
+		if pool.permissioned {
+			unpermitted := list.txs.Filter(func(tx *types.Transaction) bool {
+				return checkAccountAccess(pool.chainconfig, pool.currentState, addr, tx.To()) != nil
+			})
+			if len(unpermitted) > 0 {
+				lowest := unpermitted[0].Nonce()
+				for _, tx := range unpermitted {
+					hash := tx.Hash()
+					log.Trace("Removed unpermitted pending transaction", "hash", hash)
+					pool.all.Remove(hash)
+					pool.priced.Removed()
+					pool.txChanged(TxDropped, addr, tx, TxDropUnpermitted)
+					if tx.Nonce() < lowest {
+						lowest = tx.Nonce()
+					}
+				}
+				for _, tx := range list.txs.Filter(func(tx *types.Transaction) bool { return tx.Nonce() > lowest }) {
+					hash := tx.Hash()
+					log.Trace("Demoting pending transaction", "hash", hash)
+					pool.enqueueTx(hash, tx)
+				}
+			}
 		}
 		// Drop all transactions that are too costly (low balance or out of gas), and queue any invalids back for later
 		drops, invalids := list.Filter(pool.currentState.GetBalance(addr), pool.currentMaxGas)
@@ -1144,6 +1465,7 @@ func (pool *TxPool) demoteUnexecutables() {
 			pool.all.Remove(hash)
 			pool.priced.Removed()
 			pendingNofundsCounter.Inc(1)
+			pool.txChanged(TxDropped, addr, tx, TxDropUnpayable)
 		}
 		for _, tx := range invalids {
 			hash := tx.Hash()
@@ -1229,6 +1551,13 @@ func (as *accountSet) flatten() []common.Address {
 	return *as.cache
 }
 
+// accountRate counts the transactions included from an account since the start
+// of its current rate window.
+type accountRate struct {
+	start time.Time
+	count uint64
+}
+
 // txLookup is used internally by TxPool to track transactions while allowing lookup without
 // mutex contention.
 //
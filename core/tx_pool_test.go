@@ -326,6 +326,26 @@ func TestQuorumInvalidTransactions(t *testing.T) {
 
 }
 
+func TestValidateTx_whenGasAboveQuorumTxGasLimit(t *testing.T) {
+	config := *params.QuorumTestChainConfig
+	config.GasLimits = &params.QuorumGasLimitConfig{Block: big.NewInt(0), TxGasLimit: 50000}
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	blockchain := &testBlockChain{statedb, statedb, 1000000, new(event.Feed)}
+	pool := NewTxPool(testTxPoolConfig, &config, blockchain)
+	defer pool.Stop()
+
+	key, _ := crypto.GenerateKey()
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000))
+
+	if err := pool.AddRemote(pricedTransaction(0, 50001, common.Big0, key)); err != ErrGasLimit {
+		t.Error("expected", ErrGasLimit, "; got", err)
+	}
+	if err := pool.AddRemote(pricedTransaction(0, 50000, common.Big0, key)); err != nil {
+		t.Error("expected transaction within the gas limit to be accepted; got", err)
+	}
+}
+
 func TestValidateTx_whenValueZeroTransferForPrivateTransaction(t *testing.T) {
 	pool, key := setupQuorumTxPool()
 	defer pool.Stop()
@@ -2016,3 +2036,240 @@ func TestEIP155SignerOnTxPool(t *testing.T) {
 	}
 
 }
+
+// Tests that the lifecycle of transactions in the pool is reported in order:
+// additions, promotions, replacements and drops along with their reasons.
+func TestTransactionLifecycleEvents(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	account, _ := deriveSender(transaction(0, 0, key))
+	pool.currentState.AddBalance(account, big.NewInt(1000000000))
+
+	events := make(chan TxLifecycleEvent, 16)
+	sub := pool.SubscribeTxLifecycleEvent(events)
+	defer sub.Unsubscribe()
+
+	var (
+		tx0  = transaction(0, 100000, key)
+		tx1  = transaction(1, 100000, key)
+		tx0b = pricedTransaction(0, 100000, big.NewInt(2), key)
+	)
+	// Queue up a gapped transaction, fill the gap and replace the filler
+	if err := pool.AddRemote(tx1); err != nil {
+		t.Fatalf("failed to add gapped transaction: %v", err)
+	}
+	if err := pool.AddRemote(tx0); err != nil {
+		t.Fatalf("failed to add gap filler: %v", err)
+	}
+	if err := pool.AddRemote(tx0b); err != nil {
+		t.Fatalf("failed to add replacement: %v", err)
+	}
+	// Remove the replacement, which must not resurrect the replaced transaction
+	if !pool.Remove(tx0b.Hash()) {
+		t.Fatalf("failed to remove pooled transaction")
+	}
+	if pool.Remove(tx0b.Hash()) {
+		t.Fatalf("removed unknown transaction")
+	}
+	if pool.Get(tx0.Hash()) != nil || pool.Get(tx0b.Hash()) != nil {
+		t.Fatalf("removed transactions still pooled")
+	}
+	if pending, queued := pool.Stats(); pending != 0 || queued != 1 {
+		t.Fatalf("pool size mismatch: have %d/%d, want 0/1", pending, queued)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+	want := [][]TxChange{
+		{{Type: TxAdded, Tx: tx1, From: account}},
+		{{Type: TxAdded, Tx: tx0, From: account}, {Type: TxPromoted, Tx: tx0, From: account}, {Type: TxPromoted, Tx: tx1, From: account}},
+		{{Type: TxReplaced, Tx: tx0, From: account, Reason: tx0b.Hash().Hex()}, {Type: TxAdded, Tx: tx0b, From: account}, {Type: TxPromoted, Tx: tx0b, From: account}},
+		{{Type: TxDropped, Tx: tx0b, From: account, Reason: TxDropRemoved}},
+	}
+	for i, changes := range want {
+		select {
+		case ev := <-events:
+			if len(ev.Changes) != len(changes) {
+				t.Fatalf("event %d: change count mismatch: have %d, want %d", i, len(ev.Changes), len(changes))
+			}
+			for j, change := range changes {
+				have := ev.Changes[j]
+				if have.Type != change.Type || have.Tx.Hash() != change.Tx.Hash() || have.From != change.From || have.Reason != change.Reason {
+					t.Errorf("event %d, change %d: have %v %x %x %q, want %v %x %x %q", i, j,
+						have.Type, have.Tx.Hash(), have.From, have.Reason, change.Type, change.Tx.Hash(), change.From, change.Reason)
+				}
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("event %d not fired", i)
+		}
+	}
+	select {
+	case ev := <-events:
+		t.Fatalf("unexpected event fired: %v", ev.Changes)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// minedTestBlockChain is a testBlockChain whose blocks all hold the same
+// transactions.
+type minedTestBlockChain struct {
+	*testBlockChain
+	txs types.Transactions
+}
+
+func (bc *minedTestBlockChain) GetBlock(hash common.Hash, number uint64) *types.Block {
+	return types.NewBlock(&types.Header{Number: new(big.Int).SetUint64(number), GasLimit: bc.gasLimit}, bc.txs, nil, nil)
+}
+
+// Tests that transactions leaving the pool because they were mined are reported
+// as included, and the ones whose nonce was taken by another as dropped.
+func TestTransactionLifecycleIncluded(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	blockchain := &minedTestBlockChain{testBlockChain: &testBlockChain{statedb, statedb, 1000000, new(event.Feed)}}
+
+	key, _ := crypto.GenerateKey()
+	pool := NewTxPool(testTxPoolConfig, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	account, _ := deriveSender(transaction(0, 0, key))
+	pool.currentState.AddBalance(account, big.NewInt(1000000000))
+
+	events := make(chan TxLifecycleEvent, 16)
+	sub := pool.SubscribeTxLifecycleEvent(events)
+	defer sub.Unsubscribe()
+
+	txs := types.Transactions{transaction(0, 100000, key), transaction(1, 100000, key), transaction(2, 100000, key)}
+	for i, tx := range txs {
+		if err := pool.AddRemote(tx); err != nil {
+			t.Fatalf("failed to add transaction %d: %v", i, err)
+		}
+		select {
+		case <-events:
+		case <-time.After(time.Second):
+			t.Fatalf("addition %d not reported", i)
+		}
+	}
+
+	// Mine the first transaction, while the second one's nonce is used elsewhere
+	blockchain.txs = txs[:1]
+	statedb.SetNonce(account, 2)
+
+	parent := &types.Header{Number: big.NewInt(0), GasLimit: blockchain.gasLimit}
+	pool.lockedReset(parent, &types.Header{Number: big.NewInt(1), ParentHash: parent.Hash(), GasLimit: blockchain.gasLimit})
+
+	select {
+	case ev := <-events:
+		want := []TxChange{{Type: TxIncluded, Tx: txs[0]}, {Type: TxDropped, Tx: txs[1], Reason: TxDropStale}}
+		if len(ev.Changes) != len(want) {
+			t.Fatalf("change count mismatch: have %d, want %d", len(ev.Changes), len(want))
+		}
+		for i, change := range want {
+			if have := ev.Changes[i]; have.Type != change.Type || have.Tx.Hash() != change.Tx.Hash() || have.Reason != change.Reason {
+				t.Errorf("change %d: have %v %x %q, want %v %x %q", i, have.Type, have.Tx.Hash(), have.Reason, change.Type, change.Tx.Hash(), change.Reason)
+			}
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("event not fired")
+	}
+}
+
+// Tests that lifecycle changes pile up only to a limit behind a subscriber that
+// doesn't consume them.
+func TestTransactionLifecycleBacklog(t *testing.T) {
+	defer func(backlog int) { txChangeBacklog = backlog }(txChangeBacklog)
+	txChangeBacklog = 4
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	account, _ := deriveSender(transaction(0, 0, key))
+	pool.currentState.AddBalance(account, big.NewInt(1000000000))
+
+	events := make(chan TxLifecycleEvent)
+	sub := pool.SubscribeTxLifecycleEvent(events)
+	defer sub.Unsubscribe()
+
+	for i := uint64(0); i < 16; i++ {
+		if err := pool.AddRemote(transaction(i, 100000, key)); err != nil {
+			t.Fatalf("failed to add transaction %d: %v", i, err)
+		}
+	}
+	pool.deliverLock.Lock()
+	queued := pool.queued
+	pool.deliverLock.Unlock()
+
+	if queued > txChangeBacklog {
+		t.Fatalf("lifecycle backlog exceeded: have %d, want at most %d", queued, txChangeBacklog)
+	}
+}
+
+// Tests that the transactions of remote accounts included in blocks are charged
+// to their rate, while local and priority accounts are exempt.
+func TestTransactionAccountRateLimiting(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	blockchain := &minedTestBlockChain{testBlockChain: &testBlockChain{statedb, statedb, 1000000, new(event.Feed)}}
+
+	remote, _ := crypto.GenerateKey()
+	local, _ := crypto.GenerateKey()
+	priority, _ := crypto.GenerateKey()
+
+	config := testTxPoolConfig
+	config.AccountRate = 3
+	config.PriorityAccounts = []common.Address{crypto.PubkeyToAddress(priority.PublicKey)}
+
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	for _, key := range []*ecdsa.PrivateKey{remote, local, priority} {
+		pool.currentState.AddBalance(crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+	}
+	// Admission isn't rate limited, any number of transactions may be pooled
+	for i := uint64(0); i < 2*config.AccountRate; i++ {
+		if err := pool.AddRemote(transaction(i, 100000, remote)); err != nil {
+			t.Fatalf("tx %d: failed to add remote transaction: %v", i, err)
+		}
+	}
+	if err := pool.AddLocal(transaction(0, 100000, local)); err != nil {
+		t.Fatalf("failed to add local transaction: %v", err)
+	}
+	if allowance, limited := pool.RateAllowance(crypto.PubkeyToAddress(remote.PublicKey)); !limited || allowance != config.AccountRate {
+		t.Fatalf("fresh allowance mismatch: have %d/%v, want %d/true", allowance, limited, config.AccountRate)
+	}
+	// Include some transactions of every account and ensure only the remote is charged
+	blockchain.txs = types.Transactions{
+		transaction(0, 100000, remote), transaction(1, 100000, remote),
+		transaction(0, 100000, local), transaction(0, 100000, priority),
+	}
+	parent := &types.Header{Number: big.NewInt(0), GasLimit: blockchain.gasLimit}
+	pool.lockedReset(parent, &types.Header{Number: big.NewInt(1), ParentHash: parent.Hash(), GasLimit: blockchain.gasLimit})
+
+	if allowance, limited := pool.RateAllowance(crypto.PubkeyToAddress(remote.PublicKey)); !limited || allowance != config.AccountRate-2 {
+		t.Fatalf("charged allowance mismatch: have %d/%v, want %d/true", allowance, limited, config.AccountRate-2)
+	}
+	for _, key := range []*ecdsa.PrivateKey{local, priority} {
+		if _, limited := pool.RateAllowance(crypto.PubkeyToAddress(key.PublicKey)); limited {
+			t.Errorf("exempt account %x rate limited", crypto.PubkeyToAddress(key.PublicKey))
+		}
+	}
+	if prio := pool.Priority(); len(prio) != 1 || prio[0] != crypto.PubkeyToAddress(priority.PublicKey) {
+		t.Fatalf("priority accounts mismatch: %v", prio)
+	}
+	// Once the window passes the account gets its full allowance again
+	pool.mu.Lock()
+	pool.rates[crypto.PubkeyToAddress(remote.PublicKey)].start = time.Now().Add(-config.RateWindow)
+	pool.mu.Unlock()
+
+	if allowance, _ := pool.RateAllowance(crypto.PubkeyToAddress(remote.PublicKey)); allowance != config.AccountRate {
+		t.Fatalf("renewed allowance mismatch: have %d, want %d", allowance, config.AccountRate)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
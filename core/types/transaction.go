@@ -17,6 +17,7 @@
 package types
 
 import (
+	"bytes"
 	"container/heap"
 	"errors"
 	"io"
@@ -384,12 +385,58 @@ func (s *TxByPrice) Pop() interface{} {
 	return x
 }
 
+// txLane is the next transaction of an account in round-robin ordering.
+type txLane struct {
+	tx       *Transaction
+	from     common.Address
+	priority bool   // Whether the account is served before all non-priority ones
+	round    uint64 // Number of transactions already taken from the account
+	wrapped  bool   // Whether the account sorts before the start of the rotation
+}
+
+// txLanes implements the heap interface, ordering accounts by priority first and
+// then by the number of transactions already taken from them, so every account
+// gets its turn before any gets a second one. Within a round accounts go in
+// address order, starting from a per-block rotation point and wrapping around.
+type txLanes []*txLane
+
+func (s txLanes) Len() int { return len(s) }
+func (s txLanes) Less(i, j int) bool {
+	if s[i].priority != s[j].priority {
+		return s[i].priority
+	}
+	if s[i].round != s[j].round {
+		return s[i].round < s[j].round
+	}
+	if s[i].wrapped != s[j].wrapped {
+		return !s[i].wrapped
+	}
+	return bytes.Compare(s[i].from[:], s[j].from[:]) < 0
+}
+func (s txLanes) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+
+func (s *txLanes) Push(x interface{}) {
+	*s = append(*s, x.(*txLane))
+}
+
+func (s *txLanes) Pop() interface{} {
+	old := *s
+	n := len(old)
+	x := old[n-1]
+	*s = old[0 : n-1]
+	return x
+}
+
 // TransactionsByPriceAndNonce represents a set of transactions that can return
 // transactions in a profit-maximizing sorted order, while supporting removing
 // entire batches of transactions for non-executable accounts.
+//
+// Alternatively, it returns transactions round-robin between accounts, which is
+// what Quorum chains use, having no gas price to order by.
 type TransactionsByPriceAndNonce struct {
 	txs    map[common.Address]Transactions // Per account nonce-sorted list of transactions
 	heads  TxByPrice                       // Next transaction for each unique account (price heap)
+	lanes  *txLanes                        // Next transaction for each unique account (round-robin heap), nil if price sorted
 	signer Signer                          // Signer for the set of transactions
 }
 
@@ -424,8 +471,60 @@ func NewTransactionsByPriceAndNonce(signer Signer, txs map[common.Address]Transa
 	}
 }
 
-// Peek returns the next transaction by price.
+// NewTransactionsByRoundRobin creates a transaction set that retrieves the
+// transactions of the accounts in turns, in a nonce-honouring way. The priority
+// accounts take their turns before all others. The seed, usually the parent block
+// hash, rotates which account starts each round so no address is favoured in
+// every block.
+//
+// Note, the input map is reowned so the caller should not interact any more with
+// if after providing it to the constructor.
+func NewTransactionsByRoundRobin(signer Signer, txs map[common.Address]Transactions, priority []common.Address, seed common.Hash) *TransactionsByPriceAndNonce {
+	prioritized := make(map[common.Address]bool, len(priority))
+	for _, addr := range priority {
+		prioritized[addr] = true
+	}
+	start := common.BytesToAddress(seed[:common.AddressLength])
+
+	// Initialize a round-robin heap with the head transactions
+	lanes := make(txLanes, 0, len(txs))
+	for from, accTxs := range txs {
+		// Ensure the sender address is from the signer
+		acc, err := Sender(signer, accTxs[0])
+		if err == nil {
+			lanes = append(lanes, &txLane{
+				tx:       accTxs[0],
+				from:     acc,
+				priority: prioritized[acc],
+				wrapped:  bytes.Compare(acc[:], start[:]) < 0,
+			})
+			txs[acc] = accTxs[1:]
+		} else {
+			log.Info("Failed to recovered sender address, this transaction is skipped", "from", from, "nonce", accTxs[0].data.AccountNonce, "err", err)
+		}
+		if from != acc {
+			delete(txs, from)
+		}
+	}
+	heap.Init(&lanes)
+
+	// Assemble and return the transaction set
+	return &TransactionsByPriceAndNonce{
+		txs:    txs,
+		lanes:  &lanes,
+		signer: signer,
+	}
+}
+
+// Peek returns the next transaction by price, or the next in turn if ordered
+// round-robin.
 func (t *TransactionsByPriceAndNonce) Peek() *Transaction {
+	if t.lanes != nil {
+		if len(*t.lanes) == 0 {
+			return nil
+		}
+		return (*t.lanes)[0].tx
+	}
 	if len(t.heads) == 0 {
 		return nil
 	}
@@ -434,6 +533,18 @@ func (t *TransactionsByPriceAndNonce) Peek() *Transaction {
 
 // Shift replaces the current best head with the next one from the same account.
 func (t *TransactionsByPriceAndNonce) Shift() {
+	if t.lanes != nil {
+		// Move the account to the back of its lane, behind everyone with fewer turns
+		lane := (*t.lanes)[0]
+		if txs, ok := t.txs[lane.from]; ok && len(txs) > 0 {
+			lane.tx, t.txs[lane.from] = txs[0], txs[1:]
+			lane.round++
+			heap.Fix(t.lanes, 0)
+		} else {
+			heap.Pop(t.lanes)
+		}
+		return
+	}
 	acc, _ := Sender(t.signer, t.heads[0])
 	if txs, ok := t.txs[acc]; ok && len(txs) > 0 {
 		t.heads[0], t.txs[acc] = txs[0], txs[1:]
@@ -447,6 +558,10 @@ func (t *TransactionsByPriceAndNonce) Shift() {
 // the same account. This should be used when a transaction cannot be executed
 // and hence all subsequent ones should be discarded from the same account.
 func (t *TransactionsByPriceAndNonce) Pop() {
+	if t.lanes != nil {
+		heap.Pop(t.lanes)
+		return
+	}
 	heap.Pop(&t.heads)
 }
 
@@ -21,6 +21,7 @@ import (
 	"crypto/ecdsa"
 	"encoding/json"
 	"math/big"
+	"sort"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -201,6 +202,111 @@ func TestTransactionPriceNonceSort(t *testing.T) {
 	}
 }
 
+// Tests that round-robin ordering serves priority accounts first, hands out turns
+// evenly between accounts and keeps the nonce ordering within each account.
+func TestTransactionRoundRobinSort(t *testing.T) {
+	// Generate a batch of accounts with differing numbers of zero priced transactions
+	keys := make([]*ecdsa.PrivateKey, 10)
+	for i := 0; i < len(keys); i++ {
+		keys[i], _ = crypto.GenerateKey()
+	}
+	signer := HomesteadSigner{}
+
+	groups := map[common.Address]Transactions{}
+	for start, key := range keys {
+		addr := crypto.PubkeyToAddress(key.PublicKey)
+		for i := 0; i <= start; i++ {
+			tx, _ := SignTx(NewTransaction(uint64(i), common.Address{}, big.NewInt(100), 100, big.NewInt(0), nil), signer, key)
+			groups[addr] = append(groups[addr], tx)
+		}
+	}
+	priority := crypto.PubkeyToAddress(keys[4].PublicKey)
+
+	txset := NewTransactionsByRoundRobin(signer, groups, []common.Address{priority}, common.Hash{})
+
+	txs := Transactions{}
+	for tx := txset.Peek(); tx != nil; tx = txset.Peek() {
+		txs = append(txs, tx)
+		txset.Shift()
+	}
+	if len(txs) != 55 {
+		t.Fatalf("expected %d transactions, found %d", 55, len(txs))
+	}
+	// The priority account goes first, then everyone gets a turn per round
+	rounds := make(map[common.Address]uint64)
+	for i, tx := range txs {
+		from, _ := Sender(signer, tx)
+		if i < 5 && from != priority {
+			t.Errorf("tx #%d: priority account not served first: have %x", i, from[:4])
+		}
+		if tx.Nonce() != rounds[from] {
+			t.Errorf("tx #%d: invalid nonce ordering: have %d, want %d", i, tx.Nonce(), rounds[from])
+		}
+		rounds[from]++
+
+		if i > 0 {
+			prev, _ := Sender(signer, txs[i-1])
+			if prev != priority && from != priority && rounds[from] < rounds[prev] {
+				t.Errorf("tx #%d: account %x served out of turn after %x", i, from[:4], prev[:4])
+			}
+		}
+	}
+	// Popping an account drops all its remaining transactions
+	groups = map[common.Address]Transactions{}
+	for i := 0; i < 2; i++ {
+		for _, key := range keys[:2] {
+			tx, _ := SignTx(NewTransaction(uint64(i), common.Address{}, big.NewInt(100), 100, big.NewInt(0), nil), signer, key)
+			addr := crypto.PubkeyToAddress(key.PublicKey)
+			groups[addr] = append(groups[addr], tx)
+		}
+	}
+	txset = NewTransactionsByRoundRobin(signer, groups, nil, common.Hash{})
+	dropped, _ := Sender(signer, txset.Peek())
+	txset.Pop()
+
+	for tx := txset.Peek(); tx != nil; tx = txset.Peek() {
+		if from, _ := Sender(signer, tx); from == dropped {
+			t.Fatalf("transaction of popped account returned")
+		}
+		txset.Shift()
+	}
+}
+
+// Tests that the round-robin seed rotates the account starting each round, going
+// through the accounts in address order and wrapping around.
+func TestTransactionRoundRobinRotation(t *testing.T) {
+	keys := make([]*ecdsa.PrivateKey, 5)
+	for i := 0; i < len(keys); i++ {
+		keys[i], _ = crypto.GenerateKey()
+	}
+	signer := HomesteadSigner{}
+
+	addrs := make([]common.Address, len(keys))
+	for i, key := range keys {
+		addrs[i] = crypto.PubkeyToAddress(key.PublicKey)
+	}
+	sort.Slice(addrs, func(i, j int) bool { return bytes.Compare(addrs[i][:], addrs[j][:]) < 0 })
+
+	for i, start := range addrs {
+		groups := map[common.Address]Transactions{}
+		for _, key := range keys {
+			tx, _ := SignTx(NewTransaction(0, common.Address{}, big.NewInt(100), 100, big.NewInt(0), nil), signer, key)
+			groups[crypto.PubkeyToAddress(key.PublicKey)] = Transactions{tx}
+		}
+		var seed common.Hash
+		copy(seed[:], start[:])
+
+		txset := NewTransactionsByRoundRobin(signer, groups, nil, seed)
+		for j := 0; j < len(addrs); j++ {
+			from, _ := Sender(signer, txset.Peek())
+			if want := addrs[(i+j)%len(addrs)]; from != want {
+				t.Errorf("seed %d, turn %d: account mismatch: have %x, want %x", i, j, from[:4], want[:4])
+			}
+			txset.Shift()
+		}
+	}
+}
+
 // TestTransactionJSON tests serializing/de-serializing to/from JSON.
 func TestTransactionJSON(t *testing.T) {
 	key, err := crypto.GenerateKey()
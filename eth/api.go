@@ -478,6 +478,76 @@ func (api *PrivateDebugAPI) GetModifiedAccountsByHash(startHash common.Hash, end
 	return api.getModifiedAccounts(startBlock, endBlock)
 }
 
+// BlockStateDiff is the public and private state diff of a block.
+type BlockStateDiff struct {
+	BlockHash   common.Hash      `json:"blockHash"`
+	BlockNumber hexutil.Uint64   `json:"blockNumber"`
+	Public      *state.StateDiff `json:"public"`
+	Private     *state.StateDiff `json:"private"`
+}
+
+// StateDiff retrieves the public and private state diffs recorded for a block.
+// Diffs are only recorded while the node runs with --statediffs.
+func (api *PrivateDebugAPI) StateDiff(blockNr rpc.BlockNumber) (*BlockStateDiff, error) {
+	var block *types.Block
+	switch blockNr {
+	case rpc.PendingBlockNumber:
+		return nil, errors.New("state diff of the pending block is not available")
+	case rpc.LatestBlockNumber:
+		block = api.eth.blockchain.CurrentBlock()
+	default:
+		block = api.eth.blockchain.GetBlockByNumber(uint64(blockNr))
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block #%d not found", blockNr)
+	}
+	public, private := api.eth.blockchain.GetStateDiff(block.Hash())
+	if public == nil {
+		return nil, fmt.Errorf("no state diff recorded for block #%d", block.NumberU64())
+	}
+	return &BlockStateDiff{
+		BlockHash:   block.Hash(),
+		BlockNumber: hexutil.Uint64(block.NumberU64()),
+		Public:      public,
+		Private:     private,
+	}, nil
+}
+
+// StateDiffs creates a subscription that is notified of the public and private
+// state diffs of every block written to the chain, canonical or not.
+func (api *PrivateDebugAPI) StateDiffs(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		diffs := make(chan core.StateDiffEvent, 16)
+		sub := api.eth.blockchain.SubscribeStateDiffEvent(diffs)
+		defer sub.Unsubscribe()
+
+		for {
+			select {
+			case ev := <-diffs:
+				notifier.Notify(rpcSub.ID, &BlockStateDiff{
+					BlockHash:   ev.Block.Hash(),
+					BlockNumber: hexutil.Uint64(ev.Block.NumberU64()),
+					Public:      ev.Public,
+					Private:     ev.Private,
+				})
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			case <-sub.Err():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
+
 func (api *PrivateDebugAPI) getModifiedAccounts(startBlock, endBlock *types.Block) ([]common.Address, error) {
 	if startBlock.Number().Uint64() >= endBlock.Number().Uint64() {
 		return nil, fmt.Errorf("start block height (%d) must be less than end block height (%d)", startBlock.Number().Uint64(), endBlock.Number().Uint64())
@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"path/filepath"
 	"runtime"
 	"sync"
 	"sync/atomic"
@@ -33,6 +34,7 @@ import (
 	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/consensus/istanbul"
 	istanbulBackend "github.com/ethereum/go-ethereum/consensus/istanbul/backend"
+	"github.com/ethereum/go-ethereum/contracts/permission"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/bloombits"
 	"github.com/ethereum/go-ethereum/core/rawdb"
@@ -94,6 +96,8 @@ type Ethereum struct {
 	networkID     uint64
 	netRPCService *ethapi.PublicNetAPI
 
+	istanbulSigner *istanbulBackend.ExternalSigner // Clef connection signing Istanbul messages, if configured
+
 	lock sync.RWMutex // Protects the variadic fields (e.g. gas price and etherbase)
 }
 
@@ -122,7 +126,7 @@ func New(ctx *node.ServiceContext, config *Config) (*Ethereum, error) {
 		config.MinerGasPrice = new(big.Int).Set(DefaultConfig.MinerGasPrice)
 	}
 	// Assemble the Ethereum object
-	chainDb, err := CreateDB(ctx, config, "chaindata")
+	chainDb, err := CreateDBWithFreezer(ctx, config, "chaindata")
 	if err != nil {
 		return nil, err
 	}
@@ -160,9 +164,13 @@ func New(ctx *node.ServiceContext, config *Config) (*Ethereum, error) {
 		bloomIndexer:   NewBloomIndexer(chainDb, params.BloomBitsBlocks, params.BloomConfirms),
 	}
 
-	// force to set the istanbul etherbase to node key address
+	// force to set the istanbul etherbase to node key address, or the external
+	// signer if one is configured
 	if chainConfig.Istanbul != nil {
 		eth.etherbase = crypto.PubkeyToAddress(ctx.NodeKey().PublicKey)
+		if config.Istanbul.Signer != (common.Address{}) {
+			eth.etherbase = config.Istanbul.Signer
+		}
 	}
 
 	log.Info("Initialising Ethereum protocol", "versions", ProtocolVersions, "network", config.NetworkId)
@@ -180,7 +188,14 @@ func New(ctx *node.ServiceContext, config *Config) (*Ethereum, error) {
 			EWASMInterpreter:        config.EWASMInterpreter,
 			EVMInterpreter:          config.EVMInterpreter,
 		}
-		cacheConfig = &core.CacheConfig{Disabled: config.NoPruning, TrieNodeLimit: config.TrieCache, TrieTimeLimit: config.TrieTimeout}
+		cacheConfig = &core.CacheConfig{
+			Disabled:         config.NoPruning,
+			TrieNodeLimit:    config.TrieCache,
+			TrieTimeLimit:    config.TrieTimeout,
+			PrivateRetention: config.PrivateStateRetention,
+			ParallelTxs:      config.ParallelTxs,
+			StateDiffs:       config.StateDiffs,
+		}
 	)
 	eth.blockchain, err = core.NewBlockChain(chainDb, cacheConfig, eth.chainConfig, eth.engine, vmConfig, eth.shouldPreserve)
 	if err != nil {
@@ -194,6 +209,10 @@ func New(ctx *node.ServiceContext, config *Config) (*Ethereum, error) {
 	}
 	eth.bloomIndexer.Start(eth.blockchain)
 
+	if err := startFreezer(chainDb, chainConfig, config); err != nil {
+		return nil, err
+	}
+
 	if config.TxPool.Journal != "" {
 		config.TxPool.Journal = ctx.ResolvePath(config.TxPool.Journal)
 	}
@@ -239,12 +258,52 @@ func CreateDB(ctx *node.ServiceContext, config *Config, name string) (ethdb.Data
 	if err != nil {
 		return nil, err
 	}
-	if db, ok := db.(*ethdb.LDBDatabase); ok {
+	if db, ok := db.(interface{ Meter(prefix string) }); ok {
 		db.Meter("eth/db/chaindata/")
 	}
 	return db, nil
 }
 
+// CreateDBWithFreezer creates the chain database with an ancient store. Blocks
+// are only moved into it once freezing is started, see startFreezer.
+func CreateDBWithFreezer(ctx *node.ServiceContext, config *Config, name string) (ethdb.Database, error) {
+	db, err := CreateDB(ctx, config, name)
+	if err != nil {
+		return nil, err
+	}
+	freezer := config.DatabaseFreezer
+	if freezer == "" {
+		freezer = filepath.Join(name, "ancient")
+	}
+	// Ephemeral nodes keep everything in memory
+	if freezer = ctx.ResolvePath(freezer); freezer == "" {
+		return db, nil
+	}
+	frdb, err := rawdb.NewDatabaseWithFreezer(db, freezer)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return frdb, nil
+}
+
+// startFreezer starts moving the blocks more than config.FreezerThreshold blocks
+// behind the head into the ancient store of the chain database, if it has one. A
+// threshold of 0 keeps all blocks in the key-value store. Blocks of chains that
+// can be reorganised deeply, i.e. all but Raft and Istanbul ones, are only final
+// params.ImmutabilityThreshold blocks behind the head, so the threshold can't be
+// any lower.
+func startFreezer(db ethdb.Database, chainConfig *params.ChainConfig, config *Config) error {
+	freezer, ok := db.(rawdb.AncientFreezer)
+	if !ok || config.FreezerThreshold == 0 {
+		return nil
+	}
+	if !config.RaftMode && chainConfig.Istanbul == nil && config.FreezerThreshold < params.ImmutabilityThreshold {
+		return fmt.Errorf("freezer threshold %d below the immutability threshold %d, use 0 to disable freezing", config.FreezerThreshold, params.ImmutabilityThreshold)
+	}
+	return freezer.Freeze(config.FreezerThreshold)
+}
+
 // CreateConsensusEngine creates the required type of consensus engine instance for an Ethereum service
 func CreateConsensusEngine(ctx *node.ServiceContext, chainConfig *params.ChainConfig, config *Config, notify []string, noverify bool, db ethdb.Database) consensus.Engine {
 	// If proof-of-authority is requested, set it up
@@ -257,6 +316,17 @@ func CreateConsensusEngine(ctx *node.ServiceContext, chainConfig *params.ChainCo
 			config.Istanbul.Epoch = chainConfig.Istanbul.Epoch
 		}
 		config.Istanbul.ProposerPolicy = istanbul.ProposerPolicy(chainConfig.Istanbul.ProposerPolicy)
+		config.Istanbul.ProposerWeights = chainConfig.Istanbul.Weights
+		config.Istanbul.EmptyBlockPeriod = chainConfig.Istanbul.EmptyBlockPeriod
+		config.Istanbul.Transitions = nil
+		for _, t := range chainConfig.Istanbul.Transitions {
+			config.Istanbul.Transitions = append(config.Istanbul.Transitions, istanbul.Transition{
+				Block:          t.Block,
+				Validators:     t.Validators,
+				BlockPeriod:    t.BlockPeriod,
+				RequestTimeout: t.RequestTimeout,
+			})
+		}
 		return istanbulBackend.New(&config.Istanbul, ctx.NodeKey(), db)
 	}
 
@@ -431,6 +501,36 @@ func (s *Ethereum) SetEtherbase(etherbase common.Address) {
 	s.miner.SetEtherbase(etherbase)
 }
 
+// istanbulAuthorizer is implemented by the Istanbul engine to sign with an
+// account of the account manager instead of the node key.
+type istanbulAuthorizer interface {
+	Authorize(signer common.Address, signFn istanbulBackend.SignerFn) error
+}
+
+// istanbulSignerFn returns the callback signing Istanbul seals and messages with
+// the signer account, either through clef if an endpoint is configured or a
+// wallet of the account manager.
+func (s *Ethereum) istanbulSignerFn(signer common.Address) (istanbulBackend.SignerFn, error) {
+	if s.config.Istanbul.SignerEndpoint == "" {
+		wallet, err := s.accountManager.Find(accounts.Account{Address: signer})
+		if wallet == nil || err != nil {
+			return nil, err
+		}
+		return istanbulBackend.WalletSigner(wallet), nil
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.istanbulSigner == nil {
+		external, err := istanbulBackend.NewExternalSigner(s.config.Istanbul.SignerEndpoint)
+		if err != nil {
+			return nil, err
+		}
+		s.istanbulSigner = external
+	}
+	return s.istanbulSigner.SignData, nil
+}
+
 // StartMining starts the miner with the given number of CPU threads. If mining
 // is already running, this method adjust the number of threads allowed to use
 // and updates the minimum price required by the transaction pool.
@@ -468,6 +568,16 @@ func (s *Ethereum) StartMining(threads int) error {
 			}
 			clique.Authorize(eb, wallet.SignHash)
 		}
+		if istanbul, ok := s.engine.(istanbulAuthorizer); ok && s.config.Istanbul.Signer != (common.Address{}) {
+			signFn, err := s.istanbulSignerFn(eb)
+			if err != nil {
+				log.Error("Istanbul signer account unavailable", "err", err)
+				return fmt.Errorf("signer missing: %v", err)
+			}
+			if err := istanbul.Authorize(eb, signFn); err != nil {
+				return err
+			}
+		}
 		// If mining is started, we can disable the transaction rejection mechanism
 		// introduced to speed sync times.
 		atomic.StoreUint32(&s.protocolManager.acceptTxs, 1)
@@ -531,6 +641,17 @@ func (s *Ethereum) Start(srvr *p2p.Server) error {
 		}
 		maxPeers -= s.config.LightPeers
 	}
+	// Admit peers based on the node registry contract if one is configured
+	if s.config.NodePermissionContract != (common.Address{}) {
+		registry, err := permission.NewNodeRegistry(s.config.NodePermissionContract, newLocalContractCaller(s.APIBackend))
+		if err != nil {
+			return err
+		}
+		srvr.SetNodePermissioner(registry)
+		log.Info("Using node registry contract for permissioning", "address", s.config.NodePermissionContract)
+
+		go s.permissionLoop(srvr)
+	}
 	// Start the networking layer and the light server if requested
 	s.protocolManager.Start(maxPeers)
 	if s.lesServer != nil {
@@ -539,6 +660,25 @@ func (s *Ethereum) Start(srvr *p2p.Server) error {
 	return nil
 }
 
+// permissionLoop asks the p2p server to re-evaluate its peers on every new chain
+// head, as any block may have changed the node registry.
+func (s *Ethereum) permissionLoop(srvr *p2p.Server) {
+	heads := make(chan core.ChainHeadEvent, 16)
+	sub := s.blockchain.SubscribeChainHeadEvent(heads)
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case <-heads:
+			srvr.RecheckPermissions()
+		case <-sub.Err():
+			return
+		case <-s.shutdownChan:
+			return
+		}
+	}
+}
+
 // Stop implements node.Service, terminating all internal goroutines used by the
 // Ethereum protocol.
 func (s *Ethereum) Stop() error {
@@ -553,6 +693,9 @@ func (s *Ethereum) Stop() error {
 	s.miner.Stop()
 	s.eventMux.Stop()
 
+	if s.istanbulSigner != nil {
+		s.istanbulSigner.Close()
+	}
 	s.chainDb.Close()
 	close(s.shutdownChan)
 	return nil
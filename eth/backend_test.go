@@ -0,0 +1,63 @@
+// Copyright 2015 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tests that blocks are only frozen far enough behind the head for chains that
+// may be reorganised deeply.
+func TestStartFreezer(t *testing.T) {
+	istanbul := *params.QuorumTestChainConfig
+	istanbul.Istanbul = &params.IstanbulConfig{}
+
+	tests := []struct {
+		config    *params.ChainConfig
+		raft      bool
+		threshold uint64
+		fail      bool
+	}{
+		{params.TestChainConfig, false, 0, false},
+		{params.TestChainConfig, false, 128, true},
+		{params.TestChainConfig, false, params.ImmutabilityThreshold, false},
+		{params.QuorumTestChainConfig, true, 128, false},
+		{&istanbul, false, 128, false},
+	}
+	for i, tt := range tests {
+		dir, err := ioutil.TempDir("", "freezer")
+		if err != nil {
+			t.Fatal(err)
+		}
+		db, err := rawdb.NewDatabaseWithFreezer(ethdb.NewMemDatabase(), dir)
+		if err != nil {
+			t.Fatalf("test %d: failed to open freezer: %v", i, err)
+		}
+		err = startFreezer(db, tt.config, &Config{RaftMode: tt.raft, FreezerThreshold: tt.threshold})
+		if (err != nil) != tt.fail {
+			t.Errorf("test %d: error mismatch: have %v, want failure %v", i, err, tt.fail)
+		}
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package eth
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// localContractCaller implements bind.ContractCaller on top of the local chain,
+// allowing contract bindings to be queried from within the node without going
+// through the RPC layer.
+type localContractCaller struct {
+	api *ethapi.PublicBlockChainAPI
+}
+
+func newLocalContractCaller(b ethapi.Backend) *localContractCaller {
+	return &localContractCaller{api: ethapi.NewPublicBlockChainAPI(b)}
+}
+
+// CodeAt returns the code of the given account at the given block, or the
+// latest block if blockNumber is nil.
+func (c *localContractCaller) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return c.api.GetCode(ctx, contract, toBlockNumber(blockNumber))
+}
+
+// CallContract executes a message call against the state of the given block, or
+// the latest block if blockNumber is nil.
+func (c *localContractCaller) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	args := ethapi.CallArgs{
+		From: call.From,
+		To:   call.To,
+		Gas:  hexutil.Uint64(call.Gas),
+		Data: call.Data,
+	}
+	if call.GasPrice != nil {
+		args.GasPrice = hexutil.Big(*call.GasPrice)
+	}
+	if call.Value != nil {
+		args.Value = hexutil.Big(*call.Value)
+	}
+	return c.api.Call(ctx, args, toBlockNumber(blockNumber))
+}
+
+func toBlockNumber(number *big.Int) rpc.BlockNumber {
+	if number == nil {
+		return rpc.LatestBlockNumber
+	}
+	return rpc.BlockNumber(number.Int64())
+}
@@ -49,6 +49,7 @@ var DefaultConfig = Config{
 	DatabaseCache: 768,
 	TrieCache:     256,
 	TrieTimeout:   60 * time.Minute,
+
 	MinerGasFloor: 8000000,
 	MinerGasCeil:  8000000,
 	MinerGasPrice: big.NewInt(params.GWei),
@@ -89,6 +90,18 @@ type Config struct {
 	SyncMode  downloader.SyncMode
 	NoPruning bool
 
+	// Number of recent blocks whose private state is kept in memory before
+	// being pruned, 0 archives every private state
+	PrivateStateRetention uint64
+
+	// Number of transactions executed speculatively in parallel on block import
+	// on Quorum chains, 0 or 1 executes them serially
+	ParallelTxs int
+
+	// Whether to record the public and private state diffs of every block, to be
+	// served by debug_stateDiff
+	StateDiffs bool
+
 	// Light client options
 	LightServ  int `toml:",omitempty"` // Maximum percentage of time allowed for serving LES requests
 	LightPeers int `toml:",omitempty"` // Maximum number of LES client peers
@@ -97,6 +110,8 @@ type Config struct {
 	SkipBcVersionCheck bool `toml:"-"`
 	DatabaseHandles    int  `toml:"-"`
 	DatabaseCache      int
+	DatabaseFreezer    string `toml:",omitempty"` // Directory of the ancient store, default is "ancient" inside the chain database
+	FreezerThreshold   uint64 // Number of recent blocks kept in the chain database before moving them to the ancient store, 0 = never, at least params.ImmutabilityThreshold unless Raft or Istanbul
 	TrieCache          int
 	TrieTimeout        time.Duration
 
@@ -124,6 +139,11 @@ type Config struct {
 
 	RaftMode             bool
 	EnableNodePermission bool
+
+	// Node registry contract used for node permissioning, if set it replaces
+	// the permissioned-nodes.json file
+	NodePermissionContract common.Address `toml:",omitempty"`
+
 	// Istanbul options
 	Istanbul istanbul.Config
 
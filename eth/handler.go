@@ -63,8 +63,19 @@ var (
 // not compatible (low protocol version restrictions and high requirements).
 var errIncompatibleConfig = errors.New("incompatible configuration")
 
+// protocolError is returned for messages breaching the protocol. Peers sending
+// them are reported as faulty.
+type protocolError struct {
+	code errCode
+	msg  string
+}
+
+func (e *protocolError) Error() string {
+	return fmt.Sprintf("%v - %v", e.code, e.msg)
+}
+
 func errResp(code errCode, format string, v ...interface{}) error {
-	return fmt.Errorf("%v - %v", code, fmt.Sprintf(format, v...))
+	return &protocolError{code, fmt.Sprintf(format, v...)}
 }
 
 type ProtocolManager struct {
@@ -174,7 +185,7 @@ func NewProtocolManager(config *params.ChainConfig, mode downloader.SyncMode, ne
 		return nil, errIncompatibleConfig
 	}
 	// Construct the different synchronisation mechanisms
-	manager.downloader = downloader.New(mode, chaindb, manager.eventMux, blockchain, nil, manager.removePeer)
+	manager.downloader = downloader.New(mode, chaindb, manager.eventMux, blockchain, nil, manager.dropFaultyPeer(p2p.FaultMinor, "failed chain sync"))
 
 	validator := func(header *types.Header) error {
 		return engine.VerifyHeader(blockchain, header, true)
@@ -191,7 +202,7 @@ func NewProtocolManager(config *params.ChainConfig, mode downloader.SyncMode, ne
 		atomic.StoreUint32(&manager.acceptTxs, 1) // Mark initial sync done on any fetcher import
 		return manager.blockchain.InsertChain(blocks)
 	}
-	manager.fetcher = fetcher.New(blockchain.GetBlockByHash, validator, manager.BroadcastBlock, heighter, inserter, manager.removePeer)
+	manager.fetcher = fetcher.New(blockchain.GetBlockByHash, validator, manager.BroadcastBlock, heighter, inserter, manager.dropFaultyPeer(p2p.FaultSevere, "invalid propagated block"))
 
 	return manager, nil
 }
@@ -215,6 +226,18 @@ func (pm *ProtocolManager) removePeer(id string) {
 	}
 }
 
+// dropFaultyPeer returns a callback removing a peer after reporting a fault of
+// the given severity for it. Static and trusted peers are only removed, they are
+// exempt from automatic bans.
+func (pm *ProtocolManager) dropFaultyPeer(severity int, reason string) func(id string) {
+	return func(id string) {
+		if peer := pm.peers.Peer(id); peer != nil {
+			peer.Peer.ReportFault(severity, reason)
+		}
+		pm.removePeer(id)
+	}
+}
+
 func (pm *ProtocolManager) Start(maxPeers int) {
 	pm.maxPeers = maxPeers
 
@@ -309,6 +332,11 @@ func (pm *ProtocolManager) handle(p *peer) error {
 	// after this will be sent via broadcasts.
 	pm.syncTransactions(p)
 
+	// Quorum: let the peer know the validator account we sign with, if not our node key
+	if validators, ok := pm.engine.(consensus.ValidatorPeers); ok {
+		validators.AnnounceValidator(p)
+	}
+
 	// If we're DAO hard-fork aware, validate any remote peer with regard to the hard-fork
 	if daoBlock := pm.chainconfig.DAOForkBlock; daoBlock != nil {
 		// Request the peer's DAO fork header for extra-data validation
@@ -332,6 +360,9 @@ func (pm *ProtocolManager) handle(p *peer) error {
 	for {
 		if err := pm.handleMsg(p); err != nil {
 			p.Log().Debug("Ethereum message handling failed", "err", err)
+			if _, ok := err.(*protocolError); ok {
+				p.Peer.ReportFault(p2p.FaultSevere, err.Error())
+			}
 			return err
 		}
 	}
@@ -357,6 +388,10 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 
 			log.Info("raft: ignoring message", "code", msg.Code)
 
+			// Raft blocks are only propagated through the raft log
+			if msg.Code == NewBlockMsg || msg.Code == NewBlockHashesMsg {
+				p.Peer.ReportFault(p2p.FaultMinor, "raft: block announcement")
+			}
 			return nil
 		}
 	} else if handler, ok := pm.engine.(consensus.Handler); ok {
@@ -631,6 +666,11 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 				if header := pm.blockchain.GetHeaderByHash(hash); header == nil || header.ReceiptHash != types.EmptyRootHash {
 					continue
 				}
+			} else if block := pm.blockchain.GetBlockByHash(hash); block != nil {
+				// Quorum: don't leak the private receipts of private transactions
+				if results = core.PublicReceipts(pm.chainconfig, block, results); results == nil {
+					continue
+				}
 			}
 			// If known, encode and queue for response packet
 			if encoded, err := rlp.EncodeToBytes(results); err != nil {
@@ -845,11 +885,23 @@ func (pm *ProtocolManager) NodeInfo() *NodeInfo {
 	}
 }
 
+// FindPeers retrieves the peers of the given validators. Peers are known by their
+// node key, unless they announced another validator account they sign with.
 func (self *ProtocolManager) FindPeers(targets map[common.Address]bool) map[common.Address]consensus.Peer {
+	validators, _ := self.engine.(consensus.ValidatorPeers)
+
 	m := make(map[common.Address]consensus.Peer)
 	for _, p := range self.peers.Peers() {
 		pubKey := p.Node().Pubkey()
+		if pubKey == nil {
+			continue
+		}
 		addr := crypto.PubkeyToAddress(*pubKey)
+		if validators != nil {
+			if validator, ok := validators.PeerValidator(addr); ok {
+				addr = validator
+			}
+		}
 		if targets[addr] {
 			m[addr] = p
 		}
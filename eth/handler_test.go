@@ -17,6 +17,7 @@
 package eth
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"math/big"
@@ -24,9 +25,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/consensus/istanbul"
+	istanbulBackend "github.com/ethereum/go-ethereum/consensus/istanbul/backend"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -36,6 +40,7 @@ import (
 	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/params"
 )
 
@@ -614,3 +619,70 @@ outer:
 		t.Errorf("block broadcast to %d peers, expected %d", receivedCount, broadcastExpected)
 	}
 }
+
+// msgPeer is a consensus peer writing its messages to a message pipe.
+type msgPeer struct {
+	rw p2p.MsgWriter
+}
+
+func (p *msgPeer) Send(msgcode uint64, data interface{}) error {
+	return p2p.Send(p.rw, msgcode, data)
+}
+
+// Tests that the peer of a validator signing with an account other than its node
+// key is found by that account once it was announced.
+func TestFindPeersAnnouncedValidator(t *testing.T) {
+	pm, _ := newTestProtocolManagerMust(t, downloader.FullSync, 0, nil, nil)
+	defer pm.Stop()
+
+	localKey, _ := crypto.GenerateKey()
+	pm.engine = istanbulBackend.New(istanbul.DefaultConfig, localKey, ethdb.NewMemDatabase())
+
+	// Create a remote validator whose account differs from its node key
+	nodeKey, _ := crypto.GenerateKey()
+	signerKey, _ := crypto.GenerateKey()
+	node, signer := crypto.PubkeyToAddress(nodeKey.PublicKey), crypto.PubkeyToAddress(signerKey.PublicKey)
+
+	remote := istanbulBackend.New(istanbul.DefaultConfig, nodeKey, ethdb.NewMemDatabase())
+	signFn := func(ctx context.Context, account accounts.Account, data []byte) ([]byte, error) {
+		return crypto.Sign(crypto.Keccak256(data), signerKey)
+	}
+	if err := remote.(istanbulAuthorizer).Authorize(signer, signFn); err != nil {
+		t.Fatalf("failed to authorize remote signer: %v", err)
+	}
+	p, _ := newTestPeerFrom(p2p.NewPeerFromNode(enode.NewV4(&nodeKey.PublicKey, nil, 0, 0, 0), "peer", nil), eth63, pm, true)
+	defer p.close()
+
+	// Until the account is announced the peer is only known by its node key
+	for i := 0; ; i++ {
+		if peers := pm.FindPeers(map[common.Address]bool{node: true}); len(peers) == 1 {
+			break
+		}
+		if i == 100 {
+			t.Fatalf("peer not registered by its node key")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if peers := pm.FindPeers(map[common.Address]bool{signer: true}); len(peers) != 0 {
+		t.Fatalf("validator found before its announcement: %v", peers)
+	}
+	// Announce the account through the peer and wait for it to be found by it
+	remote.(consensus.ValidatorPeers).AnnounceValidator(&msgPeer{p.app})
+
+	for i := 0; ; i++ {
+		peers := pm.FindPeers(map[common.Address]bool{signer: true})
+		if found, ok := peers[signer]; ok {
+			if found.(*peer).id != p.id {
+				t.Fatalf("validator peer mismatch: have %v, want %v", found.(*peer).id, p.id)
+			}
+			break
+		}
+		if i == 100 {
+			t.Fatalf("announced validator not found")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if peers := pm.FindPeers(map[common.Address]bool{node: true}); len(peers) != 0 {
+		t.Fatalf("validator still found by its node key: %v", peers)
+	}
+}
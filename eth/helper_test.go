@@ -144,14 +144,20 @@ type testPeer struct {
 
 // newTestPeer creates a new peer registered at the given protocol manager.
 func newTestPeer(name string, version int, pm *ProtocolManager, shake bool) (*testPeer, <-chan error) {
-	// Create a message pipe to communicate through
-	app, net := p2p.MsgPipe()
-
 	// Generate a random id and create the peer
 	var id enode.ID
 	rand.Read(id[:])
 
-	peer := pm.newPeer(version, p2p.NewPeer(id, name, nil), net)
+	return newTestPeerFrom(p2p.NewPeer(id, name, nil), version, pm, shake)
+}
+
+// newTestPeerFrom creates a new peer registered at the given protocol manager,
+// backed by the given p2p peer.
+func newTestPeerFrom(p *p2p.Peer, version int, pm *ProtocolManager, shake bool) (*testPeer, <-chan error) {
+	// Create a message pipe to communicate through
+	app, net := p2p.MsgPipe()
+
+	peer := pm.newPeer(version, p, net)
 
 	// Start the peer on a new thread
 	errc := make(chan error, 1)
@@ -0,0 +1,343 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// +build !js
+
+package ethdb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "github.com/coreos/bbolt"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	boltFile      = "bolt.db" // Database file inside the database directory
+	boltChunkSize = 1024      // Number of keys read or deleted per transaction
+)
+
+var (
+	boltBucket      = []byte("ethdb")
+	errBoltNotFound = errors.New("not found")
+)
+
+func init() {
+	RegisterEngine("bolt", func(file string, cache int, handles int) (Database, error) {
+		return NewBoltDatabase(file, cache, handles)
+	}, func(file string) bool {
+		_, err := os.Stat(filepath.Join(file, boltFile))
+		return err == nil
+	})
+}
+
+// boltKey prepends a marker byte to a database key, since bbolt doesn't accept
+// empty keys. The marker doesn't change the ordering of the keys.
+func boltKey(key []byte) []byte {
+	return append([]byte{0}, key...)
+}
+
+// BoltDatabase is a persistent database backed by a single bbolt B+tree file.
+// Compared to LevelDB it needs no compaction and has predictable read latency,
+// at the cost of slower random writes. bbolt was picked over Pebble or Badger as
+// it is pure Go, has no dependencies and builds with the Go releases this tree
+// supports, which neither of them does.
+//
+// Every write is a bbolt transaction fsynced on commit, costing two fsyncs. The
+// file isn't opened with NoSync, as an unsynced commit lost in an OS crash or
+// power loss may leave the file corrupted rather than just missing the write, so
+// bulk writes should go through a batch instead.
+type BoltDatabase struct {
+	fn string   // filename for reporting
+	db *bolt.DB // bbolt instance
+
+	log log.Logger // Contextual logger tracking the database path
+}
+
+// NewBoltDatabase opens or creates a bbolt database in the given directory. The
+// cache and file handle allowances are unused, bbolt relies on the OS page cache.
+func NewBoltDatabase(file string, cache int, handles int) (*BoltDatabase, error) {
+	logger := log.New("database", file)
+
+	if err := os.MkdirAll(file, 0700); err != nil {
+		return nil, err
+	}
+	db, err := bolt.Open(filepath.Join(file, boltFile), 0600, &bolt.Options{
+		Timeout:        time.Second,
+		NoFreelistSync: true,
+		FreelistType:   bolt.FreelistMapType,
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(boltBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	logger.Info("Opened bolt database")
+	return &BoltDatabase{
+		fn:  file,
+		db:  db,
+		log: logger,
+	}, nil
+}
+
+// Path returns the path to the database directory.
+func (db *BoltDatabase) Path() string {
+	return db.fn
+}
+
+func (db *BoltDatabase) Put(key []byte, value []byte) error {
+	return db.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltBucket).Put(boltKey(key), common.CopyBytes(value))
+	})
+}
+
+func (db *BoltDatabase) Has(key []byte) (bool, error) {
+	var has bool
+	err := db.db.View(func(tx *bolt.Tx) error {
+		has = tx.Bucket(boltBucket).Get(boltKey(key)) != nil
+		return nil
+	})
+	return has, err
+}
+
+func (db *BoltDatabase) Get(key []byte) ([]byte, error) {
+	var value []byte
+	err := db.db.View(func(tx *bolt.Tx) error {
+		blob := tx.Bucket(boltBucket).Get(boltKey(key))
+		if blob == nil {
+			return errBoltNotFound
+		}
+		// Values are only valid during the transaction
+		value = common.CopyBytes(blob)
+		return nil
+	})
+	return value, err
+}
+
+func (db *BoltDatabase) Delete(key []byte) error {
+	return db.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(boltBucket).Delete(boltKey(key))
+	})
+}
+
+// NewIteratorWithPrefix returns an iterator over the keys starting with prefix.
+// The keys are read in chunks, each in its own transaction, so the iterator
+// doesn't see a consistent snapshot if the database is written concurrently.
+func (db *BoltDatabase) NewIteratorWithPrefix(prefix []byte) Iterator {
+	return &boltIterator{
+		db:     db.db,
+		prefix: boltKey(prefix),
+		next:   boltKey(prefix),
+		pos:    -1,
+	}
+}
+
+// DeleteRange deletes all keys in [start, limit) in chunks of boltChunkSize keys.
+func (db *BoltDatabase) DeleteRange(start, limit []byte) error {
+	var done bool
+	for !done {
+		err := db.db.Update(func(tx *bolt.Tx) error {
+			bucket := tx.Bucket(boltBucket)
+
+			var keys [][]byte
+			c := bucket.Cursor()
+			for k, _ := c.Seek(boltKey(start)); ; k, _ = c.Next() {
+				if k == nil || (limit != nil && bytes.Compare(k[1:], limit) >= 0) {
+					done = true
+					break
+				}
+				if len(keys) == boltChunkSize {
+					break
+				}
+				keys = append(keys, common.CopyBytes(k))
+			}
+			for _, key := range keys {
+				if err := bucket.Delete(key); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Stat returns the bbolt "stats" of the database: the freelist, transaction and
+// B+tree statistics.
+func (db *BoltDatabase) Stat(property string) (string, error) {
+	if property != "" && property != "stats" {
+		return "", fmt.Errorf("unknown bolt database property %q", property)
+	}
+	stats := db.db.Stats()
+
+	var tree bolt.BucketStats
+	err := db.db.View(func(tx *bolt.Tx) error {
+		tree = tx.Bucket(boltBucket).Stats()
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Keys: %d\nDepth: %d\nBranch pages: %d (%d bytes in use)\nLeaf pages: %d (%d bytes in use)\n"+
+		"Free pages: %d\nPending pages: %d\nRead transactions: %d (%d open)\n",
+		tree.KeyN, tree.Depth, tree.BranchPageN, tree.BranchInuse, tree.LeafPageN, tree.LeafInuse,
+		stats.FreePageN, stats.PendingPageN, stats.TxN, stats.OpenTxN), nil
+}
+
+// Compact is a no-op, bbolt reuses freed pages in place and needs no compaction.
+func (db *BoltDatabase) Compact(start, limit []byte) error {
+	return nil
+}
+
+func (db *BoltDatabase) Close() {
+	if err := db.db.Close(); err == nil {
+		db.log.Info("Database closed")
+	} else {
+		db.log.Error("Failed to close database", "err", err)
+	}
+}
+
+func (db *BoltDatabase) NewBatch() Batch {
+	return &boltBatch{db: db.db}
+}
+
+// boltIterator walks a key range in chunks read in separate transactions, so
+// that a long iteration doesn't keep a read transaction open and block writers
+// from growing the database file.
+type boltIterator struct {
+	db     *bolt.DB
+	prefix []byte // Encoded prefix of the iterated keys
+	next   []byte // Encoded key to resume the iteration from
+
+	keys   [][]byte
+	values [][]byte
+	pos    int  // Position of the current entry in the chunk
+	done   bool // Whether the last chunk was read
+	err    error
+}
+
+func (it *boltIterator) Next() bool {
+	if it.pos+1 < len(it.keys) {
+		it.pos++
+		return true
+	}
+	if it.done || it.err != nil {
+		return false
+	}
+	it.keys, it.values, it.pos = nil, nil, -1
+	it.err = it.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(boltBucket).Cursor()
+		for k, v := c.Seek(it.next); k != nil && bytes.HasPrefix(k, it.prefix); k, v = c.Next() {
+			if len(it.keys) == boltChunkSize {
+				it.next = common.CopyBytes(k)
+				return nil
+			}
+			it.keys = append(it.keys, common.CopyBytes(k[1:]))
+			it.values = append(it.values, common.CopyBytes(v))
+		}
+		it.done = true
+		return nil
+	})
+	if it.err != nil || len(it.keys) == 0 {
+		return false
+	}
+	it.pos = 0
+	return true
+}
+
+func (it *boltIterator) Key() []byte {
+	if it.pos < 0 || it.pos >= len(it.keys) {
+		return nil
+	}
+	return it.keys[it.pos]
+}
+
+func (it *boltIterator) Value() []byte {
+	if it.pos < 0 || it.pos >= len(it.values) {
+		return nil
+	}
+	return it.values[it.pos]
+}
+
+func (it *boltIterator) Error() error { return it.err }
+
+func (it *boltIterator) Release() {
+	it.keys, it.values, it.done = nil, nil, true
+}
+
+// boltBatch buffers writes and applies them in a single bbolt transaction.
+type boltBatch struct {
+	db     *bolt.DB
+	writes []kv
+	size   int
+}
+
+func (b *boltBatch) Put(key, value []byte) error {
+	b.writes = append(b.writes, kv{boltKey(key), common.CopyBytes(value), false})
+	b.size += len(value)
+	return nil
+}
+
+func (b *boltBatch) Delete(key []byte) error {
+	b.writes = append(b.writes, kv{boltKey(key), nil, true})
+	b.size += 1
+	return nil
+}
+
+func (b *boltBatch) Write() error {
+	if len(b.writes) == 0 {
+		return nil
+	}
+	return b.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltBucket)
+		for _, kv := range b.writes {
+			if kv.del {
+				if err := bucket.Delete(kv.k); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := bucket.Put(kv.k, kv.v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (b *boltBatch) ValueSize() int {
+	return b.size
+}
+
+func (b *boltBatch) Reset() {
+	b.writes = b.writes[:0]
+	b.size = 0
+}
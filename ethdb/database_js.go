@@ -56,6 +56,22 @@ func (db *LDBDatabase) Delete(key []byte) error {
 	return errNotSupported
 }
 
+func (db *LDBDatabase) NewIteratorWithPrefix(prefix []byte) Iterator {
+	return nil
+}
+
+func (db *LDBDatabase) DeleteRange(start, limit []byte) error {
+	return errNotSupported
+}
+
+func (db *LDBDatabase) Stat(property string) (string, error) {
+	return "", errNotSupported
+}
+
+func (db *LDBDatabase) Compact(start, limit []byte) error {
+	return errNotSupported
+}
+
 func (db *LDBDatabase) Close() {
 }
 
@@ -23,6 +23,8 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"reflect"
+	"sort"
 	"strconv"
 	"sync"
 	"testing"
@@ -46,6 +48,22 @@ func newTestLDB() (*ethdb.LDBDatabase, func()) {
 	}
 }
 
+func newTestBoltDB() (*ethdb.BoltDatabase, func()) {
+	dirname, err := ioutil.TempDir(os.TempDir(), "ethdb_test_")
+	if err != nil {
+		panic("failed to create test file: " + err.Error())
+	}
+	db, err := ethdb.NewBoltDatabase(dirname, 0, 0)
+	if err != nil {
+		panic("failed to create test database: " + err.Error())
+	}
+
+	return db, func() {
+		db.Close()
+		os.RemoveAll(dirname)
+	}
+}
+
 var test_values = []string{"", "a", "1251", "\x00123\x00"}
 
 func TestLDB_PutGet(t *testing.T) {
@@ -54,6 +72,12 @@ func TestLDB_PutGet(t *testing.T) {
 	testPutGet(db, t)
 }
 
+func TestBoltDB_PutGet(t *testing.T) {
+	db, remove := newTestBoltDB()
+	defer remove()
+	testPutGet(db, t)
+}
+
 func TestMemoryDB_PutGet(t *testing.T) {
 	testPutGet(ethdb.NewMemDatabase(), t)
 }
@@ -153,6 +177,12 @@ func TestLDB_ParallelPutGet(t *testing.T) {
 	testParallelPutGet(db, t)
 }
 
+func TestBoltDB_ParallelPutGet(t *testing.T) {
+	db, remove := newTestBoltDB()
+	defer remove()
+	testParallelPutGet(db, t)
+}
+
 func TestMemoryDB_ParallelPutGet(t *testing.T) {
 	testParallelPutGet(ethdb.NewMemDatabase(), t)
 }
@@ -212,3 +242,118 @@ func testParallelPutGet(db ethdb.Database, t *testing.T) {
 	}
 	pending.Wait()
 }
+
+func TestLDB_IterateDeleteRange(t *testing.T) {
+	db, remove := newTestLDB()
+	defer remove()
+	testIterateDeleteRange(db, t)
+}
+
+func TestBoltDB_IterateDeleteRange(t *testing.T) {
+	db, remove := newTestBoltDB()
+	defer remove()
+	testIterateDeleteRange(db, t)
+}
+
+func TestMemoryDB_IterateDeleteRange(t *testing.T) {
+	testIterateDeleteRange(ethdb.NewMemDatabase(), t)
+}
+
+func TestTable_IterateDeleteRange(t *testing.T) {
+	db := ethdb.NewMemDatabase()
+	db.Put([]byte("ta"), []byte("outside"))
+	db.Put([]byte("tc"), []byte("outside"))
+	testIterateDeleteRange(ethdb.NewTable(db, "tb"), t)
+
+	for _, key := range []string{"ta", "tc"} {
+		if _, err := db.Get([]byte(key)); err != nil {
+			t.Errorf("key %q outside the table deleted", key)
+		}
+	}
+}
+
+func testIterateDeleteRange(db ethdb.Database, t *testing.T) {
+	// Insert enough keys to span several iterator chunks
+	var keys []string
+	for i := 0; i < 3000; i++ {
+		key := fmt.Sprintf("a%05d", i)
+		if i%2 == 1 {
+			key = fmt.Sprintf("b%05d", i)
+		}
+		if err := db.Put([]byte(key), []byte("v"+key)); err != nil {
+			t.Fatalf("put failed: %v", err)
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	check := func(prefix string, want []string) {
+		it := db.NewIteratorWithPrefix([]byte(prefix))
+		defer it.Release()
+
+		var have []string
+		for it.Next() {
+			if !bytes.Equal(it.Value(), []byte("v"+string(it.Key()))) {
+				t.Fatalf("value mismatch for key %q: %q", it.Key(), it.Value())
+			}
+			have = append(have, string(it.Key()))
+		}
+		if err := it.Error(); err != nil {
+			t.Fatalf("iteration failed: %v", err)
+		}
+		if !reflect.DeepEqual(have, want) {
+			t.Fatalf("prefix %q: iterated %d keys, want %d", prefix, len(have), len(want))
+		}
+	}
+	check("", keys)
+	check("b", keys[1500:])
+	check("a001", keys[50:100])
+
+	// Delete a bounded and an unbounded range
+	if err := db.DeleteRange([]byte("a00100"), []byte("a02000")); err != nil {
+		t.Fatalf("delete range failed: %v", err)
+	}
+	if err := db.DeleteRange([]byte("b01000"), nil); err != nil {
+		t.Fatalf("delete range failed: %v", err)
+	}
+	check("a", append(append([]string{}, keys[:50]...), keys[1000:1500]...))
+	check("b", keys[1500:2000])
+}
+
+func TestOpenEngine(t *testing.T) {
+	dirname, err := ioutil.TempDir(os.TempDir(), "ethdb_test_")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dirname)
+
+	db, err := ethdb.Open("bolt", dirname, 0, 0)
+	if err != nil {
+		t.Fatalf("failed to create bolt database: %v", err)
+	}
+	db.Put([]byte("key"), []byte("value"))
+	db.Close()
+
+	if engine := ethdb.DetectEngine(dirname); engine != "bolt" {
+		t.Fatalf("detected engine %q, want bolt", engine)
+	}
+	if _, err := ethdb.Open("leveldb", dirname, 0, 0); err == nil {
+		t.Fatalf("opened bolt database with leveldb")
+	}
+	if _, err := ethdb.Open("unknown", dirname+"-new", 0, 0); err == nil {
+		t.Fatalf("opened database with unknown engine")
+	}
+	db, err = ethdb.Open("", dirname, 0, 0)
+	if err != nil {
+		t.Fatalf("failed to reopen database: %v", err)
+	}
+	defer db.Close()
+
+	copied := ethdb.NewMemDatabase()
+	if n, err := ethdb.Copy(copied, db); n != 1 || err != nil {
+		t.Fatalf("copy failed: copied %d, err %v", n, err)
+	}
+	if value, _ := copied.Get([]byte("key")); string(value) != "value" {
+		t.Fatalf("copied value mismatch: %q", value)
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethdb
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultEngine is the backend used for new databases if none is requested.
+const DefaultEngine = "leveldb"
+
+// OpenFunc opens or creates a persistent database in the given directory.
+type OpenFunc func(file string, cache int, handles int) (Database, error)
+
+// engine is a registered persistent database backend.
+type engine struct {
+	open   OpenFunc
+	exists func(file string) bool // Reports whether the directory holds a database of this engine
+}
+
+var (
+	enginesLock sync.RWMutex
+	engines     = make(map[string]engine)
+)
+
+// RegisterEngine makes a database backend available under the given name.
+// The exists function must report whether a directory already holds a
+// database created by the backend, so that it is never opened by another one.
+func RegisterEngine(name string, open OpenFunc, exists func(file string) bool) {
+	enginesLock.Lock()
+	defer enginesLock.Unlock()
+
+	if _, ok := engines[name]; ok {
+		panic(fmt.Sprintf("ethdb: engine %q registered twice", name))
+	}
+	engines[name] = engine{open: open, exists: exists}
+}
+
+// Engines returns the names of the registered database backends.
+func Engines() []string {
+	enginesLock.RLock()
+	defer enginesLock.RUnlock()
+
+	names := make([]string, 0, len(engines))
+	for name := range engines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DetectEngine returns the backend of the database in the given directory, or
+// an empty string if there is none.
+func DetectEngine(file string) string {
+	enginesLock.RLock()
+	defer enginesLock.RUnlock()
+
+	for name, engine := range engines {
+		if engine.exists(file) {
+			return name
+		}
+	}
+	return ""
+}
+
+// Open opens the database in the given directory, creating it with the named
+// backend if it doesn't exist. An empty name selects the backend of the existing
+// database, or DefaultEngine for a new one. Opening a database with a backend
+// other than the one that created it fails.
+func Open(name string, file string, cache int, handles int) (Database, error) {
+	existing := DetectEngine(file)
+	switch {
+	case name == "" && existing == "":
+		name = DefaultEngine
+	case name == "":
+		name = existing
+	case existing != "" && existing != name:
+		return nil, fmt.Errorf("database %s was created by engine %q, not %q", file, existing, name)
+	}
+	enginesLock.RLock()
+	engine, ok := engines[name]
+	enginesLock.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown database engine %q, available: %s", name, strings.Join(Engines(), ", "))
+	}
+	return engine.open(file, cache, handles)
+}
+
+// Copy writes every key/value pair of src into dst and returns the number of
+// entries copied.
+func Copy(dst, src Database) (int, error) {
+	it := src.NewIteratorWithPrefix(nil)
+	defer it.Release()
+
+	batch, copied := dst.NewBatch(), 0
+	for it.Next() {
+		if err := batch.Put(it.Key(), it.Value()); err != nil {
+			return copied, err
+		}
+		copied++
+		if batch.ValueSize() >= IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return copied, err
+			}
+			batch.Reset()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return copied, err
+	}
+	return copied, batch.Write()
+}
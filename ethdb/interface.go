@@ -30,12 +30,32 @@ type Deleter interface {
 	Delete(key []byte) error
 }
 
+// Iterator iterates over the key/value pairs of a database in ascending key
+// order. The key and value slices are only valid until the next call to Next,
+// and the iterator must be released after use.
+type Iterator interface {
+	Next() bool
+	Key() []byte
+	Value() []byte
+	Error() error
+	Release()
+}
+
 // Database wraps all database operations. All methods are safe for concurrent use.
 type Database interface {
 	Putter
 	Deleter
 	Get(key []byte) ([]byte, error)
 	Has(key []byte) (bool, error)
+	// NewIteratorWithPrefix returns an iterator over the keys starting with prefix.
+	NewIteratorWithPrefix(prefix []byte) Iterator
+	// DeleteRange deletes all keys in [start, limit). A nil limit means no upper bound.
+	DeleteRange(start, limit []byte) error
+	// Stat returns a backend specific statistic of the database.
+	Stat(property string) (string, error)
+	// Compact flattens the underlying storage of the key range [start, limit).
+	// A nil start or limit means no lower or upper bound.
+	Compact(start, limit []byte) error
 	Close()
 	NewBatch() Batch
 }
@@ -17,7 +17,11 @@
 package ethdb
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -88,6 +92,43 @@ func (db *MemDatabase) Delete(key []byte) error {
 	return nil
 }
 
+// NewIteratorWithPrefix returns an iterator over a snapshot of the keys
+// starting with prefix.
+func (db *MemDatabase) NewIteratorWithPrefix(prefix []byte) Iterator {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	it := new(memIterator)
+	for key, value := range db.db {
+		if strings.HasPrefix(key, string(prefix)) {
+			it.keys = append(it.keys, key)
+			it.values = append(it.values, common.CopyBytes(value))
+		}
+	}
+	sort.Sort(it)
+	return it
+}
+
+func (db *MemDatabase) DeleteRange(start, limit []byte) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	for key := range db.db {
+		if bytes.Compare([]byte(key), start) >= 0 && (limit == nil || bytes.Compare([]byte(key), limit) < 0) {
+			delete(db.db, key)
+		}
+	}
+	return nil
+}
+
+func (db *MemDatabase) Stat(property string) (string, error) {
+	return "", fmt.Errorf("unknown memory database property %q", property)
+}
+
+func (db *MemDatabase) Compact(start, limit []byte) error {
+	return nil
+}
+
 func (db *MemDatabase) Close() {}
 
 func (db *MemDatabase) NewBatch() Batch {
@@ -141,3 +182,45 @@ func (b *memBatch) Reset() {
 	b.writes = b.writes[:0]
 	b.size = 0
 }
+
+// memIterator iterates over a sorted snapshot of a memory database.
+type memIterator struct {
+	keys   []string
+	values [][]byte
+	pos    int // Position of the next entry, Key and Value return the one before
+}
+
+func (it *memIterator) Len() int           { return len(it.keys) }
+func (it *memIterator) Less(i, j int) bool { return it.keys[i] < it.keys[j] }
+func (it *memIterator) Swap(i, j int) {
+	it.keys[i], it.keys[j] = it.keys[j], it.keys[i]
+	it.values[i], it.values[j] = it.values[j], it.values[i]
+}
+
+func (it *memIterator) Next() bool {
+	if it.pos >= len(it.keys) {
+		return false
+	}
+	it.pos++
+	return true
+}
+
+func (it *memIterator) Key() []byte {
+	if it.pos == 0 || it.pos > len(it.keys) {
+		return nil
+	}
+	return []byte(it.keys[it.pos-1])
+}
+
+func (it *memIterator) Value() []byte {
+	if it.pos == 0 || it.pos > len(it.keys) {
+		return nil
+	}
+	return it.values[it.pos-1]
+}
+
+func (it *memIterator) Error() error { return nil }
+
+func (it *memIterator) Release() {
+	it.keys, it.values = nil, nil
+}
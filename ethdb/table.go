@@ -46,6 +46,67 @@ func (dt *table) Delete(key []byte) error {
 	return dt.db.Delete(append([]byte(dt.prefix), key...))
 }
 
+func (dt *table) NewIteratorWithPrefix(prefix []byte) Iterator {
+	return &tableIterator{
+		it:     dt.db.NewIteratorWithPrefix(append([]byte(dt.prefix), prefix...)),
+		prefix: len(dt.prefix),
+	}
+}
+
+func (dt *table) DeleteRange(start, limit []byte) error {
+	if limit == nil {
+		limit = prefixLimit([]byte(dt.prefix))
+	} else {
+		limit = append([]byte(dt.prefix), limit...)
+	}
+	return dt.db.DeleteRange(append([]byte(dt.prefix), start...), limit)
+}
+
+func (dt *table) Stat(property string) (string, error) {
+	return dt.db.Stat(property)
+}
+
+func (dt *table) Compact(start, limit []byte) error {
+	if limit == nil {
+		limit = prefixLimit([]byte(dt.prefix))
+	} else {
+		limit = append([]byte(dt.prefix), limit...)
+	}
+	return dt.db.Compact(append([]byte(dt.prefix), start...), limit)
+}
+
 func (dt *table) Close() {
 	// Do nothing; don't close the underlying DB.
 }
+
+// tableIterator strips the table prefix from the keys of the underlying iterator.
+type tableIterator struct {
+	it     Iterator
+	prefix int
+}
+
+func (it *tableIterator) Next() bool    { return it.it.Next() }
+func (it *tableIterator) Value() []byte { return it.it.Value() }
+func (it *tableIterator) Error() error  { return it.it.Error() }
+func (it *tableIterator) Release()      { it.it.Release() }
+
+func (it *tableIterator) Key() []byte {
+	if key := it.it.Key(); key != nil {
+		return key[it.prefix:]
+	}
+	return nil
+}
+
+// prefixLimit returns the smallest key greater than all keys starting with
+// prefix, or nil if there is none.
+func prefixLimit(prefix []byte) []byte {
+	for i := len(prefix) - 1; i >= 0; i-- {
+		if prefix[i] < 0xff {
+			limit := make([]byte, i+1)
+			copy(limit, prefix)
+			limit[i]++
+			return limit
+		}
+	}
+	return nil
+}
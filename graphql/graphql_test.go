@@ -0,0 +1,306 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethdb"
+	"github.com/ethereum/go-ethereum/internal/ethapi"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/private"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	testKey, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	testAddr    = crypto.PubkeyToAddress(testKey.PublicKey)
+	testEmitter = common.HexToAddress("0x00000000000000000000000000000000000000ee")
+	testTopic   = common.HexToHash("0x01")
+)
+
+// testBackend serves the chain data needed by the resolvers from a local chain.
+type testBackend struct {
+	ethapi.Backend
+	db    ethdb.Database
+	chain *core.BlockChain
+}
+
+func (b *testBackend) ChainDb() ethdb.Database { return b.db }
+
+func (b *testBackend) CurrentBlock() *types.Block { return b.chain.CurrentBlock() }
+
+func (b *testBackend) BlockByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Block, error) {
+	if number == rpc.LatestBlockNumber {
+		return b.chain.CurrentBlock(), nil
+	}
+	return b.chain.GetBlockByNumber(uint64(number)), nil
+}
+
+func (b *testBackend) GetBlock(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	return b.chain.GetBlockByHash(hash), nil
+}
+
+func (b *testBackend) GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error) {
+	return b.chain.GetReceiptsByHash(hash), nil
+}
+
+func (b *testBackend) GetTd(hash common.Hash) *big.Int {
+	return b.chain.GetTdByHash(hash)
+}
+
+func (b *testBackend) GetPoolTransaction(hash common.Hash) *types.Transaction { return nil }
+
+func (b *testBackend) StateAndHeaderByNumber(ctx context.Context, number rpc.BlockNumber) (vm.MinimalApiState, *types.Header, error) {
+	block, _ := b.BlockByNumber(ctx, number)
+	if block == nil {
+		return nil, nil, nil
+	}
+	state, _, err := b.chain.StateAt(block.Root())
+	return state, block.Header(), err
+}
+
+// newTestBackend creates a chain with a value transfer in block 1 and a call
+// emitting a log in block 2.
+func newTestBackend(t *testing.T) *testBackend {
+	// PUSH32 topic PUSH1 0 PUSH1 0 LOG1
+	code := append(append([]byte{0x7f}, testTopic.Bytes()...), 0x60, 0x00, 0x60, 0x00, 0xa1)
+
+	db := ethdb.NewMemDatabase()
+	gspec := &core.Genesis{
+		Config: params.TestChainConfig,
+		Alloc: core.GenesisAlloc{
+			testAddr:    {Balance: big.NewInt(1000000000)},
+			testEmitter: {Code: code, Balance: new(big.Int)},
+		},
+	}
+	genesis := gspec.MustCommit(db)
+	signer := types.NewEIP155Signer(gspec.Config.ChainID)
+	blocks, _ := core.GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 2, func(i int, gen *core.BlockGen) {
+		var tx *types.Transaction
+		switch i {
+		case 0:
+			tx = types.NewTransaction(gen.TxNonce(testAddr), common.Address{0x01}, big.NewInt(1000), params.TxGas, nil, nil)
+		case 1:
+			tx = types.NewTransaction(gen.TxNonce(testAddr), testEmitter, new(big.Int), 100000, nil, nil)
+		}
+		tx, _ = types.SignTx(tx, signer, testKey)
+		gen.AddTx(tx)
+	})
+	chain, err := core.NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil)
+	if err != nil {
+		t.Fatalf("failed to create chain: %v", err)
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	return &testBackend{db: db, chain: chain}
+}
+
+func query(t *testing.T, h http.Handler, q string) map[string]interface{} {
+	body, _ := json.Marshal(map[string]string{"query": q})
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body))))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status mismatch: have %d, want %d", rec.Code, http.StatusOK)
+	}
+	var resp struct {
+		Data   map[string]interface{}
+		Errors []interface{}
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if len(resp.Errors) > 0 {
+		t.Fatalf("query %q failed: %v", q, resp.Errors)
+	}
+	return resp.Data
+}
+
+func TestQueries(t *testing.T) {
+	backend := newTestBackend(t)
+	defer backend.chain.Stop()
+
+	h, err := newHandler(backend)
+	if err != nil {
+		t.Fatalf("failed to create handler: %v", err)
+	}
+	block1 := backend.chain.GetBlockByNumber(1)
+	tx := block1.Transactions()[0]
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{
+			query: `{ block { number } }`,
+			want:  `{"block":{"number":"0x2"}}`,
+		},
+		{
+			query: `{ block(number: 1) { hash transactionCount } }`,
+			want:  `{"block":{"hash":"` + block1.Hash().Hex() + `","transactionCount":1}}`,
+		},
+		{
+			query: `{ block(number: 5) { hash } }`,
+			want:  `{"block":null}`,
+		},
+		{
+			query: `{ blocks(from: 0) { number } }`,
+			want:  `{"blocks":[{"number":"0x0"},{"number":"0x1"},{"number":"0x2"}]}`,
+		},
+		{
+			query: `{ blocks(from: 1, to: 100) { number } }`,
+			want:  `{"blocks":[{"number":"0x1"},{"number":"0x2"}]}`,
+		},
+		{
+			query: `{ blocks(from: 5) { number } }`,
+			want:  `{"blocks":[]}`,
+		},
+		{
+			query: `{ transaction(hash: "` + tx.Hash().Hex() + `") { from { address } to { balance } value index isPrivate privateInputData privateReceipt { status } receipt { status gasUsed } block { number } } }`,
+			want: `{"transaction":{"from":{"address":"` + strings.ToLower(testAddr.Hex()) + `"},"to":{"balance":"0x3e8"},"value":"0x3e8","index":0,` +
+				`"isPrivate":false,"privateInputData":null,"privateReceipt":null,"receipt":{"status":"0x1","gasUsed":"0x5208"},"block":{"number":"0x1"}}}`,
+		},
+		{
+			query: `{ logs(filter: {fromBlock: 0, topics: [["` + testTopic.Hex() + `"]]}) { index account { address code } transaction { block { number } } } }`,
+			want: `{"logs":[{"index":0,"account":{"address":"` + strings.ToLower(testEmitter.Hex()) + `","code":"0x7f` + testTopic.Hex()[2:] + `60006000a1"},` +
+				`"transaction":{"block":{"number":"0x2"}}}]}`,
+		},
+		{
+			query: `{ block(number: 1) { logs(filter: {}) { index } } }`,
+			want:  `{"block":{"logs":[]}}`,
+		},
+	}
+	for i, tt := range tests {
+		var have, want interface{}
+		blob, _ := json.Marshal(query(t, h, tt.query))
+		json.Unmarshal(blob, &have)
+		json.Unmarshal([]byte(tt.want), &want)
+		if !reflect.DeepEqual(have, want) {
+			t.Errorf("test %d: result mismatch:\nhave %s\nwant %s", i, blob, tt.want)
+		}
+	}
+}
+
+func TestBlockRangeLimit(t *testing.T) {
+	backend := newTestBackend(t)
+	defer backend.chain.Stop()
+
+	h, err := newHandler(backend)
+	if err != nil {
+		t.Fatalf("failed to create handler: %v", err)
+	}
+	defer func(limit uint64) { maxBlockRange = limit }(maxBlockRange)
+	maxBlockRange = 2
+
+	tests := []struct {
+		query string
+		fail  bool
+	}{
+		{`{ blocks(from: 1) { number } }`, false},
+		{`{ blocks(from: 0) { number } }`, true},
+		{`{ blocks(from: 2, to: 1) { number } }`, true},
+		{`{ logs(filter: {fromBlock: 1}) { index } }`, false},
+		{`{ logs(filter: {fromBlock: 0, toBlock: 100}) { index } }`, true},
+		{`{ logs(filter: {fromBlock: 2, toBlock: 1}) { index } }`, true},
+	}
+	for i, tt := range tests {
+		body, _ := json.Marshal(map[string]string{"query": tt.query})
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body))))
+
+		var resp struct{ Errors []interface{} }
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		if failed := len(resp.Errors) > 0; failed != tt.fail {
+			t.Errorf("test %d: failure mismatch: have %v, want %v: %s", i, failed, tt.fail, rec.Body.Bytes())
+		}
+	}
+}
+
+// countingTxManager counts the payloads requested from it.
+type countingTxManager struct {
+	private.PrivateTransactionManager
+
+	lock     sync.Mutex
+	receives int
+}
+
+func (m *countingTxManager) Receive(data []byte) ([]byte, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.receives++
+	return append([]byte("payload:"), data...), nil
+}
+
+func TestPayloadCache(t *testing.T) {
+	manager := new(countingTxManager)
+	defer func(p private.PrivateTransactionManager) { private.P = p }(private.P)
+	private.P = manager
+
+	var (
+		cache = newPayloadCache()
+		hash  = common.HexToHash("0x01")
+		wg    sync.WaitGroup
+	)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if payload := cache.receive(hash, []byte{0x01}); string(payload) != "payload:\x01" {
+				t.Errorf("payload mismatch: have %x", payload)
+			}
+		}()
+	}
+	wg.Wait()
+	cache.receive(common.HexToHash("0x02"), []byte{0x02})
+
+	if manager.receives != 2 {
+		t.Errorf("transaction manager requests mismatch: have %d, want 2", manager.receives)
+	}
+}
+
+func TestInvalidRequests(t *testing.T) {
+	h, err := newHandler(new(testBackend))
+	if err != nil {
+		t.Fatalf("failed to create handler: %v", err)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/graphql", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET status mismatch: have %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader("{")))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("malformed body status mismatch: have %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+}
@@ -22,7 +22,6 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
-	"strings"
 	"time"
 
 	"encoding/hex"
@@ -49,8 +48,6 @@ import (
 	"github.com/ethereum/go-ethereum/private"
 	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
-	"github.com/syndtr/goleveldb/leveldb"
-	"github.com/syndtr/goleveldb/leveldb/util"
 )
 
 const (
@@ -116,13 +113,65 @@ func NewPublicTxPoolAPI(b Backend) *PublicTxPoolAPI {
 	return &PublicTxPoolAPI{b}
 }
 
-// Content returns the transactions contained within the transaction pool.
-func (s *PublicTxPoolAPI) Content() map[string]map[string]map[string]*RPCTransaction {
+// TxPoolFilter restricts the transactions reported by the txpool API. An empty
+// sender list matches all senders, a nil private flag both public and private
+// transactions.
+type TxPoolFilter struct {
+	From    []common.Address `json:"from"`
+	Private *bool            `json:"private"`
+}
+
+// matches reports whether a transaction of the given sender passes the filter.
+// A nil filter matches every transaction.
+func (f *TxPoolFilter) matches(from common.Address, tx *types.Transaction) bool {
+	if f == nil {
+		return true
+	}
+	if f.Private != nil && *f.Private != tx.IsPrivate() {
+		return false
+	}
+	if len(f.From) == 0 {
+		return true
+	}
+	for _, addr := range f.From {
+		if addr == from {
+			return true
+		}
+	}
+	return false
+}
+
+// filterTxs returns the transactions of the given accounts passing the filter,
+// leaving out the accounts with no transactions left.
+func (f *TxPoolFilter) filterTxs(content map[common.Address]types.Transactions) map[common.Address]types.Transactions {
+	if f == nil {
+		return content
+	}
+	filtered := make(map[common.Address]types.Transactions)
+	for account, txs := range content {
+		for _, tx := range txs {
+			if f.matches(account, tx) {
+				filtered[account] = append(filtered[account], tx)
+			}
+		}
+	}
+	return filtered
+}
+
+// poolContent retrieves the pending and queued transactions passing the filter.
+func (s *PublicTxPoolAPI) poolContent(filter *TxPoolFilter) (map[common.Address]types.Transactions, map[common.Address]types.Transactions) {
+	pending, queue := s.b.TxPoolContent()
+	return filter.filterTxs(pending), filter.filterTxs(queue)
+}
+
+// Content returns the transactions contained within the transaction pool,
+// optionally restricted to the ones matching the filter.
+func (s *PublicTxPoolAPI) Content(filter *TxPoolFilter) map[string]map[string]map[string]*RPCTransaction {
 	content := map[string]map[string]map[string]*RPCTransaction{
 		"pending": make(map[string]map[string]*RPCTransaction),
 		"queued":  make(map[string]map[string]*RPCTransaction),
 	}
-	pending, queue := s.b.TxPoolContent()
+	pending, queue := s.poolContent(filter)
 
 	// Flatten the pending transactions
 	for account, txs := range pending {
@@ -143,9 +192,21 @@ func (s *PublicTxPoolAPI) Content() map[string]map[string]map[string]*RPCTransac
 	return content
 }
 
-// Status returns the number of pending and queued transaction in the pool.
-func (s *PublicTxPoolAPI) Status() map[string]hexutil.Uint {
-	pending, queue := s.b.Stats()
+// Status returns the number of pending and queued transaction in the pool,
+// optionally counting only the ones matching the filter.
+func (s *PublicTxPoolAPI) Status(filter *TxPoolFilter) map[string]hexutil.Uint {
+	var pending, queue int
+	if filter == nil {
+		pending, queue = s.b.Stats()
+	} else {
+		pendingTxs, queueTxs := s.poolContent(filter)
+		for _, txs := range pendingTxs {
+			pending += len(txs)
+		}
+		for _, txs := range queueTxs {
+			queue += len(txs)
+		}
+	}
 	return map[string]hexutil.Uint{
 		"pending": hexutil.Uint(pending),
 		"queued":  hexutil.Uint(queue),
@@ -153,20 +214,24 @@ func (s *PublicTxPoolAPI) Status() map[string]hexutil.Uint {
 }
 
 // Inspect retrieves the content of the transaction pool and flattens it into an
-// easily inspectable list.
-func (s *PublicTxPoolAPI) Inspect() map[string]map[string]map[string]string {
+// easily inspectable list, optionally restricted to the ones matching the filter.
+func (s *PublicTxPoolAPI) Inspect(filter *TxPoolFilter) map[string]map[string]map[string]string {
 	content := map[string]map[string]map[string]string{
 		"pending": make(map[string]map[string]string),
 		"queued":  make(map[string]map[string]string),
 	}
-	pending, queue := s.b.TxPoolContent()
+	pending, queue := s.poolContent(filter)
 
 	// Define a formatter to flatten a transaction into a string
 	var format = func(tx *types.Transaction) string {
+		kind := ""
+		if tx.IsPrivate() {
+			kind = " (private)"
+		}
 		if to := tx.To(); to != nil {
-			return fmt.Sprintf("%s: %v wei + %v gas × %v wei", tx.To().Hex(), tx.Value(), tx.Gas(), tx.GasPrice())
+			return fmt.Sprintf("%s: %v wei + %v gas × %v wei%s", tx.To().Hex(), tx.Value(), tx.Gas(), tx.GasPrice(), kind)
 		}
-		return fmt.Sprintf("contract creation: %v wei + %v gas × %v wei", tx.Value(), tx.Gas(), tx.GasPrice())
+		return fmt.Sprintf("contract creation: %v wei + %v gas × %v wei%s", tx.Value(), tx.Gas(), tx.GasPrice(), kind)
 	}
 	// Flatten the pending transactions
 	for account, txs := range pending {
@@ -187,6 +252,76 @@ func (s *PublicTxPoolAPI) Inspect() map[string]map[string]map[string]string {
 	return content
 }
 
+// RPCTxChange is a transaction pool lifecycle change as reported over RPC.
+type RPCTxChange struct {
+	Type    string         `json:"type"`
+	Hash    common.Hash    `json:"hash"`
+	From    common.Address `json:"from"`
+	Nonce   hexutil.Uint64 `json:"nonce"`
+	Private bool           `json:"private"`
+	Reason  string         `json:"reason,omitempty"`
+}
+
+// Events creates a subscription that is notified of every transaction added to,
+// promoted within, replaced in or dropped from the pool, optionally restricted
+// to the ones matching the filter.
+func (s *PublicTxPoolAPI) Events(ctx context.Context, filter *TxPoolFilter) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		changes := make(chan core.TxLifecycleEvent, 128)
+		sub := s.b.SubscribeTxLifecycleEvent(changes)
+		defer sub.Unsubscribe()
+
+		for {
+			select {
+			case ev := <-changes:
+				for _, change := range ev.Changes {
+					if !filter.matches(change.From, change.Tx) {
+						continue
+					}
+					notifier.Notify(rpcSub.ID, &RPCTxChange{
+						Type:    change.Type.String(),
+						Hash:    change.Tx.Hash(),
+						From:    change.From,
+						Nonce:   hexutil.Uint64(change.Tx.Nonce()),
+						Private: change.Tx.IsPrivate(),
+						Reason:  change.Reason,
+					})
+				}
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			case <-sub.Err():
+				return
+			}
+		}
+	}()
+	return rpcSub, nil
+}
+
+// PrivateTxPoolAPI offers administrative access to the transaction pool.
+type PrivateTxPoolAPI struct {
+	b Backend
+}
+
+// NewPrivateTxPoolAPI creates a new tx pool service for node administration.
+func NewPrivateTxPoolAPI(b Backend) *PrivateTxPoolAPI {
+	return &PrivateTxPoolAPI{b}
+}
+
+// RemoveTransaction drops a transaction from the pool, moving the later ones of
+// the same sender back to the future queue. It returns whether the transaction
+// was found.
+func (s *PrivateTxPoolAPI) RemoveTransaction(hash common.Hash) (bool, error) {
+	return s.b.RemovePoolTransaction(hash)
+}
+
 // PublicAccountAPI provides an API to access accounts managed by this node.
 // It offers only methods that can retrieve accounts.
 type PublicAccountAPI struct {
@@ -1643,32 +1778,15 @@ func NewPrivateDebugAPI(b Backend) *PrivateDebugAPI {
 	return &PrivateDebugAPI{b: b}
 }
 
-// ChaindbProperty returns leveldb properties of the chain database.
+// ChaindbProperty returns backend specific properties of the chain database.
 func (api *PrivateDebugAPI) ChaindbProperty(property string) (string, error) {
-	ldb, ok := api.b.ChainDb().(interface {
-		LDB() *leveldb.DB
-	})
-	if !ok {
-		return "", fmt.Errorf("chaindbProperty does not work for memory databases")
-	}
-	if property == "" {
-		property = "leveldb.stats"
-	} else if !strings.HasPrefix(property, "leveldb.") {
-		property = "leveldb." + property
-	}
-	return ldb.LDB().GetProperty(property)
+	return api.b.ChainDb().Stat(property)
 }
 
 func (api *PrivateDebugAPI) ChaindbCompact() error {
-	ldb, ok := api.b.ChainDb().(interface {
-		LDB() *leveldb.DB
-	})
-	if !ok {
-		return fmt.Errorf("chaindbCompact does not work for memory databases")
-	}
 	for b := byte(0); b < 255; b++ {
 		log.Info("Compacting chain database", "range", fmt.Sprintf("0x%0.2X-0x%0.2X", b, b+1))
-		err := ldb.LDB().CompactRange(util.Range{Start: []byte{b}, Limit: []byte{b + 1}})
+		err := api.b.ChainDb().Compact([]byte{b}, []byte{b + 1})
 		if err != nil {
 			log.Error("Database compaction failed", "err", err)
 			return err
@@ -63,8 +63,19 @@ const (
 	disableClientRemovePeer = false
 )
 
+// protocolError is returned for messages breaching the protocol. Peers sending
+// them are reported as faulty.
+type protocolError struct {
+	code errCode
+	msg  string
+}
+
+func (e *protocolError) Error() string {
+	return fmt.Sprintf("%v - %v", e.code, e.msg)
+}
+
 func errResp(code errCode, format string, v ...interface{}) error {
-	return fmt.Errorf("%v - %v", code, fmt.Sprintf(format, v...))
+	return &protocolError{code, fmt.Sprintf(format, v...)}
 }
 
 type BlockChain interface {
@@ -149,7 +160,7 @@ func NewProtocolManager(chainConfig *params.ChainConfig, indexerConfig *light.In
 		manager.reqDist = odr.retriever.dist
 	}
 
-	removePeer := manager.removePeer
+	removePeer := manager.dropFaultyPeer(p2p.FaultMinor, "failed chain sync")
 	if disableClientRemovePeer {
 		removePeer = func(id string) {}
 	}
@@ -163,6 +174,17 @@ func NewProtocolManager(chainConfig *params.ChainConfig, indexerConfig *light.In
 	return manager, nil
 }
 
+// dropFaultyPeer returns a callback removing a peer after reporting a fault of
+// the given severity for it.
+func (pm *ProtocolManager) dropFaultyPeer(severity int, reason string) func(id string) {
+	return func(id string) {
+		if peer := pm.peers.Peer(id); peer != nil {
+			peer.Peer.ReportFault(severity, reason)
+		}
+		pm.removePeer(id)
+	}
+}
+
 // removePeer initiates disconnection from a peer by removing it from the peer set
 func (pm *ProtocolManager) removePeer(id string) {
 	pm.peers.Unregister(id)
@@ -319,6 +341,9 @@ func (pm *ProtocolManager) handle(p *peer) error {
 	for {
 		if err := pm.handleMsg(p); err != nil {
 			p.Log().Debug("Light Ethereum message handling failed", "err", err)
+			if _, ok := err.(*protocolError); ok {
+				p.Peer.ReportFault(p2p.FaultSevere, err.Error())
+			}
 			return err
 		}
 	}
@@ -665,6 +690,13 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 			var results types.Receipts
 			if number := rawdb.ReadHeaderNumber(pm.chainDb, hash); number != nil {
 				results = rawdb.ReadReceipts(pm.chainDb, hash, *number)
+
+				// Quorum: don't leak the private receipts of private transactions
+				if block := rawdb.ReadBlock(pm.chainDb, hash, *number); results != nil && block != nil {
+					if results = core.PublicReceipts(pm.chainConfig, block, results); results == nil {
+						continue
+					}
+				}
 			}
 			if results == nil {
 				if header := pm.blockchain.GetHeaderByHash(hash); header == nil || header.ReceiptHash != types.EmptyRootHash {
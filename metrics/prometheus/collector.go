@@ -0,0 +1,125 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package prometheus
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+var (
+	// quantiles are reported for histograms and timers
+	quantiles = []float64{0.5, 0.75, 0.95, 0.99, 0.999}
+
+	// resettingQuantiles are reported for resetting timers, in percent
+	resettingQuantiles = []float64{50, 75, 95, 99}
+)
+
+// collector renders metrics into a buffer in the Prometheus text format.
+type collector struct {
+	buff *bytes.Buffer
+}
+
+func newCollector() *collector {
+	return &collector{buff: new(bytes.Buffer)}
+}
+
+func (c *collector) addCounter(name string, m metrics.Counter) {
+	// Counters may be decremented, so they are reported as gauges
+	c.writeSingle(name, "gauge", strconv.FormatInt(m.Count(), 10))
+}
+
+func (c *collector) addGauge(name string, m metrics.Gauge) {
+	c.writeSingle(name, "gauge", strconv.FormatInt(m.Value(), 10))
+}
+
+func (c *collector) addGaugeFloat64(name string, m metrics.GaugeFloat64) {
+	c.writeSingle(name, "gauge", formatFloat(m.Value()))
+}
+
+func (c *collector) addMeter(name string, m metrics.Meter) {
+	c.writeSingle(name, "counter", strconv.FormatInt(m.Count(), 10))
+}
+
+func (c *collector) addHistogram(name string, m metrics.Histogram) {
+	ps := m.Percentiles(quantiles)
+	c.writeSummary(name, quantiles, ps, m.Sum(), m.Count())
+}
+
+func (c *collector) addTimer(name string, m metrics.Timer) {
+	ps := m.Percentiles(quantiles)
+	c.writeSummary(name, quantiles, ps, m.Sum(), m.Count())
+}
+
+func (c *collector) addResettingTimer(name string, m metrics.ResettingTimer) {
+	values := m.Values()
+	if len(values) == 0 {
+		return
+	}
+	var (
+		qs  = make([]float64, len(resettingQuantiles))
+		ps  = make([]float64, len(resettingQuantiles))
+		sum int64
+	)
+	for i, p := range m.Percentiles(resettingQuantiles) {
+		qs[i], ps[i] = resettingQuantiles[i]/100, float64(p)
+	}
+	for _, v := range values {
+		sum += v
+	}
+	c.writeSummary(name, qs, ps, sum, int64(len(values)))
+}
+
+// writeSingle writes a metric consisting of a single sample.
+func (c *collector) writeSingle(name string, typ string, value string) {
+	name = mutateKey(name)
+	fmt.Fprintf(c.buff, "# TYPE %s %s\n%s %s\n\n", name, typ, name, value)
+}
+
+// writeSummary writes a summary metric with the given quantiles.
+func (c *collector) writeSummary(name string, qs []float64, ps []float64, sum int64, count int64) {
+	name = mutateKey(name)
+	fmt.Fprintf(c.buff, "# TYPE %s summary\n", name)
+	for i, q := range qs {
+		fmt.Fprintf(c.buff, "%s{quantile=\"%s\"} %s\n", name, formatFloat(q), formatFloat(ps[i]))
+	}
+	fmt.Fprintf(c.buff, "%s_sum %d\n%s_count %d\n\n", name, sum, name, count)
+}
+
+// mutateKey converts a go-metrics name into a valid Prometheus metric name.
+func mutateKey(key string) string {
+	key = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == ':':
+			return r
+		default:
+			return '_'
+		}
+	}, key)
+	if key == "" || (key[0] >= '0' && key[0] <= '9') {
+		key = "_" + key
+	}
+	return key
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
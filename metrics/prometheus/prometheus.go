@@ -0,0 +1,93 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package prometheus exposes go-metrics registries in the Prometheus text format.
+package prometheus
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// Handler returns an HTTP handler which renders the metrics of the registry in
+// the Prometheus text exposition format.
+//
+// If reset is set, resetting timers are reset by every scrape, same as by any
+// other reporter. Otherwise they are only read, leaving them to another reporter
+// that resets them, e.g. InfluxDB.
+func Handler(reg metrics.Registry, reset bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Gather and pre-sort the metrics to avoid random listings
+		var names []string
+		reg.Each(func(name string, i interface{}) {
+			names = append(names, name)
+		})
+		sort.Strings(names)
+
+		// Aggregate all the metrics into a Prometheus collector
+		c := newCollector()
+		for _, name := range names {
+			switch m := reg.Get(name).(type) {
+			case metrics.Counter:
+				c.addCounter(name, m.Snapshot())
+			case metrics.Gauge:
+				c.addGauge(name, m.Snapshot())
+			case metrics.GaugeFloat64:
+				c.addGaugeFloat64(name, m.Snapshot())
+			case metrics.Histogram:
+				c.addHistogram(name, m.Snapshot())
+			case metrics.Meter:
+				c.addMeter(name, m.Snapshot())
+			case metrics.Timer:
+				c.addTimer(name, m.Snapshot())
+			case metrics.ResettingTimer:
+				if reset {
+					c.addResettingTimer(name, m.Snapshot())
+				} else {
+					c.addResettingTimer(name, metrics.NewResettingTimerSnapshot(m.Values()))
+				}
+			default:
+				log.Debug("Unknown Prometheus metric type", "name", name, "type", fmt.Sprintf("%T", m))
+			}
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		w.Write(c.buff.Bytes())
+	})
+}
+
+// Start serves the metrics of the registry on the /metrics path of the given
+// address, returning the listener to close it. See Handler for reset.
+func Start(address string, reg metrics.Registry, reset bool) (net.Listener, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler(reg, reset))
+
+	log.Info("Starting Prometheus metrics server", "addr", fmt.Sprintf("http://%s/metrics", listener.Addr()))
+	go func() {
+		if err := http.Serve(listener, mux); err != nil {
+			log.Debug("Prometheus metrics server stopped", "err", err)
+		}
+	}()
+	return listener, nil
+}
@@ -0,0 +1,133 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package prometheus
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+func init() {
+	metrics.Enabled = true
+}
+
+func TestHandler(t *testing.T) {
+	reg := metrics.NewRegistry()
+
+	metrics.NewRegisteredCounter("test/counter", reg).Inc(12)
+	metrics.NewRegisteredGauge("test/gauge", reg).Update(-3)
+	metrics.NewRegisteredGaugeFloat64("test/gauge.float", reg).Update(1.5)
+	metrics.NewRegisteredMeter("test/meter", reg).Mark(9)
+
+	histogram := metrics.NewRegisteredHistogram("test/histogram", reg, metrics.NewUniformSample(100))
+	for i := int64(1); i <= 4; i++ {
+		histogram.Update(i)
+	}
+	timer := metrics.NewRegisteredResettingTimer("test/resetting", reg)
+	timer.Update(10 * time.Nanosecond)
+	timer.Update(30 * time.Nanosecond)
+
+	recorder := httptest.NewRecorder()
+	Handler(reg, true).ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := ioutil.ReadAll(recorder.Body)
+
+	want := `# TYPE test_counter gauge
+test_counter 12
+
+# TYPE test_gauge gauge
+test_gauge -3
+
+# TYPE test_gauge_float gauge
+test_gauge_float 1.5
+
+# TYPE test_histogram summary
+test_histogram{quantile="0.5"} 2.5
+test_histogram{quantile="0.75"} 3.75
+test_histogram{quantile="0.95"} 4
+test_histogram{quantile="0.99"} 4
+test_histogram{quantile="0.999"} 4
+test_histogram_sum 10
+test_histogram_count 4
+
+# TYPE test_meter counter
+test_meter 9
+
+# TYPE test_resetting summary
+test_resetting{quantile="0.5"} 10
+test_resetting{quantile="0.75"} 30
+test_resetting{quantile="0.95"} 30
+test_resetting{quantile="0.99"} 30
+test_resetting_sum 40
+test_resetting_count 2
+
+`
+	if string(body) != want {
+		t.Errorf("output mismatch:\nhave:\n%s\nwant:\n%s", body, want)
+	}
+	if ct := recorder.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("content type mismatch: have %q", ct)
+	}
+}
+
+func TestHandlerResettingTimer(t *testing.T) {
+	reg := metrics.NewRegistry()
+	timer := metrics.NewRegisteredResettingTimer("test/resetting", reg)
+	timer.Update(10 * time.Nanosecond)
+
+	scrape := func(reset bool) string {
+		recorder := httptest.NewRecorder()
+		Handler(reg, reset).ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+		body, _ := ioutil.ReadAll(recorder.Body)
+		return string(body)
+	}
+	// Without reset the measurements are left to the other reporters
+	for i := 0; i < 2; i++ {
+		if body := scrape(false); !strings.Contains(body, "test_resetting_count 1\n") {
+			t.Fatalf("scrape %d: measurement missing:\n%s", i, body)
+		}
+	}
+	if n := len(timer.Values()); n != 1 {
+		t.Fatalf("timer reset by scrape: have %d measurements, want 1", n)
+	}
+	// With reset every scrape drops them
+	if body := scrape(true); !strings.Contains(body, "test_resetting_count 1\n") {
+		t.Fatalf("measurement missing:\n%s", body)
+	}
+	if n := len(timer.Values()); n != 0 {
+		t.Fatalf("timer not reset by scrape: have %d measurements, want 0", n)
+	}
+}
+
+func TestMutateKey(t *testing.T) {
+	tests := map[string]string{
+		"p2p/InboundTraffic":       "p2p_InboundTraffic",
+		"consensus/istanbul/sign":  "consensus_istanbul_sign",
+		"eth/downloader/bodies.in": "eth_downloader_bodies_in",
+		"1st":                      "_1st",
+		"raft/minter/blocks":       "raft_minter_blocks",
+	}
+	for key, want := range tests {
+		if have := mutateKey(key); have != want {
+			t.Errorf("%q: have %q, want %q", key, have, want)
+		}
+	}
+}
@@ -90,9 +90,14 @@ type StandardResettingTimer struct {
 	mutex  sync.Mutex
 }
 
-// Values returns a slice with all measurements.
+// Values returns a copy of all measurements, without resetting the timer.
 func (t *StandardResettingTimer) Values() []int64 {
-	return t.values
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	values := make([]int64, len(t.values))
+	copy(values, t.values)
+	return values
 }
 
 // Snapshot resets the timer and returns a read-only copy of its contents.
@@ -146,6 +151,12 @@ type ResettingTimerSnapshot struct {
 	calculated          bool
 }
 
+// NewResettingTimerSnapshot constructs a read-only timer holding the given
+// measurements.
+func NewResettingTimerSnapshot(values []int64) *ResettingTimerSnapshot {
+	return &ResettingTimerSnapshot{values: values}
+}
+
 // Snapshot returns the snapshot.
 func (t *ResettingTimerSnapshot) Snapshot() ResettingTimer { return t }
 
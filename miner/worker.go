@@ -88,6 +88,8 @@ type environment struct {
 	tcount    int            // tx count in cycle
 	gasPool   *core.GasPool  // available gas used to pack transactions
 
+	rated map[common.Address]uint64 // tx count per account, checked against the pool's rate limits
+
 	header   *types.Header
 	txs      []*types.Transaction
 	receipts []*types.Receipt
@@ -484,7 +486,7 @@ func (w *worker) mainLoop() {
 					acc, _ := types.Sender(w.current.signer, tx)
 					txs[acc] = append(txs[acc], tx)
 				}
-				txset := types.NewTransactionsByPriceAndNonce(w.current.signer, txs)
+				txset := w.newTransactionSet(txs)
 				w.commitTransactions(txset, coinbase, nil)
 				w.updateSnapshot()
 			} else {
@@ -605,7 +607,7 @@ func (w *worker) resultLoop() {
 			allReceipts := mergeReceipts(work.receipts, work.privateReceipts)
 
 			// Commit block and state to database.
-			stat, err := w.chain.WriteBlockWithState(block, allReceipts, work.state, nil)
+			stat, err := w.chain.WriteBlockWithState(block, allReceipts, work.state, work.privateState)
 			if err != nil {
 				log.Error("Failed writWriteBlockAndStating block to chain", "err", err)
 				continue
@@ -632,6 +634,9 @@ func (w *worker) resultLoop() {
 			case core.SideStatTy:
 				events = append(events, core.ChainSideEvent{Block: block})
 			}
+			if diff := work.state.Diff(); diff != nil {
+				events = append(events, core.StateDiffEvent{Block: block, Public: diff, Private: work.privateState.Diff()})
+			}
 			w.chain.PostChainEvents(events, logs)
 
 			// Insert the block into the set of pending ones to resultLoop for confirmations
@@ -670,6 +675,10 @@ func (w *worker) makeCurrent(parent *types.Block, header *types.Header) error {
 	if err != nil {
 		return err
 	}
+	if w.chain.StateDiffsEnabled() {
+		publicState.EnableDiff()
+		privateState.EnableDiff()
+	}
 	env := &environment{
 		signer:       types.MakeSigner(w.config, header.Number),
 		state:        publicState,
@@ -677,6 +686,7 @@ func (w *worker) makeCurrent(parent *types.Block, header *types.Header) error {
 		family:       mapset.NewSet(),
 		uncles:       mapset.NewSet(),
 		header:       header,
+		rated:        make(map[common.Address]uint64),
 		privateState: privateState,
 	}
 
@@ -824,6 +834,13 @@ func (w *worker) commitTransactions(txs *types.TransactionsByPriceAndNonce, coin
 			txs.Pop()
 			continue
 		}
+		// Skip the account if it used up its rate, no further transactions will fit
+		if allowance, limited := w.eth.TxPool().RateAllowance(from); limited && w.current.rated[from] >= allowance {
+			log.Trace("Skipping rate limited account", "sender", from, "included", w.current.rated[from])
+
+			txs.Pop()
+			continue
+		}
 		// Start executing the transaction
 		w.current.state.Prepare(tx.Hash(), common.Hash{}, w.current.tcount)
 		w.current.privateState.Prepare(tx.Hash(), common.Hash{}, w.current.tcount)
@@ -845,10 +862,21 @@ func (w *worker) commitTransactions(txs *types.TransactionsByPriceAndNonce, coin
 			log.Trace("Skipping account with hight nonce", "sender", from, "nonce", tx.Nonce())
 			txs.Pop()
 
+		case core.ErrTxGasLimit:
+			// Transaction can never fit the Quorum gas limits, skip account
+			log.Trace("Skipping transaction above gas limit", "sender", from, "gas", tx.Gas())
+			txs.Pop()
+
+		case core.ErrTransactNotPermitted, core.ErrContractDeployNotPermitted:
+			// Permissions changed since the transaction pool admitted it, skip account
+			log.Trace("Skipping account without permission", "sender", from, "err", err)
+			txs.Pop()
+
 		case nil:
 			// Everything ok, collect the logs and shift in the next transaction from the same account
 			coalescedLogs = append(coalescedLogs, logs...)
 			w.current.tcount++
+			w.current.rated[from]++
 			txs.Shift()
 
 		default:
@@ -908,6 +936,8 @@ func (w *worker) commitNewWork(interrupt *int32, noempty bool, timestamp int64)
 		Extra:      w.extra,
 		Time:       big.NewInt(timestamp),
 	}
+	header.GasLimit = core.CapGasLimit(w.config, header.Number, header.GasLimit)
+
 	// Only set the coinbase if our consensus engine is running (avoid spurious block rewards)
 	if w.isRunning() {
 		if w.coinbase == (common.Address{}) {
@@ -986,22 +1016,34 @@ func (w *worker) commitNewWork(interrupt *int32, noempty bool, timestamp int64)
 		w.updateSnapshot()
 		return
 	}
-	// Split the pending transactions into locals and remotes
-	localTxs, remoteTxs := make(map[common.Address]types.Transactions), pending
+	// Split the pending transactions into priority accounts, locals and remotes
+	priorityTxs, localTxs, remoteTxs := make(map[common.Address]types.Transactions), make(map[common.Address]types.Transactions), pending
+	for _, account := range w.eth.TxPool().Priority() {
+		if txs := remoteTxs[account]; len(txs) > 0 {
+			delete(remoteTxs, account)
+			priorityTxs[account] = txs
+		}
+	}
 	for _, account := range w.eth.TxPool().Locals() {
 		if txs := remoteTxs[account]; len(txs) > 0 {
 			delete(remoteTxs, account)
 			localTxs[account] = txs
 		}
 	}
+	if len(priorityTxs) > 0 {
+		txs := w.newTransactionSet(priorityTxs)
+		if w.commitTransactions(txs, w.coinbase, interrupt) {
+			return
+		}
+	}
 	if len(localTxs) > 0 {
-		txs := types.NewTransactionsByPriceAndNonce(w.current.signer, localTxs)
+		txs := w.newTransactionSet(localTxs)
 		if w.commitTransactions(txs, w.coinbase, interrupt) {
 			return
 		}
 	}
 	if len(remoteTxs) > 0 {
-		txs := types.NewTransactionsByPriceAndNonce(w.current.signer, remoteTxs)
+		txs := w.newTransactionSet(remoteTxs)
 		if w.commitTransactions(txs, w.coinbase, interrupt) {
 			return
 		}
@@ -1009,6 +1051,16 @@ func (w *worker) commitNewWork(interrupt *int32, noempty bool, timestamp int64)
 	w.commit(uncles, w.fullTaskHook, true, tstart)
 }
 
+// newTransactionSet orders the given transactions for inclusion in the current
+// block. Quorum chains have no gas price to order by, so accounts take turns,
+// priority accounts first, starting from a point rotated by the parent hash.
+func (w *worker) newTransactionSet(txs map[common.Address]types.Transactions) *types.TransactionsByPriceAndNonce {
+	if w.config.IsQuorum {
+		return types.NewTransactionsByRoundRobin(w.current.signer, txs, w.eth.TxPool().Priority(), w.current.header.ParentHash)
+	}
+	return types.NewTransactionsByPriceAndNonce(w.current.signer, txs)
+}
+
 // commit runs any post-transaction state modifications, assembles the final block
 // and commits new work if consensus engine is running.
 func (w *worker) commit(uncles []*types.Header, interval func(), update bool, start time.Time) error {
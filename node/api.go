@@ -59,6 +59,42 @@ func (api *PrivateAdminAPI) AddPeer(url string) (bool, error) {
 	return true, nil
 }
 
+// AddPermissionedNode allows a remote node to connect when node permissioning is
+// enabled, persisting it into permissioned-nodes.json.
+func (api *PrivateAdminAPI) AddPermissionedNode(url string) (bool, error) {
+	// Make sure the server is running, fail otherwise
+	server := api.node.Server()
+	if server == nil {
+		return false, ErrNodeStopped
+	}
+	node, err := enode.ParseV4(url)
+	if err != nil {
+		return false, fmt.Errorf("invalid enode: %v", err)
+	}
+	if err := server.AddPermissionedNode(node); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RemovePermissionedNode revokes the permission of a remote node to connect,
+// persisting the change into permissioned-nodes.json and disconnecting it.
+func (api *PrivateAdminAPI) RemovePermissionedNode(url string) (bool, error) {
+	// Make sure the server is running, fail otherwise
+	server := api.node.Server()
+	if server == nil {
+		return false, ErrNodeStopped
+	}
+	node, err := enode.ParseV4(url)
+	if err != nil {
+		return false, fmt.Errorf("invalid enode: %v", err)
+	}
+	if err := server.RemovePermissionedNode(node); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 // RemovePeer disconnects from a remote node if the connection exists
 func (api *PrivateAdminAPI) RemovePeer(url string) (bool, error) {
 	// Make sure the server is running, fail otherwise
@@ -75,6 +111,70 @@ func (api *PrivateAdminAPI) RemovePeer(url string) (bool, error) {
 	return true, nil
 }
 
+// BanPeer disconnects a remote node, given by enode URL or node ID, and refuses
+// its connections for the given number of seconds, or permanently if omitted.
+func (api *PrivateAdminAPI) BanPeer(node string, seconds *uint64, reason *string) (bool, error) {
+	// Make sure the server is running, fail otherwise
+	server := api.node.Server()
+	if server == nil {
+		return false, ErrNodeStopped
+	}
+	id, err := parseNodeID(node)
+	if err != nil {
+		return false, err
+	}
+	var duration time.Duration
+	if seconds != nil {
+		duration = time.Duration(*seconds) * time.Second
+	}
+	why := "banned by admin"
+	if reason != nil {
+		why = *reason
+	}
+	if err := server.BanPeer(id, duration, why); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// UnbanPeer lifts the ban of a remote node, given by enode URL or node ID.
+func (api *PrivateAdminAPI) UnbanPeer(node string) (bool, error) {
+	// Make sure the server is running, fail otherwise
+	server := api.node.Server()
+	if server == nil {
+		return false, ErrNodeStopped
+	}
+	id, err := parseNodeID(node)
+	if err != nil {
+		return false, err
+	}
+	if err := server.UnbanPeer(id); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// BannedPeers retrieves the currently banned nodes, why and until when.
+func (api *PrivateAdminAPI) BannedPeers() ([]p2p.BannedPeer, error) {
+	server := api.node.Server()
+	if server == nil {
+		return nil, ErrNodeStopped
+	}
+	return server.BannedPeers(), nil
+}
+
+// parseNodeID accepts an enode URL or a hex encoded node ID.
+func parseNodeID(node string) (enode.ID, error) {
+	if n, err := enode.ParseV4(node); err == nil {
+		return n.ID(), nil
+	}
+	var id enode.ID
+	if err := id.UnmarshalText([]byte(node)); err != nil {
+		return enode.ID{}, fmt.Errorf("invalid enode URL or node ID: %v", err)
+	}
+	return id, nil
+}
+
 // AddTrustedPeer allows a remote node to always connect, even if slots are full
 func (api *PrivateAdminAPI) AddTrustedPeer(url string) (bool, error) {
 	// Make sure the server is running, fail otherwise
@@ -188,7 +288,7 @@ func (api *PrivateAdminAPI) StartRPC(host *string, port *int, cors *string, apis
 		}
 	}
 
-	if err := api.node.startHTTP(fmt.Sprintf("%s:%d", *host, *port), api.node.rpcAPIs, modules, allowedOrigins, allowedVHosts, api.node.config.HTTPTimeouts); err != nil {
+	if err := api.node.startHTTP(fmt.Sprintf("%s:%d", *host, *port), api.node.rpcAPIs, modules, allowedOrigins, allowedVHosts, api.node.config.HTTPTimeouts, api.node.config.HTTPAuth); err != nil {
 		return false, err
 	}
 	return true, nil
@@ -242,7 +342,7 @@ func (api *PrivateAdminAPI) StartWS(host *string, port *int, allowedOrigins *str
 		}
 	}
 
-	if err := api.node.startWS(fmt.Sprintf("%s:%d", *host, *port), api.node.rpcAPIs, modules, origins, api.node.config.WSExposeAll); err != nil {
+	if err := api.node.startWS(fmt.Sprintf("%s:%d", *host, *port), api.node.rpcAPIs, modules, origins, api.node.config.WSExposeAll, api.node.config.WSAuth); err != nil {
 		return false, err
 	}
 	return true, nil
@@ -292,6 +392,24 @@ func (api *PublicAdminAPI) NodeInfo() (*p2p.NodeInfo, error) {
 	return server.NodeInfo(), nil
 }
 
+// PermissionedNodes retrieves the enode URLs of the nodes allowed to connect when
+// node permissioning is enabled.
+func (api *PublicAdminAPI) PermissionedNodes() ([]string, error) {
+	server := api.node.Server()
+	if server == nil {
+		return nil, ErrNodeStopped
+	}
+	nodes, err := server.PermissionedNodes()
+	if err != nil {
+		return nil, err
+	}
+	urls := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		urls = append(urls, n.String())
+	}
+	return urls, nil
+}
+
 // Datadir retrieves the current data directory the node is using.
 func (api *PublicAdminAPI) Datadir() string {
 	return api.node.DataDir()
@@ -42,6 +42,7 @@ const (
 	datadirStaticNodes     = "static-nodes.json"  // Path within the datadir to the static node list
 	datadirTrustedNodes    = "trusted-nodes.json" // Path within the datadir to the trusted node list
 	datadirNodeDatabase    = "nodes"              // Path within the datadir to store the node infos
+	datadirRPCAuditLog     = "rpc-audit.log"      // Path within the datadir to the log of denied RPC calls
 )
 
 // Config represents a small collection of configuration values to fine tune the
@@ -67,6 +68,11 @@ type Config struct {
 	// in memory.
 	DataDir string
 
+	// DatabaseEngine is the key-value backend used for new databases in the data
+	// directory (see ethdb.Engines). Existing databases are always opened with the
+	// engine that created them. If empty, ethdb.DefaultEngine is used.
+	DatabaseEngine string `toml:",omitempty"`
+
 	// Configuration of peer-to-peer networking.
 	P2P p2p.Config
 
@@ -124,6 +130,10 @@ type Config struct {
 	// interface.
 	HTTPTimeouts rpc.HTTPTimeouts
 
+	// HTTPAuth configures TLS, client certificate and bearer token authentication
+	// for the HTTP RPC interface.
+	HTTPAuth rpc.AuthConfig
+
 	// WSHost is the host interface on which to start the websocket RPC server. If
 	// this field is empty, no websocket API endpoint will be started.
 	WSHost string `toml:",omitempty"`
@@ -150,6 +160,10 @@ type Config struct {
 	// private APIs to untrusted users is a major security risk.
 	WSExposeAll bool `toml:",omitempty"`
 
+	// WSAuth configures TLS, client certificate and bearer token authentication
+	// for the websocket RPC interface.
+	WSAuth rpc.AuthConfig
+
 	EnableNodePermission bool `toml:",omitempty"`
 	// Logger is a custom logger to use with the p2p.Server.
 	Logger log.Logger `toml:",omitempty"`
@@ -18,6 +18,7 @@ package node
 
 import (
 	"crypto/ecdsa"
+	"net/http"
 	"reflect"
 
 	"github.com/ethereum/go-ethereum/accounts"
@@ -44,7 +45,7 @@ func (ctx *ServiceContext) OpenDatabase(name string, cache int, handles int) (et
 	if ctx.config.DataDir == "" {
 		return ethdb.NewMemDatabase(), nil
 	}
-	db, err := ethdb.NewLDBDatabase(ctx.config.ResolvePath(name), cache, handles)
+	db, err := ethdb.Open(ctx.config.DatabaseEngine, ctx.config.ResolvePath(name), cache, handles)
 	if err != nil {
 		return nil, err
 	}
@@ -102,3 +103,11 @@ type Service interface {
 	// are all terminated.
 	Stop() error
 }
+
+// HTTPService is implemented by services that serve plain HTTP handlers next to
+// JSON-RPC on the HTTP RPC endpoint, e.g. GraphQL.
+type HTTPService interface {
+	// HTTPHandlers retrieves the handlers the service provides, keyed by the
+	// path they are served on.
+	HTTPHandlers() map[string]http.Handler
+}
@@ -71,6 +71,8 @@ type dialstate struct {
 	maxDynDials int
 	ntab        discoverTable
 	netrestrict *netutil.Netlist
+	permitted   func(enode.ID) bool       // filters out non-permissioned nodes if set
+	banned      func(enode.ID, bool) bool // filters out banned nodes if set, the flag marks static nodes
 	self        enode.ID
 
 	lookupRunning bool
@@ -166,7 +168,7 @@ func (s *dialstate) newTasks(nRunning int, peers map[enode.ID]*Peer, now time.Ti
 
 	var newtasks []task
 	addDial := func(flag connFlag, n *enode.Node) bool {
-		if err := s.checkDial(n, peers); err != nil {
+		if err := s.checkDial(flag, n, peers); err != nil {
 			log.Trace("Skipping dial candidate", "id", n.ID(), "addr", &net.TCPAddr{IP: n.IP(), Port: n.TCP()}, "err", err)
 			return false
 		}
@@ -193,7 +195,7 @@ func (s *dialstate) newTasks(nRunning int, peers map[enode.ID]*Peer, now time.Ti
 
 	// Create dials for static nodes if they are not connected.
 	for id, t := range s.static {
-		err := s.checkDial(t.dest, peers)
+		err := s.checkDial(t.flags, t.dest, peers)
 		switch err {
 		case errNotWhitelisted, errSelf:
 			log.Warn("Removing static dial candidate", "id", t.dest.ID, "addr", &net.TCPAddr{IP: t.dest.IP(), Port: t.dest.TCP()}, "err", err)
@@ -258,9 +260,11 @@ var (
 	errAlreadyConnected = errors.New("already connected")
 	errRecentlyDialed   = errors.New("recently dialed")
 	errNotWhitelisted   = errors.New("not contained in netrestrict whitelist")
+	errNotPermissioned  = errors.New("node not permissioned")
+	errBanned           = errors.New("node is banned")
 )
 
-func (s *dialstate) checkDial(n *enode.Node, peers map[enode.ID]*Peer) error {
+func (s *dialstate) checkDial(flag connFlag, n *enode.Node, peers map[enode.ID]*Peer) error {
 	_, dialing := s.dialing[n.ID()]
 	switch {
 	case dialing:
@@ -271,6 +275,10 @@ func (s *dialstate) checkDial(n *enode.Node, peers map[enode.ID]*Peer) error {
 		return errSelf
 	case s.netrestrict != nil && !s.netrestrict.Contains(n.IP()):
 		return errNotWhitelisted
+	case s.permitted != nil && !s.permitted(n.ID()):
+		return errNotPermissioned
+	case s.banned != nil && s.banned(n.ID(), flag&staticDialedConn != 0):
+		return errBanned
 	case s.hist.contains(n.ID()):
 		return errRecentlyDialed
 	}
@@ -442,6 +442,35 @@ func TestDialStateNetRestrict(t *testing.T) {
 	})
 }
 
+// This test checks that non-permissioned candidates are not dialed.
+func TestDialStatePermitted(t *testing.T) {
+	table := fakeTable{
+		newNode(uintID(1), net.ParseIP("127.0.0.1")),
+		newNode(uintID(2), net.ParseIP("127.0.0.2")),
+		newNode(uintID(3), net.ParseIP("127.0.0.3")),
+		newNode(uintID(4), net.ParseIP("127.0.0.4")),
+	}
+	static := []*enode.Node{
+		newNode(uintID(5), net.ParseIP("127.0.0.5")),
+		newNode(uintID(6), net.ParseIP("127.0.0.6")),
+	}
+	dialer := newDialState(enode.ID{}, static, nil, table, 10, nil)
+	dialer.permitted = func(id enode.ID) bool { return id == uintID(2) || id == uintID(6) }
+
+	runDialTest(t, dialtest{
+		init: dialer,
+		rounds: []round{
+			{
+				new: []task{
+					&dialTask{flags: staticDialedConn, dest: static[1]},
+					&dialTask{flags: dynDialedConn, dest: table[1]},
+					&discoverTask{},
+				},
+			},
+		},
+	})
+}
+
 // This test checks that static dials are launched.
 func TestDialStateStaticDial(t *testing.T) {
 	wantStatic := []*enode.Node{
@@ -43,6 +43,7 @@ var (
 	errTimeout          = errors.New("RPC timeout")
 	errClockWarp        = errors.New("reply deadline too far in the future")
 	errClosed           = errors.New("socket closed")
+	errNotPermissioned  = errors.New("node not permissioned")
 )
 
 // Timeouts
@@ -127,6 +128,11 @@ func makeEndpoint(addr *net.UDPAddr, tcpPort uint16) rpcEndpoint {
 	return rpcEndpoint{IP: ip, UDP: uint16(addr.Port), TCP: tcpPort}
 }
 
+// permitted reports whether the node passes the configured node filter.
+func (t *udp) permitted(id enode.ID) bool {
+	return t.nodeFilter == nil || t.nodeFilter(id)
+}
+
 func (t *udp) nodeFromRPC(sender *net.UDPAddr, rn rpcNode) (*node, error) {
 	if rn.UDP <= 1024 {
 		return nil, errors.New("low port")
@@ -141,6 +147,9 @@ func (t *udp) nodeFromRPC(sender *net.UDPAddr, rn rpcNode) (*node, error) {
 	if err != nil {
 		return nil, err
 	}
+	if !t.permitted(enode.PubkeyToIDV4(key)) {
+		return nil, errNotPermissioned
+	}
 	n := wrapNode(enode.NewV4(key, rn.IP, int(rn.TCP), int(rn.UDP), 0))
 	err = n.ValidateComplete()
 	return n, err
@@ -171,6 +180,7 @@ type conn interface {
 type udp struct {
 	conn        conn
 	netrestrict *netutil.Netlist
+	nodeFilter  func(enode.ID) bool
 	priv        *ecdsa.PrivateKey
 	localNode   *enode.LocalNode
 	db          *enode.DB
@@ -231,9 +241,10 @@ type Config struct {
 	PrivateKey *ecdsa.PrivateKey
 
 	// These settings are optional:
-	NetRestrict *netutil.Netlist  // network whitelist
-	Bootnodes   []*enode.Node     // list of bootstrap nodes
-	Unhandled   chan<- ReadPacket // unhandled packets are sent on this channel
+	NetRestrict *netutil.Netlist    // network whitelist
+	NodeFilter  func(enode.ID) bool // excludes nodes from lookups and responses if set
+	Bootnodes   []*enode.Node       // list of bootstrap nodes
+	Unhandled   chan<- ReadPacket   // unhandled packets are sent on this channel
 }
 
 // ListenUDP returns a new table that listens for UDP packets on laddr.
@@ -250,6 +261,7 @@ func newUDP(c conn, ln *enode.LocalNode, cfg Config) (*Table, *udp, error) {
 		conn:        c,
 		priv:        cfg.PrivateKey,
 		netrestrict: cfg.NetRestrict,
+		nodeFilter:  cfg.NodeFilter,
 		localNode:   ln,
 		db:          ln.Database(),
 		closing:     make(chan struct{}),
@@ -630,6 +642,11 @@ func (req *ping) handle(t *udp, from *net.UDPAddr, fromKey encPubkey, mac []byte
 	})
 	n := wrapNode(enode.NewV4(key, from.IP, int(req.From.TCP), from.Port, 0))
 	t.handleReply(n.ID(), pingPacket, req)
+	// Non-permissioned nodes, e.g. bootnodes, may bond with us to answer our
+	// queries, but they are never added to the table
+	if !t.permitted(n.ID()) {
+		return errNotPermissioned
+	}
 	if time.Since(t.db.LastPongReceived(n.ID())) > bondExpiration {
 		t.sendPing(n.ID(), from, func() { t.tab.addThroughPing(n) })
 	} else {
@@ -671,6 +688,9 @@ func (req *findnode) handle(t *udp, from *net.UDPAddr, fromKey encPubkey, mac []
 		// findnode) to the victim.
 		return errUnknownNode
 	}
+	if !t.permitted(fromID) {
+		return errNotPermissioned
+	}
 	target := enode.ID(crypto.Keccak256Hash(req.Target[:]))
 	t.tab.mutex.Lock()
 	closest := t.tab.closest(target, bucketSize).entries
@@ -681,7 +701,7 @@ func (req *findnode) handle(t *udp, from *net.UDPAddr, fromKey encPubkey, mac []
 	// Send neighbors in chunks with at most maxNeighbors per packet
 	// to stay below the 1280 byte limit.
 	for _, n := range closest {
-		if netutil.CheckRelayIP(from.IP, n.IP()) == nil {
+		if netutil.CheckRelayIP(from.IP, n.IP()) == nil && t.permitted(n.ID()) {
 			p.Nodes = append(p.Nodes, nodeToRPC(n))
 		}
 		if len(p.Nodes) == maxNeighbors {
@@ -274,6 +274,59 @@ func TestUDP_findnode(t *testing.T) {
 	waitNeighbors(expected.entries[maxNeighbors:])
 }
 
+func TestUDP_findnodeFiltered(t *testing.T) {
+	test := newUDPTest(t)
+	defer test.table.Close()
+
+	// only nodes on even UDP ports are permitted
+	permitted := make(map[enode.ID]bool)
+	test.udp.nodeFilter = func(id enode.ID) bool { return permitted[id] }
+
+	nodes := &nodesByDistance{target: testTarget.id()}
+	for i := 0; i < maxNeighbors; i++ {
+		key := newkey()
+		n := wrapNode(enode.NewV4(&key.PublicKey, net.IP{10, 13, 0, 1}, 0, i, 0))
+		nodes.push(n, bucketSize)
+		permitted[n.ID()] = i%2 == 0
+	}
+	test.table.stuff(nodes.entries)
+
+	remoteID := encodePubkey(&test.remotekey.PublicKey).id()
+	test.table.db.UpdateLastPongReceived(remoteID, time.Now())
+
+	// check that non-permitted nodes can't query the table, and that they are
+	// answered but not added to it when pinging.
+	test.packetIn(errNotPermissioned, findnodePacket, &findnode{Target: testTarget, Expiration: futureExp})
+	test.packetIn(errNotPermissioned, pingPacket, &ping{From: testRemote, To: testLocalAnnounced, Version: 4, Expiration: futureExp})
+	test.waitPacketOut(func(p *pong) {})
+	test.table.mutex.Lock()
+	added := contains(test.table.bucket(remoteID).entries, remoteID)
+	test.table.mutex.Unlock()
+	if added {
+		t.Errorf("non-permitted node added to the table through ping")
+	}
+	permitted[remoteID] = true
+
+	// check that only permitted neighbors are returned.
+	test.packetIn(nil, findnodePacket, &findnode{Target: testTarget, Expiration: futureExp})
+	test.waitPacketOut(func(p *neighbors) {
+		if len(p.Nodes) != (maxNeighbors+1)/2 {
+			t.Errorf("wrong number of results: got %d, want %d", len(p.Nodes), (maxNeighbors+1)/2)
+		}
+		for _, n := range p.Nodes {
+			if !permitted[n.ID.id()] {
+				t.Errorf("non-permitted node returned: %v", n.ID.id())
+			}
+		}
+	})
+	// check that non-permitted nodes received from others are rejected.
+	denied := newkey()
+	rn := rpcNode{ID: encodePubkey(&denied.PublicKey), IP: net.IP{10, 13, 0, 2}, UDP: 30303, TCP: 30303}
+	if _, err := test.udp.nodeFromRPC(test.remoteaddr, rn); err == nil {
+		t.Errorf("non-permitted node accepted from neighbors response")
+	}
+}
+
 func TestUDP_findnodeMultiReply(t *testing.T) {
 	test := newUDPTest(t)
 	defer test.table.Close()
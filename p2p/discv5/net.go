@@ -34,8 +34,9 @@ import (
 )
 
 var (
-	errInvalidEvent = errors.New("invalid in current state")
-	errNoQuery      = errors.New("no pending query")
+	errInvalidEvent    = errors.New("invalid in current state")
+	errNoQuery         = errors.New("no pending query")
+	errNotPermissioned = errors.New("node not permissioned")
 )
 
 const (
@@ -57,6 +58,7 @@ type Network struct {
 	db          *nodeDB // database of known nodes
 	conn        transport
 	netrestrict *netutil.Netlist
+	nodeFilter  func(NodeID) bool // excludes nodes from the table and responses if set
 
 	closed           chan struct{}          // closed when loop is done
 	closeReq         chan struct{}          // 'request to close'
@@ -132,7 +134,7 @@ type timeoutEvent struct {
 	node *Node
 }
 
-func newNetwork(conn transport, ourPubkey ecdsa.PublicKey, dbPath string, netrestrict *netutil.Netlist) (*Network, error) {
+func newNetwork(conn transport, ourPubkey ecdsa.PublicKey, dbPath string, netrestrict *netutil.Netlist, nodeFilter func(NodeID) bool) (*Network, error) {
 	ourID := PubkeyID(&ourPubkey)
 
 	var db *nodeDB
@@ -148,6 +150,7 @@ func newNetwork(conn transport, ourPubkey ecdsa.PublicKey, dbPath string, netres
 		db:               db,
 		conn:             conn,
 		netrestrict:      netrestrict,
+		nodeFilter:       nodeFilter,
 		tab:              tab,
 		topictab:         newTopicTable(db, tab.self),
 		ticketStore:      newTicketStore(),
@@ -421,6 +424,10 @@ loop:
 		case pkt := <-net.read:
 			//fmt.Println("read", pkt.ev)
 			log.Trace("<-net.read")
+			if !net.permitted(pkt.remoteID) {
+				log.Trace("Ignoring packet from non-permissioned node", "id", pkt.remoteID, "addr", pkt.remoteAddr)
+				continue
+			}
 			n := net.internNode(&pkt)
 			prestate := n.state
 			status := "ok"
@@ -731,6 +738,25 @@ func (net *Network) internNodeFromDB(dbn *Node) *Node {
 	return n
 }
 
+// permitted reports whether the node passes the configured node filter.
+func (net *Network) permitted(id NodeID) bool {
+	return net.nodeFilter == nil || net.nodeFilter(id)
+}
+
+// permittedNodes returns the nodes passing the configured node filter.
+func (net *Network) permittedNodes(nodes []*Node) []*Node {
+	if net.nodeFilter == nil {
+		return nodes
+	}
+	permitted := make([]*Node, 0, len(nodes))
+	for _, n := range nodes {
+		if net.nodeFilter(n.ID) {
+			permitted = append(permitted, n)
+		}
+	}
+	return permitted
+}
+
 func (net *Network) internNodeFromNeighbours(sender *net.UDPAddr, rn rpcNode) (n *Node, err error) {
 	if rn.ID == net.tab.self.ID {
 		return nil, errors.New("is self")
@@ -741,6 +767,9 @@ func (net *Network) internNodeFromNeighbours(sender *net.UDPAddr, rn rpcNode) (n
 	n = net.nodes[rn.ID]
 	if n == nil {
 		// We haven't seen this node before.
+		if !net.permitted(rn.ID) {
+			return nil, errNotPermissioned
+		}
 		n, err = nodeFromRPC(sender, rn)
 		if net.netrestrict != nil && !net.netrestrict.Contains(n.IP) {
 			return n, errors.New("not contained in netrestrict whitelist")
@@ -1151,7 +1180,7 @@ func (net *Network) handleQueryEvent(n *Node, ev nodeEvent, pkt *ingressPacket)
 	case findnodePacket:
 		target := crypto.Keccak256Hash(pkt.data.(*findnode).Target[:])
 		results := net.tab.closest(target, bucketSize).entries
-		net.conn.sendNeighbours(n, results)
+		net.conn.sendNeighbours(n, net.permittedNodes(results))
 		return n.state, nil
 	case neighborsPacket:
 		err := net.handleNeighboursPacket(n, pkt)
@@ -1171,7 +1200,7 @@ func (net *Network) handleQueryEvent(n *Node, ev nodeEvent, pkt *ingressPacket)
 
 	case findnodeHashPacket:
 		results := net.tab.closest(pkt.data.(*findnodeHash).Target, bucketSize).entries
-		net.conn.sendNeighbours(n, results)
+		net.conn.sendNeighbours(n, net.permittedNodes(results))
 		return n.state, nil
 	case topicRegisterPacket:
 		//fmt.Println("got topicRegisterPacket")
@@ -28,7 +28,7 @@ import (
 
 func TestNetwork_Lookup(t *testing.T) {
 	key, _ := crypto.GenerateKey()
-	network, err := newNetwork(lookupTestnet, key.PublicKey, "", nil)
+	network, err := newNetwork(lookupTestnet, key.PublicKey, "", nil, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -372,3 +372,44 @@ func (tn *preminedTestnet) mine(target NodeID) {
 func injectResponse(net *Network, from *Node, ev nodeEvent, packet interface{}) {
 	go net.reqReadPacket(ingressPacket{remoteID: from.ID, remoteAddr: from.addr(), ev: ev, data: packet})
 }
+
+func TestNetwork_NodeFilter(t *testing.T) {
+	var (
+		self      = nodeIDFromKey(t)
+		permitted = nodeIDFromKey(t)
+		denied    = nodeIDFromKey(t)
+	)
+	network := &Network{
+		tab:        newTable(self, &net.UDPAddr{IP: net.IP{127, 0, 0, 1}, Port: 30303}),
+		nodes:      make(map[NodeID]*Node),
+		nodeFilter: func(id NodeID) bool { return id != denied },
+	}
+	sender := &net.UDPAddr{IP: net.IP{10, 0, 0, 1}, Port: 30303}
+
+	// Non-permissioned nodes announced by others must not be interned
+	if _, err := network.internNodeFromNeighbours(sender, rpcNode{ID: denied, IP: net.IP{10, 0, 0, 2}, UDP: 30303, TCP: 30303}); err != errNotPermissioned {
+		t.Errorf("non-permissioned neighbour error mismatch: have %v, want %v", err, errNotPermissioned)
+	}
+	if _, err := network.internNodeFromNeighbours(sender, rpcNode{ID: permitted, IP: net.IP{10, 0, 0, 3}, UDP: 30303, TCP: 30303}); err != nil {
+		t.Errorf("permissioned neighbour rejected: %v", err)
+	}
+	if _, ok := network.nodes[denied]; ok {
+		t.Errorf("non-permissioned node interned")
+	}
+	// Non-permissioned nodes must not be included in responses
+	nodes := []*Node{
+		NewNode(permitted, net.IP{10, 0, 0, 3}, 30303, 30303),
+		NewNode(denied, net.IP{10, 0, 0, 2}, 30303, 30303),
+	}
+	if results := network.permittedNodes(nodes); len(results) != 1 || results[0].ID != permitted {
+		t.Errorf("filtered nodes mismatch: have %v, want [%v]", results, nodes[0])
+	}
+}
+
+func nodeIDFromKey(t *testing.T) NodeID {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return PubkeyID(&key.PublicKey)
+}
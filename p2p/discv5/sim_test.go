@@ -282,7 +282,7 @@ func (s *simulation) launchNode(log bool) *Network {
 	addr := &net.UDPAddr{IP: ip, Port: 30303}
 
 	transport := &simTransport{joinTime: time.Now(), sender: id, senderAddr: addr, sim: s, priv: key}
-	net, err := newNetwork(transport, key.PublicKey, "<no database>", nil)
+	net, err := newNetwork(transport, key.PublicKey, "<no database>", nil, nil)
 	if err != nil {
 		panic("cannot launch new node: " + err.Error())
 	}
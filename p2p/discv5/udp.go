@@ -229,14 +229,15 @@ type udp struct {
 	net         *Network
 }
 
-// ListenUDP returns a new table that listens for UDP packets on laddr.
-func ListenUDP(priv *ecdsa.PrivateKey, conn conn, nodeDBPath string, netrestrict *netutil.Netlist) (*Network, error) {
+// ListenUDP returns a new table that listens for UDP packets on laddr. Nodes
+// rejected by the optional nodeFilter are kept out of the table and responses.
+func ListenUDP(priv *ecdsa.PrivateKey, conn conn, nodeDBPath string, netrestrict *netutil.Netlist, nodeFilter func(NodeID) bool) (*Network, error) {
 	realaddr := conn.LocalAddr().(*net.UDPAddr)
 	transport, err := listenUDP(priv, conn, realaddr)
 	if err != nil {
 		return nil, err
 	}
-	net, err := newNetwork(transport, priv.PublicKey, nodeDBPath, netrestrict)
+	net, err := newNetwork(transport, priv.PublicKey, nodeDBPath, netrestrict, nodeFilter)
 	if err != nil {
 		return nil, err
 	}
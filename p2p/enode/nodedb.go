@@ -39,6 +39,7 @@ import (
 const (
 	dbVersionKey = "version" // Version of the database to flush if changes
 	dbItemPrefix = "n:"      // Identifier to prefix node entries with
+	dbBanPrefix  = "ban:"    // Identifier to prefix node bans with, kept apart from expiring node entries
 
 	dbDiscoverRoot      = ":discover"
 	dbDiscoverSeq       = dbDiscoverRoot + ":seq"
@@ -336,6 +337,66 @@ func (db *DB) storeLocalSeq(id ID, n uint64) {
 	db.storeUint64(makeKey(id, dbLocalSeq), n)
 }
 
+// Ban records why and until when a node is banned.
+type Ban struct {
+	Expires   uint64 // Unix time the ban is lifted at, zero if permanent
+	Reason    string
+	Automatic bool // Whether the ban was imposed for faults rather than by an operator
+}
+
+// Expired reports whether the ban has been lifted at the given time.
+func (b *Ban) Expired(now time.Time) bool {
+	return b.Expires != 0 && uint64(now.Unix()) >= b.Expires
+}
+
+// Ban retrieves the ban of a node, nil if it isn't banned.
+func (db *DB) Ban(id ID) *Ban {
+	blob, err := db.lvl.Get(append([]byte(dbBanPrefix), id[:]...), nil)
+	if err != nil {
+		return nil
+	}
+	ban := new(Ban)
+	if err := rlp.DecodeBytes(blob, ban); err != nil {
+		return nil
+	}
+	return ban
+}
+
+// UpdateBan inserts - potentially overwriting - the ban of a node.
+func (db *DB) UpdateBan(id ID, ban *Ban) error {
+	blob, err := rlp.EncodeToBytes(ban)
+	if err != nil {
+		return err
+	}
+	return db.lvl.Put(append([]byte(dbBanPrefix), id[:]...), blob, nil)
+}
+
+// DeleteBan lifts the ban of a node.
+func (db *DB) DeleteBan(id ID) error {
+	return db.lvl.Delete(append([]byte(dbBanPrefix), id[:]...), nil)
+}
+
+// Bans retrieves the bans of all nodes, including expired ones.
+func (db *DB) Bans() map[ID]*Ban {
+	bans := make(map[ID]*Ban)
+
+	it := db.lvl.NewIterator(util.BytesPrefix([]byte(dbBanPrefix)), nil)
+	defer it.Release()
+	for it.Next() {
+		var id ID
+		if len(it.Key()) != len(dbBanPrefix)+len(id) {
+			continue
+		}
+		copy(id[:], it.Key()[len(dbBanPrefix):])
+		ban := new(Ban)
+		if err := rlp.DecodeBytes(it.Value(), ban); err != nil {
+			continue
+		}
+		bans[id] = ban
+	}
+	return bans
+}
+
 // QuerySeeds retrieves random nodes to be used as potential seed nodes
 // for bootstrapping.
 func (db *DB) QuerySeeds(n int, maxAge time.Duration) []*Node {
@@ -380,3 +380,39 @@ func TestDBExpiration(t *testing.T) {
 		}
 	}
 }
+
+func TestDBBans(t *testing.T) {
+	db, _ := OpenDB("")
+	defer db.Close()
+
+	id := ID{0x01}
+	if ban := db.Ban(id); ban != nil {
+		t.Fatalf("non-existing ban: %v", ban)
+	}
+	want := &Ban{Expires: 1000, Reason: "invalid blocks"}
+	if err := db.UpdateBan(id, want); err != nil {
+		t.Fatalf("failed to update ban: %v", err)
+	}
+	// Bans must outlive the discovery data of the node
+	if err := db.DeleteNode(id); err != nil {
+		t.Fatalf("failed to delete node: %v", err)
+	}
+	if ban := db.Ban(id); !reflect.DeepEqual(ban, want) {
+		t.Errorf("ban mismatch: have %v, want %v", ban, want)
+	}
+	if bans := db.Bans(); len(bans) != 1 || !reflect.DeepEqual(bans[id], want) {
+		t.Errorf("ban list mismatch: have %v", bans)
+	}
+	if !want.Expired(time.Unix(1000, 0)) || want.Expired(time.Unix(999, 0)) {
+		t.Error("wrong ban expiry")
+	}
+	if (&Ban{}).Expired(time.Now()) {
+		t.Error("permanent ban expired")
+	}
+	if err := db.DeleteBan(id); err != nil {
+		t.Fatalf("failed to delete ban: %v", err)
+	}
+	if ban := db.Ban(id); ban != nil {
+		t.Errorf("deleted ban still present: %v", ban)
+	}
+}
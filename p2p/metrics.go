@@ -38,6 +38,9 @@ const (
 	MetricsOutboundConnects = "p2p/OutboundConnects" // Name for the registered outbound connects meter
 	MetricsOutboundTraffic  = "p2p/OutboundTraffic"  // Name for the registered outbound traffic meter
 
+	MetricsPermissionDisconnects = "p2p/PermissionDisconnects" // Name for the registered permission disconnects meter
+	MetricsPeerBans              = "p2p/PeerBans"              // Name for the registered peer bans meter
+
 	MeteredPeerLimit = 1024 // This amount of peers are individually metered
 )
 
@@ -47,6 +50,9 @@ var (
 	egressConnectMeter  = metrics.NewRegisteredMeter(MetricsOutboundConnects, nil) // Meter counting the egress connections
 	egressTrafficMeter  = metrics.NewRegisteredMeter(MetricsOutboundTraffic, nil)  // Meter metering the cumulative egress traffic
 
+	permissionDisconnectMeter = metrics.NewRegisteredMeter(MetricsPermissionDisconnects, nil) // Meter counting peers dropped after losing permission
+	peerBanMeter              = metrics.NewRegisteredMeter(MetricsPeerBans, nil)              // Meter counting peers banned for misbehaving
+
 	PeerIngressRegistry = metrics.NewPrefixedChildRegistry(metrics.EphemeralRegistry, MetricsInboundTraffic+"/")  // Registry containing the peer ingress
 	PeerEgressRegistry  = metrics.NewPrefixedChildRegistry(metrics.EphemeralRegistry, MetricsOutboundTraffic+"/") // Registry containing the peer egress
 
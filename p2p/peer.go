@@ -113,12 +113,20 @@ type Peer struct {
 
 	// events receives message send / receive events if set
 	events *event.Feed
+
+	// faults receives the faults reported by the protocols if set
+	faults func(severity int, reason string)
 }
 
 // NewPeer returns a peer for testing purposes.
 func NewPeer(id enode.ID, name string, caps []Cap) *Peer {
+	return NewPeerFromNode(enode.SignNull(new(enr.Record), id), name, caps)
+}
+
+// NewPeerFromNode returns a peer for testing purposes of the given node, e.g. one
+// whose record carries a public key.
+func NewPeerFromNode(node *enode.Node, name string, caps []Cap) *Peer {
 	pipe, _ := net.Pipe()
-	node := enode.SignNull(new(enr.Record), id)
 	conn := &conn{fd: pipe, transport: nil, node: node, caps: caps, name: name}
 	peer := newPeer(conn, nil)
 	close(peer.closed) // ensures Disconnect doesn't block
@@ -190,6 +198,16 @@ func newPeer(conn *conn, protocols []Protocol) *Peer {
 	return p
 }
 
+// ReportFault reports misbehaviour of the peer, e.g. sending invalid blocks, to
+// the server. Peers accumulating too many faults are disconnected and banned.
+// Static and trusted peers are exempt. Severity is one of FaultMinor, FaultSevere
+// and FaultFatal.
+func (p *Peer) ReportFault(severity int, reason string) {
+	if p.faults != nil {
+		p.faults(severity, reason)
+	}
+}
+
 func (p *Peer) Log() log.Logger {
 	return p.log
 }
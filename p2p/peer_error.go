@@ -69,6 +69,8 @@ const (
 	DiscUnexpectedIdentity
 	DiscSelf
 	DiscReadTimeout
+	DiscNodeNotPermissioned
+	DiscPeerBanned
 	DiscSubprotocolError = 0x10
 )
 
@@ -85,6 +87,8 @@ var discReasonToString = [...]string{
 	DiscUnexpectedIdentity:  "unexpected identity",
 	DiscSelf:                "connected to self",
 	DiscReadTimeout:         "read timeout",
+	DiscNodeNotPermissioned: "node not permissioned",
+	DiscPeerBanned:          "peer banned",
 	DiscSubprotocolError:    "subprotocol error",
 }
 
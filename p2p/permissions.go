@@ -1,10 +1,30 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
 package p2p
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/p2p/enode"
@@ -15,26 +35,391 @@ const (
 	PERMISSIONED_CONFIG = "permissioned-nodes.json"
 )
 
-// check if a given node is permissioned to connect to the change
-func isNodePermissioned(nodename string, currentNode string, datadir string, direction string) bool {
+// permissionsWriteAttempts is how often an edit of permissioned-nodes.json is
+// retried when the file keeps being changed concurrently.
+const permissionsWriteAttempts = 3
+
+// permissionsCheckInterval is how often permissioned-nodes.json is checked for
+// modifications.
+var permissionsCheckInterval = 5 * time.Second
+
+const (
+	// permissionCacheSize bounds the number of cached permissions of dial and
+	// discovery candidates.
+	permissionCacheSize = 1024
+
+	// permissionChecksQueue is the number of candidates that may wait for their
+	// permission to be checked. Further ones are checked when next seen.
+	permissionChecksQueue = 256
+)
+
+// permissionCacheExpiry is how long a cached permission is trusted before it is
+// checked again.
+var permissionCacheExpiry = time.Minute
+
+// permissionDecision is the cached permission of a dial or discovery candidate.
+type permissionDecision struct {
+	permitted bool
+	checked   time.Time
+}
+
+// NodePermissioner is an alternative source of node admission decisions. When
+// one is set on the server it is consulted instead of permissioned-nodes.json.
+// Any error denies the node, except ErrPermissionsUnavailable.
+type NodePermissioner interface {
+	IsNodePermissioned(id enode.ID) (bool, error)
+}
+
+// nodeLister is implemented by permission sources that can enumerate the nodes
+// they allow.
+type nodeLister interface {
+	Nodes() ([]*enode.Node, error)
+}
+
+var (
+	// ErrPermissionsUnavailable is returned by permission sources that can't decide
+	// yet, e.g. a registry contract not deployed in the local state while syncing.
+	// Until they can, only the bootstrap nodes are admitted: the ones listed in
+	// permissioned-nodes.json, the static nodes and the bootnodes.
+	ErrPermissionsUnavailable = errors.New("node permissions unavailable")
+
+	errPermissionsFileChanged  = errors.New("permissioned-nodes.json changed concurrently, try again")
+	errPermissioningDisabled   = errors.New("node permissioning is disabled")
+	errPermissionSourceManaged = errors.New("permissioned nodes are managed by the permission source")
+)
+
+// SetNodePermissioner replaces the permissioned-nodes.json file with the given
+// permission source. Passing nil reverts to the file.
+func (srv *Server) SetNodePermissioner(p NodePermissioner) {
+	srv.permMu.Lock()
+	defer srv.permMu.Unlock()
 
-	var permissionedList []string
-	nodes := parsePermissionedNodes(datadir)
-	for _, v := range nodes {
-		permissionedList = append(permissionedList, v.ID().String())
+	srv.permissioner = p
+	srv.RecheckPermissions()
+}
+
+// RecheckPermissions asks the server to re-evaluate the permissions of all
+// connected peers, disconnecting the ones that are no longer allowed. It is
+// meant to be called by permission sources whenever their node list changes.
+func (srv *Server) RecheckPermissions() {
+	select {
+	case srv.permissionsChanged <- struct{}{}:
+	default:
 	}
+}
 
-	log.Debug("isNodePermissioned", "permissionedList", permissionedList)
-	for _, v := range permissionedList {
-		if v == nodename {
-			log.Debug("isNodePermissioned", "connection", direction, "nodename", nodename[:NODE_NAME_LENGTH], "ALLOWED-BY", currentNode[:NODE_NAME_LENGTH])
-			return true
+// PermissionedNodes returns the nodes allowed to connect.
+func (srv *Server) PermissionedNodes() ([]*enode.Node, error) {
+	srv.permMu.RLock()
+	defer srv.permMu.RUnlock()
+
+	if !srv.EnableNodePermission {
+		return nil, errPermissioningDisabled
+	}
+	if srv.permissioner != nil {
+		if lister, ok := srv.permissioner.(nodeLister); ok {
+			return lister.Nodes()
 		}
+		return nil, errPermissionSourceManaged
+	}
+	return append([]*enode.Node{}, srv.permissionedNodes...), nil
+}
+
+// AddPermissionedNode allows the given node to connect, persisting it into
+// permissioned-nodes.json. A node already in the list has its URL updated.
+func (srv *Server) AddPermissionedNode(node *enode.Node) error {
+	srv.permMu.Lock()
+	defer srv.permMu.Unlock()
+
+	if err := srv.checkPermissionsEditable(); err != nil {
+		return err
+	}
+	return srv.updatePermissionedNodes(func(current []*enode.Node) ([]*enode.Node, bool) {
+		nodes := make([]*enode.Node, 0, len(current)+1)
+		for _, n := range current {
+			if n.ID() != node.ID() {
+				nodes = append(nodes, n)
+			}
+		}
+		return append(nodes, node), true
+	})
+}
+
+// RemovePermissionedNode revokes the permission of the given node, persisting
+// the change into permissioned-nodes.json and dropping the node if connected.
+func (srv *Server) RemovePermissionedNode(node *enode.Node) error {
+	srv.permMu.Lock()
+	defer srv.permMu.Unlock()
+
+	if err := srv.checkPermissionsEditable(); err != nil {
+		return err
+	}
+	err := srv.updatePermissionedNodes(func(current []*enode.Node) ([]*enode.Node, bool) {
+		nodes := make([]*enode.Node, 0, len(current))
+		for _, n := range current {
+			if n.ID() != node.ID() {
+				nodes = append(nodes, n)
+			}
+		}
+		return nodes, len(nodes) != len(current)
+	})
+	if err != nil {
+		return err
+	}
+	srv.RecheckPermissions()
+	return nil
+}
+
+// updatePermissionedNodes applies an edit to permissioned-nodes.json and caches
+// the result. The edit is applied to the file as it is on disk rather than to
+// the cache, which may predate changes made by the operator, and the file is
+// only replaced if it didn't change in the meantime. The caller must hold permMu.
+func (srv *Server) updatePermissionedNodes(edit func([]*enode.Node) ([]*enode.Node, bool)) error {
+	path := filepath.Join(srv.DataDir, PERMISSIONED_CONFIG)
+	for i := 0; i < permissionsWriteAttempts; i++ {
+		blob, current, err := readPermissionedNodes(path)
+		if err != nil {
+			return err
+		}
+		nodes, changed := edit(current)
+		if !changed {
+			srv.permissionedNodes = current
+			return nil
+		}
+		switch err := savePermissionedNodes(path, nodes, blob); err {
+		case nil:
+			srv.permissionedNodes = nodes
+			return nil
+		case errPermissionsFileChanged:
+			continue
+		default:
+			return err
+		}
+	}
+	return errPermissionsFileChanged
+}
+
+// checkPermissionsEditable returns an error if the permissioned node list can't
+// be modified. The caller must hold permMu.
+func (srv *Server) checkPermissionsEditable() error {
+	if !srv.EnableNodePermission {
+		return errPermissioningDisabled
+	}
+	if srv.permissioner != nil {
+		return errPermissionSourceManaged
+	}
+	return nil
+}
+
+// loadPermissionedNodes refreshes the cached permissioned-nodes.json list.
+func (srv *Server) loadPermissionedNodes() {
+	nodes := parsePermissionedNodes(srv.DataDir)
+
+	srv.permMu.Lock()
+	defer srv.permMu.Unlock()
+
+	srv.permissionedNodes = nodes
+}
+
+// nodePermissioner returns the configured permission source, if any.
+func (srv *Server) nodePermissioner() NodePermissioner {
+	srv.permMu.RLock()
+	defer srv.permMu.RUnlock()
+
+	return srv.permissioner
+}
+
+// isPermissioned checks whether the remote node may connect, using the
+// configured permission source or falling back to permissioned-nodes.json.
+func (srv *Server) isPermissioned(id enode.ID, currentNode string, direction string) bool {
+	nodename := id.String()
+	permitted := srv.nodePermitted(id)
+	srv.cachePermission(id, permitted)
+	if permitted {
+		log.Debug("isNodePermissioned", "connection", direction, "nodename", nodename[:NODE_NAME_LENGTH], "ALLOWED-BY", currentNode[:NODE_NAME_LENGTH])
+		return true
 	}
 	log.Debug("isNodePermissioned", "connection", direction, "nodename", nodename[:NODE_NAME_LENGTH], "DENIED-BY", currentNode[:NODE_NAME_LENGTH])
 	return false
 }
 
+// nodePermitted checks whether the node is allowed to connect, without logging.
+// It may block on the permission source.
+func (srv *Server) nodePermitted(id enode.ID) bool {
+	if p := srv.nodePermissioner(); p != nil {
+		permitted, err := p.IsNodePermissioned(id)
+		if err != ErrPermissionsUnavailable {
+			return permitted && err == nil
+		}
+		return srv.isBootstrapNode(id)
+	}
+	return srv.isListedNode(id)
+}
+
+// cachedPermitted filters dial and discovery candidates. It never blocks on the
+// permission source, which may be slow: unknown nodes are reported as not
+// permitted until permissionLoop has checked them, and expired decisions are
+// used until refreshed. Connections are still checked against the source.
+func (srv *Server) cachedPermitted(id enode.ID) bool {
+	srv.permCacheMu.Lock()
+	decision, ok := srv.permCache[id]
+	srv.permCacheMu.Unlock()
+
+	if !ok || time.Since(decision.checked) > permissionCacheExpiry {
+		select {
+		case srv.permChecks <- id:
+		default:
+		}
+	}
+	return decision.permitted
+}
+
+// cachePermission records the permission of a node, evicting a random one if
+// the cache is full. It wakes up the dialer if the node became permitted.
+func (srv *Server) cachePermission(id enode.ID, permitted bool) {
+	srv.permCacheMu.Lock()
+	prev, ok := srv.permCache[id]
+	if !ok && len(srv.permCache) >= permissionCacheSize {
+		for evict := range srv.permCache {
+			delete(srv.permCache, evict)
+			break
+		}
+	}
+	srv.permCache[id] = permissionDecision{permitted: permitted, checked: time.Now()}
+	srv.permCacheMu.Unlock()
+
+	if permitted && !prev.permitted {
+		select {
+		case srv.permissionsCached <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// refreshPermissionCache checks the given candidate unless a recent decision is
+// cached, which happens when it was queued more than once.
+func (srv *Server) refreshPermissionCache(id enode.ID) {
+	srv.permCacheMu.Lock()
+	decision, ok := srv.permCache[id]
+	srv.permCacheMu.Unlock()
+
+	if ok && time.Since(decision.checked) <= permissionCacheExpiry {
+		return
+	}
+	srv.cachePermission(id, srv.nodePermitted(id))
+}
+
+// recheckPermissionCache re-evaluates all cached candidates after the permissions
+// changed.
+func (srv *Server) recheckPermissionCache() {
+	srv.permCacheMu.Lock()
+	ids := make([]enode.ID, 0, len(srv.permCache))
+	for id := range srv.permCache {
+		ids = append(ids, id)
+	}
+	srv.permCacheMu.Unlock()
+
+	for _, id := range ids {
+		srv.cachePermission(id, srv.nodePermitted(id))
+	}
+}
+
+// isBootstrapNode checks whether the node is one the operator configured to
+// connect to, admitted while the permission source is unavailable.
+func (srv *Server) isBootstrapNode(id enode.ID) bool {
+	if srv.isListedNode(id) {
+		return true
+	}
+	for _, nodes := range [][]*enode.Node{srv.StaticNodes, srv.BootstrapNodes} {
+		for _, n := range nodes {
+			if n.ID() == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isListedNode checks whether the node is in the cached permissioned-nodes.json.
+func (srv *Server) isListedNode(id enode.ID) bool {
+	srv.permMu.RLock()
+	defer srv.permMu.RUnlock()
+
+	for _, n := range srv.permissionedNodes {
+		if n.ID() == id {
+			return true
+		}
+	}
+	return false
+}
+
+// permissionLoop re-evaluates connected peers and cached candidates whenever
+// permissioned-nodes.json is modified or the permission source signals a change.
+// It also checks the candidates queued by the dialer and discovery, so that they
+// never wait for the permission source.
+func (srv *Server) permissionLoop() {
+	defer srv.loopWG.Done()
+
+	ticker := time.NewTicker(permissionsCheckInterval)
+	defer ticker.Stop()
+
+	// Check the nodes the operator configured first, the dialer needs them
+	for _, nodes := range [][]*enode.Node{srv.StaticNodes, srv.BootstrapNodes} {
+		for _, n := range nodes {
+			srv.refreshPermissionCache(n.ID())
+		}
+	}
+	lastMod := permissionsFileModTime(srv.DataDir)
+	for {
+		select {
+		case id := <-srv.permChecks:
+			srv.refreshPermissionCache(id)
+			continue
+		case <-ticker.C:
+			// The file is polled even with a permission source, which falls back
+			// to it while unavailable
+			mod := permissionsFileModTime(srv.DataDir)
+			if mod.Equal(lastMod) {
+				continue
+			}
+			lastMod = mod
+			srv.loadPermissionedNodes()
+		case <-srv.permissionsChanged:
+		case <-srv.quit:
+			return
+		}
+		srv.checkPeerPermissions()
+		srv.recheckPermissionCache()
+	}
+}
+
+// checkPeerPermissions disconnects all peers that are no longer permissioned.
+func (srv *Server) checkPeerPermissions() {
+	currentNode := srv.localnode.ID().String()
+	for _, p := range srv.Peers() {
+		direction := "OUTGOING"
+		if p.rw.is(inboundConn) {
+			direction = "INCOMING"
+		}
+		if srv.isPermissioned(p.ID(), currentNode, direction) {
+			continue
+		}
+		srv.log.Info("Disconnecting peer that is no longer permissioned", "id", p.ID(), "addr", p.RemoteAddr())
+		permissionDisconnectMeter.Mark(1)
+		p.Disconnect(DiscNodeNotPermissioned)
+	}
+}
+
+// permissionsFileModTime returns the modification time of permissioned-nodes.json,
+// or the zero time if the file doesn't exist.
+func permissionsFileModTime(datadir string) time.Time {
+	info, err := os.Stat(filepath.Join(datadir, PERMISSIONED_CONFIG))
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
 //this is a shameless copy from the config.go. It is a duplication of the code
 //for the timebeing to allow reload of the permissioned nodes while the server is running
 
@@ -59,7 +444,12 @@ func parsePermissionedNodes(DataDir string) []*enode.Node {
 		log.Error("parsePermissionedNodes: Failed to load nodes", "err", err)
 		return nil
 	}
-	// Interpret the list as a discovery node array
+	return decodePermissionedNodes(nodelist)
+}
+
+// decodePermissionedNodes interprets the list as a discovery node array,
+// skipping invalid URLs.
+func decodePermissionedNodes(nodelist []string) []*enode.Node {
 	var nodes []*enode.Node
 	for _, url := range nodelist {
 		if url == "" {
@@ -75,3 +465,50 @@ func parsePermissionedNodes(DataDir string) []*enode.Node {
 	}
 	return nodes
 }
+
+// readPermissionedNodes returns the raw content and the nodes of the given
+// permissioned-nodes.json, which may not exist yet. Unlike parsePermissionedNodes
+// it fails on a malformed file, which must not be overwritten.
+func readPermissionedNodes(path string) ([]byte, []*enode.Node, error) {
+	blob, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	nodelist := []string{}
+	if err := json.Unmarshal(blob, &nodelist); err != nil {
+		return nil, nil, fmt.Errorf("invalid %s: %v", PERMISSIONED_CONFIG, err)
+	}
+	return blob, decodePermissionedNodes(nodelist), nil
+}
+
+// savePermissionedNodes atomically replaces the given permissioned-nodes.json
+// with the nodes, provided it still has the content it was edited from (nil if
+// it didn't exist). Otherwise errPermissionsFileChanged is returned.
+func savePermissionedNodes(path string, nodes []*enode.Node, prev []byte) error {
+	urls := make([]string, 0, len(nodes))
+	for _, n := range nodes {
+		urls = append(urls, n.String())
+	}
+	blob, err := json.MarshalIndent(urls, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, blob, 0644); err != nil {
+		return err
+	}
+	// Check for concurrent edits as late as possible, right before the swap
+	current, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		os.Remove(tmp)
+		return err
+	}
+	if !bytes.Equal(current, prev) || (current == nil) != (prev == nil) {
+		os.Remove(tmp)
+		return errPermissionsFileChanged
+	}
+	return os.Rename(tmp, path)
+}
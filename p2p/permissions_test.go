@@ -0,0 +1,415 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+type revocablePermissioner struct {
+	lock    sync.Mutex
+	allowed map[enode.ID]bool
+}
+
+func (p *revocablePermissioner) IsNodePermissioned(id enode.ID) (bool, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return p.allowed[id], nil
+}
+
+func (p *revocablePermissioner) revoke(id enode.ID) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	delete(p.allowed, id)
+}
+
+// startPermissionedServer starts a permissioned test server and connects a
+// remote peer with the given key to it. The caller must close the returned
+// connection, which also keeps it from being garbage collected.
+func startPermissionedServer(t *testing.T, remoteKey *ecdsa.PublicKey, datadir string, permissioner NodePermissioner) (*Server, chan *PeerEvent, net.Conn) {
+	srv := &Server{
+		Config: Config{
+			Name:                 "test",
+			MaxPeers:             10,
+			ListenAddr:           "127.0.0.1:0",
+			PrivateKey:           newkey(),
+			EnableNodePermission: true,
+			DataDir:              datadir,
+		},
+		newTransport: func(fd net.Conn) transport { return newTestTransport(remoteKey, fd) },
+	}
+	if permissioner != nil {
+		srv.SetNodePermissioner(permissioner)
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatalf("could not start server: %v", err)
+	}
+	events := make(chan *PeerEvent, 10)
+	srv.SubscribeEvents(events)
+
+	conn, err := net.DialTimeout("tcp", srv.ListenAddr, 5*time.Second)
+	if err != nil {
+		t.Fatalf("could not dial: %v", err)
+	}
+	select {
+	case ev := <-events:
+		if ev.Type != PeerEventTypeAdd {
+			t.Fatalf("unexpected peer event: %v", ev.Type)
+		}
+	case <-time.After(time.Second):
+		conn.Close()
+		t.Fatal("server did not accept permissioned peer")
+	}
+	return srv, events, conn
+}
+
+// waitPermissionDrop waits for the peer to be dropped for losing its permission.
+func waitPermissionDrop(t *testing.T, events chan *PeerEvent, id enode.ID) {
+	select {
+	case ev := <-events:
+		if ev.Type != PeerEventTypeDrop || ev.Peer != id {
+			t.Fatalf("unexpected peer event: %v %v", ev.Type, ev.Peer)
+		}
+		if ev.Error != DiscNodeNotPermissioned.Error() {
+			t.Errorf("disconnect reason mismatch: have %q, want %q", ev.Error, DiscNodeNotPermissioned.Error())
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("peer not dropped after losing permission")
+	}
+}
+
+// Tests that peers are dropped when the permission source revokes them.
+func TestPermissionRevokedBySource(t *testing.T) {
+	remote := newkey()
+	id := enode.PubkeyToIDV4(&remote.PublicKey)
+
+	permissioner := &revocablePermissioner{allowed: map[enode.ID]bool{id: true}}
+	srv, events, conn := startPermissionedServer(t, &remote.PublicKey, "", permissioner)
+	defer srv.Stop()
+	defer conn.Close()
+
+	// Re-evaluating without changes must keep the peer
+	srv.RecheckPermissions()
+	select {
+	case ev := <-events:
+		t.Fatalf("unexpected peer event: %v", ev.Type)
+	case <-time.After(100 * time.Millisecond):
+	}
+	permissioner.revoke(id)
+	srv.RecheckPermissions()
+	waitPermissionDrop(t, events, id)
+}
+
+// Tests that peers are dropped when they are removed from permissioned-nodes.json.
+func TestPermissionRevokedByFile(t *testing.T) {
+	defer func(interval time.Duration) { permissionsCheckInterval = interval }(permissionsCheckInterval)
+	permissionsCheckInterval = 10 * time.Millisecond
+
+	datadir, err := ioutil.TempDir("", "p2p-permissions-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(datadir)
+
+	remote := newkey()
+	node := enode.NewV4(&remote.PublicKey, net.IP{127, 0, 0, 1}, 30303, 0, 0)
+	writePermissionedNodes(t, datadir, []string{node.String()}, time.Now())
+
+	srv, events, conn := startPermissionedServer(t, &remote.PublicKey, datadir, nil)
+	defer srv.Stop()
+	defer conn.Close()
+
+	writePermissionedNodes(t, datadir, []string{}, time.Now().Add(time.Minute))
+	waitPermissionDrop(t, events, node.ID())
+}
+
+func writePermissionedNodes(t *testing.T, datadir string, nodes []string, modtime time.Time) {
+	blob, err := json.Marshal(nodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(datadir, PERMISSIONED_CONFIG)
+	if err := ioutil.WriteFile(path, blob, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modtime, modtime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Tests that the permissioned node list can be edited at runtime, persisting
+// the changes into permissioned-nodes.json.
+func TestPermissionedNodesEdit(t *testing.T) {
+	datadir, err := ioutil.TempDir("", "p2p-permissions-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(datadir)
+
+	var (
+		remote = newkey()
+		node   = enode.NewV4(&remote.PublicKey, net.IP{127, 0, 0, 1}, 30303, 0, 0)
+		moved  = enode.NewV4(&remote.PublicKey, net.IP{127, 0, 0, 2}, 30303, 0, 0)
+		other  = enode.NewV4(&newkey().PublicKey, net.IP{127, 0, 0, 3}, 30303, 0, 0)
+	)
+	writePermissionedNodes(t, datadir, []string{other.String()}, time.Now())
+
+	srv := &Server{Config: Config{PrivateKey: newkey(), EnableNodePermission: true, DataDir: datadir}}
+	srv.loadPermissionedNodes()
+
+	// Re-adding a node must replace its URL rather than duplicate it
+	for _, n := range []*enode.Node{node, moved} {
+		if err := srv.AddPermissionedNode(n); err != nil {
+			t.Fatalf("failed to add node: %v", err)
+		}
+	}
+	checkPermissionedNodes(t, srv, []*enode.Node{other, moved})
+
+	// The file must be consistent with the cache
+	if nodes := parsePermissionedNodes(datadir); len(nodes) != 2 || nodes[1].String() != moved.String() {
+		t.Errorf("persisted nodes mismatch: have %v", nodes)
+	}
+	if err := srv.RemovePermissionedNode(other); err != nil {
+		t.Fatalf("failed to remove node: %v", err)
+	}
+	checkPermissionedNodes(t, srv, []*enode.Node{moved})
+	if nodes := parsePermissionedNodes(datadir); len(nodes) != 1 || nodes[0].String() != moved.String() {
+		t.Errorf("persisted nodes mismatch: have %v", nodes)
+	}
+	// Lists managed by a permission source can't be edited
+	srv.SetNodePermissioner(&revocablePermissioner{})
+	if err := srv.AddPermissionedNode(other); err != errPermissionSourceManaged {
+		t.Errorf("add error mismatch: have %v, want %v", err, errPermissionSourceManaged)
+	}
+	// Neither can the list of a server without permissioning
+	srv = &Server{Config: Config{PrivateKey: newkey(), DataDir: datadir}}
+	if err := srv.AddPermissionedNode(other); err != errPermissioningDisabled {
+		t.Errorf("add error mismatch: have %v, want %v", err, errPermissioningDisabled)
+	}
+}
+
+// Tests that edits through the server don't lose changes made to
+// permissioned-nodes.json since it was last loaded.
+func TestPermissionedNodesConcurrentEdit(t *testing.T) {
+	datadir, err := ioutil.TempDir("", "p2p-permissions-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(datadir)
+
+	var (
+		first  = enode.NewV4(&newkey().PublicKey, net.IP{127, 0, 0, 1}, 30303, 0, 0)
+		second = enode.NewV4(&newkey().PublicKey, net.IP{127, 0, 0, 2}, 30303, 0, 0)
+		added  = enode.NewV4(&newkey().PublicKey, net.IP{127, 0, 0, 3}, 30303, 0, 0)
+	)
+	writePermissionedNodes(t, datadir, []string{first.String()}, time.Now())
+
+	srv := &Server{Config: Config{PrivateKey: newkey(), EnableNodePermission: true, DataDir: datadir}}
+	srv.loadPermissionedNodes()
+
+	// The operator edits the file before the server reloads it
+	writePermissionedNodes(t, datadir, []string{first.String(), second.String()}, time.Now())
+	if err := srv.AddPermissionedNode(added); err != nil {
+		t.Fatalf("failed to add node: %v", err)
+	}
+	checkPermissionedNodes(t, srv, []*enode.Node{first, second, added})
+	if nodes := parsePermissionedNodes(datadir); len(nodes) != 3 {
+		t.Errorf("persisted nodes mismatch: have %v", nodes)
+	}
+	// A malformed file must be left alone for the operator to fix
+	path := filepath.Join(datadir, PERMISSIONED_CONFIG)
+	if err := ioutil.WriteFile(path, []byte("[\"enode://"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.RemovePermissionedNode(first); err == nil {
+		t.Errorf("edited malformed permissioned-nodes.json")
+	}
+	// The file is only replaced if it still has the content the edit is based on
+	if err := savePermissionedNodes(path, []*enode.Node{first}, []byte("[]")); err != errPermissionsFileChanged {
+		t.Errorf("save error mismatch: have %v, want %v", err, errPermissionsFileChanged)
+	}
+	if blob, _ := ioutil.ReadFile(path); string(blob) != "[\"enode://" {
+		t.Errorf("concurrently changed file overwritten: %s", blob)
+	}
+	os.Remove(path)
+	if err := savePermissionedNodes(path, []*enode.Node{first}, nil); err != nil {
+		t.Errorf("failed to create permissioned-nodes.json: %v", err)
+	}
+	if err := savePermissionedNodes(path, []*enode.Node{first}, nil); err != errPermissionsFileChanged {
+		t.Errorf("save error mismatch: have %v, want %v", err, errPermissionsFileChanged)
+	}
+}
+
+// Tests that removing a connected node from the permissioned list drops it.
+func TestPermissionedNodeRemoveConnected(t *testing.T) {
+	datadir, err := ioutil.TempDir("", "p2p-permissions-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(datadir)
+
+	remote := newkey()
+	node := enode.NewV4(&remote.PublicKey, net.IP{127, 0, 0, 1}, 30303, 0, 0)
+	writePermissionedNodes(t, datadir, []string{node.String()}, time.Now())
+
+	srv, events, conn := startPermissionedServer(t, &remote.PublicKey, datadir, nil)
+	defer srv.Stop()
+	defer conn.Close()
+
+	if err := srv.RemovePermissionedNode(node); err != nil {
+		t.Fatalf("failed to remove node: %v", err)
+	}
+	waitPermissionDrop(t, events, node.ID())
+}
+
+func checkPermissionedNodes(t *testing.T, srv *Server, want []*enode.Node) {
+	have, err := srv.PermissionedNodes()
+	if err != nil {
+		t.Fatalf("failed to list nodes: %v", err)
+	}
+	if len(have) != len(want) {
+		t.Fatalf("node count mismatch: have %d, want %d", len(have), len(want))
+	}
+	for i := range have {
+		if have[i].String() != want[i].String() {
+			t.Errorf("node %d mismatch: have %v, want %v", i, have[i], want[i])
+		}
+	}
+}
+
+type unavailablePermissioner struct{}
+
+func (unavailablePermissioner) IsNodePermissioned(id enode.ID) (bool, error) {
+	return false, ErrPermissionsUnavailable
+}
+
+type failingPermissioner struct{}
+
+func (failingPermissioner) IsNodePermissioned(id enode.ID) (bool, error) {
+	return true, errors.New("call timed out")
+}
+
+// Tests that only the bootstrap nodes are admitted while the permission source
+// is unavailable, and that no node is admitted if it fails.
+func TestPermissionSourceUnavailable(t *testing.T) {
+	var (
+		static  = enode.NewV4(&newkey().PublicKey, net.IP{127, 0, 0, 1}, 30303, 30303, 0)
+		boot    = enode.NewV4(&newkey().PublicKey, net.IP{127, 0, 0, 1}, 30304, 30304, 0)
+		listed  = enode.NewV4(&newkey().PublicKey, net.IP{127, 0, 0, 1}, 30305, 30305, 0)
+		unknown = enode.NewV4(&newkey().PublicKey, net.IP{127, 0, 0, 1}, 30306, 30306, 0)
+	)
+	srv := &Server{
+		Config: Config{
+			EnableNodePermission: true,
+			StaticNodes:          []*enode.Node{static},
+			BootstrapNodes:       []*enode.Node{boot},
+		},
+		permissionedNodes: []*enode.Node{listed},
+	}
+	srv.permissioner = unavailablePermissioner{}
+	for _, n := range []*enode.Node{static, boot, listed} {
+		if !srv.nodePermitted(n.ID()) {
+			t.Errorf("bootstrap node %v denied", n.ID())
+		}
+	}
+	if srv.nodePermitted(unknown.ID()) {
+		t.Errorf("unknown node %v admitted", unknown.ID())
+	}
+	srv.permissioner = failingPermissioner{}
+	if srv.nodePermitted(static.ID()) {
+		t.Errorf("node %v admitted by failing permission source", static.ID())
+	}
+}
+
+// blockingPermissioner is a permission source that doesn't answer until released.
+type blockingPermissioner struct {
+	revocablePermissioner
+	release chan struct{}
+}
+
+func (p *blockingPermissioner) IsNodePermissioned(id enode.ID) (bool, error) {
+	<-p.release
+	return p.revocablePermissioner.IsNodePermissioned(id)
+}
+
+// waitCachedPermission waits for the cached permission of the node to settle.
+func waitCachedPermission(t *testing.T, srv *Server, id enode.ID, want bool) {
+	for start := time.Now(); time.Since(start) < 2*time.Second; time.Sleep(10 * time.Millisecond) {
+		if srv.cachedPermitted(id) == want {
+			return
+		}
+	}
+	t.Fatalf("cached permission of %v mismatch: want %v", id, want)
+}
+
+// Tests that dial and discovery candidates are filtered without waiting for the
+// permission source, and that their cached permissions follow its changes.
+func TestPermissionCache(t *testing.T) {
+	id := enode.PubkeyToIDV4(&newkey().PublicKey)
+	permissioner := &blockingPermissioner{
+		revocablePermissioner: revocablePermissioner{allowed: map[enode.ID]bool{id: true}},
+		release:               make(chan struct{}),
+	}
+	srv := &Server{
+		Config: Config{
+			Name:                 "test",
+			MaxPeers:             10,
+			PrivateKey:           newkey(),
+			EnableNodePermission: true,
+			NoDiscovery:          true,
+		},
+	}
+	srv.SetNodePermissioner(permissioner)
+	if err := srv.Start(); err != nil {
+		t.Fatalf("could not start server: %v", err)
+	}
+	defer srv.Stop()
+	defer close(permissioner.release)
+
+	// Unknown candidates are denied while checked in the background
+	done := make(chan bool)
+	go func() { done <- srv.cachedPermitted(id) }()
+	select {
+	case permitted := <-done:
+		if permitted {
+			t.Fatal("unchecked candidate permitted")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("candidate filter blocked on the permission source")
+	}
+	permissioner.release <- struct{}{}
+	waitCachedPermission(t, srv, id, true)
+
+	// Revocations are picked up when the permission source signals a change
+	permissioner.revoke(id)
+	srv.RecheckPermissions()
+	permissioner.release <- struct{}{}
+	waitCachedPermission(t, srv, id, false)
+}
@@ -0,0 +1,261 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"bytes"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+// Fault severities reported through Peer.ReportFault. The fault score of a
+// peer halves every faultHalfLife and peers reaching banThreshold are banned.
+const (
+	FaultMinor  = 10  // Stalled requests, useless data
+	FaultSevere = 50  // Invalid blocks, transactions or protocol messages
+	FaultFatal  = 100 // Misbehaviour warranting an immediate ban
+)
+
+const (
+	banThreshold       = 100
+	faultHalfLife      = 10 * time.Minute
+	defaultBanDuration = time.Hour
+
+	maxFaultScores = 1024 // Number of scores tracked before decayed ones are pruned
+)
+
+// BannedPeer describes a banned node.
+type BannedPeer struct {
+	ID        enode.ID   `json:"id"`
+	Reason    string     `json:"reason"`
+	Expires   *time.Time `json:"expires"`   // nil if the ban is permanent
+	Automatic bool       `json:"automatic"` // true if banned for faults
+}
+
+// reputation tracks the fault scores of peers and bans the misbehaving ones.
+// Bans are persisted in the node database so they survive restarts.
+type reputation struct {
+	db          *enode.DB
+	banDuration time.Duration
+	now         func() time.Time
+
+	mu     sync.Mutex
+	scores map[enode.ID]*faultScore
+}
+
+type faultScore struct {
+	value   float64
+	updated time.Time
+}
+
+func newReputation(db *enode.DB, banDuration time.Duration) *reputation {
+	if banDuration <= 0 {
+		banDuration = defaultBanDuration
+	}
+	return &reputation{
+		db:          db,
+		banDuration: banDuration,
+		now:         time.Now,
+		scores:      make(map[enode.ID]*faultScore),
+	}
+}
+
+// decayed returns the score at the given time.
+func (s *faultScore) decayed(now time.Time) float64 {
+	return s.value * math.Pow(0.5, float64(now.Sub(s.updated))/float64(faultHalfLife))
+}
+
+// fault adds a fault to the score of the peer, banning it if the threshold is
+// reached. It reports whether the peer got banned.
+func (r *reputation) fault(id enode.ID, severity int, reason string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	score := r.scores[id]
+	if score == nil {
+		if len(r.scores) >= maxFaultScores {
+			r.prune(now)
+		}
+		score = new(faultScore)
+		r.scores[id] = score
+	}
+	score.value = score.decayed(now) + float64(severity)
+	score.updated = now
+	if score.value < banThreshold {
+		return false, nil
+	}
+	delete(r.scores, id)
+	return true, r.db.UpdateBan(id, &enode.Ban{
+		Expires:   uint64(now.Add(r.banDuration).Unix()),
+		Reason:    reason,
+		Automatic: true,
+	})
+}
+
+// prune drops the scores that decayed to insignificance.
+func (r *reputation) prune(now time.Time) {
+	for id, score := range r.scores {
+		if score.decayed(now) < 1 {
+			delete(r.scores, id)
+		}
+	}
+}
+
+// ban bans the node for the given duration, permanently if zero.
+func (r *reputation) ban(id enode.ID, duration time.Duration, reason string) error {
+	ban := &enode.Ban{Reason: reason}
+	if duration > 0 {
+		ban.Expires = uint64(r.now().Add(duration).Unix())
+	}
+	return r.db.UpdateBan(id, ban)
+}
+
+// unban lifts the ban of the node and resets its score.
+func (r *reputation) unban(id enode.ID) error {
+	r.mu.Lock()
+	delete(r.scores, id)
+	r.mu.Unlock()
+
+	return r.db.DeleteBan(id)
+}
+
+// banned reports whether the node is currently banned, dropping expired bans.
+// Automatic bans don't apply to exempt nodes.
+func (r *reputation) banned(id enode.ID, exempt bool) bool {
+	if r == nil {
+		return false
+	}
+	ban := r.db.Ban(id)
+	if ban == nil {
+		return false
+	}
+	if ban.Expired(r.now()) {
+		r.db.DeleteBan(id)
+		return false
+	}
+	return !(exempt && ban.Automatic)
+}
+
+// list returns the current bans, ordered by node ID.
+func (r *reputation) list() []BannedPeer {
+	now := r.now()
+
+	bans := make([]BannedPeer, 0)
+	for id, ban := range r.db.Bans() {
+		if ban.Expired(now) {
+			r.db.DeleteBan(id)
+			continue
+		}
+		banned := BannedPeer{ID: id, Reason: ban.Reason, Automatic: ban.Automatic}
+		if ban.Expires != 0 {
+			expires := time.Unix(int64(ban.Expires), 0)
+			banned.Expires = &expires
+		}
+		bans = append(bans, banned)
+	}
+	sort.Slice(bans, func(i, j int) bool {
+		return bytes.Compare(bans[i].ID[:], bans[j].ID[:]) < 0
+	})
+	return bans
+}
+
+// banExempt reports whether automatic bans are lifted for a node. Static and
+// trusted nodes are chosen by the operator, so only manual bans apply to them.
+// Permissioned nodes aren't exempt: being allowed on the network doesn't stop a
+// node from misbehaving, and automatic bans expire anyway.
+func (srv *Server) banExempt(chosen bool) bool {
+	return chosen
+}
+
+// banned reports whether a node is banned, chosen being set for static and
+// trusted nodes.
+func (srv *Server) banned(id enode.ID, chosen bool) bool {
+	return srv.reputation.banned(id, srv.banExempt(chosen))
+}
+
+// reportFault reports misbehaviour of a connected peer, unless it is exempt from
+// automatic bans.
+func (srv *Server) reportFault(c *conn, severity int, reason string) {
+	if srv.banExempt(c.is(trustedConn | staticDialedConn)) {
+		srv.log.Debug("Ignoring fault of exempt peer", "id", c.node.ID(), "reason", reason)
+		return
+	}
+	srv.ReportFault(c.node.ID(), severity, reason)
+}
+
+// ReportFault reports misbehaviour of a node, e.g. a peer sending invalid
+// blocks. Nodes accumulating too many faults are disconnected and banned for
+// BanDuration. Severity is one of FaultMinor, FaultSevere and FaultFatal.
+func (srv *Server) ReportFault(id enode.ID, severity int, reason string) {
+	if srv.reputation == nil {
+		return
+	}
+	banned, err := srv.reputation.fault(id, severity, reason)
+	if err != nil {
+		srv.log.Error("Failed to store peer ban", "id", id, "err", err)
+	}
+	if banned {
+		srv.log.Info("Banning misbehaving peer", "id", id, "reason", reason, "duration", srv.reputation.banDuration)
+		peerBanMeter.Mark(1)
+		srv.disconnectBanned(id)
+	}
+}
+
+// BanPeer bans a node for the given duration, or permanently if zero, and
+// disconnects it if connected.
+func (srv *Server) BanPeer(id enode.ID, duration time.Duration, reason string) error {
+	if srv.reputation == nil {
+		return errServerStopped
+	}
+	if err := srv.reputation.ban(id, duration, reason); err != nil {
+		return err
+	}
+	srv.log.Info("Banning peer", "id", id, "reason", reason, "duration", duration)
+	peerBanMeter.Mark(1)
+	srv.disconnectBanned(id)
+	return nil
+}
+
+// UnbanPeer lifts the ban of a node.
+func (srv *Server) UnbanPeer(id enode.ID) error {
+	if srv.reputation == nil {
+		return errServerStopped
+	}
+	return srv.reputation.unban(id)
+}
+
+// BannedPeers returns the currently banned nodes.
+func (srv *Server) BannedPeers() []BannedPeer {
+	if srv.reputation == nil {
+		return nil
+	}
+	return srv.reputation.list()
+}
+
+// disconnectBanned drops the connection to a banned node, if any.
+func (srv *Server) disconnectBanned(id enode.ID) {
+	for _, p := range srv.Peers() {
+		if p.ID() == id {
+			p.Disconnect(DiscPeerBanned)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package p2p
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+)
+
+func TestReputationFaults(t *testing.T) {
+	db, _ := enode.OpenDB("")
+	defer db.Close()
+
+	now := time.Unix(1000000, 0)
+	rep := newReputation(db, time.Hour)
+	rep.now = func() time.Time { return now }
+
+	// Minor faults spread out over time decay before reaching the threshold
+	id := enode.ID{0x01}
+	for i := 0; i < 20; i++ {
+		if banned, _ := rep.fault(id, FaultMinor, "slow"); banned {
+			t.Fatalf("peer banned after %d decayed minor faults", i+1)
+		}
+		now = now.Add(faultHalfLife)
+	}
+	// Repeated severe faults ban the peer until the ban duration passes
+	if banned, _ := rep.fault(id, FaultSevere, "invalid block"); banned {
+		t.Fatal("peer banned after single severe fault")
+	}
+	if banned, _ := rep.fault(id, FaultSevere, "invalid block"); !banned {
+		t.Fatal("peer not banned after repeated severe faults")
+	}
+	if !rep.banned(id, false) {
+		t.Fatal("ban not recorded")
+	}
+	bans := rep.list()
+	if len(bans) != 1 || bans[0].ID != id || bans[0].Reason != "invalid block" || !bans[0].Expires.Equal(now.Add(time.Hour)) {
+		t.Fatalf("ban list mismatch: %+v", bans)
+	}
+	now = now.Add(time.Hour)
+	if rep.banned(id, false) {
+		t.Fatal("ban not lifted after ban duration")
+	}
+	// Permanent bans stay until lifted
+	if err := rep.ban(id, 0, "manual"); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(24 * 365 * time.Hour)
+	if bans := rep.list(); len(bans) != 1 || bans[0].Expires != nil {
+		t.Fatalf("permanent ban mismatch: %+v", bans)
+	}
+	if err := rep.unban(id); err != nil {
+		t.Fatal(err)
+	}
+	if rep.banned(id, false) {
+		t.Fatal("ban not lifted by unban")
+	}
+	// Exempt nodes only honour manual bans
+	if banned, _ := rep.fault(id, FaultFatal, "invalid block"); !banned {
+		t.Fatal("peer not banned after fatal fault")
+	}
+	if rep.banned(id, true) {
+		t.Fatal("automatic ban applied to exempt node")
+	}
+	if err := rep.ban(id, time.Hour, "manual"); err != nil {
+		t.Fatal(err)
+	}
+	if !rep.banned(id, true) {
+		t.Fatal("manual ban not applied to exempt node")
+	}
+}
+
+// Tests that banned nodes are rejected after the encryption handshake.
+func TestServerSetupConnBanned(t *testing.T) {
+	remkey := newkey()
+	remid := enode.PubkeyToIDV4(&remkey.PublicKey)
+	tt := &setupTransport{pubkey: &remkey.PublicKey, phs: protoHandshake{ID: crypto.FromECDSAPub(&remkey.PublicKey)[1:]}}
+
+	srv := &Server{
+		Config: Config{
+			PrivateKey: newkey(),
+			MaxPeers:   10,
+			NoDial:     true,
+			Protocols:  []Protocol{discard},
+		},
+		newTransport: func(fd net.Conn) transport { return tt },
+		log:          log.New(),
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatalf("couldn't start server: %v", err)
+	}
+	defer srv.Stop()
+
+	if err := srv.BanPeer(remid, time.Hour, "test"); err != nil {
+		t.Fatalf("failed to ban peer: %v", err)
+	}
+	p1, _ := net.Pipe()
+	srv.SetupConn(p1, inboundConn, nil)
+	if tt.calls != "doEncHandshake,close," || tt.closeErr != DiscPeerBanned {
+		t.Fatalf("banned peer not rejected: calls %q, close error %v", tt.calls, tt.closeErr)
+	}
+	if bans := srv.BannedPeers(); len(bans) != 1 || bans[0].ID != remid {
+		t.Fatalf("ban list mismatch: %+v", bans)
+	}
+	if err := srv.UnbanPeer(remid); err != nil {
+		t.Fatalf("failed to unban peer: %v", err)
+	}
+	if bans := srv.BannedPeers(); len(bans) != 0 {
+		t.Fatalf("ban list not empty after unban: %+v", bans)
+	}
+}
+
+// Tests that trusted nodes are only rejected for manual bans.
+func TestServerSetupConnBanExempt(t *testing.T) {
+	remkey := newkey()
+	remid := enode.PubkeyToIDV4(&remkey.PublicKey)
+
+	srv := &Server{
+		Config: Config{
+			PrivateKey:   newkey(),
+			MaxPeers:     10,
+			NoDial:       true,
+			Protocols:    []Protocol{discard},
+			TrustedNodes: []*enode.Node{enode.NewV4(&remkey.PublicKey, net.IP{127, 0, 0, 1}, 30303, 0, 0)},
+		},
+		log: log.New(),
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatalf("couldn't start server: %v", err)
+	}
+	defer srv.Stop()
+
+	setup := func() *setupTransport {
+		tt := &setupTransport{pubkey: &remkey.PublicKey, phs: protoHandshake{ID: crypto.FromECDSAPub(&remkey.PublicKey)[1:]}}
+		srv.newTransport = func(fd net.Conn) transport { return tt }
+		p1, _ := net.Pipe()
+		srv.SetupConn(p1, inboundConn, nil)
+		return tt
+	}
+	// Automatic bans don't apply, the peer only fails for its missing protocols
+	srv.ReportFault(remid, FaultFatal, "invalid block")
+	if tt := setup(); tt.closeErr != DiscUselessPeer {
+		t.Fatalf("automatically banned trusted peer rejected: calls %q, close error %v", tt.calls, tt.closeErr)
+	}
+	if err := srv.BanPeer(remid, time.Hour, "test"); err != nil {
+		t.Fatalf("failed to ban peer: %v", err)
+	}
+	if tt := setup(); tt.calls != "doEncHandshake,close," || tt.closeErr != DiscPeerBanned {
+		t.Fatalf("manually banned trusted peer not rejected: calls %q, close error %v", tt.calls, tt.closeErr)
+	}
+}
+
+// Tests that peers reporting fatal faults are disconnected and banned.
+func TestServerReportFault(t *testing.T) {
+	connected := make(chan *Peer, 1)
+	remid := &newkey().PublicKey
+	srv := startTestServer(t, remid, func(p *Peer) { connected <- p })
+	defer srv.Stop()
+
+	events := make(chan *PeerEvent, 4)
+	sub := srv.SubscribeEvents(events)
+	defer sub.Unsubscribe()
+
+	conn, err := net.DialTimeout("tcp", srv.ListenAddr, 5*time.Second)
+	if err != nil {
+		t.Fatalf("could not dial: %v", err)
+	}
+	defer conn.Close()
+
+	var peer *Peer
+	select {
+	case peer = <-connected:
+	case <-time.After(time.Second):
+		t.Fatal("server did not accept within one second")
+	}
+	peer.ReportFault(FaultFatal, "invalid block")
+
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case ev := <-events:
+			if ev.Type != PeerEventTypeDrop {
+				continue
+			}
+			if ev.Error != DiscPeerBanned.Error() {
+				t.Errorf("disconnect reason mismatch: have %q, want %q", ev.Error, DiscPeerBanned.Error())
+			}
+			if !srv.reputation.banned(peer.ID(), false) {
+				t.Error("peer not banned")
+			}
+			return
+		case <-timeout:
+			t.Fatal("banned peer not disconnected")
+		}
+	}
+}
+
+// Tests that node permissioning doesn't exempt peers from automatic bans, which
+// expire after the ban duration.
+func TestServerPermissionedAutomaticBan(t *testing.T) {
+	remid := enode.PubkeyToIDV4(&newkey().PublicKey)
+
+	srv := &Server{
+		Config: Config{
+			PrivateKey:           newkey(),
+			MaxPeers:             10,
+			NoDial:               true,
+			Protocols:            []Protocol{discard},
+			EnableNodePermission: true,
+		},
+		log: log.New(),
+	}
+	if err := srv.Start(); err != nil {
+		t.Fatalf("couldn't start server: %v", err)
+	}
+	defer srv.Stop()
+
+	if srv.banExempt(false) {
+		t.Fatalf("permissioned peer exempt from automatic bans")
+	}
+	srv.ReportFault(remid, FaultFatal, "invalid block")
+	if !srv.banned(remid, false) {
+		t.Fatalf("permissioned peer not banned")
+	}
+	bans := srv.BannedPeers()
+	if len(bans) != 1 || bans[0].ID != remid || !bans[0].Automatic || bans[0].Expires == nil {
+		t.Fatalf("ban mismatch: %+v", bans)
+	}
+	// Once the ban expires the peer is accepted again
+	srv.reputation.now = func() time.Time { return time.Now().Add(srv.reputation.banDuration + time.Second) }
+	if srv.banned(remid, false) {
+		t.Fatalf("permissioned peer still banned after the ban duration")
+	}
+}
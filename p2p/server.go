@@ -149,6 +149,10 @@ type Config struct {
 
 	EnableNodePermission bool `toml:",omitempty"`
 
+	// BanDuration is how long peers reaching the fault threshold are banned.
+	// Zero defaults to one hour.
+	BanDuration time.Duration `toml:",omitempty"`
+
 	DataDir string `toml:",omitempty"`
 	// Logger is a custom logger to use with the p2p.Server.
 	Logger log.Logger `toml:",omitempty"`
@@ -168,6 +172,7 @@ type Server struct {
 	running bool
 
 	nodedb       *enode.DB
+	reputation   *reputation
 	localnode    *enode.LocalNode
 	ntab         discoverTable
 	listener     net.Listener
@@ -190,6 +195,17 @@ type Server struct {
 	loopWG        sync.WaitGroup // loop, listenLoop
 	peerFeed      event.Feed
 	log           log.Logger
+
+	permMu             sync.RWMutex     // protects permissioner and permissionedNodes
+	permissioner       NodePermissioner // replaces permissioned-nodes.json if set
+	permissionedNodes  []*enode.Node    // cached contents of permissioned-nodes.json
+	permissionsChanged chan struct{}    // signals permissionLoop to re-evaluate peers
+
+	permCacheMu sync.Mutex                      // protects permCache
+	permCache   map[enode.ID]permissionDecision // permissions of dial and discovery candidates
+	permChecks  chan enode.ID                   // candidates for permissionLoop to (re)check
+
+	permissionsCached chan struct{} // wakes up the dialer when a candidate becomes permitted
 }
 
 type peerOpFunc func(map[enode.ID]*Peer)
@@ -446,6 +462,14 @@ func (srv *Server) Start() (err error) {
 	srv.removetrusted = make(chan *enode.Node)
 	srv.peerOp = make(chan peerOpFunc)
 	srv.peerOpDone = make(chan struct{})
+	srv.permissionsChanged = make(chan struct{}, 1)
+	srv.permCache = make(map[enode.ID]permissionDecision)
+	srv.permChecks = make(chan enode.ID, permissionChecksQueue)
+	srv.permissionsCached = make(chan struct{}, 1)
+
+	if srv.EnableNodePermission {
+		srv.loadPermissionedNodes()
+	}
 
 	if err := srv.setupLocalNode(); err != nil {
 		return err
@@ -461,8 +485,17 @@ func (srv *Server) Start() (err error) {
 
 	dynPeers := srv.maxDialedConns()
 	dialer := newDialState(srv.localnode.ID(), srv.StaticNodes, srv.BootstrapNodes, srv.ntab, dynPeers, srv.NetRestrict)
+	if srv.EnableNodePermission {
+		dialer.permitted = srv.cachedPermitted
+	}
+	dialer.banned = srv.banned
 	srv.loopWG.Add(1)
 	go srv.run(dialer)
+
+	if srv.EnableNodePermission {
+		srv.loopWG.Add(1)
+		go srv.permissionLoop()
+	}
 	return nil
 }
 
@@ -481,6 +514,7 @@ func (srv *Server) setupLocalNode() error {
 		return err
 	}
 	srv.nodedb = db
+	srv.reputation = newReputation(db, srv.BanDuration)
 	srv.localnode = enode.NewLocalNode(db, srv.PrivateKey)
 	srv.localnode.SetFallbackIP(net.IP{127, 0, 0, 1})
 	srv.localnode.Set(capsByNameAndVersion(srv.ourHandshake.Caps))
@@ -547,6 +581,9 @@ func (srv *Server) setupDiscovery() error {
 			Bootnodes:   srv.BootstrapNodes,
 			Unhandled:   unhandled,
 		}
+		if srv.EnableNodePermission {
+			cfg.NodeFilter = srv.cachedPermitted
+		}
 		ntab, err := discover.ListenUDP(conn, srv.localnode, cfg)
 		if err != nil {
 			return err
@@ -555,12 +592,20 @@ func (srv *Server) setupDiscovery() error {
 	}
 	// Discovery V5
 	if srv.DiscoveryV5 {
-		var ntab *discv5.Network
-		var err error
+		var (
+			ntab   *discv5.Network
+			err    error
+			filter func(discv5.NodeID) bool
+		)
+		if srv.EnableNodePermission {
+			filter = func(id discv5.NodeID) bool {
+				return srv.cachedPermitted(enode.ID(crypto.Keccak256Hash(id[:])))
+			}
+		}
 		if sconn != nil {
-			ntab, err = discv5.ListenUDP(srv.PrivateKey, sconn, "", srv.NetRestrict)
+			ntab, err = discv5.ListenUDP(srv.PrivateKey, sconn, "", srv.NetRestrict, filter)
 		} else {
-			ntab, err = discv5.ListenUDP(srv.PrivateKey, conn, "", srv.NetRestrict)
+			ntab, err = discv5.ListenUDP(srv.PrivateKey, conn, "", srv.NetRestrict, filter)
 		}
 		if err != nil {
 			return err
@@ -697,6 +742,9 @@ running:
 			if p, ok := peers[n.ID()]; ok {
 				p.rw.set(trustedConn, false)
 			}
+		case <-srv.permissionsCached:
+			// A dial candidate became permissioned, the next round of scheduling
+			// may dial it.
 		case op := <-srv.peerOp:
 			// This channel is used by Peers and PeerCount.
 			op(peers)
@@ -733,6 +781,7 @@ running:
 				if srv.EnableMsgEvents {
 					p.events = &srv.peerFeed
 				}
+				p.faults = func(severity int, reason string) { srv.reportFault(c, severity, reason) }
 				name := truncateName(c.name)
 				srv.log.Debug("Adding p2p peer", "name", name, "addr", c.fd.RemoteAddr(), "peers", len(peers)+1)
 				go srv.runPeer(p)
@@ -803,6 +852,8 @@ func (srv *Server) encHandshakeChecks(peers map[enode.ID]*Peer, inboundCount int
 		return DiscAlreadyConnected
 	case c.node.ID() == srv.localnode.ID():
 		return DiscSelf
+	case srv.banned(c.node.ID(), c.is(trustedConn|staticDialedConn)):
+		return DiscPeerBanned
 	default:
 		return nil
 	}
@@ -941,15 +992,15 @@ func (srv *Server) setupConn(c *conn, flags connFlag, dialDest *enode.Node) erro
 
 	if srv.EnableNodePermission {
 		clog.Trace("Node Permissioning is Enabled.")
-		node := c.node.ID().String()
+		node := c.node.ID()
 		direction := "INCOMING"
 		if dialDest != nil {
-			node = dialDest.ID().String()
+			node = dialDest.ID()
 			direction = "OUTGOING"
 			log.Trace("Node Permissioning", "Connection Direction", direction)
 		}
 
-		if !isNodePermissioned(node, currentNode, srv.DataDir, direction) {
+		if !srv.isPermissioned(node, currentNode, direction) {
 			return nil
 		}
 	} else {
@@ -567,6 +567,59 @@ func TestServerSetupConn(t *testing.T) {
 	}
 }
 
+type testPermissioner map[enode.ID]bool
+
+func (p testPermissioner) IsNodePermissioned(id enode.ID) (bool, error) {
+	return p[id], nil
+}
+
+// Tests that a configured permission source decides node admission.
+func TestServerSetupConnPermissioner(t *testing.T) {
+	var (
+		allowedkey, deniedkey = newkey(), newkey()
+		allowedpub            = &allowedkey.PublicKey
+		deniedpub             = &deniedkey.PublicKey
+	)
+	permissioner := testPermissioner{enode.PubkeyToIDV4(allowedpub): true}
+
+	tests := []struct {
+		tt        *setupTransport
+		wantCalls string
+	}{
+		{
+			tt:        &setupTransport{pubkey: deniedpub, phs: protoHandshake{ID: crypto.FromECDSAPub(deniedpub)[1:]}},
+			wantCalls: "doEncHandshake,",
+		},
+		{
+			tt:        &setupTransport{pubkey: allowedpub, phs: protoHandshake{ID: crypto.FromECDSAPub(allowedpub)[1:]}},
+			wantCalls: "doEncHandshake,doProtoHandshake,close,",
+		},
+	}
+	for i, test := range tests {
+		srv := &Server{
+			Config: Config{
+				PrivateKey:           newkey(),
+				MaxPeers:             10,
+				NoDial:               true,
+				Protocols:            []Protocol{discard},
+				EnableNodePermission: true,
+			},
+			newTransport: func(fd net.Conn) transport { return test.tt },
+			log:          log.New(),
+		}
+		srv.SetNodePermissioner(permissioner)
+		if err := srv.Start(); err != nil {
+			t.Fatalf("couldn't start server: %v", err)
+		}
+		p1, _ := net.Pipe()
+		srv.SetupConn(p1, inboundConn, nil)
+		if test.tt.calls != test.wantCalls {
+			t.Errorf("test %d: calls mismatch: got %q, want %q", i, test.tt.calls, test.wantCalls)
+		}
+		srv.Stop()
+	}
+}
+
 type setupTransport struct {
 	pubkey            *ecdsa.PublicKey
 	encHandshakeErr   error
@@ -132,19 +132,19 @@ var (
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllEthashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, new(EthashConfig), nil, nil, false, 32}
+	AllEthashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, new(EthashConfig), nil, nil, false, 32, nil, nil, nil}
 
 	// AllCliqueProtocolChanges contains every protocol change (EIPs) introduced
 	// and accepted by the Ethereum core developers into the Clique consensus.
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, &CliqueConfig{Period: 0, Epoch: 30000}, nil, false, 32}
+	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, &CliqueConfig{Period: 0, Epoch: 30000}, nil, false, 32, nil, nil, nil}
 
-	TestChainConfig = &ChainConfig{big.NewInt(10), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, new(EthashConfig), nil, nil, false, 32}
+	TestChainConfig = &ChainConfig{big.NewInt(10), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, new(EthashConfig), nil, nil, false, 32, nil, nil, nil}
 	TestRules       = TestChainConfig.Rules(new(big.Int))
 
-	QuorumTestChainConfig = &ChainConfig{big.NewInt(10), big.NewInt(0), nil, false, nil, common.Hash{}, nil, nil, nil, nil, nil, new(EthashConfig), nil, nil, true, 64}
+	QuorumTestChainConfig = &ChainConfig{big.NewInt(10), big.NewInt(0), nil, false, nil, common.Hash{}, nil, nil, nil, nil, nil, new(EthashConfig), nil, nil, true, 64, nil, nil, nil}
 )
 
 // TrustedCheckpoint represents a set of post-processed trie roots (CHT and
@@ -188,8 +188,12 @@ type ChainConfig struct {
 	Clique   *CliqueConfig   `json:"clique,omitempty"`
 	Istanbul *IstanbulConfig `json:"istanbul,omitempty"`
 
-	IsQuorum             bool   `json:"isQuorum"`
-	TransactionSizeLimit uint64 `json:"txnSizeLimit"`
+	IsQuorum             bool                  `json:"isQuorum"`
+	TransactionSizeLimit uint64                `json:"txnSizeLimit"`
+	GasLimits            *QuorumGasLimitConfig `json:"gasLimits,omitempty"` // Block and transaction gas ceilings of a Quorum chain (nil = unbounded)
+
+	AccountPermissionsBlock *big.Int                  `json:"accountPermissionsBlock,omitempty"` // Block from which account permissions are enforced (nil = never)
+	AccountPermissions      *AccountPermissionsConfig `json:"accountPermissions,omitempty"`      // Restricts which accounts may write to the ledger (nil = unrestricted)
 }
 
 // EthashConfig is the consensus engine configs for proof-of-work based sealing.
@@ -211,10 +215,45 @@ func (c *CliqueConfig) String() string {
 	return "clique"
 }
 
+// MaxIstanbulProposerWeight is the largest proposer weight a validator may be
+// given, keeping the total weight of any validator set far from overflowing.
+const MaxIstanbulProposerWeight = 1 << 16
+
 // IstanbulConfig is the consensus engine configs for Istanbul based sealing.
 type IstanbulConfig struct {
-	Epoch          uint64 `json:"epoch"`  // Epoch length to reset votes and checkpoint
-	ProposerPolicy uint64 `json:"policy"` // The policy for proposer selection
+	Epoch          uint64                    `json:"epoch"`             // Epoch length to reset votes and checkpoint
+	ProposerPolicy uint64                    `json:"policy"`            // The policy for proposer selection
+	Weights        map[common.Address]uint64 `json:"weights,omitempty"` // Relative proposer weights for the weighted policy
+
+	EmptyBlockPeriod uint64 `json:"emptyblockperiod,omitempty"` // Maximum seconds an empty block is held back after its parent, 0 to disable
+
+	Transitions []IstanbulTransition `json:"transitions,omitempty"` // Changes scheduled at fixed block heights, in ascending block order
+}
+
+// IstanbulTransition schedules a change of the Istanbul validator set, block
+// period or request timeout from the given block onwards. Zero values leave the
+// corresponding setting unchanged.
+type IstanbulTransition struct {
+	Block          *big.Int         `json:"block"`                    // Block number from which the transition is effective
+	Validators     []common.Address `json:"validators,omitempty"`     // Validator set replacing the voted one at Block
+	BlockPeriod    uint64           `json:"blockperiod,omitempty"`    // Minimum number of seconds between blocks
+	RequestTimeout uint64           `json:"requesttimeout,omitempty"` // Round timeout in milliseconds
+}
+
+// equal returns whether two transitions schedule the same change.
+func (t *IstanbulTransition) equal(o *IstanbulTransition) bool {
+	if !configNumEqual(t.Block, o.Block) || t.BlockPeriod != o.BlockPeriod || t.RequestTimeout != o.RequestTimeout {
+		return false
+	}
+	if len(t.Validators) != len(o.Validators) {
+		return false
+	}
+	for i := range t.Validators {
+		if t.Validators[i] != o.Validators[i] {
+			return false
+		}
+	}
+	return true
 }
 
 // String implements the stringer interface, returning the consensus engine details.
@@ -222,6 +261,139 @@ func (c *IstanbulConfig) String() string {
 	return "istanbul"
 }
 
+// Validate checks the Istanbul settings for values that would break consensus.
+func (c *IstanbulConfig) Validate() error {
+	for addr, weight := range c.Weights {
+		if weight > MaxIstanbulProposerWeight {
+			return fmt.Errorf("Istanbul proposer weight of %x must be at most %d, have %d", addr, MaxIstanbulProposerWeight, weight)
+		}
+	}
+	for i, t := range c.Transitions {
+		if t.Block == nil || t.Block.Sign() < 0 {
+			return fmt.Errorf("Istanbul transition %d must set a block number", i)
+		}
+		// The genesis extra-data defines the initial validators, a transition can
+		// only replace them from block 1 onwards
+		if t.Block.Sign() == 0 && len(t.Validators) > 0 {
+			return fmt.Errorf("Istanbul transition %d at the genesis block can't set validators, use the genesis extra-data", i)
+		}
+		if i > 0 && t.Block.Cmp(c.Transitions[i-1].Block) <= 0 {
+			return fmt.Errorf("Istanbul transitions must be in strictly ascending block order, transition %d at block %v follows block %v", i, t.Block, c.Transitions[i-1].Block)
+		}
+	}
+	return nil
+}
+
+// sameProposerSelection returns whether two configs select proposers alike.
+func (c *IstanbulConfig) sameProposerSelection(o *IstanbulConfig) bool {
+	if c.ProposerPolicy != o.ProposerPolicy || len(c.Weights) != len(o.Weights) {
+		return false
+	}
+	for addr, weight := range c.Weights {
+		if w, ok := o.Weights[addr]; !ok || w != weight {
+			return false
+		}
+	}
+	return true
+}
+
+// QuorumGasLimitConfig bounds the gas of the blocks and transactions of a Quorum
+// chain from a given block onwards. Quorum consensus engines don't verify the
+// header gas limit, so without it a single transaction may run for as long as
+// its own gas allows.
+type QuorumGasLimitConfig struct {
+	Block         *big.Int `json:"block"`                   // Block number from which the limits are enforced
+	BlockGasLimit uint64   `json:"blockGasLimit,omitempty"` // Maximum gas limit of a block (0 = GenesisGasLimit)
+	TxGasLimit    uint64   `json:"txGasLimit,omitempty"`    // Maximum gas of a single transaction (0 = block gas limit)
+}
+
+// AccountAccess is the level of access an account has to the ledger. Each level
+// includes the rights of the levels below it.
+type AccountAccess uint8
+
+const (
+	AccountAccessUnset          AccountAccess = iota // No explicit access, fall back to the default
+	AccountAccessReadOnly                            // May not send transactions
+	AccountAccessTransact                            // May send transactions, but not deploy contracts
+	AccountAccessContractDeploy                      // May send transactions and deploy contracts
+	AccountAccessAdmin                               // May also administer the permissions contract
+)
+
+var accountAccessNames = map[AccountAccess]string{
+	AccountAccessReadOnly:       "readonly",
+	AccountAccessTransact:       "transact",
+	AccountAccessContractDeploy: "contractdeploy",
+	AccountAccessAdmin:          "admin",
+}
+
+// String implements the fmt.Stringer interface.
+func (a AccountAccess) String() string {
+	if name, ok := accountAccessNames[a]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown(%d)", uint8(a))
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (a AccountAccess) MarshalText() ([]byte, error) {
+	if name, ok := accountAccessNames[a]; ok {
+		return []byte(name), nil
+	}
+	return nil, fmt.Errorf("unknown account access %d", uint8(a))
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (a *AccountAccess) UnmarshalText(text []byte) error {
+	for access, name := range accountAccessNames {
+		if name == string(text) {
+			*a = access
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown account access %q, want one of readonly, transact, contractdeploy or admin", text)
+}
+
+// AccountPermissionsConfig restricts what accounts are allowed to do on chain.
+// The access of an account is looked up in the permissions contract first, then
+// in the statically listed accounts, falling back to the default access. With
+// no default set, unlisted accounts are read-only.
+type AccountPermissionsConfig struct {
+	DefaultAccess AccountAccess                    `json:"defaultAccess,omitempty"` // Access of accounts not listed anywhere
+	Accounts      map[common.Address]AccountAccess `json:"accounts,omitempty"`      // Access of individual accounts
+	Contract      *common.Address                  `json:"contract,omitempty"`      // Permissions contract holding the access of accounts
+	AccessSlot    *uint64                          `json:"accessSlot,omitempty"`    // Storage slot of the contract's address => uint8 access mapping, required with a contract
+}
+
+// validate checks that the account permissions can be enforced.
+func (c *AccountPermissionsConfig) validate() error {
+	if c.Contract != nil && c.AccessSlot == nil {
+		return errors.New("Genesis account permissions must declare the accessSlot of the permissions contract")
+	}
+	if c.AccessSlot != nil && c.Contract == nil {
+		return errors.New("Genesis account permissions declare an accessSlot without a contract")
+	}
+	return nil
+}
+
+// equal reports whether the two configs enforce the same permissions.
+func (c *AccountPermissionsConfig) equal(other *AccountPermissionsConfig) bool {
+	if c == nil || other == nil {
+		return c == other
+	}
+	if c.DefaultAccess != other.DefaultAccess || len(c.Accounts) != len(other.Accounts) {
+		return false
+	}
+	for addr, access := range c.Accounts {
+		if other.Accounts[addr] != access {
+			return false
+		}
+	}
+	if (c.Contract == nil) != (other.Contract == nil) || (c.Contract != nil && *c.Contract != *other.Contract) {
+		return false
+	}
+	return (c.AccessSlot == nil) == (other.AccessSlot == nil) && (c.AccessSlot == nil || *c.AccessSlot == *other.AccessSlot)
+}
+
 // String implements the fmt.Stringer interface.
 func (c *ChainConfig) String() string {
 	var engine interface{}
@@ -254,6 +426,30 @@ func (c *ChainConfig) IsValid() error {
 	if c.TransactionSizeLimit < 32 || c.TransactionSizeLimit > 128 {
 		return errors.New("Genesis transaction size limit must be between 32 and 128")
 	}
+	if limits := c.GasLimits; limits != nil {
+		if limits.Block == nil {
+			return errors.New("Genesis gas limits must set the block to enforce them from")
+		}
+		if limits.BlockGasLimit != 0 && limits.BlockGasLimit < TxGas {
+			return fmt.Errorf("Genesis block gas limit must be at least %d", TxGas)
+		}
+		if limits.TxGasLimit != 0 && limits.TxGasLimit < TxGas {
+			return fmt.Errorf("Genesis transaction gas limit must be at least %d", TxGas)
+		}
+	}
+	if c.Istanbul != nil {
+		if err := c.Istanbul.Validate(); err != nil {
+			return err
+		}
+	}
+	if c.AccountPermissions != nil {
+		if c.AccountPermissionsBlock == nil {
+			return errors.New("Genesis account permissions must set the accountPermissionsBlock to enforce them from")
+		}
+		if err := c.AccountPermissions.validate(); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
@@ -298,6 +494,34 @@ func (c *ChainConfig) IsEWASM(num *big.Int) bool {
 	return isForked(c.EWASMBlock, num)
 }
 
+// IsAccountPermissions returns whether account permissions are enforced in block
+// num.
+func (c *ChainConfig) IsAccountPermissions(num *big.Int) bool {
+	return c.AccountPermissions != nil && isForked(c.AccountPermissionsBlock, num)
+}
+
+// QuorumBlockGasLimit returns the maximum gas limit of block num of a Quorum
+// chain, or zero if block gas limits are not enforced at num.
+func (c *ChainConfig) QuorumBlockGasLimit(num *big.Int) uint64 {
+	if !c.IsQuorum || c.GasLimits == nil || !isForked(c.GasLimits.Block, num) {
+		return 0
+	}
+	if c.GasLimits.BlockGasLimit == 0 {
+		return GenesisGasLimit
+	}
+	return c.GasLimits.BlockGasLimit
+}
+
+// QuorumTxGasLimit returns the maximum gas of a transaction in block num of a
+// Quorum chain, or zero if transaction gas limits are not enforced at num.
+func (c *ChainConfig) QuorumTxGasLimit(num *big.Int) uint64 {
+	limit := c.QuorumBlockGasLimit(num)
+	if limit != 0 && c.GasLimits.TxGasLimit != 0 && c.GasLimits.TxGasLimit < limit {
+		return c.GasLimits.TxGasLimit
+	}
+	return limit
+}
+
 // GasTable returns the gas table corresponding to the current phase (homestead or homestead reprice).
 //
 // The returned GasTable's fields shouldn't, under any circumstances, be changed.
@@ -369,6 +593,72 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, head *big.Int, isQuor
 	if isForkIncompatible(c.EWASMBlock, newcfg.EWASMBlock, head) {
 		return newCompatError("ewasm fork block", c.EWASMBlock, newcfg.EWASMBlock)
 	}
+	if err := checkQuorumGasLimits(c.GasLimits, newcfg.GasLimits, head); err != nil {
+		return err
+	}
+	if isForkIncompatible(c.AccountPermissionsBlock, newcfg.AccountPermissionsBlock, head) {
+		return newCompatError("Account permissions block", c.AccountPermissionsBlock, newcfg.AccountPermissionsBlock)
+	}
+	if isForked(c.AccountPermissionsBlock, head) && !c.AccountPermissions.equal(newcfg.AccountPermissions) {
+		return newCompatError("Account permissions", c.AccountPermissionsBlock, newcfg.AccountPermissionsBlock)
+	}
+	if c.Istanbul != nil && newcfg.Istanbul != nil {
+		// Proposer selection applies from genesis, any block sealed makes it immutable
+		if head.Sign() > 0 && !c.Istanbul.sameProposerSelection(newcfg.Istanbul) {
+			return newCompatError(IstanbulProposerSelection, common.Big0, common.Big0)
+		}
+		if err := checkIstanbulTransitions(c.Istanbul.Transitions, newcfg.Istanbul.Transitions, head); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkIstanbulTransitions ensures that no Istanbul transition which already took
+// effect at head was altered, removed or inserted.
+func checkIstanbulTransitions(stored, next []IstanbulTransition, head *big.Int) *ConfigCompatError {
+	for i := 0; i < len(stored) || i < len(next); i++ {
+		var s1, s2 *IstanbulTransition
+		if i < len(stored) {
+			s1 = &stored[i]
+		}
+		if i < len(next) {
+			s2 = &next[i]
+		}
+		switch {
+		case s1 != nil && s2 != nil:
+			if (isForked(s1.Block, head) || isForked(s2.Block, head)) && !s1.equal(s2) {
+				return newCompatError("Istanbul transition", s1.Block, s2.Block)
+			}
+		case s1 != nil:
+			if isForked(s1.Block, head) {
+				return newCompatError("Istanbul transition", s1.Block, nil)
+			}
+		case s2 != nil:
+			if isForked(s2.Block, head) {
+				return newCompatError("Istanbul transition", nil, s2.Block)
+			}
+		}
+	}
+	return nil
+}
+
+// checkQuorumGasLimits ensures that gas limits already enforced at head were not
+// altered, and that their activation was not moved across head.
+func checkQuorumGasLimits(stored, next *QuorumGasLimitConfig, head *big.Int) *ConfigCompatError {
+	var storedBlock, nextBlock *big.Int
+	if stored != nil {
+		storedBlock = stored.Block
+	}
+	if next != nil {
+		nextBlock = next.Block
+	}
+	if isForkIncompatible(storedBlock, nextBlock, head) {
+		return newCompatError("Quorum gas limit block", storedBlock, nextBlock)
+	}
+	if isForked(storedBlock, head) && (stored.BlockGasLimit != next.BlockGasLimit || stored.TxGasLimit != next.TxGasLimit) {
+		return newCompatError("Quorum gas limits", storedBlock, nextBlock)
+	}
 	return nil
 }
 
@@ -396,6 +686,11 @@ func configNumEqual(x, y *big.Int) bool {
 	return x.Cmp(y) == 0
 }
 
+// IstanbulProposerSelection is the compatibility error subject raised when the
+// Istanbul proposer policy or weights of a chain with blocks are changed. Being
+// in force since genesis, such a change can't be applied by rewinding the chain.
+const IstanbulProposerSelection = "Istanbul proposer selection"
+
 // ConfigCompatError is raised if the locally-stored blockchain is initialised with a
 // ChainConfig that would alter the past.
 type ConfigCompatError struct {
@@ -17,9 +17,12 @@
 package params
 
 import (
+	"encoding/json"
 	"math/big"
 	"reflect"
 	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
 )
 
 func TestCheckCompatible(t *testing.T) {
@@ -70,6 +73,113 @@ func TestCheckCompatible(t *testing.T) {
 				RewindTo:     9,
 			},
 		},
+		{
+			stored:  &ChainConfig{Istanbul: &IstanbulConfig{Transitions: []IstanbulTransition{{Block: big.NewInt(10), BlockPeriod: 5}}}},
+			new:     &ChainConfig{Istanbul: &IstanbulConfig{Transitions: []IstanbulTransition{{Block: big.NewInt(20), BlockPeriod: 5}}}},
+			head:    9,
+			wantErr: nil,
+		},
+		{
+			stored: &ChainConfig{Istanbul: &IstanbulConfig{Transitions: []IstanbulTransition{{Block: big.NewInt(10), BlockPeriod: 5}}}},
+			new:    &ChainConfig{Istanbul: &IstanbulConfig{Transitions: []IstanbulTransition{{Block: big.NewInt(10), BlockPeriod: 2}}}},
+			head:   15,
+			wantErr: &ConfigCompatError{
+				What:         "Istanbul transition",
+				StoredConfig: big.NewInt(10),
+				NewConfig:    big.NewInt(10),
+				RewindTo:     9,
+			},
+		},
+		{
+			stored: &ChainConfig{Istanbul: &IstanbulConfig{}},
+			new:    &ChainConfig{Istanbul: &IstanbulConfig{Transitions: []IstanbulTransition{{Block: big.NewInt(10), BlockPeriod: 2}}}},
+			head:   15,
+			wantErr: &ConfigCompatError{
+				What:         "Istanbul transition",
+				StoredConfig: nil,
+				NewConfig:    big.NewInt(10),
+				RewindTo:     9,
+			},
+		},
+		{
+			stored:  &ChainConfig{Istanbul: &IstanbulConfig{ProposerPolicy: 2, Weights: map[common.Address]uint64{{1}: 3}}},
+			new:     &ChainConfig{Istanbul: &IstanbulConfig{ProposerPolicy: 2, Weights: map[common.Address]uint64{{1}: 5}}},
+			head:    0,
+			wantErr: nil,
+		},
+		{
+			stored: &ChainConfig{Istanbul: &IstanbulConfig{ProposerPolicy: 2, Weights: map[common.Address]uint64{{1}: 3}}},
+			new:    &ChainConfig{Istanbul: &IstanbulConfig{ProposerPolicy: 2, Weights: map[common.Address]uint64{{1}: 5}}},
+			head:   15,
+			wantErr: &ConfigCompatError{
+				What:         IstanbulProposerSelection,
+				StoredConfig: big.NewInt(0),
+				NewConfig:    big.NewInt(0),
+				RewindTo:     0,
+			},
+		},
+		{
+			stored:  &ChainConfig{GasLimits: &QuorumGasLimitConfig{Block: big.NewInt(10), TxGasLimit: 100000}},
+			new:     &ChainConfig{GasLimits: &QuorumGasLimitConfig{Block: big.NewInt(20), TxGasLimit: 50000}},
+			head:    9,
+			wantErr: nil,
+		},
+		{
+			stored: &ChainConfig{GasLimits: &QuorumGasLimitConfig{Block: big.NewInt(10), TxGasLimit: 100000}},
+			new:    &ChainConfig{GasLimits: &QuorumGasLimitConfig{Block: big.NewInt(10), TxGasLimit: 50000}},
+			head:   15,
+			wantErr: &ConfigCompatError{
+				What:         "Quorum gas limits",
+				StoredConfig: big.NewInt(10),
+				NewConfig:    big.NewInt(10),
+				RewindTo:     9,
+			},
+		},
+		{
+			stored: &ChainConfig{},
+			new:    &ChainConfig{GasLimits: &QuorumGasLimitConfig{Block: big.NewInt(10)}},
+			head:   15,
+			wantErr: &ConfigCompatError{
+				What:         "Quorum gas limit block",
+				StoredConfig: nil,
+				NewConfig:    big.NewInt(10),
+				RewindTo:     9,
+			},
+		},
+		{
+			stored:  &ChainConfig{AccountPermissionsBlock: big.NewInt(10), AccountPermissions: &AccountPermissionsConfig{}},
+			new:     &ChainConfig{AccountPermissionsBlock: big.NewInt(10), AccountPermissions: &AccountPermissionsConfig{DefaultAccess: AccountAccessTransact}},
+			head:    9,
+			wantErr: nil,
+		},
+		{
+			stored: &ChainConfig{AccountPermissionsBlock: big.NewInt(10), AccountPermissions: &AccountPermissionsConfig{}},
+			new:    &ChainConfig{AccountPermissionsBlock: big.NewInt(10), AccountPermissions: &AccountPermissionsConfig{DefaultAccess: AccountAccessTransact}},
+			head:   15,
+			wantErr: &ConfigCompatError{
+				What:         "Account permissions",
+				StoredConfig: big.NewInt(10),
+				NewConfig:    big.NewInt(10),
+				RewindTo:     9,
+			},
+		},
+		{
+			stored: &ChainConfig{},
+			new:    &ChainConfig{AccountPermissionsBlock: big.NewInt(10), AccountPermissions: &AccountPermissionsConfig{}},
+			head:   15,
+			wantErr: &ConfigCompatError{
+				What:         "Account permissions block",
+				StoredConfig: nil,
+				NewConfig:    big.NewInt(10),
+				RewindTo:     9,
+			},
+		},
+		{
+			stored:  &ChainConfig{},
+			new:     &ChainConfig{AccountPermissionsBlock: big.NewInt(20), AccountPermissions: &AccountPermissionsConfig{}},
+			head:    15,
+			wantErr: nil,
+		},
 	}
 
 	for _, test := range tests {
@@ -79,3 +189,95 @@ func TestCheckCompatible(t *testing.T) {
 		}
 	}
 }
+
+func TestAccountPermissionsJSON(t *testing.T) {
+	blob := `{
+		"defaultAccess": "transact",
+		"accounts": {
+			"0x0000000000000000000000000000000000000001": "readonly",
+			"0x0000000000000000000000000000000000000002": "admin"
+		},
+		"contract": "0x0000000000000000000000000000000000000020",
+		"accessSlot": 3
+	}`
+	var config AccountPermissionsConfig
+	if err := json.Unmarshal([]byte(blob), &config); err != nil {
+		t.Fatalf("failed to decode config: %v", err)
+	}
+	contract, slot := common.HexToAddress("0x20"), uint64(3)
+	want := AccountPermissionsConfig{
+		DefaultAccess: AccountAccessTransact,
+		Accounts: map[common.Address]AccountAccess{
+			common.HexToAddress("0x01"): AccountAccessReadOnly,
+			common.HexToAddress("0x02"): AccountAccessAdmin,
+		},
+		Contract:   &contract,
+		AccessSlot: &slot,
+	}
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("config mismatch: have %+v, want %+v", config, want)
+	}
+	// Unknown access levels must be rejected
+	if err := json.Unmarshal([]byte(`{"defaultAccess": "owner"}`), &config); err == nil {
+		t.Errorf("unknown access level accepted")
+	}
+}
+
+func TestAccountPermissionsValidity(t *testing.T) {
+	contract, slot := common.HexToAddress("0x20"), uint64(0)
+	tests := []struct {
+		config *ChainConfig
+		valid  bool
+	}{
+		{&ChainConfig{TransactionSizeLimit: 32, AccountPermissionsBlock: big.NewInt(0), AccountPermissions: &AccountPermissionsConfig{}}, true},
+		{&ChainConfig{TransactionSizeLimit: 32, AccountPermissions: &AccountPermissionsConfig{}}, false},
+		{&ChainConfig{TransactionSizeLimit: 32, AccountPermissionsBlock: big.NewInt(0), AccountPermissions: &AccountPermissionsConfig{Contract: &contract, AccessSlot: &slot}}, true},
+		{&ChainConfig{TransactionSizeLimit: 32, AccountPermissionsBlock: big.NewInt(0), AccountPermissions: &AccountPermissionsConfig{Contract: &contract}}, false},
+		{&ChainConfig{TransactionSizeLimit: 32, AccountPermissionsBlock: big.NewInt(0), AccountPermissions: &AccountPermissionsConfig{AccessSlot: &slot}}, false},
+	}
+	for i, test := range tests {
+		if err := test.config.IsValid(); (err == nil) != test.valid {
+			t.Errorf("test %d: validity mismatch: have %v, want valid %v", i, err, test.valid)
+		}
+	}
+	config := &ChainConfig{AccountPermissionsBlock: big.NewInt(5), AccountPermissions: &AccountPermissionsConfig{}}
+	if config.IsAccountPermissions(big.NewInt(4)) || !config.IsAccountPermissions(big.NewInt(5)) {
+		t.Errorf("activation mismatch")
+	}
+}
+
+func TestQuorumGasLimits(t *testing.T) {
+	config := &ChainConfig{IsQuorum: true, GasLimits: &QuorumGasLimitConfig{Block: big.NewInt(5), TxGasLimit: 100000}}
+	if block, tx := config.QuorumBlockGasLimit(big.NewInt(4)), config.QuorumTxGasLimit(big.NewInt(4)); block != 0 || tx != 0 {
+		t.Errorf("limits enforced before activation: block %d, tx %d", block, tx)
+	}
+	if block, tx := config.QuorumBlockGasLimit(big.NewInt(5)), config.QuorumTxGasLimit(big.NewInt(5)); block != GenesisGasLimit || tx != 100000 {
+		t.Errorf("limits mismatch: have block %d, tx %d, want block %d, tx %d", block, tx, GenesisGasLimit, 100000)
+	}
+	config.IsQuorum = false
+	if block := config.QuorumBlockGasLimit(big.NewInt(5)); block != 0 {
+		t.Errorf("limits enforced on a non-Quorum chain: block %d", block)
+	}
+}
+
+func TestIstanbulConfigValidate(t *testing.T) {
+	tests := []struct {
+		config *IstanbulConfig
+		valid  bool
+	}{
+		{&IstanbulConfig{Transitions: []IstanbulTransition{{Block: big.NewInt(10)}, {Block: big.NewInt(20)}}}, true},
+		{&IstanbulConfig{Transitions: []IstanbulTransition{{Block: big.NewInt(20)}, {Block: big.NewInt(10)}}}, false},
+		{&IstanbulConfig{Transitions: []IstanbulTransition{{Block: big.NewInt(10)}, {Block: big.NewInt(10)}}}, false},
+		{&IstanbulConfig{Transitions: []IstanbulTransition{{}}}, false},
+		{&IstanbulConfig{Transitions: []IstanbulTransition{{Block: big.NewInt(0), BlockPeriod: 5}}}, true},
+		{&IstanbulConfig{Transitions: []IstanbulTransition{{Block: big.NewInt(0), Validators: []common.Address{{1}}}}}, false},
+		{&IstanbulConfig{Transitions: []IstanbulTransition{{Block: big.NewInt(1), Validators: []common.Address{{1}}}}}, true},
+		{&IstanbulConfig{Weights: map[common.Address]uint64{{1}: MaxIstanbulProposerWeight}}, true},
+		{&IstanbulConfig{Weights: map[common.Address]uint64{{1}: MaxIstanbulProposerWeight + 1}}, false},
+	}
+	for i, test := range tests {
+		if err := test.config.Validate(); (err == nil) != test.valid {
+			t.Errorf("test %d: validity mismatch: have %v, want valid %v", i, err, test.valid)
+		}
+	}
+}
@@ -51,4 +51,9 @@ const (
 	// HelperTrieProcessConfirmations is the number of confirmations before a HelperTrie
 	// is generated
 	HelperTrieProcessConfirmations = 256
+
+	// ImmutabilityThreshold is the number of blocks after which a chain segment of
+	// a proof-of-work or clique chain is considered immutable, i.e. soft finality.
+	// It is the minimum distance from the head at which blocks are frozen.
+	ImmutabilityThreshold = 90000
 )
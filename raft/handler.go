@@ -548,9 +548,11 @@ func (pm *ProtocolManager) handleRoleChange(roleC <-chan interface{}) {
 
 			if intRole == minterRole {
 				log.EmitCheckpoint(log.BecameMinter)
+				minterRoleGauge.Update(1)
 				pm.minter.start()
 			} else { // verifier
 				log.EmitCheckpoint(log.BecameVerifier)
+				minterRoleGauge.Update(0)
 				pm.minter.stop()
 			}
 
@@ -657,6 +659,10 @@ func (pm *ProtocolManager) addPeer(address *Address) {
 
 	// Add P2P connection:
 	p2pNode := enode.NewV4(pubKey, address.Ip, 0, int(address.P2pPort), int(address.RaftPort))
+	// A node removed from the cluster may re-enter with a new raft ID
+	if err := pm.p2pServer.UnbanPeer(p2pNode.ID()); err != nil {
+		log.Error("failed to unban raft peer", "raft id", raftId, "err", err)
+	}
 	pm.p2pServer.AddPeer(p2pNode)
 
 	// Add raft transport connection:
@@ -666,6 +672,9 @@ func (pm *ProtocolManager) addPeer(address *Address) {
 
 func (pm *ProtocolManager) disconnectFromPeer(raftId uint16, peer *Peer) {
 	pm.p2pServer.RemovePeer(peer.p2pNode)
+	if err := pm.p2pServer.BanPeer(peer.p2pNode.ID(), 0, "removed from raft cluster"); err != nil {
+		log.Error("failed to ban removed raft peer", "raft id", raftId, "err", err)
+	}
 	pm.transport.RemovePeer(raftTypes.ID(raftId))
 }
 
@@ -878,6 +887,7 @@ func (pm *ProtocolManager) applyNewChainHead(block *types.Block) {
 		headBlock := pm.blockchain.CurrentBlock()
 
 		log.Info("Non-extending block", "block", block.Hash(), "parent", block.ParentHash(), "head", headBlock.Hash())
+		nonExtendingMeter.Mark(1)
 
 		pm.minter.invalidRaftOrderingChan <- InvalidRaftOrdering{headBlock: headBlock, invalidBlock: block}
 	} else {
@@ -897,6 +907,7 @@ func (pm *ProtocolManager) applyNewChainHead(block *types.Block) {
 			panic(fmt.Sprintf("failed to extend chain: %s", err.Error()))
 		}
 
+		appliedBlockMeter.Mark(1)
 		log.EmitCheckpoint(log.BlockCreated, "block", fmt.Sprintf("%x", block.Hash()))
 	}
 }
@@ -267,6 +267,7 @@ func (minter *minter) createWork() *work {
 		Coinbase:   minter.coinbase,
 		Time:       big.NewInt(tstamp),
 	}
+	header.GasLimit = core.CapGasLimit(minter.config, header.Number, header.GasLimit)
 
 	publicState, privateState, err := minter.chain.StateAt(parent.Root())
 	if err != nil {
@@ -281,14 +282,25 @@ func (minter *minter) createWork() *work {
 	}
 }
 
-func (minter *minter) getTransactions() *types.TransactionsByPriceAndNonce {
+// getTransactions returns the pending transactions not yet proposed, along with
+// the number of proposed ones per account. The pool only charges transactions to
+// the account rates once they reach the chain, so the speculative ones are
+// counted here.
+func (minter *minter) getTransactions() (*types.TransactionsByPriceAndNonce, map[common.Address]uint64) {
 	allAddrTxes, err := minter.eth.TxPool().Pending()
 	if err != nil { // TODO: handle
 		panic(err)
 	}
 	addrTxes := minter.speculativeChain.withoutProposedTxes(allAddrTxes)
+
+	proposed := make(map[common.Address]uint64)
+	for addr, txes := range allAddrTxes {
+		if n := len(txes) - len(addrTxes[addr]); n > 0 {
+			proposed[addr] = uint64(n)
+		}
+	}
 	signer := types.MakeSigner(minter.chain.Config(), minter.chain.CurrentBlock().Number())
-	return types.NewTransactionsByPriceAndNonce(signer, addrTxes)
+	return types.NewTransactionsByRoundRobin(signer, addrTxes, minter.eth.TxPool().Priority(), minter.speculativeChain.head.Hash()), proposed
 }
 
 // Sends-off events asynchronously.
@@ -310,10 +322,11 @@ func (minter *minter) mintNewBlock() {
 	minter.mu.Lock()
 	defer minter.mu.Unlock()
 
+	start := time.Now()
 	work := minter.createWork()
-	transactions := minter.getTransactions()
+	transactions, proposed := minter.getTransactions()
 
-	committedTxes, publicReceipts, privateReceipts, logs := work.commitTransactions(transactions, minter.chain)
+	committedTxes, publicReceipts, privateReceipts, logs := work.commitTransactions(transactions, minter.chain, minter.eth.TxPool(), proposed)
 	txCount := len(committedTxes)
 
 	if txCount == 0 {
@@ -363,11 +376,15 @@ func (minter *minter) mintNewBlock() {
 
 	minter.mux.Post(core.NewMinedBlockEvent{Block: block})
 
+	mintTimer.UpdateSince(start)
+	mintedBlockMeter.Mark(1)
+	mintedTxMeter.Mark(int64(txCount))
+
 	elapsed := time.Since(time.Unix(0, header.Time.Int64()))
 	log.Info("🔨  Mined block", "number", block.Number(), "hash", fmt.Sprintf("%x", block.Hash().Bytes()[:4]), "elapsed", elapsed)
 }
 
-func (env *work) commitTransactions(txes *types.TransactionsByPriceAndNonce, bc *core.BlockChain) (types.Transactions, types.Receipts, types.Receipts, []*types.Log) {
+func (env *work) commitTransactions(txes *types.TransactionsByPriceAndNonce, bc *core.BlockChain, pool *core.TxPool, rated map[common.Address]uint64) (types.Transactions, types.Receipts, types.Receipts, []*types.Log) {
 	var allLogs []*types.Log
 	var committedTxes types.Transactions
 	var publicReceipts types.Receipts
@@ -375,12 +392,19 @@ func (env *work) commitTransactions(txes *types.TransactionsByPriceAndNonce, bc
 
 	gp := new(core.GasPool).AddGas(env.header.GasLimit)
 	txCount := 0
+	signer := types.MakeSigner(env.config, env.header.Number)
 
 	for {
 		tx := txes.Peek()
 		if tx == nil {
 			break
 		}
+		from, _ := types.Sender(signer, tx)
+		if allowance, limited := pool.RateAllowance(from); limited && rated[from] >= allowance {
+			log.Trace("Skipping rate limited account", "sender", from, "included", rated[from])
+			txes.Pop() // skip rest of txes from this account
+			continue
+		}
 
 		env.publicState.Prepare(tx.Hash(), common.Hash{}, txCount)
 
@@ -391,6 +415,7 @@ func (env *work) commitTransactions(txes *types.TransactionsByPriceAndNonce, bc
 			txes.Pop() // skip rest of txes from this account
 		default:
 			txCount++
+			rated[from]++
 			committedTxes = append(committedTxes, tx)
 
 			publicReceipts = append(publicReceipts, publicReceipt)
@@ -0,0 +1,259 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+)
+
+var (
+	errClientCAWithoutTLS = errors.New("client certificate authentication requires TLS")
+	errJWTAlgorithm       = errors.New("JWT key requires a JWTAlgorithm")
+	errMissingToken       = errors.New("missing bearer token")
+	errInvalidIssuer      = errors.New("invalid token issuer")
+	errMissingExpiry      = errors.New("token has no expiry")
+	errTokenLifetime      = errors.New("token lifetime too long")
+)
+
+// AuthConfig configures the transport security and client authentication of
+// an HTTP or WebSocket RPC endpoint. The zero value serves plain connections
+// without any authentication.
+type AuthConfig struct {
+	// TLSCert and TLSKey are the PEM encoded certificate and private key files
+	// used to serve HTTPS and WSS. Both must be set to enable TLS.
+	TLSCert string `toml:",omitempty"`
+	TLSKey  string `toml:",omitempty"`
+
+	// TLSClientCA is a PEM encoded CA bundle. If set, clients must present a
+	// certificate signed by one of these authorities (mutual TLS).
+	TLSClientCA string `toml:",omitempty"`
+
+	// JWTKey is the file holding the key that verifies bearer tokens. If set,
+	// every request must carry a valid token in its Authorization header.
+	JWTKey string `toml:",omitempty"`
+
+	// JWTAlgorithm is the only algorithm bearer tokens may be signed with, and
+	// determines the type of JWTKey: a PEM encoded RSA public key for RS256,
+	// RS384, RS512, PS256, PS384 and PS512, a PEM encoded ECDSA public key for
+	// ES256, ES384 and ES512, or a raw secret for HS256, HS384 and HS512.
+	JWTAlgorithm string `toml:",omitempty"`
+
+	// JWTIssuer is the required "iss" claim of bearer tokens, if set.
+	JWTIssuer string `toml:",omitempty"`
+
+	// JWTMaxLifetime rejects bearer tokens expiring further in the future, if
+	// set. Tokens must expire in any case.
+	JWTMaxLifetime time.Duration `toml:",omitempty"`
+
+	// Policy is the JSON file mapping client identities to the methods they may
+	// call, see AccessPolicy. If set, all other calls are denied.
+	Policy string `toml:",omitempty"`
+
+	// AuditLog is the file denied calls are recorded in, if a Policy is set.
+	AuditLog string `toml:",omitempty"`
+}
+
+// TLSConfig loads the TLS configuration of the endpoint, returning nil if TLS
+// is disabled.
+func (c *AuthConfig) TLSConfig() (*tls.Config, error) {
+	if c.TLSCert == "" && c.TLSKey == "" {
+		if c.TLSClientCA != "" {
+			return nil, errClientCAWithoutTLS
+		}
+		return nil, nil
+	}
+	cert, err := tls.LoadX509KeyPair(c.TLSCert, c.TLSKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS certificate: %v", err)
+	}
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if c.TLSClientCA != "" {
+		blob, err := ioutil.ReadFile(c.TLSClientCA)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client CA: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(blob) {
+			return nil, fmt.Errorf("no certificates found in client CA %s", c.TLSClientCA)
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return config, nil
+}
+
+// TokenAuth loads the bearer token verifier of the endpoint, returning nil if
+// token authentication is disabled.
+func (c *AuthConfig) TokenAuth() (*TokenAuth, error) {
+	if c.JWTKey == "" {
+		return nil, nil
+	}
+	if c.JWTAlgorithm == "" {
+		return nil, errJWTAlgorithm
+	}
+	method := jwt.GetSigningMethod(c.JWTAlgorithm)
+	if method == nil || method == jwt.SigningMethodNone {
+		return nil, fmt.Errorf("unsupported JWT algorithm %q", c.JWTAlgorithm)
+	}
+	blob, err := ioutil.ReadFile(c.JWTKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWT key: %v", err)
+	}
+	auth := &TokenAuth{method: method, issuer: c.JWTIssuer, maxLifetime: c.JWTMaxLifetime}
+	switch method.(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		auth.key, err = jwt.ParseRSAPublicKeyFromPEM(blob)
+	case *jwt.SigningMethodECDSA:
+		auth.key, err = jwt.ParseECPublicKeyFromPEM(blob)
+	case *jwt.SigningMethodHMAC:
+		// A PEM file is most likely a public key, known to anyone, that was meant
+		// for an asymmetric algorithm
+		switch secret := bytes.TrimSpace(blob); {
+		case len(secret) == 0:
+			err = errors.New("empty secret")
+		case bytes.HasPrefix(secret, []byte("-----BEGIN")):
+			err = errors.New("PEM encoded secret")
+		default:
+			auth.key = secret
+		}
+	default:
+		err = fmt.Errorf("unsupported algorithm %q", c.JWTAlgorithm)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s key %s: %v", c.JWTAlgorithm, c.JWTKey, err)
+	}
+	return auth, nil
+}
+
+// AccessPolicy loads the access policy of the endpoint, returning nil if all
+// calls are allowed.
+func (c *AuthConfig) AccessPolicy() (*AccessPolicy, error) {
+	if c.Policy == "" {
+		return nil, nil
+	}
+	policy, err := LoadAccessPolicy(c.Policy)
+	if err != nil {
+		return nil, err
+	}
+	if c.AuditLog != "" {
+		if err := policy.SetAuditLog(c.AuditLog); err != nil {
+			return nil, fmt.Errorf("failed to open audit log: %v", err)
+		}
+	}
+	return policy, nil
+}
+
+// TokenAuth verifies the JWT bearer tokens of incoming RPC requests.
+type TokenAuth struct {
+	method      jwt.SigningMethod // only accepted signing algorithm
+	key         interface{}       // *rsa.PublicKey, *ecdsa.PublicKey or HMAC secret
+	issuer      string
+	maxLifetime time.Duration
+}
+
+// keyFunc returns the verification key if the token is signed with the
+// configured algorithm, preventing algorithm substitution.
+func (a *TokenAuth) keyFunc(token *jwt.Token) (interface{}, error) {
+	if token.Method.Alg() != a.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+	}
+	return a.key, nil
+}
+
+// verify checks the bearer token of the request, returning its subject.
+func (a *TokenAuth) verify(r *http.Request) (string, error) {
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return "", errMissingToken
+	}
+	token, err := jwt.Parse(strings.TrimPrefix(header, "Bearer "), a.keyFunc)
+	if err != nil {
+		return "", err
+	}
+	// Expired tokens are rejected by Parse, but tokens never expiring aren't
+	claims := token.Claims.(jwt.MapClaims)
+	if !claims.VerifyExpiresAt(0, true) {
+		return "", errMissingExpiry
+	}
+	if a.maxLifetime > 0 && claims.VerifyExpiresAt(time.Now().Add(a.maxLifetime).Unix()+1, true) {
+		return "", errTokenLifetime
+	}
+	if a.issuer != "" && !claims.VerifyIssuer(a.issuer, true) {
+		return "", errInvalidIssuer
+	}
+	subject, _ := claims["sub"].(string)
+	return subject, nil
+}
+
+type clientIdentityKey struct{}
+
+// Prefixes of client identities, telling apart a token subject from the same
+// certificate common name.
+const (
+	tokenIdentityPrefix = "jwt:"
+	certIdentityPrefix  = "cert:"
+)
+
+// ClientIdentity returns the authenticated identity of the client issuing an
+// RPC call: "jwt:" followed by the subject of its bearer token or else "cert:"
+// followed by the common name of its TLS certificate.
+func ClientIdentity(ctx context.Context) (string, bool) {
+	identity, ok := ctx.Value(clientIdentityKey{}).(string)
+	return identity, ok
+}
+
+// newAuthHandler wraps the handler with bearer token verification, if enabled,
+// and attaches the identity of authenticated clients to the request context.
+// Health checks are let through as they don't reach the API, but websocket
+// upgrades never count as one.
+func newAuthHandler(auth *TokenAuth, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var identity string
+		if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 && r.TLS.PeerCertificates[0].Subject.CommonName != "" {
+			identity = certIdentityPrefix + r.TLS.PeerCertificates[0].Subject.CommonName
+		}
+		if auth != nil && !(isHealthCheck(r) && r.Header.Get("Upgrade") == "") {
+			subject, err := auth.verify(r)
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				http.Error(w, "unauthorized: "+err.Error(), http.StatusUnauthorized)
+				return
+			}
+			if subject != "" {
+				identity = tokenIdentityPrefix + subject
+			}
+		}
+		if identity != "" {
+			r = r.WithContext(context.WithValue(r.Context(), clientIdentityKey{}, identity))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
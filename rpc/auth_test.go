@@ -0,0 +1,293 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+)
+
+func TestHTTPTokenAuth(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rpc-auth-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	secret := []byte("0123456789abcdef")
+	keyfile := filepath.Join(dir, "jwt.key")
+	if err := ioutil.WriteFile(keyfile, append(secret, '\n'), 0600); err != nil {
+		t.Fatal(err)
+	}
+	config := AuthConfig{JWTKey: keyfile, JWTAlgorithm: "HS256", JWTIssuer: "issuer", JWTMaxLifetime: time.Hour}
+	auth, err := config.TokenAuth()
+	if err != nil {
+		t.Fatalf("failed to load token auth: %v", err)
+	}
+	srv := NewServer()
+	defer srv.Stop()
+	if err := srv.RegisterName("test", new(Service)); err != nil {
+		t.Fatal(err)
+	}
+	hs := httptest.NewServer(newHTTPServer(nil, []string{"*"}, DefaultHTTPTimeouts, auth, srv).Handler)
+	defer hs.Close()
+
+	sign := func(method jwt.SigningMethod, key interface{}, claims jwt.MapClaims) string {
+		token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return "Bearer " + token
+	}
+	exp := time.Now().Add(time.Minute).Unix()
+	tests := []struct {
+		header string
+		want   int
+	}{
+		{"", http.StatusUnauthorized},
+		{"Basic dXNlcjpwYXNz", http.StatusUnauthorized},
+		{sign(jwt.SigningMethodHS256, secret, jwt.MapClaims{"iss": "issuer", "exp": exp}), http.StatusOK},
+		{sign(jwt.SigningMethodHS256, secret, jwt.MapClaims{"iss": "other", "exp": exp}), http.StatusUnauthorized},
+		{sign(jwt.SigningMethodHS256, secret, jwt.MapClaims{"iss": "issuer", "exp": time.Now().Add(-time.Minute).Unix()}), http.StatusUnauthorized},
+		{sign(jwt.SigningMethodHS256, secret, jwt.MapClaims{"iss": "issuer"}), http.StatusUnauthorized},
+		{sign(jwt.SigningMethodHS256, secret, jwt.MapClaims{"iss": "issuer", "exp": time.Now().Add(2 * time.Hour).Unix()}), http.StatusUnauthorized},
+		{sign(jwt.SigningMethodHS512, secret, jwt.MapClaims{"iss": "issuer", "exp": exp}), http.StatusUnauthorized},
+		{sign(jwt.SigningMethodHS256, []byte("wrong secret"), jwt.MapClaims{"iss": "issuer", "exp": exp}), http.StatusUnauthorized},
+		{sign(jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, jwt.MapClaims{"iss": "issuer", "exp": exp}), http.StatusUnauthorized},
+	}
+	for i, tt := range tests {
+		req, _ := http.NewRequest(http.MethodPost, hs.URL, strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"test_rets"}`))
+		req.Header.Set("content-type", contentType)
+		if tt.header != "" {
+			req.Header.Set("Authorization", tt.header)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("test %d: request failed: %v", i, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.want {
+			t.Errorf("test %d: status mismatch: have %d, want %d", i, resp.StatusCode, tt.want)
+		}
+	}
+	// Health checks don't need a token
+	resp, err := http.Get(hs.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("health check status mismatch: have %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+}
+
+func TestWebsocketTokenAuth(t *testing.T) {
+	srv := NewServer()
+	defer srv.Stop()
+
+	auth := &TokenAuth{method: jwt.SigningMethodHS256, key: []byte("secret")}
+	hs := httptest.NewServer(newWSServer([]string{"*"}, auth, srv).Handler)
+	defer hs.Close()
+
+	wsURL := "ws:" + strings.TrimPrefix(hs.URL, "http:")
+	if _, err := DialWebsocket(context.Background(), wsURL, "http://localhost"); err == nil {
+		t.Fatal("websocket connection without token succeeded")
+	}
+}
+
+func TestTokenAuthKeys(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rpc-auth-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	write := func(name string, blob []byte) string {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, blob, 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	var (
+		ecKey   = write("ec.pem", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+		badPEM  = write("bad.pem", []byte("-----BEGIN PUBLIC KEY-----\nbm90IGEga2V5\n-----END PUBLIC KEY-----\n"))
+		secret  = write("secret", []byte("0123456789abcdef"))
+		missing = filepath.Join(dir, "missing")
+	)
+	tests := []struct {
+		key, alg string
+		valid    bool
+	}{
+		{ecKey, "ES256", true},
+		{secret, "HS256", true},
+		{ecKey, "", false},      // the algorithm must be explicit
+		{ecKey, "none", false},  // unsigned tokens are never accepted
+		{ecKey, "RS256", false}, // key of the wrong type
+		{ecKey, "HS256", false},
+		{badPEM, "ES256", false},
+		{badPEM, "HS256", false}, // PEM files are never used as secrets
+		{secret, "ES256", false},
+		{missing, "HS256", false},
+	}
+	for i, tt := range tests {
+		auth, err := (&AuthConfig{JWTKey: tt.key, JWTAlgorithm: tt.alg}).TokenAuth()
+		if (err == nil) != tt.valid {
+			t.Errorf("test %d: validity mismatch: have %v, want valid %v", i, err, tt.valid)
+		}
+		if err == nil && auth.method.Alg() != tt.alg {
+			t.Errorf("test %d: algorithm mismatch: have %s, want %s", i, auth.method.Alg(), tt.alg)
+		}
+	}
+}
+
+func TestHTTPMutualTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rpc-auth-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ca := newTestCert(t, nil, "ca")
+	server := newTestCert(t, ca, "server")
+	client := newTestCert(t, ca, "client")
+	ca.write(t, dir)
+	server.write(t, dir)
+
+	config := AuthConfig{
+		TLSCert:     filepath.Join(dir, "server.crt"),
+		TLSKey:      filepath.Join(dir, "server.key"),
+		TLSClientCA: filepath.Join(dir, "ca.crt"),
+	}
+	listener, srv, err := StartSecureHTTPEndpoint("127.0.0.1:0", nil, nil, nil, nil, DefaultHTTPTimeouts, config, nil)
+	if err != nil {
+		t.Fatalf("failed to start endpoint: %v", err)
+	}
+	defer srv.Stop()
+	defer listener.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	dial := func(certs []tls.Certificate) error {
+		transport := &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots, Certificates: certs}}
+		defer transport.CloseIdleConnections()
+
+		c, err := DialHTTPWithClient("https://"+listener.Addr().String(), &http.Client{Transport: transport})
+		if err != nil {
+			return err
+		}
+		defer c.Close()
+		_, err = c.SupportedModules()
+		return err
+	}
+	if err := dial(nil); err == nil {
+		t.Error("request without client certificate succeeded")
+	}
+	if err := dial([]tls.Certificate{client.tlsCertificate()}); err != nil {
+		t.Errorf("request with client certificate failed: %v", err)
+	}
+	// The client CA can't be used without TLS
+	if _, err := (&AuthConfig{TLSClientCA: config.TLSClientCA}).TLSConfig(); err != errClientCAWithoutTLS {
+		t.Errorf("error mismatch: have %v, want %v", err, errClientCAWithoutTLS)
+	}
+}
+
+type testCert struct {
+	name string
+	key  *ecdsa.PrivateKey
+	cert *x509.Certificate
+	der  []byte
+}
+
+// newTestCert creates a certificate signed by parent, or a self-signed CA if
+// parent is nil.
+func newTestCert(t *testing.T, parent *testCert, name string) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA, template.BasicConstraintsValid = true, true
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCert{name: name, key: key, cert: cert, der: der}
+}
+
+// write stores the PEM encoded certificate and key as <name>.crt and <name>.key.
+func (c *testCert) write(t *testing.T, dir string) {
+	keyDer, err := x509.MarshalECPrivateKey(c.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	crt := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.der})
+	key := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+	if err := ioutil.WriteFile(filepath.Join(dir, c.name+".crt"), crt, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, c.name+".key"), key, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (c *testCert) tlsCertificate() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.der}, PrivateKey: c.key}
+}
@@ -17,13 +17,31 @@
 package rpc
 
 import (
+	"crypto/tls"
 	"net"
+	"net/http"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/log"
 )
 
 // StartHTTPEndpoint starts the HTTP RPC endpoint, configured with cors/vhosts/modules
 func StartHTTPEndpoint(endpoint string, apis []API, modules []string, cors []string, vhosts []string, timeouts HTTPTimeouts) (net.Listener, *Server, error) {
+	return StartSecureHTTPEndpoint(endpoint, apis, modules, cors, vhosts, timeouts, AuthConfig{}, nil)
+}
+
+// StartSecureHTTPEndpoint starts the HTTP RPC endpoint like StartHTTPEndpoint,
+// secured according to auth. Additional handlers are served on their own paths
+// next to JSON-RPC, behind the same security checks.
+func StartSecureHTTPEndpoint(endpoint string, apis []API, modules []string, cors []string, vhosts []string, timeouts HTTPTimeouts, auth AuthConfig, handlers map[string]http.Handler) (net.Listener, *Server, error) {
+	tokens, err := auth.TokenAuth()
+	if err != nil {
+		return nil, nil, err
+	}
+	policy, err := auth.AccessPolicy()
+	if err != nil {
+		return nil, nil, err
+	}
 	// Generate the whitelist based on the allowed modules
 	whitelist := make(map[string]bool)
 	for _, module := range modules {
@@ -31,6 +49,7 @@ func StartHTTPEndpoint(endpoint string, apis []API, modules []string, cors []str
 	}
 	// Register all the APIs exposed by the services
 	handler := NewServer()
+	handler.SetAccessPolicy(policy)
 	for _, api := range apis {
 		if whitelist[api.Namespace] || (len(whitelist) == 0 && api.Public) {
 			if err := handler.RegisterName(api.Namespace, api.Service); err != nil {
@@ -40,19 +59,40 @@ func StartHTTPEndpoint(endpoint string, apis []API, modules []string, cors []str
 		}
 	}
 	// All APIs registered, start the HTTP listener
-	var (
-		listener net.Listener
-		err      error
-	)
-	if listener, err = net.Listen("tcp", endpoint); err != nil {
+	listener, err := listen(endpoint, auth)
+	if err != nil {
 		return nil, nil, err
 	}
-	go NewHTTPServer(cors, vhosts, timeouts, handler).Serve(listener)
+	var mux http.Handler = handler
+	if len(handlers) > 0 {
+		paths := http.NewServeMux()
+		paths.Handle("/", handler)
+		for path, h := range handlers {
+			paths.Handle(path, newPolicyHandler(policy, strings.Trim(path, "/"), h))
+			log.Debug("HTTP registered", "path", path)
+		}
+		mux = paths
+	}
+	go newHTTPServer(cors, vhosts, timeouts, tokens, mux).Serve(listener)
 	return listener, handler, err
 }
 
 // StartWSEndpoint starts a websocket endpoint
 func StartWSEndpoint(endpoint string, apis []API, modules []string, wsOrigins []string, exposeAll bool) (net.Listener, *Server, error) {
+	return StartSecureWSEndpoint(endpoint, apis, modules, wsOrigins, exposeAll, AuthConfig{})
+}
+
+// StartSecureWSEndpoint starts a websocket endpoint like StartWSEndpoint,
+// secured according to auth
+func StartSecureWSEndpoint(endpoint string, apis []API, modules []string, wsOrigins []string, exposeAll bool, auth AuthConfig) (net.Listener, *Server, error) {
+	tokens, err := auth.TokenAuth()
+	if err != nil {
+		return nil, nil, err
+	}
+	policy, err := auth.AccessPolicy()
+	if err != nil {
+		return nil, nil, err
+	}
 
 	// Generate the whitelist based on the allowed modules
 	whitelist := make(map[string]bool)
@@ -61,6 +101,7 @@ func StartWSEndpoint(endpoint string, apis []API, modules []string, wsOrigins []
 	}
 	// Register all the APIs exposed by the services
 	handler := NewServer()
+	handler.SetAccessPolicy(policy)
 	for _, api := range apis {
 		if exposeAll || whitelist[api.Namespace] || (len(whitelist) == 0 && api.Public) {
 			if err := handler.RegisterName(api.Namespace, api.Service); err != nil {
@@ -70,18 +111,31 @@ func StartWSEndpoint(endpoint string, apis []API, modules []string, wsOrigins []
 		}
 	}
 	// All APIs registered, start the HTTP listener
-	var (
-		listener net.Listener
-		err      error
-	)
-	if listener, err = net.Listen("tcp", endpoint); err != nil {
+	listener, err := listen(endpoint, auth)
+	if err != nil {
 		return nil, nil, err
 	}
-	go NewWSServer(wsOrigins, handler).Serve(listener)
+	go newWSServer(wsOrigins, tokens, handler).Serve(listener)
 	return listener, handler, err
 
 }
 
+// listen opens a TCP listener on the endpoint, serving TLS if configured.
+func listen(endpoint string, auth AuthConfig) (net.Listener, error) {
+	config, err := auth.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("tcp", endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if config != nil {
+		listener = tls.NewListener(listener, config)
+	}
+	return listener, nil
+}
+
 // StartIPCEndpoint starts an IPC endpoint.
 func StartIPCEndpoint(ipcEndpoint string, apis []API) (net.Listener, *Server, error) {
 	// Register all the APIs exposed by the services.
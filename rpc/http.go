@@ -195,8 +195,15 @@ func (t *httpReadWriteNopCloser) Close() error {
 //
 // Deprecated: Server implements http.Handler
 func NewHTTPServer(cors []string, vhosts []string, timeouts HTTPTimeouts, srv *Server) *http.Server {
-	// Wrap the CORS-handler within a host-handler
-	handler := newCorsHandler(srv, cors)
+	return newHTTPServer(cors, vhosts, timeouts, nil, srv)
+}
+
+// newHTTPServer creates a new HTTP RPC server around the handler. If auth is set,
+// requests must carry a valid bearer token.
+func newHTTPServer(cors []string, vhosts []string, timeouts HTTPTimeouts, auth *TokenAuth, srv http.Handler) *http.Server {
+	// Wrap the auth-handler within a CORS-handler, so preflights pass, and the
+	// CORS-handler within a host-handler
+	handler := newCorsHandler(newAuthHandler(auth, srv), cors)
 	handler = newVHostHandler(vhosts, handler)
 
 	// Make sure timeout values are meaningful
@@ -224,7 +231,7 @@ func NewHTTPServer(cors []string, vhosts []string, timeouts HTTPTimeouts, srv *S
 // ServeHTTP serves JSON-RPC requests over HTTP.
 func (srv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Permit dumb empty requests for remote health-checks (AWS)
-	if r.Method == http.MethodGet && r.ContentLength == 0 && r.URL.RawQuery == "" {
+	if isHealthCheck(r) {
 		return
 	}
 	if code, err := validateRequest(r); err != nil {
@@ -253,6 +260,11 @@ func (srv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	srv.ServeSingleRequest(ctx, codec, OptionMethodInvocation)
 }
 
+// isHealthCheck reports whether the request is an empty remote health-check.
+func isHealthCheck(r *http.Request) bool {
+	return r.Method == http.MethodGet && r.ContentLength == 0 && r.URL.RawQuery == ""
+}
+
 // validateRequest returns a non-zero response code and error message if the
 // request is invalid.
 func validateRequest(r *http.Request) (int, error) {
@@ -271,7 +283,7 @@ func validateRequest(r *http.Request) (int, error) {
 	return 0, nil
 }
 
-func newCorsHandler(srv *Server, allowedOrigins []string) http.Handler {
+func newCorsHandler(srv http.Handler, allowedOrigins []string) http.Handler {
 	// disable CORS support if user has not specified a custom CORS configuration
 	if len(allowedOrigins) == 0 {
 		return srv
@@ -0,0 +1,162 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// AccessPolicy maps authenticated client identities to the RPC methods they are
+// allowed to call. Methods are matched against shell patterns, e.g. "eth_get*"
+// or "raft_*".
+type AccessPolicy struct {
+	// Default lists the methods every client may call, including the ones
+	// without an identity.
+	Default []string `json:"default"`
+
+	// Clients lists the additional methods each client identity may call. The
+	// identities are prefixed by their source, see ClientIdentity.
+	Clients map[string][]string `json:"clients"`
+
+	audit log.Logger // records denied calls
+}
+
+// LoadAccessPolicy reads an access policy from a JSON file.
+func LoadAccessPolicy(file string) (*AccessPolicy, error) {
+	blob, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	policy := new(AccessPolicy)
+	if err := json.Unmarshal(blob, policy); err != nil {
+		return nil, fmt.Errorf("invalid access policy %s: %v", file, err)
+	}
+	if err := policy.validate(); err != nil {
+		return nil, fmt.Errorf("invalid access policy %s: %v", file, err)
+	}
+	return policy, nil
+}
+
+// validate checks that all method patterns are well formed.
+func (p *AccessPolicy) validate() error {
+	check := func(patterns []string) error {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("bad method pattern %q", pattern)
+			}
+		}
+		return nil
+	}
+	if err := check(p.Default); err != nil {
+		return err
+	}
+	for identity, patterns := range p.Clients {
+		if !strings.HasPrefix(identity, tokenIdentityPrefix) && !strings.HasPrefix(identity, certIdentityPrefix) {
+			return fmt.Errorf("client %q is neither a %q nor a %q identity", identity, tokenIdentityPrefix, certIdentityPrefix)
+		}
+		if err := check(patterns); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetAuditLog records denied calls in the given file, appending to it. Without
+// an audit log they are recorded in the node log.
+func (p *AccessPolicy) SetAuditLog(path string) error {
+	handler, err := log.FileHandler(path, log.LogfmtFormat())
+	if err != nil {
+		return err
+	}
+	p.audit = log.New("api", "rpc")
+	p.audit.SetHandler(handler)
+	return nil
+}
+
+// deny records a denied call in the audit log.
+func (p *AccessPolicy) deny(method string, identity string, remote string) {
+	audit := p.audit
+	if audit == nil {
+		audit = log.New("audit", "rpc")
+	}
+	audit.Warn("Denied RPC call", "method", method, "identity", identity, "remote", remote)
+}
+
+// Allowed reports whether the client with the given identity, empty if it is
+// not authenticated, may call the method.
+func (p *AccessPolicy) Allowed(identity string, method string) bool {
+	if matchMethod(p.Default, method) {
+		return true
+	}
+	if identity == "" {
+		return false
+	}
+	return matchMethod(p.Clients[identity], method)
+}
+
+func matchMethod(patterns []string, method string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, method); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// authorize checks the request against the access policy of the server, if
+// any, logging denied calls for auditing.
+func (s *Server) authorize(ctx context.Context, req *serverRequest) Error {
+	if s.policy == nil {
+		return nil
+	}
+	method := req.svcname + subscribeMethodSuffix
+	if !req.callb.isSubscribe {
+		method = req.svcname + serviceMethodSeparator + formatName(req.callb.method.Name)
+	}
+	identity, _ := ClientIdentity(ctx)
+	if s.policy.Allowed(identity, method) {
+		return nil
+	}
+	remote, _ := ctx.Value("remote").(string)
+	s.policy.deny(method, identity, remote)
+	return &accessDeniedError{method}
+}
+
+// newPolicyHandler guards an HTTP handler served next to JSON-RPC with the
+// access policy, treating its path as the method name, e.g. "graphql".
+func newPolicyHandler(policy *AccessPolicy, name string, next http.Handler) http.Handler {
+	if policy == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity, _ := ClientIdentity(r.Context())
+		if !policy.Allowed(identity, name) {
+			policy.deny(name, identity, r.RemoteAddr)
+			http.Error(w, (&accessDeniedError{name}).Error(), http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,205 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+)
+
+func TestAccessPolicyAllowed(t *testing.T) {
+	policy := &AccessPolicy{
+		Default: []string{"rpc_modules"},
+		Clients: map[string][]string{
+			"jwt:reporting": {"eth_get*", "eth_blockNumber"},
+			"cert:ops":      {"raft_*", "istanbul_*"},
+		},
+	}
+	tests := []struct {
+		identity, method string
+		want             bool
+	}{
+		{"", "rpc_modules", true},
+		{"", "eth_getBalance", false},
+		{"jwt:unknown", "eth_getBalance", false},
+		{"jwt:reporting", "rpc_modules", true},
+		{"jwt:reporting", "eth_getBalance", true},
+		{"jwt:reporting", "eth_blockNumber", true},
+		{"jwt:reporting", "eth_sendTransaction", false},
+		{"cert:reporting", "eth_getBalance", false},
+		{"cert:ops", "raft_addPeer", true},
+		{"cert:ops", "istanbul_propose", true},
+		{"cert:ops", "eth_getBalance", false},
+		{"jwt:ops", "raft_addPeer", false},
+	}
+	for i, tt := range tests {
+		if have := policy.Allowed(tt.identity, tt.method); have != tt.want {
+			t.Errorf("test %d: %q calling %s: have %v, want %v", i, tt.identity, tt.method, have, tt.want)
+		}
+	}
+}
+
+func TestLoadAccessPolicy(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rpc-policy-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "policy.json")
+	ioutil.WriteFile(file, []byte(`{"default": ["rpc_modules"], "clients": {"jwt:app": ["eth_sendTransaction"]}}`), 0600)
+	policy, err := LoadAccessPolicy(file)
+	if err != nil {
+		t.Fatalf("failed to load policy: %v", err)
+	}
+	if !policy.Allowed("jwt:app", "eth_sendTransaction") {
+		t.Error("policy not loaded")
+	}
+	ioutil.WriteFile(file, []byte(`{"clients": {"jwt:app": ["eth_[get"]}}`), 0600)
+	if _, err := LoadAccessPolicy(file); err == nil {
+		t.Error("invalid method pattern accepted")
+	}
+	ioutil.WriteFile(file, []byte(`{"clients": {"app": ["eth_sendTransaction"]}}`), 0600)
+	if _, err := LoadAccessPolicy(file); err == nil {
+		t.Error("identity without source prefix accepted")
+	}
+}
+
+func TestServerAccessPolicy(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rpc-policy-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	secret := []byte("secret")
+	srv := NewServer()
+	defer srv.Stop()
+	if err := srv.RegisterName("test", new(Service)); err != nil {
+		t.Fatal(err)
+	}
+	policy := &AccessPolicy{
+		Default: []string{"test_noArgsRets"},
+		Clients: map[string][]string{"jwt:app": {"test_rets"}, "cert:app": {"test_echo"}},
+	}
+	audit := filepath.Join(dir, "audit.log")
+	if err := policy.SetAuditLog(audit); err != nil {
+		t.Fatal(err)
+	}
+	srv.SetAccessPolicy(policy)
+	hs := httptest.NewServer(newHTTPServer(nil, []string{"*"}, DefaultHTTPTimeouts, &TokenAuth{method: jwt.SigningMethodHS256, key: secret}, srv).Handler)
+	defer hs.Close()
+
+	call := func(subject, method string) *jsonError {
+		token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": subject, "exp": time.Now().Add(time.Minute).Unix()}).SignedString(secret)
+		req, _ := http.NewRequest(http.MethodPost, hs.URL, strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"`+method+`"}`))
+		req.Header.Set("content-type", contentType)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		var msg jsonrpcMessage
+		if err := json.NewDecoder(resp.Body).Decode(&msg); err != nil {
+			t.Fatalf("invalid response: %v", err)
+		}
+		return msg.Error
+	}
+	tests := []struct {
+		subject, method string
+		allowed         bool
+	}{
+		{"", "test_noArgsRets", true},
+		{"", "test_rets", false},
+		{"other", "test_rets", false},
+		{"app", "test_rets", true},
+		{"app", "test_echo", false},
+		{"cert:app", "test_echo", false},
+	}
+	for i, tt := range tests {
+		err := call(tt.subject, tt.method)
+		switch {
+		case tt.allowed && err != nil:
+			t.Errorf("test %d: %q calling %s denied: %v", i, tt.subject, tt.method, err.Message)
+		case !tt.allowed && (err == nil || err.Code != -32001):
+			t.Errorf("test %d: %q calling %s not denied: %v", i, tt.subject, tt.method, err)
+		}
+	}
+	// Denied calls are recorded in the audit log
+	blob, err := ioutil.ReadFile(audit)
+	if err != nil {
+		t.Fatalf("failed to read audit log: %v", err)
+	}
+	if !strings.Contains(string(blob), "method=test_echo identity=jwt:app") {
+		t.Errorf("denied call missing from audit log:\n%s", blob)
+	}
+	if strings.Contains(string(blob), "method=test_rets identity=jwt:app") {
+		t.Errorf("allowed call in audit log:\n%s", blob)
+	}
+}
+
+func TestHandlerAccessPolicy(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rpc-policy-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	extra := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("extra"))
+	})
+	tests := []struct {
+		policy string
+		want   int
+	}{
+		{``, http.StatusOK},
+		{`{"default": ["graphql"]}`, http.StatusOK},
+		{`{"default": ["rpc_modules"]}`, http.StatusForbidden},
+	}
+	for i, tt := range tests {
+		var config AuthConfig
+		if tt.policy != "" {
+			config.Policy = filepath.Join(dir, "policy.json")
+			ioutil.WriteFile(config.Policy, []byte(tt.policy), 0600)
+		}
+		listener, srv, err := StartSecureHTTPEndpoint("127.0.0.1:0", nil, nil, nil, []string{"*"}, DefaultHTTPTimeouts, config, map[string]http.Handler{"/graphql": extra})
+		if err != nil {
+			t.Fatalf("test %d: failed to start endpoint: %v", i, err)
+		}
+		resp, err := http.Post("http://"+listener.Addr().String()+"/graphql", "application/json", strings.NewReader("{}"))
+		if err != nil {
+			t.Fatalf("test %d: request failed: %v", i, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.want {
+			t.Errorf("test %d: status mismatch: have %d, want %d", i, resp.StatusCode, tt.want)
+		}
+		listener.Close()
+		srv.Stop()
+	}
+}
@@ -223,6 +223,12 @@ func (s *Server) ServeSingleRequest(ctx context.Context, codec ServerCodec, opti
 	s.serveRequest(ctx, codec, true, options)
 }
 
+// SetAccessPolicy restricts the methods clients may call. It must be called
+// before serving any requests.
+func (s *Server) SetAccessPolicy(policy *AccessPolicy) {
+	s.policy = policy
+}
+
 // Stop will stop reading new requests, wait for stopPendingRequestTimeout to allow pending requests to finish,
 // close all codecs which will cancel pending requests/subscriptions.
 func (s *Server) Stop() {
@@ -257,6 +263,11 @@ func (s *Server) handle(ctx context.Context, codec ServerCodec, req *serverReque
 		return codec.CreateErrorResponse(&req.id, req.err), nil
 	}
 
+	if !req.isUnsubscribe {
+		if err := s.authorize(ctx, req); err != nil {
+			return codec.CreateErrorResponse(&req.id, err), nil
+		}
+	}
 	if req.isUnsubscribe { // cancel subscription, first param must be the subscription id
 		if len(req.args) >= 1 && req.args[0].Kind() == reflect.String {
 			notifier, supported := NotifierFromContext(ctx)
@@ -74,6 +74,8 @@ type Server struct {
 	run      int32
 	codecsMu sync.Mutex
 	codecs   mapset.Set
+
+	policy *AccessPolicy // restricts the callable methods if set
 }
 
 // rpcRequest represents a raw incoming RPC request
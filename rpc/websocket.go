@@ -69,7 +69,10 @@ func (srv *Server) WebsocketHandler(allowedOrigins []string) http.Handler {
 			decoder := func(v interface{}) error {
 				return websocketJSONCodec.Receive(conn, v)
 			}
-			srv.ServeCodec(NewCodec(conn, encoder, decoder), OptionMethodInvocation|OptionSubscriptions)
+			// Serve with the context of the upgrade request to retain the client identity
+			codec := NewCodec(conn, encoder, decoder)
+			defer codec.Close()
+			srv.serveRequest(conn.Request().Context(), codec, false, OptionMethodInvocation|OptionSubscriptions)
 		},
 	}
 }
@@ -78,7 +81,13 @@ func (srv *Server) WebsocketHandler(allowedOrigins []string) http.Handler {
 //
 // Deprecated: use Server.WebsocketHandler
 func NewWSServer(allowedOrigins []string, srv *Server) *http.Server {
-	return &http.Server{Handler: srv.WebsocketHandler(allowedOrigins)}
+	return newWSServer(allowedOrigins, nil, srv)
+}
+
+// newWSServer creates a new websocket RPC server around an API provider. If auth
+// is set, the upgrade request must carry a valid bearer token.
+func newWSServer(allowedOrigins []string, auth *TokenAuth, srv *Server) *http.Server {
+	return &http.Server{Handler: newAuthHandler(auth, srv.WebsocketHandler(allowedOrigins))}
 }
 
 // wsHandshakeValidator returns a handler that verifies the origin during the
@@ -49,6 +49,8 @@ type ExternalAPI interface {
 	SignTransaction(ctx context.Context, args SendTxArgs, methodSelector *string) (*ethapi.SignTransactionResult, error)
 	// Sign - request to sign the given data (plus prefix)
 	Sign(ctx context.Context, addr common.MixedcaseAddress, data hexutil.Bytes) (hexutil.Bytes, error)
+	// SignIstanbul - request to sign an Istanbul consensus message or seal (no prefix)
+	SignIstanbul(ctx context.Context, addr common.MixedcaseAddress, data hexutil.Bytes) (hexutil.Bytes, error)
 	// Export - request to export an account
 	Export(ctx context.Context, addr common.Address) (json.RawMessage, error)
 	// Import - request to import an account
@@ -218,6 +220,13 @@ type (
 
 var ErrRequestDenied = errors.New("Request denied")
 
+// errNotIstanbulMessage is returned by SignIstanbul for data that isn't an
+// Istanbul seal or consensus message.
+var errNotIstanbulMessage = errors.New("data is not an Istanbul seal or message")
+
+// istanbulMsgCommit is the Istanbul message code appended to committed seals.
+const istanbulMsgCommit = 2
+
 // NewSignerAPI creates a new API that can be used for Account management.
 // ksLocation specifies the directory where to store the password protected private
 // key that is generated when a new Account is created.
@@ -548,6 +557,68 @@ func (api *SignerAPI) Sign(ctx context.Context, addr common.MixedcaseAddress, da
 	return signature, nil
 }
 
+// SignIstanbul signs an Istanbul consensus message or block seal. Unlike Sign, the
+// keccak256 hash of the data is signed without a prefix and the signature is
+// returned with V as 0/1, which is what validators put in headers and messages.
+// Only data shaped like a seal hash, a committed seal or an encoded consensus
+// message is accepted, the rest could as well be an unsigned transaction.
+func (api *SignerAPI) SignIstanbul(ctx context.Context, addr common.MixedcaseAddress, data hexutil.Bytes) (hexutil.Bytes, error) {
+	msg, err := istanbulMessage(data)
+	if err != nil {
+		return nil, err
+	}
+	sighash := crypto.Keccak256(data)
+	// We make the request prior to looking up if we actually have the account, to prevent
+	// account-enumeration via the API
+	req := &SignDataRequest{Address: addr, Rawdata: data, Message: msg, Hash: sighash, Meta: MetadataFromContext(ctx)}
+	res, err := api.UI.ApproveSignData(req)
+
+	if err != nil {
+		return nil, err
+	}
+	if !res.Approved {
+		return nil, ErrRequestDenied
+	}
+	// Look up the wallet containing the requested signer
+	account := accounts.Account{Address: addr.Address()}
+	wallet, err := api.am.Find(account)
+	if err != nil {
+		return nil, err
+	}
+	// The requester may have given up waiting for the approval already
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	signature, err := wallet.SignHashWithPassphrase(account, res.Password, sighash)
+	if err != nil {
+		api.UI.ShowError(err.Error())
+		return nil, err
+	}
+	return signature, nil
+}
+
+// istanbulMessage describes the Istanbul payload being signed for the approval
+// request, or returns an error if the data isn't one.
+func istanbulMessage(data []byte) (string, error) {
+	switch {
+	case len(data) == common.HashLength:
+		return fmt.Sprintf("Istanbul block seal %x", data), nil
+	case len(data) == common.HashLength+1 && data[common.HashLength] == istanbulMsgCommit:
+		return fmt.Sprintf("Istanbul committed seal %x", data[:common.HashLength]), nil
+	}
+	var msg struct {
+		Code          uint64
+		Msg           []byte
+		Address       common.Address
+		Signature     []byte
+		CommittedSeal []byte
+	}
+	if err := rlp.DecodeBytes(data, &msg); err != nil || len(msg.Signature) != 0 {
+		return "", errNotIstanbulMessage
+	}
+	return fmt.Sprintf("Istanbul consensus message %d from %s", msg.Code, msg.Address.Hex()), nil
+}
+
 // SignHash is a helper function that calculates a hash for the given message that can be
 // safely used to calculate a signature from.
 //
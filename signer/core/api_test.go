@@ -33,6 +33,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/internal/ethapi"
 	"github.com/ethereum/go-ethereum/rlp"
 )
@@ -284,6 +285,51 @@ func TestSignData(t *testing.T) {
 		t.Errorf("Expected 65 byte signature (got %d bytes)", len(h))
 	}
 }
+
+func TestSignIstanbul(t *testing.T) {
+	api, control := setup(t)
+	createAccount(control, api, t)
+	createAccount(control, api, t)
+	control <- "1"
+	list, err := api.List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := common.NewMixedcaseAddress(list[0])
+
+	// Arbitrary data must be rejected without asking the user
+	if _, err := api.SignIstanbul(context.Background(), a, []byte("EHLO world")); err != errNotIstanbulMessage {
+		t.Errorf("Expected errNotIstanbulMessage! %v", err)
+	}
+	seal := crypto.Keccak256([]byte("header"))
+	for _, data := range [][]byte{seal, append(common.CopyBytes(seal), istanbulMsgCommit)} {
+		control <- "Y"
+		control <- "a_long_password"
+		sig, err := api.SignIstanbul(context.Background(), a, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(sig) != 65 || sig[64] > 1 {
+			t.Fatalf("Expected 65 byte signature with V 0/1, got %x", sig)
+		}
+		pub, err := crypto.SigToPub(crypto.Keccak256(data), sig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if addr := crypto.PubkeyToAddress(*pub); addr != a.Address() {
+			t.Errorf("Signer mismatch: have %x, want %x", addr, a.Address())
+		}
+	}
+	// The request is abandoned if the caller gave up waiting
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	control <- "Y"
+	control <- "a_long_password"
+	if _, err := api.SignIstanbul(ctx, a, seal); err != context.Canceled {
+		t.Errorf("Expected context.Canceled! %v", err)
+	}
+}
+
 func mkTestTx(from common.MixedcaseAddress) SendTxArgs {
 	to := common.NewMixedcaseAddress(common.HexToAddress("0x1337"))
 	gas := hexutil.Uint64(21000)